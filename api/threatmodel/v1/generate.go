@@ -0,0 +1,5 @@
+// Package threatmodelv1 holds the protobuf messages and gRPC stubs of the
+// threat model gRPC API, generated from threatmodel.proto.
+package threatmodelv1
+
+//go:generate protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative --go-grpc_out=../../.. --go-grpc_opt=paths=source_relative api/threatmodel/v1/threatmodel.proto
@@ -0,0 +1,882 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v4.23.4
+// source: api/threatmodel/v1/threatmodel.proto
+
+// The gRPC equivalent of the threat model REST API, for internal services.
+// Callers authenticate as they would over REST, by sending their token in
+// the "authorization" metadata as "Bearer <token>"; service accounts name
+// the tenant they act on in "x-tenant-id".
+
+package threatmodelv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Threat struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Title       string `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Description string `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+}
+
+func (x *Threat) Reset() {
+	*x = Threat{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Threat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Threat) ProtoMessage() {}
+
+func (x *Threat) ProtoReflect() protoreflect.Message {
+	mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Threat.ProtoReflect.Descriptor instead.
+func (*Threat) Descriptor() ([]byte, []int) {
+	return file_api_threatmodel_v1_threatmodel_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Threat) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Threat) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type ThreatModel struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ThreatModelId     string    `protobuf:"bytes,1,opt,name=threat_model_id,json=threatModelId,proto3" json:"threat_model_id,omitempty"`
+	DataFlowDiagramId string    `protobuf:"bytes,2,opt,name=data_flow_diagram_id,json=dataFlowDiagramId,proto3" json:"data_flow_diagram_id,omitempty"`
+	Title             string    `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Description       string    `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Threats           []*Threat `protobuf:"bytes,5,rep,name=threats,proto3" json:"threats,omitempty"`
+}
+
+func (x *ThreatModel) Reset() {
+	*x = ThreatModel{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ThreatModel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThreatModel) ProtoMessage() {}
+
+func (x *ThreatModel) ProtoReflect() protoreflect.Message {
+	mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThreatModel.ProtoReflect.Descriptor instead.
+func (*ThreatModel) Descriptor() ([]byte, []int) {
+	return file_api_threatmodel_v1_threatmodel_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ThreatModel) GetThreatModelId() string {
+	if x != nil {
+		return x.ThreatModelId
+	}
+	return ""
+}
+
+func (x *ThreatModel) GetDataFlowDiagramId() string {
+	if x != nil {
+		return x.DataFlowDiagramId
+	}
+	return ""
+}
+
+func (x *ThreatModel) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ThreatModel) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ThreatModel) GetThreats() []*Threat {
+	if x != nil {
+		return x.Threats
+	}
+	return nil
+}
+
+type ThreatModels struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ThreatModels []*ThreatModel `protobuf:"bytes,1,rep,name=threat_models,json=threatModels,proto3" json:"threat_models,omitempty"`
+}
+
+func (x *ThreatModels) Reset() {
+	*x = ThreatModels{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ThreatModels) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThreatModels) ProtoMessage() {}
+
+func (x *ThreatModels) ProtoReflect() protoreflect.Message {
+	mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThreatModels.ProtoReflect.Descriptor instead.
+func (*ThreatModels) Descriptor() ([]byte, []int) {
+	return file_api_threatmodel_v1_threatmodel_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ThreatModels) GetThreatModels() []*ThreatModel {
+	if x != nil {
+		return x.ThreatModels
+	}
+	return nil
+}
+
+// The fields of a threat model that callers may set. Unset fields are left
+// unchanged on update.
+type ThreatModelParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DataFlowDiagramId *string `protobuf:"bytes,1,opt,name=data_flow_diagram_id,json=dataFlowDiagramId,proto3,oneof" json:"data_flow_diagram_id,omitempty"`
+	Title             *string `protobuf:"bytes,2,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	Description       *string `protobuf:"bytes,3,opt,name=description,proto3,oneof" json:"description,omitempty"`
+}
+
+func (x *ThreatModelParams) Reset() {
+	*x = ThreatModelParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ThreatModelParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThreatModelParams) ProtoMessage() {}
+
+func (x *ThreatModelParams) ProtoReflect() protoreflect.Message {
+	mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThreatModelParams.ProtoReflect.Descriptor instead.
+func (*ThreatModelParams) Descriptor() ([]byte, []int) {
+	return file_api_threatmodel_v1_threatmodel_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ThreatModelParams) GetDataFlowDiagramId() string {
+	if x != nil && x.DataFlowDiagramId != nil {
+		return *x.DataFlowDiagramId
+	}
+	return ""
+}
+
+func (x *ThreatModelParams) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+func (x *ThreatModelParams) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+type GetThreatModelRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ThreatModelId string `protobuf:"bytes,1,opt,name=threat_model_id,json=threatModelId,proto3" json:"threat_model_id,omitempty"`
+}
+
+func (x *GetThreatModelRequest) Reset() {
+	*x = GetThreatModelRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetThreatModelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetThreatModelRequest) ProtoMessage() {}
+
+func (x *GetThreatModelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetThreatModelRequest.ProtoReflect.Descriptor instead.
+func (*GetThreatModelRequest) Descriptor() ([]byte, []int) {
+	return file_api_threatmodel_v1_threatmodel_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetThreatModelRequest) GetThreatModelId() string {
+	if x != nil {
+		return x.ThreatModelId
+	}
+	return ""
+}
+
+type GetAllThreatModelsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetAllThreatModelsRequest) Reset() {
+	*x = GetAllThreatModelsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetAllThreatModelsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllThreatModelsRequest) ProtoMessage() {}
+
+func (x *GetAllThreatModelsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllThreatModelsRequest.ProtoReflect.Descriptor instead.
+func (*GetAllThreatModelsRequest) Descriptor() ([]byte, []int) {
+	return file_api_threatmodel_v1_threatmodel_proto_rawDescGZIP(), []int{5}
+}
+
+type QueryThreatModelsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DataFlowDiagramId *string `protobuf:"bytes,1,opt,name=data_flow_diagram_id,json=dataFlowDiagramId,proto3,oneof" json:"data_flow_diagram_id,omitempty"`
+	Title             *string `protobuf:"bytes,2,opt,name=title,proto3,oneof" json:"title,omitempty"`
+}
+
+func (x *QueryThreatModelsRequest) Reset() {
+	*x = QueryThreatModelsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryThreatModelsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryThreatModelsRequest) ProtoMessage() {}
+
+func (x *QueryThreatModelsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryThreatModelsRequest.ProtoReflect.Descriptor instead.
+func (*QueryThreatModelsRequest) Descriptor() ([]byte, []int) {
+	return file_api_threatmodel_v1_threatmodel_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *QueryThreatModelsRequest) GetDataFlowDiagramId() string {
+	if x != nil && x.DataFlowDiagramId != nil {
+		return *x.DataFlowDiagramId
+	}
+	return ""
+}
+
+func (x *QueryThreatModelsRequest) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+type CreateThreatModelRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Params *ThreatModelParams `protobuf:"bytes,1,opt,name=params,proto3" json:"params,omitempty"`
+}
+
+func (x *CreateThreatModelRequest) Reset() {
+	*x = CreateThreatModelRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateThreatModelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateThreatModelRequest) ProtoMessage() {}
+
+func (x *CreateThreatModelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateThreatModelRequest.ProtoReflect.Descriptor instead.
+func (*CreateThreatModelRequest) Descriptor() ([]byte, []int) {
+	return file_api_threatmodel_v1_threatmodel_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreateThreatModelRequest) GetParams() *ThreatModelParams {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+type UpdateThreatModelRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ThreatModelId string             `protobuf:"bytes,1,opt,name=threat_model_id,json=threatModelId,proto3" json:"threat_model_id,omitempty"`
+	Params        *ThreatModelParams `protobuf:"bytes,2,opt,name=params,proto3" json:"params,omitempty"`
+}
+
+func (x *UpdateThreatModelRequest) Reset() {
+	*x = UpdateThreatModelRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateThreatModelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateThreatModelRequest) ProtoMessage() {}
+
+func (x *UpdateThreatModelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateThreatModelRequest.ProtoReflect.Descriptor instead.
+func (*UpdateThreatModelRequest) Descriptor() ([]byte, []int) {
+	return file_api_threatmodel_v1_threatmodel_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateThreatModelRequest) GetThreatModelId() string {
+	if x != nil {
+		return x.ThreatModelId
+	}
+	return ""
+}
+
+func (x *UpdateThreatModelRequest) GetParams() *ThreatModelParams {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+type DeleteThreatModelRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ThreatModelId string `protobuf:"bytes,1,opt,name=threat_model_id,json=threatModelId,proto3" json:"threat_model_id,omitempty"`
+}
+
+func (x *DeleteThreatModelRequest) Reset() {
+	*x = DeleteThreatModelRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteThreatModelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteThreatModelRequest) ProtoMessage() {}
+
+func (x *DeleteThreatModelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_threatmodel_v1_threatmodel_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteThreatModelRequest.ProtoReflect.Descriptor instead.
+func (*DeleteThreatModelRequest) Descriptor() ([]byte, []int) {
+	return file_api_threatmodel_v1_threatmodel_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteThreatModelRequest) GetThreatModelId() string {
+	if x != nil {
+		return x.ThreatModelId
+	}
+	return ""
+}
+
+var File_api_threatmodel_v1_threatmodel_proto protoreflect.FileDescriptor
+
+var file_api_threatmodel_v1_threatmodel_proto_rawDesc = []byte{
+	0x0a, 0x24, 0x61, 0x70, 0x69, 0x2f, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d, 0x6f, 0x64, 0x65,
+	0x6c, 0x2f, 0x76, 0x31, 0x2f, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d, 0x6f, 0x64, 0x65, 0x6c,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d, 0x6f,
+	0x64, 0x65, 0x6c, 0x2e, 0x76, 0x31, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0x40, 0x0a, 0x06, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x12, 0x14, 0x0a,
+	0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69,
+	0x74, 0x6c, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xd0, 0x01, 0x0a, 0x0b, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74,
+	0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x26, 0x0a, 0x0f, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x5f,
+	0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d,
+	0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x2f, 0x0a,
+	0x14, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x66, 0x6c, 0x6f, 0x77, 0x5f, 0x64, 0x69, 0x61, 0x67, 0x72,
+	0x61, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x64, 0x61, 0x74,
+	0x61, 0x46, 0x6c, 0x6f, 0x77, 0x44, 0x69, 0x61, 0x67, 0x72, 0x61, 0x6d, 0x49, 0x64, 0x12, 0x14,
+	0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74,
+	0x69, 0x74, 0x6c, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x30, 0x0a, 0x07, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74,
+	0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74,
+	0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x52,
+	0x07, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x73, 0x22, 0x50, 0x0a, 0x0c, 0x54, 0x68, 0x72, 0x65,
+	0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x12, 0x40, 0x0a, 0x0d, 0x74, 0x68, 0x72, 0x65,
+	0x61, 0x74, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x76, 0x31,
+	0x2e, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x0c, 0x74, 0x68,
+	0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x22, 0xbe, 0x01, 0x0a, 0x11, 0x54,
+	0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73,
+	0x12, 0x34, 0x0a, 0x14, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x66, 0x6c, 0x6f, 0x77, 0x5f, 0x64, 0x69,
+	0x61, 0x67, 0x72, 0x61, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00,
+	0x52, 0x11, 0x64, 0x61, 0x74, 0x61, 0x46, 0x6c, 0x6f, 0x77, 0x44, 0x69, 0x61, 0x67, 0x72, 0x61,
+	0x6d, 0x49, 0x64, 0x88, 0x01, 0x01, 0x12, 0x19, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x88, 0x01,
+	0x01, 0x12, 0x25, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x02, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x64, 0x61, 0x74,
+	0x61, 0x5f, 0x66, 0x6c, 0x6f, 0x77, 0x5f, 0x64, 0x69, 0x61, 0x67, 0x72, 0x61, 0x6d, 0x5f, 0x69,
+	0x64, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x42, 0x0e, 0x0a, 0x0c, 0x5f,
+	0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x3f, 0x0a, 0x15, 0x47,
+	0x65, 0x74, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x5f, 0x6d,
+	0x6f, 0x64, 0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74,
+	0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x49, 0x64, 0x22, 0x1b, 0x0a, 0x19,
+	0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65,
+	0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x8e, 0x01, 0x0a, 0x18, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x34, 0x0a, 0x14, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x66,
+	0x6c, 0x6f, 0x77, 0x5f, 0x64, 0x69, 0x61, 0x67, 0x72, 0x61, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x11, 0x64, 0x61, 0x74, 0x61, 0x46, 0x6c, 0x6f, 0x77,
+	0x44, 0x69, 0x61, 0x67, 0x72, 0x61, 0x6d, 0x49, 0x64, 0x88, 0x01, 0x01, 0x12, 0x19, 0x0a, 0x05,
+	0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x05, 0x74,
+	0x69, 0x74, 0x6c, 0x65, 0x88, 0x01, 0x01, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x64, 0x61, 0x74, 0x61,
+	0x5f, 0x66, 0x6c, 0x6f, 0x77, 0x5f, 0x64, 0x69, 0x61, 0x67, 0x72, 0x61, 0x6d, 0x5f, 0x69, 0x64,
+	0x42, 0x08, 0x0a, 0x06, 0x5f, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x22, 0x55, 0x0a, 0x18, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x39, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d,
+	0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f,
+	0x64, 0x65, 0x6c, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d,
+	0x73, 0x22, 0x7d, 0x0a, 0x18, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x68, 0x72, 0x65, 0x61,
+	0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a,
+	0x0f, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f,
+	0x64, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x39, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d, 0x6f,
+	0x64, 0x65, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64,
+	0x65, 0x6c, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73,
+	0x22, 0x42, 0x0a, 0x18, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74,
+	0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x26, 0x0a, 0x0f,
+	0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64,
+	0x65, 0x6c, 0x49, 0x64, 0x32, 0xc2, 0x04, 0x0a, 0x12, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d,
+	0x6f, 0x64, 0x65, 0x6c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x49, 0x0a, 0x03, 0x47,
+	0x65, 0x74, 0x12, 0x25, 0x2e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d, 0x6f, 0x64, 0x65, 0x6c,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64,
+	0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x74, 0x68, 0x72, 0x65,
+	0x61, 0x74, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x68, 0x72, 0x65, 0x61,
+	0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x51, 0x0a, 0x06, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c,
+	0x12, 0x29, 0x2e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x76,
+	0x31, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x6c, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f,
+	0x64, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x74, 0x68,
+	0x72, 0x65, 0x61, 0x74, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x68, 0x72,
+	0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x12, 0x4f, 0x0a, 0x05, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x12, 0x28, 0x2e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d, 0x6f, 0x64, 0x65, 0x6c,
+	0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d,
+	0x6f, 0x64, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x74,
+	0x68, 0x72, 0x65, 0x61, 0x74, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x68,
+	0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x73, 0x12, 0x4f, 0x0a, 0x06, 0x43, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x12, 0x28, 0x2e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d, 0x6f, 0x64,
+	0x65, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x54, 0x68, 0x72, 0x65,
+	0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b,
+	0x2e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x76, 0x31, 0x2e,
+	0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x4f, 0x0a, 0x06, 0x55,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x28, 0x2e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d, 0x6f,
+	0x64, 0x65, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x54, 0x68, 0x72,
+	0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1b, 0x2e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x76, 0x31,
+	0x2e, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x4a, 0x0a, 0x06,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x28, 0x2e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d,
+	0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x68,
+	0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x4f, 0x0a, 0x04, 0x4c, 0x69, 0x73, 0x74,
+	0x12, 0x28, 0x2e, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x76,
+	0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x54, 0x68, 0x72, 0x65, 0x61, 0x74, 0x4d, 0x6f, 0x64,
+	0x65, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x74, 0x68, 0x72,
+	0x65, 0x61, 0x74, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x68, 0x72, 0x65,
+	0x61, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x30, 0x01, 0x42, 0x4b, 0x5a, 0x49, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6a, 0x74, 0x79, 0x65, 0x72, 0x73, 0x2f, 0x74,
+	0x6d, 0x61, 0x61, 0x73, 0x2d, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x2d, 0x6d, 0x6f, 0x64, 0x65,
+	0x6c, 0x2d, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74,
+	0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2f, 0x76, 0x31, 0x3b, 0x74, 0x68, 0x72, 0x65, 0x61, 0x74, 0x6d,
+	0x6f, 0x64, 0x65, 0x6c, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_api_threatmodel_v1_threatmodel_proto_rawDescOnce sync.Once
+	file_api_threatmodel_v1_threatmodel_proto_rawDescData = file_api_threatmodel_v1_threatmodel_proto_rawDesc
+)
+
+func file_api_threatmodel_v1_threatmodel_proto_rawDescGZIP() []byte {
+	file_api_threatmodel_v1_threatmodel_proto_rawDescOnce.Do(func() {
+		file_api_threatmodel_v1_threatmodel_proto_rawDescData = protoimpl.X.CompressGZIP(file_api_threatmodel_v1_threatmodel_proto_rawDescData)
+	})
+	return file_api_threatmodel_v1_threatmodel_proto_rawDescData
+}
+
+var file_api_threatmodel_v1_threatmodel_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_api_threatmodel_v1_threatmodel_proto_goTypes = []interface{}{
+	(*Threat)(nil),                    // 0: threatmodel.v1.Threat
+	(*ThreatModel)(nil),               // 1: threatmodel.v1.ThreatModel
+	(*ThreatModels)(nil),              // 2: threatmodel.v1.ThreatModels
+	(*ThreatModelParams)(nil),         // 3: threatmodel.v1.ThreatModelParams
+	(*GetThreatModelRequest)(nil),     // 4: threatmodel.v1.GetThreatModelRequest
+	(*GetAllThreatModelsRequest)(nil), // 5: threatmodel.v1.GetAllThreatModelsRequest
+	(*QueryThreatModelsRequest)(nil),  // 6: threatmodel.v1.QueryThreatModelsRequest
+	(*CreateThreatModelRequest)(nil),  // 7: threatmodel.v1.CreateThreatModelRequest
+	(*UpdateThreatModelRequest)(nil),  // 8: threatmodel.v1.UpdateThreatModelRequest
+	(*DeleteThreatModelRequest)(nil),  // 9: threatmodel.v1.DeleteThreatModelRequest
+	(*emptypb.Empty)(nil),             // 10: google.protobuf.Empty
+}
+var file_api_threatmodel_v1_threatmodel_proto_depIdxs = []int32{
+	0,  // 0: threatmodel.v1.ThreatModel.threats:type_name -> threatmodel.v1.Threat
+	1,  // 1: threatmodel.v1.ThreatModels.threat_models:type_name -> threatmodel.v1.ThreatModel
+	3,  // 2: threatmodel.v1.CreateThreatModelRequest.params:type_name -> threatmodel.v1.ThreatModelParams
+	3,  // 3: threatmodel.v1.UpdateThreatModelRequest.params:type_name -> threatmodel.v1.ThreatModelParams
+	4,  // 4: threatmodel.v1.ThreatModelService.Get:input_type -> threatmodel.v1.GetThreatModelRequest
+	5,  // 5: threatmodel.v1.ThreatModelService.GetAll:input_type -> threatmodel.v1.GetAllThreatModelsRequest
+	6,  // 6: threatmodel.v1.ThreatModelService.Query:input_type -> threatmodel.v1.QueryThreatModelsRequest
+	7,  // 7: threatmodel.v1.ThreatModelService.Create:input_type -> threatmodel.v1.CreateThreatModelRequest
+	8,  // 8: threatmodel.v1.ThreatModelService.Update:input_type -> threatmodel.v1.UpdateThreatModelRequest
+	9,  // 9: threatmodel.v1.ThreatModelService.Delete:input_type -> threatmodel.v1.DeleteThreatModelRequest
+	6,  // 10: threatmodel.v1.ThreatModelService.List:input_type -> threatmodel.v1.QueryThreatModelsRequest
+	1,  // 11: threatmodel.v1.ThreatModelService.Get:output_type -> threatmodel.v1.ThreatModel
+	2,  // 12: threatmodel.v1.ThreatModelService.GetAll:output_type -> threatmodel.v1.ThreatModels
+	2,  // 13: threatmodel.v1.ThreatModelService.Query:output_type -> threatmodel.v1.ThreatModels
+	1,  // 14: threatmodel.v1.ThreatModelService.Create:output_type -> threatmodel.v1.ThreatModel
+	1,  // 15: threatmodel.v1.ThreatModelService.Update:output_type -> threatmodel.v1.ThreatModel
+	10, // 16: threatmodel.v1.ThreatModelService.Delete:output_type -> google.protobuf.Empty
+	1,  // 17: threatmodel.v1.ThreatModelService.List:output_type -> threatmodel.v1.ThreatModel
+	11, // [11:18] is the sub-list for method output_type
+	4,  // [4:11] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_api_threatmodel_v1_threatmodel_proto_init() }
+func file_api_threatmodel_v1_threatmodel_proto_init() {
+	if File_api_threatmodel_v1_threatmodel_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_api_threatmodel_v1_threatmodel_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Threat); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_threatmodel_v1_threatmodel_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ThreatModel); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_threatmodel_v1_threatmodel_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ThreatModels); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_threatmodel_v1_threatmodel_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ThreatModelParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_threatmodel_v1_threatmodel_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetThreatModelRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_threatmodel_v1_threatmodel_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetAllThreatModelsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_threatmodel_v1_threatmodel_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryThreatModelsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_threatmodel_v1_threatmodel_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateThreatModelRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_threatmodel_v1_threatmodel_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateThreatModelRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_threatmodel_v1_threatmodel_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteThreatModelRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_api_threatmodel_v1_threatmodel_proto_msgTypes[3].OneofWrappers = []interface{}{}
+	file_api_threatmodel_v1_threatmodel_proto_msgTypes[6].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_api_threatmodel_v1_threatmodel_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_threatmodel_v1_threatmodel_proto_goTypes,
+		DependencyIndexes: file_api_threatmodel_v1_threatmodel_proto_depIdxs,
+		MessageInfos:      file_api_threatmodel_v1_threatmodel_proto_msgTypes,
+	}.Build()
+	File_api_threatmodel_v1_threatmodel_proto = out.File
+	file_api_threatmodel_v1_threatmodel_proto_rawDesc = nil
+	file_api_threatmodel_v1_threatmodel_proto_goTypes = nil
+	file_api_threatmodel_v1_threatmodel_proto_depIdxs = nil
+}
@@ -0,0 +1,381 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.23.4
+// source: api/threatmodel/v1/threatmodel.proto
+
+// The gRPC equivalent of the threat model REST API, for internal services.
+// Callers authenticate as they would over REST, by sending their token in
+// the "authorization" metadata as "Bearer <token>"; service accounts name
+// the tenant they act on in "x-tenant-id".
+
+package threatmodelv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ThreatModelService_Get_FullMethodName    = "/threatmodel.v1.ThreatModelService/Get"
+	ThreatModelService_GetAll_FullMethodName = "/threatmodel.v1.ThreatModelService/GetAll"
+	ThreatModelService_Query_FullMethodName  = "/threatmodel.v1.ThreatModelService/Query"
+	ThreatModelService_Create_FullMethodName = "/threatmodel.v1.ThreatModelService/Create"
+	ThreatModelService_Update_FullMethodName = "/threatmodel.v1.ThreatModelService/Update"
+	ThreatModelService_Delete_FullMethodName = "/threatmodel.v1.ThreatModelService/Delete"
+	ThreatModelService_List_FullMethodName   = "/threatmodel.v1.ThreatModelService/List"
+)
+
+// ThreatModelServiceClient is the client API for ThreatModelService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ThreatModelServiceClient interface {
+	// Retrieve a threat model by ID.
+	Get(ctx context.Context, in *GetThreatModelRequest, opts ...grpc.CallOption) (*ThreatModel, error)
+	// Retrieve all threat models.
+	GetAll(ctx context.Context, in *GetAllThreatModelsRequest, opts ...grpc.CallOption) (*ThreatModels, error)
+	// Retrieve the threat models matching every field set in the request.
+	Query(ctx context.Context, in *QueryThreatModelsRequest, opts ...grpc.CallOption) (*ThreatModels, error)
+	// Create a threat model.
+	Create(ctx context.Context, in *CreateThreatModelRequest, opts ...grpc.CallOption) (*ThreatModel, error)
+	// Update the fields of a threat model set in the request.
+	Update(ctx context.Context, in *UpdateThreatModelRequest, opts ...grpc.CallOption) (*ThreatModel, error)
+	// Delete a threat model by ID.
+	Delete(ctx context.Context, in *DeleteThreatModelRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Stream the threat models matching every field set in the request, or
+	// all threat models if none are set.
+	List(ctx context.Context, in *QueryThreatModelsRequest, opts ...grpc.CallOption) (ThreatModelService_ListClient, error)
+}
+
+type threatModelServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewThreatModelServiceClient(cc grpc.ClientConnInterface) ThreatModelServiceClient {
+	return &threatModelServiceClient{cc}
+}
+
+func (c *threatModelServiceClient) Get(ctx context.Context, in *GetThreatModelRequest, opts ...grpc.CallOption) (*ThreatModel, error) {
+	out := new(ThreatModel)
+	err := c.cc.Invoke(ctx, ThreatModelService_Get_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *threatModelServiceClient) GetAll(ctx context.Context, in *GetAllThreatModelsRequest, opts ...grpc.CallOption) (*ThreatModels, error) {
+	out := new(ThreatModels)
+	err := c.cc.Invoke(ctx, ThreatModelService_GetAll_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *threatModelServiceClient) Query(ctx context.Context, in *QueryThreatModelsRequest, opts ...grpc.CallOption) (*ThreatModels, error) {
+	out := new(ThreatModels)
+	err := c.cc.Invoke(ctx, ThreatModelService_Query_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *threatModelServiceClient) Create(ctx context.Context, in *CreateThreatModelRequest, opts ...grpc.CallOption) (*ThreatModel, error) {
+	out := new(ThreatModel)
+	err := c.cc.Invoke(ctx, ThreatModelService_Create_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *threatModelServiceClient) Update(ctx context.Context, in *UpdateThreatModelRequest, opts ...grpc.CallOption) (*ThreatModel, error) {
+	out := new(ThreatModel)
+	err := c.cc.Invoke(ctx, ThreatModelService_Update_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *threatModelServiceClient) Delete(ctx context.Context, in *DeleteThreatModelRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, ThreatModelService_Delete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *threatModelServiceClient) List(ctx context.Context, in *QueryThreatModelsRequest, opts ...grpc.CallOption) (ThreatModelService_ListClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ThreatModelService_ServiceDesc.Streams[0], ThreatModelService_List_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &threatModelServiceListClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ThreatModelService_ListClient interface {
+	Recv() (*ThreatModel, error)
+	grpc.ClientStream
+}
+
+type threatModelServiceListClient struct {
+	grpc.ClientStream
+}
+
+func (x *threatModelServiceListClient) Recv() (*ThreatModel, error) {
+	m := new(ThreatModel)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ThreatModelServiceServer is the server API for ThreatModelService service.
+// All implementations must embed UnimplementedThreatModelServiceServer
+// for forward compatibility
+type ThreatModelServiceServer interface {
+	// Retrieve a threat model by ID.
+	Get(context.Context, *GetThreatModelRequest) (*ThreatModel, error)
+	// Retrieve all threat models.
+	GetAll(context.Context, *GetAllThreatModelsRequest) (*ThreatModels, error)
+	// Retrieve the threat models matching every field set in the request.
+	Query(context.Context, *QueryThreatModelsRequest) (*ThreatModels, error)
+	// Create a threat model.
+	Create(context.Context, *CreateThreatModelRequest) (*ThreatModel, error)
+	// Update the fields of a threat model set in the request.
+	Update(context.Context, *UpdateThreatModelRequest) (*ThreatModel, error)
+	// Delete a threat model by ID.
+	Delete(context.Context, *DeleteThreatModelRequest) (*emptypb.Empty, error)
+	// Stream the threat models matching every field set in the request, or
+	// all threat models if none are set.
+	List(*QueryThreatModelsRequest, ThreatModelService_ListServer) error
+	mustEmbedUnimplementedThreatModelServiceServer()
+}
+
+// UnimplementedThreatModelServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedThreatModelServiceServer struct {
+}
+
+func (UnimplementedThreatModelServiceServer) Get(context.Context, *GetThreatModelRequest) (*ThreatModel, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedThreatModelServiceServer) GetAll(context.Context, *GetAllThreatModelsRequest) (*ThreatModels, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAll not implemented")
+}
+func (UnimplementedThreatModelServiceServer) Query(context.Context, *QueryThreatModelsRequest) (*ThreatModels, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (UnimplementedThreatModelServiceServer) Create(context.Context, *CreateThreatModelRequest) (*ThreatModel, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedThreatModelServiceServer) Update(context.Context, *UpdateThreatModelRequest) (*ThreatModel, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedThreatModelServiceServer) Delete(context.Context, *DeleteThreatModelRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedThreatModelServiceServer) List(*QueryThreatModelsRequest, ThreatModelService_ListServer) error {
+	return status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedThreatModelServiceServer) mustEmbedUnimplementedThreatModelServiceServer() {}
+
+// UnsafeThreatModelServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ThreatModelServiceServer will
+// result in compilation errors.
+type UnsafeThreatModelServiceServer interface {
+	mustEmbedUnimplementedThreatModelServiceServer()
+}
+
+func RegisterThreatModelServiceServer(s grpc.ServiceRegistrar, srv ThreatModelServiceServer) {
+	s.RegisterService(&ThreatModelService_ServiceDesc, srv)
+}
+
+func _ThreatModelService_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetThreatModelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThreatModelServiceServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThreatModelService_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThreatModelServiceServer).Get(ctx, req.(*GetThreatModelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThreatModelService_GetAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAllThreatModelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThreatModelServiceServer).GetAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThreatModelService_GetAll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThreatModelServiceServer).GetAll(ctx, req.(*GetAllThreatModelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThreatModelService_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryThreatModelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThreatModelServiceServer).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThreatModelService_Query_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThreatModelServiceServer).Query(ctx, req.(*QueryThreatModelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThreatModelService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateThreatModelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThreatModelServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThreatModelService_Create_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThreatModelServiceServer).Create(ctx, req.(*CreateThreatModelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThreatModelService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateThreatModelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThreatModelServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThreatModelService_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThreatModelServiceServer).Update(ctx, req.(*UpdateThreatModelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThreatModelService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteThreatModelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThreatModelServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThreatModelService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThreatModelServiceServer).Delete(ctx, req.(*DeleteThreatModelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThreatModelService_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(QueryThreatModelsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ThreatModelServiceServer).List(m, &threatModelServiceListServer{stream})
+}
+
+type ThreatModelService_ListServer interface {
+	Send(*ThreatModel) error
+	grpc.ServerStream
+}
+
+type threatModelServiceListServer struct {
+	grpc.ServerStream
+}
+
+func (x *threatModelServiceListServer) Send(m *ThreatModel) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// ThreatModelService_ServiceDesc is the grpc.ServiceDesc for ThreatModelService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ThreatModelService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "threatmodel.v1.ThreatModelService",
+	HandlerType: (*ThreatModelServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _ThreatModelService_Get_Handler,
+		},
+		{
+			MethodName: "GetAll",
+			Handler:    _ThreatModelService_GetAll_Handler,
+		},
+		{
+			MethodName: "Query",
+			Handler:    _ThreatModelService_Query_Handler,
+		},
+		{
+			MethodName: "Create",
+			Handler:    _ThreatModelService_Create_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _ThreatModelService_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _ThreatModelService_Delete_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _ThreatModelService_List_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/threatmodel/v1/threatmodel.proto",
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/jtyers/tmaas-threat-model-api/events"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	"github.com/jtyers/tmaas-threat-model-api/outbox"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+	"google.golang.org/grpc"
+)
+
+// App holds the APIs main serves, and the event consumers and publishers
+// it runs.
+type App struct {
+	// The REST API.
+	Handler http.Handler
+
+	// The gRPC API, served if GRPC_PORT is set.
+	GRPCServer *grpc.Server
+
+	// Consumes data flow diagram events, if DFD_EVENTS_SUBSCRIPTION is
+	// set; nil otherwise.
+	DataFlowDiagramEvents *events.Consumer
+
+	// Publishes threat model change events from the outbox, if
+	// OUTBOX_TOPIC is set; nil otherwise.
+	OutboxRelay *outbox.Relay
+
+	// Writes and links the audit records of the APIs.
+	AuditWriter *service.AuditWriter
+
+	// The metrics, served if METRICS_PORT is set, and the job keeping the
+	// threat model count among them up to date.
+	Metrics            *metrics.Metrics
+	ThreatModelCounter *service.ThreatModelCounter
+}
+
+func NewApp(handler http.Handler, grpcServer *grpc.Server, dfdEvents *events.Consumer, outboxRelay *outbox.Relay, auditWriter *service.AuditWriter, metrics *metrics.Metrics, threatModelCounter *service.ThreatModelCounter) *App {
+	return &App{Handler: handler, GRPCServer: grpcServer, DataFlowDiagramEvents: dfdEvents, OutboxRelay: outboxRelay, AuditWriter: auditWriter, Metrics: metrics, ThreatModelCounter: threatModelCounter}
+}
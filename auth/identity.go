@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	jwt "github.com/jtyers/gin-jwt/v2"
+	m "github.com/jtyers/tmaas-model"
+)
+
+var (
+	ErrNoIdentity = errors.New("no identity in context")
+)
+
+var (
+	// The claim holding a Firebase user's ID
+	UserIDClaim = "user_id"
+
+	// The claim holding a Google service account's email address
+	EmailClaim = "email"
+
+	// The custom claim marking a user as an administrator of their tenant
+	AdminClaim = "admin"
+
+	serviceAccountEmailSuffix = ".iam.gserviceaccount.com"
+)
+
+// UserIDExpr matches a user ID: Firebase UIDs are 1-128 letters, digits,
+// '-' or '_'. It is unanchored, for use within other expressions.
+const UserIDExpr = `[A-Za-z0-9_-]{1,128}`
+
+// Identity describes the caller of an API, as derived from the JWT
+// claims placed into the context by ComboMiddlewareFactory.ExtractTokensToContext.
+// Exactly one of UserID or ServiceAccountName is set.
+type Identity struct {
+	UserID             m.UserID
+	ServiceAccountName string
+
+	// The organisation the caller belongs to, or for service accounts,
+	// is acting on. Empty if neither the token nor TenantConfig gives one,
+	// in which case no tenant data is accessible.
+	TenantID TenantID
+
+	// Set for users granted the admin claim. Service accounts are always
+	// treated as administrators; see IsAdmin.
+	Admin bool
+}
+
+// IsServiceAccount returns true if the identity is a service account
+// rather than a user.
+func (i *Identity) IsServiceAccount() bool {
+	return i.ServiceAccountName != ""
+}
+
+// IsAdmin returns true if the identity may call administrative APIs.
+func (i *Identity) IsAdmin() bool {
+	return i.Admin || i.IsServiceAccount()
+}
+
+// String returns the user ID or service account name, whichever is set.
+func (i *Identity) String() string {
+	if i.IsServiceAccount() {
+		return i.ServiceAccountName
+	}
+	return string(i.UserID)
+}
+
+type identityContextKey struct{}
+
+// WithIdentity returns a copy of ctx carrying the given identity.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey{}, identity)
+}
+
+// IdentityFromContext returns the identity placed into ctx by WithIdentity,
+// or ErrNoIdentity if there is none.
+func IdentityFromContext(ctx context.Context) (*Identity, error) {
+	identity, ok := ctx.Value(identityContextKey{}).(*Identity)
+	if !ok || identity == nil {
+		return nil, ErrNoIdentity
+	}
+	return identity, nil
+}
+
+// IdentityExtractor derives the caller's identity from a request.
+type IdentityExtractor interface {
+	// Extract the identity of the caller, returning ErrNoIdentity if
+	// the request carries no recognisable identity.
+	Extract(c *gin.Context) (*Identity, error)
+}
+
+// ClaimsIdentityExtractor reads the identity from the JWT claims of
+// the request.
+type ClaimsIdentityExtractor struct {
+	config TenantConfig
+}
+
+var _ IdentityExtractor = (*ClaimsIdentityExtractor)(nil)
+
+func NewClaimsIdentityExtractor(config TenantConfig) *ClaimsIdentityExtractor {
+	return &ClaimsIdentityExtractor{config}
+}
+
+func (e *ClaimsIdentityExtractor) Extract(c *gin.Context) (*Identity, error) {
+	claims := jwt.ExtractClaims(c)
+
+	var identity *Identity
+
+	if email, ok := claims[EmailClaim].(string); ok && strings.HasSuffix(email, serviceAccountEmailSuffix) {
+		name, _, _ := strings.Cut(email, "@")
+		identity = &Identity{ServiceAccountName: name}
+
+	} else if userID, ok := claims[UserIDClaim].(string); ok && userID != "" {
+		identity = &Identity{UserID: m.UserID(userID)}
+		identity.Admin, _ = claims[AdminClaim].(bool)
+
+	} else {
+		return nil, ErrNoIdentity
+	}
+
+	tenantID, err := extractTenantID(c, claims, e.config, identity.ServiceAccountName)
+	if err != nil {
+		return nil, err
+	}
+	identity.TenantID = tenantID
+
+	return identity, nil
+}
+
+// UserIDFromContext returns the user ID of the identity in ctx. It returns
+// ErrNoIdentity if there is no identity, or if the identity is a service
+// account.
+func UserIDFromContext(ctx context.Context) (m.UserID, error) {
+	identity, err := IdentityFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	if identity.IsServiceAccount() {
+		return "", ErrNoIdentity
+	}
+	return identity.UserID, nil
+}
+
+// StaticIdentityExtractor always returns the same identity, and is
+// intended for tests. A nil identity yields ErrNoIdentity.
+type StaticIdentityExtractor struct {
+	Identity *Identity
+}
+
+var _ IdentityExtractor = (*StaticIdentityExtractor)(nil)
+
+func NewStaticIdentityExtractor(identity *Identity) *StaticIdentityExtractor {
+	return &StaticIdentityExtractor{identity}
+}
+
+func (e *StaticIdentityExtractor) Extract(c *gin.Context) (*Identity, error) {
+	if e.Identity == nil {
+		return nil, ErrNoIdentity
+	}
+	return e.Identity, nil
+}
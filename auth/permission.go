@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jtyers/tmaas-api-util/combo"
+	m "github.com/jtyers/tmaas-model"
+)
+
+var (
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// PermissionChecker checks the permissions of a caller outside of gin
+// routing, for APIs that serve many operations from one route (GraphQL) or
+// none (gRPC).
+//
+// Permissions are checked by ComboMiddlewareFactory, which only comes as gin
+// middleware, so each check runs the caller's headers as a request through
+// a gin engine holding the same middleware as the REST routes.
+type PermissionChecker struct {
+	engine *gin.Engine
+}
+
+type permissionResultContextKey struct{}
+
+type permissionResult struct {
+	identity *Identity
+	err      error
+}
+
+func NewPermissionChecker(comboFactory combo.ComboMiddlewareFactory, identityExtractor IdentityExtractor) *PermissionChecker {
+	r := gin.New()
+	r.ContextWithFallback = true
+
+	r.Use(comboFactory.ExtractTokensToContext())
+
+	extract := func(c *gin.Context) {
+		result := c.Request.Context().Value(permissionResultContextKey{}).(*permissionResult)
+		result.identity, result.err = identityExtractor.Extract(c)
+	}
+
+	r.POST("/any/:permission", func(c *gin.Context) {
+		comboFactory.StrictPermission(m.Permission(c.Param("permission")))(c)
+	}, extract)
+	r.POST("/user/:permission", func(c *gin.Context) {
+		comboFactory.StrictUserPermission(m.Permission(c.Param("permission")))(c)
+	}, extract)
+
+	return &PermissionChecker{engine: r}
+}
+
+// Check that the caller presenting header holds permission p, and if
+// userOnly, that they are a user rather than a service account, as
+// StrictPermission and StrictUserPermission do. It returns their identity,
+// or ErrNoIdentity if they did not authenticate, or ErrPermissionDenied.
+func (pc *PermissionChecker) Check(ctx context.Context, header http.Header, p m.Permission, userOnly bool) (*Identity, error) {
+	path := "/any/" + string(p)
+	if userOnly {
+		path = "/user/" + string(p)
+	}
+
+	result := &permissionResult{}
+
+	req, err := http.NewRequestWithContext(context.WithValue(ctx, permissionResultContextKey{}, result), http.MethodPost, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header.Clone()
+
+	w := &statusRecorder{header: http.Header{}, status: http.StatusOK}
+	pc.engine.ServeHTTP(w, req)
+
+	switch {
+	case w.status == http.StatusUnauthorized:
+		return nil, ErrNoIdentity
+	case w.status >= http.StatusBadRequest:
+		return nil, ErrPermissionDenied
+	case result.err != nil:
+		return nil, result.err
+	case result.identity == nil:
+		return nil, ErrNoIdentity
+	}
+
+	return result.identity, nil
+}
+
+// statusRecorder captures the status written by the middleware, and
+// discards the body.
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+func (w *statusRecorder) Header() http.Header {
+	return w.header
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+}
@@ -0,0 +1,12 @@
+package auth
+
+import (
+	"github.com/google/wire"
+)
+
+var AuthProviderSet = wire.NewSet(
+	wire.Bind(new(IdentityExtractor), new(*ClaimsIdentityExtractor)),
+	NewClaimsIdentityExtractor,
+	NewTenantConfig,
+	NewPermissionChecker,
+)
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	jwt "github.com/jtyers/gin-jwt/v2"
+	util "github.com/jtyers/tmaas-service-util"
+)
+
+var (
+	ErrNoTenant      = errors.New("no tenant in context")
+	ErrInvalidTenant = errors.New("tenant IDs must be 1-64 letters, digits, '.', '_' or '-'")
+	ErrTenantHeader  = errors.New("the tenant is taken from the token, and may not be set by header")
+)
+
+var (
+	// A custom claim naming the caller's organisation
+	TenantIDClaim = "tenant_id"
+
+	// Identity Platform places the tenant of multi-tenant users in the
+	// "tenant" field of this claim
+	FirebaseClaim = "firebase"
+
+	// Formerly used by service accounts to choose their tenant. Requests
+	// setting it are rejected, so that callers relying on it fail loudly
+	// rather than acting on another tenant
+	TenantIDHeader = "X-Tenant-ID"
+
+	firebaseTenantField = "tenant"
+
+	tenantNamespacePrefix = "tenant-"
+)
+
+// tenantIDPattern matches the characters permitted in Datastore namespaces.
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// TenantID identifies a customer organisation. All data belongs to exactly
+// one tenant, and is only visible to callers from that tenant.
+type TenantID string
+
+// ParseTenantID validates s as a TenantID.
+func ParseTenantID(s string) (TenantID, error) {
+	if !tenantIDPattern.MatchString(s) {
+		return "", ErrInvalidTenant
+	}
+	return TenantID(s), nil
+}
+
+func (t TenantID) String() string {
+	return string(t)
+}
+
+// Namespace returns the Datastore namespace holding the tenant's data.
+func (t TenantID) Namespace() string {
+	return tenantNamespacePrefix + string(t)
+}
+
+// TenantIDFromNamespace returns the tenant whose data is held in the
+// Datastore namespace ns, or false if ns does not hold a tenant's data.
+func TenantIDFromNamespace(ns string) (TenantID, bool) {
+	if !strings.HasPrefix(ns, tenantNamespacePrefix) {
+		return "", false
+	}
+
+	tenantID, err := ParseTenantID(strings.TrimPrefix(ns, tenantNamespacePrefix))
+	if err != nil {
+		return "", false
+	}
+	return tenantID, true
+}
+
+// TenantIDFromContext returns the tenant of the identity in ctx, or
+// ErrNoTenant if there is no identity or it has no tenant.
+func TenantIDFromContext(ctx context.Context) (TenantID, error) {
+	identity, err := IdentityFromContext(ctx)
+	if err != nil || identity.TenantID == "" {
+		return "", ErrNoTenant
+	}
+	return identity.TenantID, nil
+}
+
+// TenantConfig sets the tenant of callers whose token does not name one.
+type TenantConfig struct {
+	// The tenant of users, and of service accounts not in
+	// ServiceAccountTenants, whose token names no tenant. This keeps data
+	// and callers predating tenants working; see dao.MigrateToTenantNamespaces.
+	// Such callers have no tenant, and so no access to tenant data, if empty.
+	DefaultTenantID TenantID
+
+	// The tenant each service account acts on, keyed by the account name
+	// (the part of its email before "@"), unless its token has a
+	// TenantIDClaim.
+	ServiceAccountTenants map[string]TenantID
+}
+
+// NewTenantConfig reads the default tenant from DEFAULT_TENANT_ID, and the
+// tenants of service accounts from SERVICE_ACCOUNT_TENANTS, a JSON object
+// mapping account names to tenants, eg {"lookup": "acme"}.
+func NewTenantConfig() (TenantConfig, error) {
+	config := TenantConfig{ServiceAccountTenants: map[string]TenantID{}}
+
+	if s := util.GetEnvWithDefault("DEFAULT_TENANT_ID", ""); s != "" {
+		tenantID, err := ParseTenantID(s)
+		if err != nil {
+			return TenantConfig{}, fmt.Errorf("error parsing DEFAULT_TENANT_ID: %v", err)
+		}
+		config.DefaultTenantID = tenantID
+	}
+
+	if s := util.GetEnvWithDefault("SERVICE_ACCOUNT_TENANTS", ""); s != "" {
+		tenants := map[string]string{}
+		if err := json.Unmarshal([]byte(s), &tenants); err != nil {
+			return TenantConfig{}, fmt.Errorf("error parsing SERVICE_ACCOUNT_TENANTS: %v", err)
+		}
+
+		for name, tenant := range tenants {
+			tenantID, err := ParseTenantID(tenant)
+			if err != nil {
+				return TenantConfig{}, fmt.Errorf("error parsing SERVICE_ACCOUNT_TENANTS tenant of %s: %v", name, err)
+			}
+			config.ServiceAccountTenants[name] = tenantID
+		}
+	}
+
+	return config, nil
+}
+
+// extractTenantID reads the tenant from the claims of the caller, falling
+// back for service accounts to their tenant in config, then for everyone to
+// the default tenant. It returns "" if there is none. Requests setting
+// TenantIDHeader are refused, since the tenant must come from the token.
+func extractTenantID(c *gin.Context, claims jwt.MapClaims, config TenantConfig, serviceAccountName string) (TenantID, error) {
+	if c.GetHeader(TenantIDHeader) != "" {
+		return "", ErrTenantHeader
+	}
+
+	var tenant string
+
+	if t, ok := claims[TenantIDClaim].(string); ok {
+		tenant = t
+
+	} else if firebase, ok := claims[FirebaseClaim].(map[string]interface{}); ok {
+		tenant, _ = firebase[firebaseTenantField].(string)
+	}
+
+	if tenant != "" {
+		return ParseTenantID(tenant)
+	}
+
+	if serviceAccountName != "" {
+		if tenantID, ok := config.ServiceAccountTenants[serviceAccountName]; ok {
+			return tenantID, nil
+		}
+	}
+
+	return config.DefaultTenantID, nil
+}
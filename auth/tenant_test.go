@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	jwt "github.com/jtyers/gin-jwt/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimsIdentityExtractorTenant(t *testing.T) {
+	config := TenantConfig{
+		DefaultTenantID:       "initech",
+		ServiceAccountTenants: map[string]TenantID{"lookup": "globex"},
+	}
+
+	var tests = []struct {
+		name             string
+		config           TenantConfig
+		claims           jwt.MapClaims
+		header           string
+		expectedIdentity *Identity
+		expectedError    error
+	}{
+		{
+			"should read the tenant_id claim",
+			config,
+			jwt.MapClaims{"user_id": "u-1234", "tenant_id": "acme"},
+			"",
+			&Identity{UserID: "u-1234", TenantID: "acme"},
+			nil,
+		},
+		{
+			"should read the Identity Platform tenant",
+			config,
+			jwt.MapClaims{"user_id": "u-1234", "firebase": map[string]interface{}{"tenant": "acme-x1y2z"}},
+			"",
+			&Identity{UserID: "u-1234", TenantID: "acme-x1y2z"},
+			nil,
+		},
+		{
+			"should refuse the tenant header for users",
+			config,
+			jwt.MapClaims{"user_id": "u-1234", "tenant_id": "acme"},
+			"globex",
+			nil,
+			ErrTenantHeader,
+		},
+		{
+			"should refuse the tenant header for service accounts",
+			config,
+			jwt.MapClaims{"email": "lookup@proj.iam.gserviceaccount.com"},
+			"acme",
+			nil,
+			ErrTenantHeader,
+		},
+		{
+			"should read the tenant_id claim of service accounts",
+			config,
+			jwt.MapClaims{"email": "lookup@proj.iam.gserviceaccount.com", "tenant_id": "acme"},
+			"",
+			&Identity{ServiceAccountName: "lookup", TenantID: "acme"},
+			nil,
+		},
+		{
+			"should read the configured tenant of service accounts",
+			config,
+			jwt.MapClaims{"email": "lookup@proj.iam.gserviceaccount.com"},
+			"",
+			&Identity{ServiceAccountName: "lookup", TenantID: "globex"},
+			nil,
+		},
+		{
+			"should give unlisted service accounts the default tenant",
+			config,
+			jwt.MapClaims{"email": "sync@proj.iam.gserviceaccount.com"},
+			"",
+			&Identity{ServiceAccountName: "sync", TenantID: "initech"},
+			nil,
+		},
+		{
+			"should give users without a tenant the default tenant",
+			config,
+			jwt.MapClaims{"user_id": "u-1234"},
+			"",
+			&Identity{UserID: "u-1234", TenantID: "initech"},
+			nil,
+		},
+		{
+			"should leave the tenant empty when none is given or configured",
+			TenantConfig{},
+			jwt.MapClaims{"user_id": "u-1234"},
+			"",
+			&Identity{UserID: "u-1234"},
+			nil,
+		},
+		{
+			"should refuse invalid tenants",
+			config,
+			jwt.MapClaims{"user_id": "u-1234", "tenant_id": "../globex"},
+			"",
+			nil,
+			ErrInvalidTenant,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/", nil)
+			if test.header != "" {
+				c.Request.Header.Set(TenantIDHeader, test.header)
+			}
+			c.Set("JWT_PAYLOAD", test.claims)
+
+			// when
+			identity, err := NewClaimsIdentityExtractor(test.config).Extract(c)
+
+			// then
+			require.Equal(t, test.expectedError, err)
+			require.Equal(t, test.expectedIdentity, identity)
+		})
+	}
+}
+
+func TestNewTenantConfig(t *testing.T) {
+	// given
+	t.Setenv("DEFAULT_TENANT_ID", "initech")
+	t.Setenv("SERVICE_ACCOUNT_TENANTS", `{"lookup": "globex"}`)
+
+	// when
+	config, err := NewTenantConfig()
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, TenantConfig{
+		DefaultTenantID:       "initech",
+		ServiceAccountTenants: map[string]TenantID{"lookup": "globex"},
+	}, config)
+
+	t.Setenv("SERVICE_ACCOUNT_TENANTS", `{"lookup": "../globex"}`)
+	_, err = NewTenantConfig()
+	require.NotNil(t, err)
+}
+
+func TestTenantIDFromNamespace(t *testing.T) {
+	var tests = []struct {
+		name       string
+		namespace  string
+		expected   TenantID
+		expectedOK bool
+	}{
+		{"should read the tenant of tenant namespaces", TenantID("acme").Namespace(), "acme", true},
+		{"should ignore the default namespace", "", "", false},
+		{"should ignore other namespaces", "other", "", false},
+		{"should ignore namespaces with invalid tenants", "tenant-", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tenantID, ok := TenantIDFromNamespace(test.namespace)
+
+			require.Equal(t, test.expectedOK, ok)
+			require.Equal(t, test.expected, tenantID)
+		})
+	}
+}
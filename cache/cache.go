@@ -0,0 +1,24 @@
+// Package cache implements a key-value cache with expiring entries over
+// pluggable backends: an in-process LRU, or Redis so that entries (and
+// their invalidation) are shared between instances of the API.
+package cache
+
+//go:generate mockgen -source=$GOFILE -destination=${GOFILE}_mocks.go -package $GOPACKAGE
+
+import (
+	"context"
+	"time"
+)
+
+// Cache holds values for a time.
+type Cache interface {
+	// Get the value with the given key, returning false if there is none
+	// or it has expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set the value with the given key, to expire after ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete the values with the given keys, if there are any.
+	Delete(ctx context.Context, keys ...string) error
+}
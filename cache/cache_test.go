@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+// op is one call to the cache, made at an offset from the start of the
+// test. Gets expect expectedValue, or a miss if it is empty.
+type op struct {
+	at            time.Duration
+	method        string
+	key           string
+	value         string
+	expectedValue string
+}
+
+var cacheTests = []struct {
+	name string
+	ops  []op
+}{
+	{
+		"should return what was set",
+		[]op{
+			{0, "set", "a", "1", ""},
+			{0, "get", "a", "", "1"},
+			{0, "get", "b", "", ""},
+		},
+	},
+	{
+		"should replace existing entries",
+		[]op{
+			{0, "set", "a", "1", ""},
+			{0, "set", "a", "2", ""},
+			{0, "get", "a", "", "2"},
+		},
+	},
+	{
+		"should expire entries after the TTL",
+		[]op{
+			{0, "set", "a", "1", ""},
+			{59 * time.Second, "get", "a", "", "1"},
+			{time.Minute, "get", "a", "", ""},
+		},
+	},
+	{
+		"should delete entries",
+		[]op{
+			{0, "set", "a", "1", ""},
+			{0, "set", "b", "2", ""},
+			{0, "delete", "a", "", ""},
+			{0, "get", "a", "", ""},
+			{0, "get", "b", "", "2"},
+		},
+	},
+}
+
+func runCacheTests(t *testing.T, newCache func(now func() time.Time) Cache) {
+	for _, test := range cacheTests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			start := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+			now := start
+			cache := newCache(func() time.Time { return now })
+			ctx := context.Background()
+
+			for i, op := range test.ops {
+				now = start.Add(op.at)
+
+				// when
+				switch op.method {
+				case "set":
+					require.Nil(t, cache.Set(ctx, op.key, []byte(op.value), time.Minute), "op %d", i)
+
+				case "delete":
+					require.Nil(t, cache.Delete(ctx, op.key), "op %d", i)
+
+				case "get":
+					value, found, err := cache.Get(ctx, op.key)
+
+					// then
+					require.Nil(t, err, "op %d", i)
+					require.Equal(t, op.expectedValue != "", found, "op %d", i)
+					require.Equal(t, op.expectedValue, string(value), "op %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	runCacheTests(t, func(now func() time.Time) Cache {
+		cache := NewMemoryCache(10)
+		cache.now = now
+		return cache
+	})
+}
+
+func TestRedisCache(t *testing.T) {
+	runCacheTests(t, func(now func() time.Time) Cache {
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+
+		// miniredis only expires keys when told time has passed, so
+		// keep it in step with now on every call
+		return &clockedCache{NewRedisCache(client, "cache:"), server, now, now()}
+	})
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	// given
+	ctx := context.Background()
+	cache := NewMemoryCache(2)
+	cache.Set(ctx, "a", []byte("1"), time.Minute)
+	cache.Set(ctx, "b", []byte("2"), time.Minute)
+	cache.Get(ctx, "a")
+
+	// when
+	cache.Set(ctx, "c", []byte("3"), time.Minute)
+
+	// then
+	_, foundA, _ := cache.Get(ctx, "a")
+	_, foundB, _ := cache.Get(ctx, "b")
+	_, foundC, _ := cache.Get(ctx, "c")
+	require.True(t, foundA)
+	require.False(t, foundB)
+	require.True(t, foundC)
+	require.Equal(t, 2, cache.Len())
+}
+
+// clockedCache fast-forwards a miniredis server to now before each call.
+type clockedCache struct {
+	Cache
+	server *miniredis.Miniredis
+	now    func() time.Time
+	last   time.Time
+}
+
+func (c *clockedCache) tick() {
+	now := c.now()
+	c.server.FastForward(now.Sub(c.last))
+	c.last = now
+}
+
+func (c *clockedCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.tick()
+	return c.Cache.Get(ctx, key)
+}
+
+func (c *clockedCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.tick()
+	return c.Cache.Set(ctx, key, value, ttl)
+}
+
+func (c *clockedCache) Delete(ctx context.Context, keys ...string) error {
+	c.tick()
+	return c.Cache.Delete(ctx, keys...)
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	util "github.com/jtyers/tmaas-service-util"
+	"github.com/jtyers/tmaas-service-util/log"
+)
+
+const (
+	redisKeyPrefix = "cache:"
+
+	DefaultTTL        = 30 * time.Second
+	DefaultMaxEntries = 10000
+)
+
+// Config configures the cache.
+type Config struct {
+	// How long entries are kept. 0 disables caching.
+	TTL time.Duration
+
+	// The most entries held in memory. Unused with Redis, which bounds
+	// its memory itself.
+	MaxEntries int
+
+	// The address of the Redis server holding entries, if they are to be
+	// shared between instances. Entries are held in memory if empty.
+	RedisAddr string
+}
+
+// NewConfig reads the TTL from CACHE_TTL (a duration such as "30s", or "0"
+// to disable caching), the in-memory bound from CACHE_MAX_ENTRIES and the
+// Redis address from CACHE_REDIS_ADDR.
+func NewConfig() (Config, error) {
+	config := Config{TTL: DefaultTTL, MaxEntries: DefaultMaxEntries}
+
+	if ttl := util.GetEnvWithDefault("CACHE_TTL", ""); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d < 0 {
+			return Config{}, fmt.Errorf("error parsing CACHE_TTL %q: must be a non-negative duration", ttl)
+		}
+		config.TTL = d
+	}
+
+	if maxEntries := util.GetEnvWithDefault("CACHE_MAX_ENTRIES", ""); maxEntries != "" {
+		n, err := strconv.Atoi(maxEntries)
+		if err != nil || n < 0 {
+			return Config{}, fmt.Errorf("error parsing CACHE_MAX_ENTRIES %q: must be a non-negative integer", maxEntries)
+		}
+		config.MaxEntries = n
+	}
+
+	config.RedisAddr = util.GetEnvWithDefault("CACHE_REDIS_ADDR", "")
+
+	return config, nil
+}
+
+// NewCache returns a RedisCache if config names a Redis server, otherwise
+// a MemoryCache. The returned cleanup function closes the Redis client.
+func NewCache(config Config) (Cache, func()) {
+	if config.RedisAddr != "" {
+		client := redis.NewClient(&redis.Options{Addr: config.RedisAddr})
+
+		cleanup := func() {
+			if err := client.Close(); err != nil {
+				log.Errorf("error closing cache Redis client: %v", err)
+			}
+		}
+
+		return NewRedisCache(client, redisKeyPrefix), cleanup
+	}
+	return NewMemoryCache(config.MaxEntries), func() {}
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCache is a Cache held in memory, which evicts the least recently
+// used entries once it holds maxEntries. Entries are not shared between
+// instances of the API, so an entry can outlive a change made through
+// another instance until it expires.
+type MemoryCache struct {
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // of *memoryEntry, most recently used first
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+var _ Cache = (*MemoryCache)(nil)
+
+// NewMemoryCache returns a MemoryCache holding at most maxEntries, or any
+// number if maxEntries is 0.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+	}
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*memoryEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(element)
+		return nil, false, nil
+	}
+
+	c.lru.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryEntry{key: key, value: value, expires: c.now().Add(ttl)}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return nil
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+
+	if c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+
+	return nil
+}
+
+func (c *MemoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := c.entries[key]; ok {
+			c.remove(element)
+		}
+	}
+
+	return nil
+}
+
+// Len returns the number of entries held, including any expired but not
+// yet removed.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// remove element; c.mu must be held.
+func (c *MemoryCache) remove(element *list.Element) {
+	c.lru.Remove(element)
+	delete(c.entries, element.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,10 @@
+package cache
+
+import (
+	"github.com/google/wire"
+)
+
+var CacheProviderSet = wire.NewSet(
+	NewConfig,
+	NewCache,
+)
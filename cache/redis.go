@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisCache is a Cache held in Redis, shared by every instance using the
+// same server and prefix. Entries expire through Redis key expiry, and
+// the number held is bounded by the server's maxmemory policy.
+type RedisCache struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var _ Cache = (*RedisCache)(nil)
+
+func NewRedisCache(client redis.UniversalClient, prefix string) *RedisCache {
+	return &RedisCache{client, prefix}
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error getting cache entry %s: %v", key, err)
+	}
+
+	return value, true, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := c.client.Set(ctx, c.prefix+key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("error setting cache entry %s: %v", key, err)
+	}
+	return nil
+}
+
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+
+	if err := c.client.Del(ctx, prefixed...).Err(); err != nil {
+		return fmt.Errorf("error deleting cache entries: %v", err)
+	}
+	return nil
+}
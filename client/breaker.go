@@ -0,0 +1,93 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker open: the threat model API has failed repeatedly, so calls are not being made")
+
+// CircuitBreakerConfig controls the circuit breaker, which fails calls
+// fast once the API has failed repeatedly, rather than adding to its load
+// while it recovers. The zero value disables it.
+type CircuitBreakerConfig struct {
+	// Consecutive failures (server errors, rate limiting or network
+	// errors) that open the circuit. 0 disables the circuit breaker.
+	FailureThreshold int
+
+	// How long the circuit stays open before a single trial call is let
+	// through. If the trial succeeds the circuit closes; if it fails the
+	// circuit opens again.
+	OpenTimeout time.Duration
+}
+
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	}
+}
+
+type circuitBreaker struct {
+	config CircuitBreakerConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time // zero while closed
+	trial    bool      // whether a trial call is in flight
+}
+
+// newCircuitBreaker returns a circuit breaker, or nil if config disables
+// it. A nil *circuitBreaker allows every call.
+func newCircuitBreaker(config CircuitBreakerConfig) *circuitBreaker {
+	if config.FailureThreshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{config: config, now: time.Now}
+}
+
+// allow returns ErrCircuitOpen if a call should not be made. Each call
+// allowed must be followed by a call to record.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() {
+		return nil
+	}
+	if b.trial || b.now().Sub(b.openedAt) < b.config.OpenTimeout {
+		return ErrCircuitOpen
+	}
+
+	b.trial = true
+	return nil
+}
+
+// record the outcome of a call.
+func (b *circuitBreaker) record(failed bool) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+
+	if !failed {
+		b.failures = 0
+		b.openedAt = time.Time{}
+		return
+	}
+
+	b.failures++
+	if !b.openedAt.IsZero() || b.failures >= b.config.FailureThreshold {
+		b.openedAt = b.now()
+	}
+}
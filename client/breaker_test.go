@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	m "github.com/jtyers/tmaas-model"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	// given
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, OpenTimeout: time.Minute})
+	b.now = func() time.Time { return now }
+
+	// when two failures, a success, then three failures are recorded
+	for _, failed := range []bool{true, true, false, true, true} {
+		require.Nil(t, b.allow())
+		b.record(failed)
+	}
+	require.Nil(t, b.allow())
+	b.record(true)
+
+	// then the circuit is open until OpenTimeout has passed
+	require.Equal(t, ErrCircuitOpen, b.allow())
+
+	now = now.Add(time.Minute)
+
+	// and a single trial is then let through
+	require.Nil(t, b.allow())
+	require.Equal(t, ErrCircuitOpen, b.allow())
+
+	// which if it fails opens the circuit again
+	b.record(true)
+	require.Equal(t, ErrCircuitOpen, b.allow())
+
+	// and if it succeeds closes it
+	now = now.Add(time.Minute)
+	require.Nil(t, b.allow())
+	b.record(false)
+	require.Nil(t, b.allow())
+	require.Nil(t, b.allow())
+}
+
+func TestDisabledCircuitBreaker(t *testing.T) {
+	b := newCircuitBreaker(CircuitBreakerConfig{})
+
+	for i := 0; i < 10; i++ {
+		require.Nil(t, b.allow())
+		b.record(true)
+	}
+}
+
+func TestClientFailsFastWhenCircuitOpen(t *testing.T) {
+	// given
+	requests := 0
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer testServer.Close()
+
+	client := NewThreatModelServiceClient(ThreatModelServiceClientConfig{
+		BaseURL:        testServer.URL + "/",
+		CircuitBreaker: CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute},
+	})
+
+	// when
+	var errs []error
+	for i := 0; i < 4; i++ {
+		_, err := client.Get(context.Background(), m.NewThreatModelIDP("tm-1234"))
+		errs = append(errs, err)
+	}
+
+	// then
+	require.Equal(t, 2, requests)
+	require.NotEqual(t, ErrCircuitOpen, errs[1])
+	require.Equal(t, ErrCircuitOpen, errs[2])
+	require.Equal(t, ErrCircuitOpen, errs[3])
+}
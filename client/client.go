@@ -3,45 +3,156 @@ package client
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"time"
 
 	m "github.com/jtyers/tmaas-model"
 	"github.com/jtyers/tmaas-service-util/requestor"
+	"github.com/jtyers/tmaas-threat-model-api/httpclient"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
 	"github.com/jtyers/tmaas-threat-model-api/service"
+	"github.com/jtyers/tmaas-threat-model-api/tracing"
 )
 
 var (
 	URLPrefix       = "%sapi/v1/threatmodel"
 	URLPrefixWithID = URLPrefix + "/%s"
+	URLBatchGet     = URLPrefix + ":batchGet"
 )
 
 type ThreatModelServiceClientConfig struct {
 	// The base URL for API requests.
 	BaseURL string
+
+	// How calls that are safe to repeat are retried. The zero value
+	// disables retries.
+	Retry RetryConfig
+
+	// When to stop calling the API after repeated failures. The zero value
+	// disables the circuit breaker.
+	CircuitBreaker CircuitBreakerConfig
+
+	// The transport requests are sent through, such as one adding
+	// credentials. Nil means http.DefaultTransport.
+	Transport http.RoundTripper
 }
 
-// A client for ThreatModelService that makes calls over HTTPS.
+// A client for ThreatModelService that makes calls over HTTPS, through an
+// http.Client of its own rather than http.DefaultClient. Calls carry the
+// W3C trace context of ctx.
+//
+// Calls safe to repeat are retried as config.Retry says, waiting as long
+// as the API asks in any Retry-After header. Calls are refused with
+// ErrCircuitOpen while the circuit breaker is open.
 type ThreatModelServiceClient struct {
 	config    ThreatModelServiceClientConfig
 	requestor requestor.RequestorWithContext
+	breaker   *circuitBreaker
+	sleep     func(ctx context.Context, d time.Duration) error
 }
 
 var _ service.ThreatModelService = (*ThreatModelServiceClient)(nil)
 
-func NewThreatModelServiceClient(
-	config ThreatModelServiceClientConfig,
-	requestor requestor.RequestorWithContext,
-) *ThreatModelServiceClient {
-	return &ThreatModelServiceClient{config, requestor}
+func NewThreatModelServiceClient(config ThreatModelServiceClientConfig) *ThreatModelServiceClient {
+	httpClient := &http.Client{Transport: NewTransport(tracing.NewTransport(config.Transport))}
+
+	return &ThreatModelServiceClient{
+		config:    config,
+		requestor: httpclient.NewRequestor(httpClient),
+		breaker:   newCircuitBreaker(config.CircuitBreaker),
+		sleep:     sleep,
+	}
+}
+
+// call calls f, retrying if idempotent and f fails in a way worth
+// retrying. Failed calls are returned as an *APIError.
+func (s *ThreatModelServiceClient) call(ctx context.Context, idempotent bool, f func(ctx context.Context) error) error {
+	attempts := 1
+	if idempotent && s.config.Retry.MaxAttempts > 1 {
+		attempts = s.config.Retry.MaxAttempts
+	}
+
+	sleepFunc := s.sleep
+	if sleepFunc == nil {
+		sleepFunc = sleep
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := s.breaker.allow(); err != nil {
+			return err
+		}
+
+		header := &responseHeader{}
+		err := f(withResponseHeader(ctx, header))
+		s.breaker.record(isServerFailure(err))
+
+		if err == nil || attempt >= attempts || !isRetryable(err) {
+			return toAPIError(err, header)
+		}
+
+		wait := s.config.Retry.backoff(attempt)
+		if retryAfter, ok := parseRetryAfter(header.get("Retry-After"), time.Now()); ok {
+			if s.config.Retry.MaxRetryAfter > 0 && retryAfter > s.config.Retry.MaxRetryAfter {
+				return toAPIError(err, header)
+			}
+			wait = retryAfter
+		}
+
+		if sleepFunc(ctx, wait) != nil {
+			return toAPIError(err, header)
+		}
+	}
 }
 
 // Retrieve a ThreatModel by ID.
 func (s *ThreatModelServiceClient) Get(ctx context.Context, id m.ThreatModelID) (*m.ThreatModel, error) {
 	result := m.ThreatModel{}
-	err := s.requestor.GetInto(ctx, fmt.Sprintf(URLPrefixWithID, s.config.BaseURL, id.String()), &result)
+	err := s.call(ctx, true, func(ctx context.Context) error {
+		return s.requestor.GetInto(ctx, fmt.Sprintf(URLPrefixWithID, s.config.BaseURL, id.String()), &result)
+	})
 	if err != nil {
-		if reqErr, ok := err.(requestor.ErrRequestFailed); ok && reqErr.StatusCode == 404 {
-			return nil, service.ErrNoSuchThreatModel
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Retrieve a ThreatModel by ID, along with its ETag, to pass to WithIfMatch
+// when updating it.
+func (s *ThreatModelServiceClient) GetWithETag(ctx context.Context, id m.ThreatModelID) (*m.ThreatModel, string, error) {
+	result := m.ThreatModel{}
+	etag := ""
+	err := s.call(ctx, true, func(ctx context.Context) error {
+		err := s.requestor.GetInto(ctx, fmt.Sprintf(URLPrefixWithID, s.config.BaseURL, id.String()), &result)
+		if err == nil {
+			etag = ctx.Value(responseHeaderContextKey{}).(*responseHeader).get("ETag")
+		}
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &result, etag, nil
+}
+
+// Retrieve the ThreatModels with the given IDs (at most
+// service.MaxGetManyIDs) in one request, along with the IDs of any that do
+// not exist or are not visible to the caller.
+func (s *ThreatModelServiceClient) GetMany(ctx context.Context, ids []m.ThreatModelID) (*tm.BatchGetResult, error) {
+	request := tm.BatchGetRequest{IDs: ids}
+
+	// a read, so safe to retry, but each attempt needs a body of its own
+	result := tm.BatchGetResult{}
+	err := s.call(ctx, true, func(ctx context.Context) error {
+		body, err := requestor.StructReader(request)
+		if err != nil {
+			return err
 		}
+
+		return s.requestor.PostInto(ctx, fmt.Sprintf(URLBatchGet, s.config.BaseURL), body, &result)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -51,7 +162,9 @@ func (s *ThreatModelServiceClient) Get(ctx context.Context, id m.ThreatModelID)
 // Retrieve all ThreatModels.
 func (s *ThreatModelServiceClient) GetAll(ctx context.Context) ([]*m.ThreatModel, error) {
 	result := []*m.ThreatModel{}
-	err := s.requestor.GetInto(ctx, fmt.Sprintf(URLPrefix, s.config.BaseURL), &result)
+	err := s.call(ctx, true, func(ctx context.Context) error {
+		return s.requestor.GetInto(ctx, fmt.Sprintf(URLPrefix, s.config.BaseURL), &result)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -81,8 +194,11 @@ func (s *ThreatModelServiceClient) Create(ctx context.Context, params m.ThreatMo
 		return nil, err
 	}
 
+	// never retried, as a retry could create a second threat model
 	result := m.ThreatModel{}
-	err = s.requestor.PutInto(ctx, fmt.Sprintf(URLPrefix, s.config.BaseURL), body, &result)
+	err = s.call(ctx, false, func(ctx context.Context) error {
+		return s.requestor.PutInto(ctx, fmt.Sprintf(URLPrefix, s.config.BaseURL), body, &result)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -90,18 +206,28 @@ func (s *ThreatModelServiceClient) Create(ctx context.Context, params m.ThreatMo
 	return &result, nil
 }
 
-// Updates a ThreatModel.
+// Updates a ThreatModel. The update is only retried if ctx carries an ETag
+// from WithIfMatch, as otherwise a retry could overwrite changes made
+// since the first attempt.
 func (s *ThreatModelServiceClient) Update(ctx context.Context, id m.ThreatModelID, params m.ThreatModelParams) (*m.ThreatModel, error) {
 	body, err := requestor.StructReader(params)
 	if err != nil {
-		if reqErr, ok := err.(requestor.ErrRequestFailed); ok && reqErr.StatusCode == 404 {
-			return nil, service.ErrNoSuchThreatModel
-		}
 		return nil, err
 	}
 
 	result := m.ThreatModel{}
-	err = s.requestor.PatchInto(ctx, fmt.Sprintf(URLPrefixWithID, s.config.BaseURL, id.String()), body, &result)
+	attempts := 0
+	err = s.call(ctx, ifMatchFromContext(ctx) != "", func(ctx context.Context) error {
+		// a retry needs a body of its own, as the last attempt consumed it
+		if attempts > 0 {
+			if body, err = requestor.StructReader(params); err != nil {
+				return err
+			}
+		}
+		attempts++
+
+		return s.requestor.PatchInto(ctx, fmt.Sprintf(URLPrefixWithID, s.config.BaseURL, id.String()), body, &result)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -111,11 +237,11 @@ func (s *ThreatModelServiceClient) Update(ctx context.Context, id m.ThreatModelI
 
 // Delete a ThreatModel by ID..
 func (s *ThreatModelServiceClient) Delete(ctx context.Context, id m.ThreatModelID) error {
-	_, err := s.requestor.Delete(ctx, fmt.Sprintf(URLPrefixWithID, s.config.BaseURL, id.String()))
+	err := s.call(ctx, true, func(ctx context.Context) error {
+		_, err := s.requestor.Delete(ctx, fmt.Sprintf(URLPrefixWithID, s.config.BaseURL, id.String()))
+		return err
+	})
 	if err != nil {
-		if reqErr, ok := err.(requestor.ErrRequestFailed); ok && reqErr.StatusCode == 404 {
-			return service.ErrNoSuchThreatModel
-		}
 		return err
 	}
 
@@ -9,10 +9,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/jtyers/tmaas-api-util/combo"
 	"github.com/jtyers/tmaas-api-util/errors"
@@ -20,10 +22,31 @@ import (
 	m "github.com/jtyers/tmaas-model"
 	"github.com/jtyers/tmaas-service-util/log"
 	"github.com/jtyers/tmaas-service-util/requestor"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/health"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/ratelimit"
 	"github.com/jtyers/tmaas-threat-model-api/service"
 	"github.com/jtyers/tmaas-threat-model-api/web"
 )
 
+// allowAllAccessChecker treats every threat model as outside any project.
+type allowAllAccessChecker struct{}
+
+func (allowAllAccessChecker) CheckThreatModelAccess(ctx context.Context, id m.ThreatModelID, role tm.ProjectRole) error {
+	return nil
+}
+
+func (allowAllAccessChecker) FilterThreatModelAccess(ctx context.Context, ids []m.ThreatModelID, role tm.ProjectRole) ([]m.ThreatModelID, error) {
+	return ids, nil
+}
+
+// noopAuditor discards audit events.
+type noopAuditor struct{}
+
+func (noopAuditor) Record(ctx context.Context, record tm.AuditRecord) {}
+
 func createServer(comboFactory combo.ComboMiddlewareFactory, svc *service.MockThreatModelService) (*httptest.Server, func()) {
 	log.InitialiseLogging()
 
@@ -33,21 +56,69 @@ func createServer(comboFactory combo.ComboMiddlewareFactory, svc *service.MockTh
 	corsMiddlware := comocks.NewMockCorsMiddleware()
 
 	// generate a test server so we can capture and inspect the request
-	handlers := web.NewThreatModelHandlers(svc)
-	testServer := httptest.NewServer(web.NewRouter(handlers, comboFactory, errors, corsMiddlware))
+	handlers := web.NewThreatModelHandlers(svc, nil, nil, allowAllAccessChecker{}, noopAuditor{})
+	commentHandlers := web.NewCommentHandlers(nil)
+	identityExtractor := auth.NewStaticIdentityExtractor(nil)
+	testServer := httptest.NewServer(web.NewRouter(handlers, commentHandlers, web.NewSearchHandlers(nil), web.NewProjectHandlers(nil), web.NewAuditHandlers(nil), web.NewHealthHandlers(health.NewChecker(time.Second)), web.NewGraphQLHandlers(nil), comboFactory, errors, corsMiddlware, identityExtractor, allowAllAccessChecker{}, noopAuditor{}, web.NewRateLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{}), metrics.NewMetrics(), trace.NewNoopTracerProvider()))
 
 	gin.SetMode(gin.TestMode)
 	closer := func() { testServer.Close() }
 	return testServer, closer
 }
 
-func createClient(server *httptest.Server) *ThreatModelServiceClient {
-	return &ThreatModelServiceClient{
-		config:    ThreatModelServiceClientConfig{BaseURL: server.URL + "/"},
-		requestor: requestor.NewDefaultRequestorWithContext(),
+// requireErrorIs requires err to match expected, or to be nil if expected
+// is.
+func requireErrorIs(t *testing.T, expected error, err error) {
+	if expected == nil {
+		require.Nil(t, err)
+	} else {
+		require.ErrorIs(t, err, expected)
 	}
 }
 
+func createClient(server *httptest.Server) *ThreatModelServiceClient {
+	return NewThreatModelServiceClient(ThreatModelServiceClientConfig{BaseURL: server.URL + "/"})
+}
+
+// roundTripFunc is an http.RoundTripper calling itself.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClientSendsRequestsThroughConfiguredTransport(t *testing.T) {
+	// given
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	defaultTransport := http.DefaultClient.Transport
+
+	client := NewThreatModelServiceClient(ThreatModelServiceClientConfig{
+		BaseURL: server.URL + "/",
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Set("Authorization", "Bearer abc")
+			return http.DefaultTransport.RoundTrip(req)
+		}),
+	})
+
+	// when
+	_, err := client.Get(context.Background(), m.NewThreatModelIDP("tm-1234"))
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, "Bearer abc", authorization)
+
+	// and the client leaves http.DefaultClient, which the rest of the
+	// process uses, alone
+	require.Equal(t, defaultTransport, http.DefaultClient.Transport)
+}
+
 func TestGetThreatModelHandler(t *testing.T) {
 	authorisedServiceAccount := "lookup-service-go"
 	serviceAccountPermissionsJson := combo.ServiceAccountPermissionsJson(`{"` + authorisedServiceAccount + `": ["readOwnThreatModels"]}`)
@@ -103,7 +174,7 @@ func TestGetThreatModelHandler(t *testing.T) {
 			nil, // <- both of these being nil means
 			nil, // ThreatModelService call is not expected
 			nil,
-			requestor.ErrRequestFailed{http.StatusUnauthorized, ``},
+			ErrUnauthorized,
 		},
 	}
 
@@ -131,12 +202,71 @@ func TestGetThreatModelHandler(t *testing.T) {
 			response, err := client.Get(ctx, threatModel.ThreatModelID)
 
 			// then
-			require.Equal(t, test.expectedError, err)
+			requireErrorIs(t, test.expectedError, err)
 			require.Equal(t, test.expectedBody, response)
 		})
 	}
 }
 
+func TestGetManyThreatModelsHandler(t *testing.T) {
+	threatModel := &m.ThreatModel{
+		ThreatModelID: m.NewThreatModelIDP("1234-1234-1234-1234"),
+		Title:         "my-first-threatModel",
+	}
+	missingID := m.NewThreatModelIDP("2345-2345-2345-2345")
+
+	var tests = []struct {
+		name           string
+		ids            []m.ThreatModelID
+		dsReturnValue  *tm.BatchGetResult // GetMany not expected if nil
+		expectedResult *tm.BatchGetResult
+		expectedError  error
+	}{
+		{
+			"should get threat models and missing IDs",
+			[]m.ThreatModelID{threatModel.ThreatModelID, missingID},
+			&tm.BatchGetResult{ThreatModels: []*m.ThreatModel{threatModel}, Missing: []m.ThreatModelID{missingID}},
+			&tm.BatchGetResult{ThreatModels: []*m.ThreatModel{threatModel}, Missing: []m.ThreatModelID{missingID}},
+			nil,
+		},
+		{
+			"should return ErrBadRequest for too many IDs",
+			make([]m.ThreatModelID, service.MaxGetManyIDs+1),
+			nil,
+			nil,
+			ErrBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			mockThreatModelService := service.NewMockThreatModelService(ctrl)
+
+			if test.dsReturnValue != nil {
+				mockThreatModelService.EXPECT().GetMany(gomock.AssignableToTypeOf(&gin.Context{}), test.ids).Return(test.dsReturnValue, nil)
+			}
+
+			token := &m.AuthenticationInfo{UserID: "u-12345678", Roles: []m.Role{m.RoleUser}}
+			comboFactory := combo.NewMockComboMiddlewareFactoryWithTokensAndPermissions(ctrl, token, combo.ServiceAccountPermissionsJson(`{}`))
+			server, closeServer := createServer(comboFactory, mockThreatModelService)
+			defer closeServer()
+
+			client := createClient(server)
+
+			// when
+			response, err := client.GetMany(context.Background(), test.ids)
+
+			// then
+			requireErrorIs(t, test.expectedError, err)
+			require.Equal(t, test.expectedResult, response)
+		})
+	}
+}
+
 func TestGetThreatModelsHandler(t *testing.T) {
 	serviceAccountPermissionsJson := combo.ServiceAccountPermissionsJson(`{}`)
 
@@ -185,7 +315,7 @@ func TestGetThreatModelsHandler(t *testing.T) {
 			[]*m.ThreatModel{},
 			nil,
 			nil,
-			requestor.ErrRequestFailed{http.StatusUnauthorized, ``},
+			ErrUnauthorized,
 		},
 	}
 
@@ -213,7 +343,7 @@ func TestGetThreatModelsHandler(t *testing.T) {
 			response, err := client.GetAll(ctx)
 
 			// then
-			require.Equal(t, test.expectedError, err)
+			requireErrorIs(t, test.expectedError, err)
 			require.Equal(t, test.expectedResponse, response)
 		})
 
@@ -267,7 +397,7 @@ func TestCreateThreatModelHandler(t *testing.T) {
 			nil,
 			errors.ErrUnauthorized,
 			nil,
-			requestor.ErrRequestFailed{http.StatusUnauthorized, ``},
+			ErrUnauthorized,
 		},
 	}
 
@@ -293,7 +423,7 @@ func TestCreateThreatModelHandler(t *testing.T) {
 			response, err := client.Create(ctx, test.input)
 
 			// then
-			require.Equal(t, test.expectedError, err)
+			requireErrorIs(t, test.expectedError, err)
 			require.Equal(t, test.expectedResponse, response)
 		})
 	}
@@ -333,7 +463,7 @@ func TestPatchThreatModelHandler(t *testing.T) {
 			nil,
 			nil,
 			nil,
-			requestor.ErrRequestFailed{http.StatusUnauthorized, ``},
+			ErrUnauthorized,
 		},
 	}
 
@@ -359,12 +489,46 @@ func TestPatchThreatModelHandler(t *testing.T) {
 			result, err := client.Update(ctx, test.inputThreatModelID, test.input)
 
 			// then
-			require.Equal(t, test.expectedError, err)
+			requireErrorIs(t, test.expectedError, err)
 			require.Equal(t, test.expectedResult, result)
 		})
 	}
 }
 
+func TestUpdateWithETag(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	threatModel := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("d-1234"), Title: "foo"}
+	params := m.ThreatModelParams{Title: m.String("bar")}
+
+	mockThreatModelService := service.NewMockThreatModelService(ctrl)
+	mockThreatModelService.EXPECT().Get(gomock.Any(), threatModel.ThreatModelID).Return(threatModel, nil)
+	mockThreatModelService.EXPECT().Update(gomock.Any(), threatModel.ThreatModelID, params).Return(nil, service.ErrThreatModelModified)
+
+	comboFactory := combo.NewMockComboMiddlewareFactoryWithTokensAndPermissions(ctrl,
+		&m.AuthenticationInfo{UserID: "u-1234", Roles: []m.Role{&m.RoleUser}}, combo.ServiceAccountPermissionsJson(`{}`))
+	server, closeServer := createServer(comboFactory, mockThreatModelService)
+	defer closeServer()
+
+	client := createClient(server)
+
+	// when
+	got, etag, getErr := client.GetWithETag(context.Background(), threatModel.ThreatModelID)
+	_, updateErr := client.Update(WithIfMatch(context.Background(), etag), threatModel.ThreatModelID, params)
+
+	// then
+	require.Nil(t, getErr)
+	require.Equal(t, threatModel, got)
+
+	expectedETag, err := tm.ThreatModelETag(threatModel)
+	require.Nil(t, err)
+	require.Equal(t, expectedETag, etag)
+
+	requireErrorIs(t, ErrPreconditionFailed, updateErr)
+}
+
 func TestDeleteThreatModelHandler(t *testing.T) {
 	serviceAccountPermissionsJson := combo.ServiceAccountPermissionsJson(`{}`)
 
@@ -390,7 +554,7 @@ func TestDeleteThreatModelHandler(t *testing.T) {
 			nil,
 			m.NewThreatModelIDP("d-12345678"),
 			nil,
-			requestor.ErrRequestFailed{http.StatusUnauthorized, ""},
+			ErrUnauthorized,
 		},
 	}
 
@@ -415,7 +579,7 @@ func TestDeleteThreatModelHandler(t *testing.T) {
 			err := client.Delete(ctx, test.inputThreatModelID)
 
 			// then
-			require.Equal(t, test.expectedError, err)
+			requireErrorIs(t, test.expectedError, err)
 		})
 	}
 }
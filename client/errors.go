@@ -0,0 +1,171 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jtyers/tmaas-service-util/requestor"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+// Errors for the statuses the API fails calls with. Every client method
+// returns an *APIError for a failed call, which errors.Is matches against
+// these. A 404 matches service.ErrNoSuchThreatModel, as returned by other
+// ThreatModelService implementations.
+var (
+	ErrBadRequest         = errors.New("bad request")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrConflict           = errors.New("conflict")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrRateLimited        = errors.New("rate limited")
+)
+
+var statusErrors = map[int]error{
+	http.StatusBadRequest:         ErrBadRequest,
+	http.StatusUnauthorized:       ErrUnauthorized,
+	http.StatusForbidden:          ErrForbidden,
+	http.StatusNotFound:           service.ErrNoSuchThreatModel,
+	http.StatusConflict:           ErrConflict,
+	http.StatusPreconditionFailed: ErrPreconditionFailed,
+	http.StatusTooManyRequests:    ErrRateLimited,
+}
+
+// FieldError is a field that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// APIError is a call the API failed or rejected.
+type APIError struct {
+	StatusCode int
+
+	// The code and message from the response body, if it had them.
+	Code    string
+	Message string
+
+	// For 400s, the fields that failed validation, if the response said.
+	FieldErrors []FieldError
+
+	// For 429s and 503s, how long the API asked us to wait before calling
+	// again, if it said.
+	RetryAfter time.Duration
+
+	// the sentinel for StatusCode, if there is one
+	err error
+
+	// the error from the requestor
+	cause requestor.ErrRequestFailed
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+
+	if e.err != nil {
+		fmt.Fprintf(&b, "%v (HTTP %d)", e.err, e.StatusCode)
+	} else {
+		fmt.Fprintf(&b, "request failed (HTTP %d)", e.StatusCode)
+	}
+
+	if e.Message != "" {
+		b.WriteString(": " + e.Message)
+	}
+
+	for i, fieldErr := range e.FieldErrors {
+		if i == 0 && e.Message == "" {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteString(fieldErr.Field + ": " + fieldErr.Message)
+	}
+
+	return b.String()
+}
+
+// Is matches the sentinel error for the status code, such as
+// service.ErrNoSuchThreatModel for a 404.
+func (e *APIError) Is(target error) bool {
+	return e.err != nil && target == e.err
+}
+
+// Unwrap returns the requestor.ErrRequestFailed the API's response was
+// reported as.
+func (e *APIError) Unwrap() error {
+	return e.cause
+}
+
+// errorBody is the body the API fails calls with, such as
+// {"code": "RATE_LIMITED", "message": "Too many requests"}. Validation
+// errors are given in errors, either as a list of FieldErrors or as an
+// object of messages keyed by field.
+type errorBody struct {
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Errors  json.RawMessage `json:"errors"`
+}
+
+// toAPIError converts a requestor.ErrRequestFailed into an *APIError,
+// returning other errors, such as network errors, unchanged. header is
+// that of the response, if known.
+func toAPIError(err error, header *responseHeader) error {
+	var reqErr requestor.ErrRequestFailed
+	if !errors.As(err, &reqErr) {
+		return err
+	}
+
+	apiErr := &APIError{
+		StatusCode: reqErr.StatusCode,
+		err:        statusErrors[reqErr.StatusCode],
+		cause:      reqErr,
+	}
+
+	var body errorBody
+	if json.Unmarshal([]byte(reqErr.Body), &body) == nil {
+		apiErr.Code = body.Code
+		apiErr.Message = body.Message
+
+		if reqErr.StatusCode == http.StatusBadRequest {
+			apiErr.FieldErrors = parseFieldErrors(body.Errors)
+		}
+
+	} else {
+		apiErr.Message = strings.TrimSpace(reqErr.Body)
+	}
+
+	if header != nil {
+		if retryAfter, ok := parseRetryAfter(header.get("Retry-After"), time.Now()); ok {
+			apiErr.RetryAfter = retryAfter
+		}
+	}
+
+	return apiErr
+}
+
+func parseFieldErrors(raw json.RawMessage) []FieldError {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var list []FieldError
+	if json.Unmarshal(raw, &list) == nil {
+		return list
+	}
+
+	var byField map[string]string
+	if json.Unmarshal(raw, &byField) == nil {
+		for field, message := range byField {
+			list = append(list, FieldError{Field: field, Message: message})
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Field < list[j].Field })
+		return list
+	}
+
+	return nil
+}
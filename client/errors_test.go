@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-service-util/requestor"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+func TestErrorMapping(t *testing.T) {
+	var tests = []struct {
+		name                string
+		status              int
+		body                string
+		retryAfter          string
+		expectedErr         error
+		expectedMessage     string
+		expectedFieldErrors []FieldError
+		expectedRetryAfter  time.Duration
+	}{
+		{
+			name:            "should map 400 with validation errors as a list",
+			status:          http.StatusBadRequest,
+			body:            `{"code": "VALIDATION_FAILED", "message": "invalid threat model", "errors": [{"field": "Title", "message": "required"}]}`,
+			expectedErr:     ErrBadRequest,
+			expectedMessage: "invalid threat model",
+			expectedFieldErrors: []FieldError{
+				{Field: "Title", Message: "required"},
+			},
+		},
+		{
+			name:        "should map 400 with validation errors by field",
+			status:      http.StatusBadRequest,
+			body:        `{"errors": {"Title": "required", "DataFlowDiagramID": "invalid"}}`,
+			expectedErr: ErrBadRequest,
+			expectedFieldErrors: []FieldError{
+				{Field: "DataFlowDiagramID", Message: "invalid"},
+				{Field: "Title", Message: "required"},
+			},
+		},
+		{
+			name:        "should map 401",
+			status:      http.StatusUnauthorized,
+			expectedErr: ErrUnauthorized,
+		},
+		{
+			name:        "should map 403",
+			status:      http.StatusForbidden,
+			expectedErr: ErrForbidden,
+		},
+		{
+			name:        "should map 404 to ErrNoSuchThreatModel",
+			status:      http.StatusNotFound,
+			expectedErr: service.ErrNoSuchThreatModel,
+		},
+		{
+			name:            "should map 409",
+			status:          http.StatusConflict,
+			body:            "project is not empty",
+			expectedErr:     ErrConflict,
+			expectedMessage: "project is not empty",
+		},
+		{
+			name:        "should map 412",
+			status:      http.StatusPreconditionFailed,
+			expectedErr: ErrPreconditionFailed,
+		},
+		{
+			name:               "should map 429 with Retry-After",
+			status:             http.StatusTooManyRequests,
+			body:               `{"code": "RATE_LIMITED", "message": "Too many requests"}`,
+			retryAfter:         "7",
+			expectedErr:        ErrRateLimited,
+			expectedMessage:    "Too many requests",
+			expectedRetryAfter: 7 * time.Second,
+		},
+		{
+			name:        "should keep other statuses as the requestor's error",
+			status:      http.StatusInternalServerError,
+			expectedErr: requestor.ErrRequestFailed{StatusCode: http.StatusInternalServerError},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if test.retryAfter != "" {
+					w.Header().Set("Retry-After", test.retryAfter)
+				}
+				w.WriteHeader(test.status)
+				fmt.Fprint(w, test.body)
+			}))
+			defer testServer.Close()
+
+			client := NewThreatModelServiceClient(ThreatModelServiceClientConfig{BaseURL: testServer.URL + "/"})
+
+			// every method maps errors the same way
+			calls := map[string]func() error{
+				"Get": func() error {
+					_, err := client.Get(context.Background(), m.NewThreatModelIDP("tm-1234"))
+					return err
+				},
+				"GetAll": func() error {
+					_, err := client.GetAll(context.Background())
+					return err
+				},
+				"Create": func() error {
+					_, err := client.Create(context.Background(), m.ThreatModelParams{})
+					return err
+				},
+				"Update": func() error {
+					_, err := client.Update(context.Background(), m.NewThreatModelIDP("tm-1234"), m.ThreatModelParams{})
+					return err
+				},
+				"Delete": func() error {
+					return client.Delete(context.Background(), m.NewThreatModelIDP("tm-1234"))
+				},
+			}
+
+			for method, call := range calls {
+				// when
+				err := call()
+
+				// then
+				require.ErrorIs(t, err, test.expectedErr, method)
+
+				var apiErr *APIError
+				require.True(t, errors.As(err, &apiErr), method)
+				require.Equal(t, test.status, apiErr.StatusCode, method)
+				require.Equal(t, test.expectedMessage, apiErr.Message, method)
+				require.Equal(t, test.expectedFieldErrors, apiErr.FieldErrors, method)
+				require.Equal(t, test.expectedRetryAfter, apiErr.RetryAfter, method)
+			}
+		})
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	err := &APIError{
+		StatusCode:  http.StatusBadRequest,
+		FieldErrors: []FieldError{{Field: "Title", Message: "required"}, {Field: "Description", Message: "too long"}},
+		err:         ErrBadRequest,
+	}
+
+	require.Equal(t, "bad request (HTTP 400): Title: required; Description: too long", err.Error())
+}
+
+func TestCheckID(t *testing.T) {
+	var tests = []struct {
+		name          string
+		status        int
+		expected      bool
+		expectedError error
+	}{
+		{"should find existing threat model", http.StatusOK, true, nil},
+		{"should not find non-existent threat model", http.StatusNotFound, false, nil},
+		{"should return other errors", http.StatusForbidden, false, ErrForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				fmt.Fprint(w, `{}`)
+			}))
+			defer testServer.Close()
+
+			client := NewThreatModelServiceClient(ThreatModelServiceClientConfig{BaseURL: testServer.URL + "/"})
+			checker := NewClientThreatModelIDChecker(client)
+
+			// when
+			result, err := checker.CheckID(context.Background(), m.NewThreatModelIDP("tm-1234"))
+
+			// then
+			require.Equal(t, test.expected, result)
+			requireErrorIs(t, test.expectedError, err)
+		})
+	}
+}
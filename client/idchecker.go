@@ -2,9 +2,9 @@ package client
 
 import (
 	"context"
+	"errors"
 
 	m "github.com/jtyers/tmaas-model"
-	"github.com/jtyers/tmaas-service-util/idchecker"
 	"github.com/jtyers/tmaas-threat-model-api/service"
 )
 
@@ -16,7 +16,7 @@ func NewClientThreatModelIDChecker(client *ThreatModelServiceClient) *ClientThre
 	return &ClientThreatModelIDChecker{client}
 }
 
-var _ idchecker.IDCheckerForType = (*ClientThreatModelIDChecker)(nil)
+var _ service.BatchIDCheckerForType = (*ClientThreatModelIDChecker)(nil)
 
 func (c *ClientThreatModelIDChecker) CanHandle(id any) bool {
 	switch id.(type) {
@@ -39,11 +39,15 @@ func (c *ClientThreatModelIDChecker) CheckID(ctx context.Context, id any) (bool,
 	if err == nil {
 		return true, nil
 
-	} else if err == service.ErrNoSuchThreatModel {
-		// FIXME will never match, as it'll turn up as a RequestFailedError
+	} else if errors.Is(err, service.ErrNoSuchThreatModel) {
 		return false, nil
 
 	} else {
 		return false, err
 	}
 }
+
+// CheckIDs checks IDs with the API's batch get.
+func (c *ClientThreatModelIDChecker) CheckIDs(ctx context.Context, ids []any) ([]bool, error) {
+	return service.CheckThreatModelIDs(ctx, c.client.GetMany, ids)
+}
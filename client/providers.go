@@ -5,18 +5,18 @@ package client
 import (
 	"github.com/google/wire"
 	serviceutil "github.com/jtyers/tmaas-service-util"
-	"github.com/jtyers/tmaas-service-util/requestor"
 	"github.com/jtyers/tmaas-threat-model-api/service"
 )
 
 func NewThreatModelServiceClientConfig() ThreatModelServiceClientConfig {
 	return ThreatModelServiceClientConfig{
-		BaseURL: serviceutil.EnsureSuffix(serviceutil.GetEnvWithDefault("THREATPLANE_THREAT_MODEL_API_URL", "https://threatmodel.api.threatplane.io/"), "/"),
+		BaseURL:        serviceutil.EnsureSuffix(serviceutil.GetEnvWithDefault("THREATPLANE_THREAT_MODEL_API_URL", "https://threatmodel.api.threatplane.io/"), "/"),
+		Retry:          DefaultRetryConfig(),
+		CircuitBreaker: DefaultCircuitBreakerConfig(),
 	}
 }
 
 var ThreatModelServiceClientProviderSet = wire.NewSet(
-	requestor.RequestorWithContextProviderSet,
 	ThreatModelServiceClientMinimalProviderSet,
 )
 
@@ -26,7 +26,9 @@ var ThreatModelServiceClientProviderSet = wire.NewSet(
 // Only one 'full' client provider set should be used (doesn't
 // matter which generally) and other clients should use the
 // minimal sets. This avoids duplicate provides for core dependencies
-// that all clients use, like RequestorWithContext.
+// that all clients use. This client sends requests through an
+// http.Client of its own, so needs no RequestorWithContext, and its
+// full and minimal sets are the same.
 var ThreatModelServiceClientMinimalProviderSet = wire.NewSet(
 	NewThreatModelServiceClientConfig,
 
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jtyers/tmaas-service-util/requestor"
+)
+
+// RetryConfig controls how calls that are safe to repeat are retried:
+// Get, GetWithETag, GetMany, GetAll, Delete, and Update when ctx carries
+// an ETag (see WithIfMatch). The zero value disables retries.
+type RetryConfig struct {
+	// Attempts made in total, including the first. 0 or 1 disables
+	// retries.
+	MaxAttempts int
+
+	// The wait before the first retry, which grows by Multiplier for each
+	// retry after it, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+
+	// The fraction, from 0 to 1, of each wait that is randomised, so that
+	// clients failing together do not retry together.
+	Jitter float64
+
+	// The longest Retry-After the API may ask us to wait. If it asks for
+	// longer, the call fails without retrying.
+	MaxRetryAfter time.Duration
+}
+
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		MaxRetryAfter:  30 * time.Second,
+	}
+}
+
+// backoff returns the wait before retrying after attempt (counted from 1)
+// failed.
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	multiplier := c.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(c.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if c.MaxBackoff > 0 && backoff > float64(c.MaxBackoff) {
+		backoff = float64(c.MaxBackoff)
+	}
+
+	jitter := math.Min(math.Max(c.Jitter, 0), 1)
+	return time.Duration(backoff * (1 - jitter*rand.Float64()))
+}
+
+// retryableStatusCodes are those returned for failures expected to pass,
+// such as Cloud Run scaling up or our own rate limiter.
+var retryableStatusCodes = map[int]bool{
+	http.StatusRequestTimeout:     true,
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// isRetryable reports whether err is a failure worth retrying.
+func isRetryable(err error) bool {
+	var reqErr requestor.ErrRequestFailed
+	if errors.As(err, &reqErr) {
+		return retryableStatusCodes[reqErr.StatusCode]
+	}
+
+	return isNetworkError(err)
+}
+
+// isServerFailure reports whether err shows the API to be failing, as
+// opposed to rejecting the call, and so counts towards opening the
+// circuit breaker.
+func isServerFailure(err error) bool {
+	var reqErr requestor.ErrRequestFailed
+	if errors.As(err, &reqErr) {
+		return reqErr.StatusCode >= 500 || reqErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	return isNetworkError(err)
+}
+
+func isNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number
+// of seconds or a date, returning false if there is none.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,310 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	m "github.com/jtyers/tmaas-model"
+)
+
+// scriptedResponse is a response for scriptedServer to give.
+type scriptedResponse struct {
+	status     int
+	retryAfter string
+}
+
+// scriptedServer gives the responses in turn, then 200 with a threat
+// model, recording the requests made.
+type scriptedServer struct {
+	mu        sync.Mutex
+	responses []scriptedResponse
+	requests  []*http.Request
+}
+
+func (s *scriptedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r)
+
+	if len(s.requests) <= len(s.responses) {
+		response := s.responses[len(s.requests)-1]
+		if response.retryAfter != "" {
+			w.Header().Set("Retry-After", response.retryAfter)
+		}
+		w.WriteHeader(response.status)
+		return
+	}
+
+	json.NewEncoder(w).Encode(m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-1234")})
+}
+
+func createRetryingClient(t *testing.T, server *scriptedServer, retry RetryConfig) (*ThreatModelServiceClient, *[]time.Duration) {
+	testServer := httptest.NewServer(server)
+	t.Cleanup(testServer.Close)
+
+	client := NewThreatModelServiceClient(ThreatModelServiceClientConfig{
+		BaseURL: testServer.URL + "/",
+		Retry:   retry,
+	})
+
+	waits := []time.Duration{}
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	return client, &waits
+}
+
+// testRetryConfig retries with fixed, predictable waits
+var testRetryConfig = RetryConfig{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     time.Second,
+	Multiplier:     2,
+	MaxRetryAfter:  10 * time.Second,
+}
+
+func TestRetry(t *testing.T) {
+	id := m.NewThreatModelIDP("tm-1234")
+
+	var tests = []struct {
+		name             string
+		call             func(ctx context.Context, c *ThreatModelServiceClient) error
+		responses        []scriptedResponse
+		expectedError    bool
+		expectedRequests int
+		expectedWaits    []time.Duration
+	}{
+		{
+			"should retry Get after 503s with backoff",
+			func(ctx context.Context, c *ThreatModelServiceClient) error {
+				_, err := c.Get(ctx, id)
+				return err
+			},
+			[]scriptedResponse{{status: 503}, {status: 503}},
+			false,
+			3,
+			[]time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			"should give up after MaxAttempts",
+			func(ctx context.Context, c *ThreatModelServiceClient) error {
+				_, err := c.GetAll(ctx)
+				return err
+			},
+			[]scriptedResponse{{status: 503}, {status: 502}, {status: 504}},
+			true,
+			3,
+			[]time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			"should wait as long as Retry-After says",
+			func(ctx context.Context, c *ThreatModelServiceClient) error {
+				return c.Delete(ctx, id)
+			},
+			[]scriptedResponse{{status: 429, retryAfter: "2"}},
+			false,
+			2,
+			[]time.Duration{2 * time.Second},
+		},
+		{
+			"should not wait longer than MaxRetryAfter",
+			func(ctx context.Context, c *ThreatModelServiceClient) error {
+				_, err := c.Get(ctx, id)
+				return err
+			},
+			[]scriptedResponse{{status: 503, retryAfter: "60"}},
+			true,
+			1,
+			[]time.Duration{},
+		},
+		{
+			"should not retry errors that will not pass",
+			func(ctx context.Context, c *ThreatModelServiceClient) error {
+				_, err := c.Get(ctx, id)
+				return err
+			},
+			[]scriptedResponse{{status: 500}},
+			true,
+			1,
+			[]time.Duration{},
+		},
+		{
+			"should not retry Create",
+			func(ctx context.Context, c *ThreatModelServiceClient) error {
+				_, err := c.Create(ctx, m.ThreatModelParams{Title: m.String("new")})
+				return err
+			},
+			[]scriptedResponse{{status: 503}},
+			true,
+			1,
+			[]time.Duration{},
+		},
+		{
+			"should not retry Update without an ETag",
+			func(ctx context.Context, c *ThreatModelServiceClient) error {
+				_, err := c.Update(ctx, id, m.ThreatModelParams{Title: m.String("renamed")})
+				return err
+			},
+			[]scriptedResponse{{status: 503}},
+			true,
+			1,
+			[]time.Duration{},
+		},
+		{
+			"should retry Update with an ETag",
+			func(ctx context.Context, c *ThreatModelServiceClient) error {
+				_, err := c.Update(WithIfMatch(ctx, `"v1"`), id, m.ThreatModelParams{Title: m.String("renamed")})
+				return err
+			},
+			[]scriptedResponse{{status: 503}},
+			false,
+			2,
+			[]time.Duration{100 * time.Millisecond},
+		},
+		{
+			"should not retry Update whose ETag is stale",
+			func(ctx context.Context, c *ThreatModelServiceClient) error {
+				_, err := c.Update(WithIfMatch(ctx, `"v1"`), id, m.ThreatModelParams{Title: m.String("renamed")})
+				return err
+			},
+			[]scriptedResponse{{status: 412}},
+			true,
+			1,
+			[]time.Duration{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			server := &scriptedServer{responses: test.responses}
+			client, waits := createRetryingClient(t, server, testRetryConfig)
+
+			// when
+			err := test.call(context.Background(), client)
+
+			// then
+			if test.expectedError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+			}
+			require.Len(t, server.requests, test.expectedRequests)
+			require.Equal(t, test.expectedWaits, *waits)
+		})
+	}
+}
+
+func TestRetryUpdateResendsBodyAndETag(t *testing.T) {
+	// given
+	var bodies []string
+	var etags []string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params m.ThreatModelParams
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&params))
+
+		bodies = append(bodies, *params.Title)
+		etags = append(etags, r.Header.Get("If-Match"))
+
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(m.ThreatModel{Title: *params.Title})
+	}))
+	defer testServer.Close()
+
+	client := NewThreatModelServiceClient(ThreatModelServiceClientConfig{
+		BaseURL: testServer.URL + "/",
+		Retry:   testRetryConfig,
+	})
+	client.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	// when
+	_, err := client.Update(WithIfMatch(context.Background(), `"v1"`), m.NewThreatModelIDP("tm-1234"), m.ThreatModelParams{Title: m.String("renamed")})
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, []string{"renamed", "renamed"}, bodies)
+	require.Equal(t, []string{`"v1"`, `"v1"`}, etags)
+}
+
+func TestRetryStopsWhenContextDone(t *testing.T) {
+	// given
+	server := &scriptedServer{responses: []scriptedResponse{{status: 503}, {status: 503}}}
+	client, _ := createRetryingClient(t, server, testRetryConfig)
+	client.sleep = sleep
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// when
+	_, err := client.Get(ctx, m.NewThreatModelIDP("tm-1234"))
+
+	// then
+	require.NotNil(t, err)
+	require.LessOrEqual(t, len(server.requests), 1)
+}
+
+func TestBackoff(t *testing.T) {
+	config := RetryConfig{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     3,
+		Jitter:         0.5,
+	}
+
+	var tests = []struct {
+		attempt     int
+		expectedMin time.Duration
+		expectedMax time.Duration
+	}{
+		{1, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 150 * time.Millisecond, 300 * time.Millisecond},
+		{3, 450 * time.Millisecond, 900 * time.Millisecond},
+		{4, 500 * time.Millisecond, time.Second}, // capped by MaxBackoff
+	}
+
+	for _, test := range tests {
+		for i := 0; i < 100; i++ {
+			backoff := config.backoff(test.attempt)
+			require.GreaterOrEqual(t, backoff, test.expectedMin)
+			require.LessOrEqual(t, backoff, test.expectedMax)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		value        string
+		expected     time.Duration
+		expectedOkay bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"Thu, 01 Jun 2023 12:00:30 GMT", 30 * time.Second, true},
+		{"Thu, 01 Jun 2023 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result, ok := parseRetryAfter(test.value, now)
+			require.Equal(t, test.expectedOkay, ok)
+			require.Equal(t, test.expected, result)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// The requestor gives us neither the request nor the response, so headers
+// are passed between ThreatModelServiceClient and the HTTP client through
+// the request context, by a Transport in the client's own http.Client.
+
+type ifMatchContextKey struct{}
+
+// WithIfMatch returns a context that makes Update calls made with it send
+// etag, as returned by GetWithETag, in an If-Match header. The API then
+// rejects the update with 412 if the threat model has changed since, which
+// makes Update safe to retry, so such calls are retried like Get.
+func WithIfMatch(ctx context.Context, etag string) context.Context {
+	return context.WithValue(ctx, ifMatchContextKey{}, etag)
+}
+
+func ifMatchFromContext(ctx context.Context) string {
+	etag, _ := ctx.Value(ifMatchContextKey{}).(string)
+	return etag
+}
+
+// responseHeader records the headers of the last response to a request
+// made with its context.
+type responseHeader struct {
+	mu     sync.Mutex
+	header http.Header
+}
+
+func (r *responseHeader) set(header http.Header) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.header = header
+}
+
+func (r *responseHeader) get(key string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.header.Get(key)
+}
+
+type responseHeaderContextKey struct{}
+
+func withResponseHeader(ctx context.Context, header *responseHeader) context.Context {
+	return context.WithValue(ctx, responseHeaderContextKey{}, header)
+}
+
+// Transport adds If-Match headers to requests, and records response
+// headers, for requests made by ThreatModelServiceClient.
+type Transport struct {
+	base http.RoundTripper
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// NewTransport wraps base, or http.DefaultTransport if base is nil.
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{base}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if etag := ifMatchFromContext(req.Context()); etag != "" {
+		// RoundTrippers must not modify the request they are given
+		req = req.Clone(req.Context())
+		req.Header.Set("If-Match", etag)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+
+	if header, ok := req.Context().Value(responseHeaderContextKey{}).(*responseHeader); ok && resp != nil {
+		header.set(resp.Header)
+	}
+
+	return resp, err
+}
@@ -0,0 +1,352 @@
+// Package clienttest provides an in-memory ThreatModelService, for the
+// tests of services that call this API through
+// client.ThreatModelServiceClient. Swap FakeThreatModelServiceProviderSet
+// in for client.ThreatModelServiceClientMinimalProviderSet to use it.
+package clienttest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	m "github.com/jtyers/tmaas-model"
+
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+var ErrMultipleThreatModels = errors.New("more than one threat model matches the query")
+
+// Method names a ThreatModelService method, for injecting errors.
+type Method string
+
+const (
+	MethodGet         Method = "Get"
+	MethodGetMany     Method = "GetMany"
+	MethodGetAll      Method = "GetAll"
+	MethodQuery       Method = "Query"
+	MethodQuerySingle Method = "QuerySingle"
+	MethodCreate      Method = "Create"
+	MethodUpdate      Method = "Update"
+	MethodDelete      Method = "Delete"
+)
+
+// Hook is called before each call is made. If it returns an error, the
+// call fails with it and the fake is left unchanged.
+type Hook func(ctx context.Context, method Method) error
+
+// FakeThreatModelService is a ThreatModelService that keeps threat models
+// in memory. It is safe for concurrent use.
+//
+// It behaves as ThreatModelServiceClient does: missing threat models are
+// reported as service.ErrNoSuchThreatModel, and ThreatModels are copied
+// in and out, so callers cannot change those it holds.
+type FakeThreatModelService struct {
+	mu           sync.Mutex
+	threatModels map[m.ThreatModelID]*m.ThreatModel
+	order        []m.ThreatModelID // creation order, which GetAll and Query keep
+	errs         map[Method]error
+	nextErrs     map[Method][]error
+	hook         Hook
+	calls        map[Method]int
+}
+
+var _ service.ThreatModelService = (*FakeThreatModelService)(nil)
+
+func NewFakeThreatModelService() *FakeThreatModelService {
+	return &FakeThreatModelService{
+		threatModels: map[m.ThreatModelID]*m.ThreatModel{},
+		errs:         map[Method]error{},
+		nextErrs:     map[Method][]error{},
+		calls:        map[Method]int{},
+	}
+}
+
+// Add threat models as they are, replacing any with the same ID.
+func (f *FakeThreatModelService) Add(threatModels ...*m.ThreatModel) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, threatModel := range threatModels {
+		f.put(copyThreatModel(threatModel))
+	}
+}
+
+// FailWith makes every call to method fail with err, until Reset.
+func (f *FakeThreatModelService) FailWith(method Method, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errs[method] = err
+}
+
+// FailNextWith makes the next call to method fail with err. Called again,
+// the calls after fail in turn.
+func (f *FakeThreatModelService) FailNextWith(method Method, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextErrs[method] = append(f.nextErrs[method], err)
+}
+
+// SetHook sets a Hook to call before every call, replacing any set
+// before. The hook is called without the fake's lock held, so it may
+// call the fake.
+func (f *FakeThreatModelService) SetHook(hook Hook) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hook = hook
+}
+
+// Calls returns the number of calls made to method, including those that
+// failed.
+func (f *FakeThreatModelService) Calls(method Method) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[method]
+}
+
+// Reset removes all threat models, injected errors, the hook and the call
+// counts.
+func (f *FakeThreatModelService) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.threatModels = map[m.ThreatModelID]*m.ThreatModel{}
+	f.order = nil
+	f.errs = map[Method]error{}
+	f.nextErrs = map[Method][]error{}
+	f.hook = nil
+	f.calls = map[Method]int{}
+}
+
+// begin records a call to method, returning an error if it should fail.
+func (f *FakeThreatModelService) begin(ctx context.Context, method Method) error {
+	f.mu.Lock()
+	f.calls[method]++
+	hook := f.hook
+	f.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if hook != nil {
+		if err := hook(ctx, method); err != nil {
+			return err
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if next := f.nextErrs[method]; len(next) > 0 {
+		f.nextErrs[method] = next[1:]
+		return next[0]
+	}
+
+	return f.errs[method]
+}
+
+func (f *FakeThreatModelService) Get(ctx context.Context, id m.ThreatModelID) (*m.ThreatModel, error) {
+	if err := f.begin(ctx, MethodGet); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	threatModel, ok := f.threatModels[id]
+	if !ok {
+		return nil, service.ErrNoSuchThreatModel
+	}
+
+	return copyThreatModel(threatModel), nil
+}
+
+// GetMany returns the threat models with the given IDs in the order
+// given, each once, and the IDs of those it does not hold.
+func (f *FakeThreatModelService) GetMany(ctx context.Context, ids []m.ThreatModelID) (*tm.BatchGetResult, error) {
+	if err := f.begin(ctx, MethodGetMany); err != nil {
+		return nil, err
+	}
+
+	if len(ids) > service.MaxGetManyIDs {
+		return nil, service.ErrTooManyIDs
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	result := &tm.BatchGetResult{ThreatModels: []*m.ThreatModel{}, Missing: []m.ThreatModelID{}}
+	seen := map[m.ThreatModelID]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if threatModel, ok := f.threatModels[id]; ok {
+			result.ThreatModels = append(result.ThreatModels, copyThreatModel(threatModel))
+		} else {
+			result.Missing = append(result.Missing, id)
+		}
+	}
+
+	return result, nil
+}
+
+func (f *FakeThreatModelService) GetAll(ctx context.Context) ([]*m.ThreatModel, error) {
+	if err := f.begin(ctx, MethodGetAll); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.query(&m.ThreatModelQuery{}), nil
+}
+
+// Query returns the threat models whose fields equal every field set in
+// q, as the service's QueryExact does.
+func (f *FakeThreatModelService) Query(ctx context.Context, q *m.ThreatModelQuery) ([]*m.ThreatModel, error) {
+	if err := f.begin(ctx, MethodQuery); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.query(q), nil
+}
+
+// QuerySingle returns the one threat model matching q, as Query does. It
+// returns service.ErrNoSuchThreatModel if none match, and
+// ErrMultipleThreatModels if several do.
+func (f *FakeThreatModelService) QuerySingle(ctx context.Context, q *m.ThreatModelQuery) (*m.ThreatModel, error) {
+	if err := f.begin(ctx, MethodQuerySingle); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	result := f.query(q)
+	switch len(result) {
+	case 0:
+		return nil, service.ErrNoSuchThreatModel
+	case 1:
+		return result[0], nil
+	default:
+		return nil, ErrMultipleThreatModels
+	}
+}
+
+func (f *FakeThreatModelService) Create(ctx context.Context, params m.ThreatModelParams) (*m.ThreatModel, error) {
+	if err := f.begin(ctx, MethodCreate); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	threatModel := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP(m.ThreatModelIDPrefix + uuid.NewString())}
+	applyParams(threatModel, params)
+	f.put(threatModel)
+
+	return copyThreatModel(threatModel), nil
+}
+
+// Update sets the fields set in params, leaving the others alone.
+func (f *FakeThreatModelService) Update(ctx context.Context, id m.ThreatModelID, params m.ThreatModelParams) (*m.ThreatModel, error) {
+	if err := f.begin(ctx, MethodUpdate); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	threatModel, ok := f.threatModels[id]
+	if !ok {
+		return nil, service.ErrNoSuchThreatModel
+	}
+
+	applyParams(threatModel, params)
+	return copyThreatModel(threatModel), nil
+}
+
+func (f *FakeThreatModelService) Delete(ctx context.Context, id m.ThreatModelID) error {
+	if err := f.begin(ctx, MethodDelete); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.threatModels[id]; !ok {
+		return service.ErrNoSuchThreatModel
+	}
+
+	delete(f.threatModels, id)
+	for i, existing := range f.order {
+		if existing == id {
+			f.order = append(f.order[:i], f.order[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// put stores threatModel; f.mu must be held.
+func (f *FakeThreatModelService) put(threatModel *m.ThreatModel) {
+	if _, ok := f.threatModels[threatModel.ThreatModelID]; !ok {
+		f.order = append(f.order, threatModel.ThreatModelID)
+	}
+	f.threatModels[threatModel.ThreatModelID] = threatModel
+}
+
+// query returns copies of the threat models matching q; f.mu must be held.
+func (f *FakeThreatModelService) query(q *m.ThreatModelQuery) []*m.ThreatModel {
+	result := []*m.ThreatModel{}
+
+	for _, id := range f.order {
+		threatModel := f.threatModels[id]
+
+		if q != nil && q.DataFlowDiagramID != nil && threatModel.DataFlowDiagramID != *q.DataFlowDiagramID {
+			continue
+		}
+		if q != nil && q.Title != nil && threatModel.Title != *q.Title {
+			continue
+		}
+
+		result = append(result, copyThreatModel(threatModel))
+	}
+
+	return result
+}
+
+func applyParams(threatModel *m.ThreatModel, params m.ThreatModelParams) {
+	if params.DataFlowDiagramID != nil {
+		threatModel.DataFlowDiagramID = *params.DataFlowDiagramID
+	}
+	if params.Title != nil {
+		threatModel.Title = *params.Title
+	}
+	if params.Description != nil {
+		threatModel.Description = *params.Description
+	}
+}
+
+func copyThreatModel(threatModel *m.ThreatModel) *m.ThreatModel {
+	result := *threatModel
+
+	if threatModel.Threats != nil {
+		result.Threats = make([]*m.Threat, len(threatModel.Threats))
+		for i, threat := range threatModel.Threats {
+			t := *threat
+			result.Threats[i] = &t
+		}
+	}
+
+	return &result
+}
@@ -0,0 +1,270 @@
+package clienttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-service-util/idchecker"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+var (
+	threatModel1 = &m.ThreatModel{
+		ThreatModelID:     m.NewThreatModelIDP("tm-1"),
+		DataFlowDiagramID: m.NewDataFlowDiagramIDP("dfd-1"),
+		Title:             "payments",
+		Threats:           []*m.Threat{{Title: "spoofing"}},
+	}
+	threatModel2 = &m.ThreatModel{
+		ThreatModelID:     m.NewThreatModelIDP("tm-2"),
+		DataFlowDiagramID: m.NewDataFlowDiagramIDP("dfd-1"),
+		Title:             "search",
+	}
+	threatModel3 = &m.ThreatModel{
+		ThreatModelID:     m.NewThreatModelIDP("tm-3"),
+		DataFlowDiagramID: m.NewDataFlowDiagramIDP("dfd-2"),
+		Title:             "search",
+	}
+)
+
+func TestCRUD(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeThreatModelService()
+
+	// create
+	created, err := fake.Create(ctx, m.ThreatModelParams{
+		DataFlowDiagramID: m.NewDataFlowDiagramIDPPtr("dfd-1"),
+		Title:             m.String("payments"),
+	})
+	require.Nil(t, err)
+	require.NotEmpty(t, created.ThreatModelID.String())
+	require.Equal(t, "payments", created.Title)
+
+	// get
+	got, err := fake.Get(ctx, created.ThreatModelID)
+	require.Nil(t, err)
+	require.Equal(t, created, got)
+
+	// update only the fields given
+	updated, err := fake.Update(ctx, created.ThreatModelID, m.ThreatModelParams{Description: m.String("card payments")})
+	require.Nil(t, err)
+	require.Equal(t, "payments", updated.Title)
+	require.Equal(t, "card payments", updated.Description)
+
+	// delete
+	require.Nil(t, fake.Delete(ctx, created.ThreatModelID))
+
+	_, err = fake.Get(ctx, created.ThreatModelID)
+	require.Equal(t, service.ErrNoSuchThreatModel, err)
+
+	_, err = fake.Update(ctx, created.ThreatModelID, m.ThreatModelParams{})
+	require.Equal(t, service.ErrNoSuchThreatModel, err)
+
+	require.Equal(t, service.ErrNoSuchThreatModel, fake.Delete(ctx, created.ThreatModelID))
+}
+
+func TestCopiesThreatModels(t *testing.T) {
+	// given
+	ctx := context.Background()
+	fake := NewFakeThreatModelService()
+	added := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-1"), Threats: []*m.Threat{{Title: "spoofing"}}}
+	fake.Add(added)
+
+	// when the caller changes what they added, and what they got
+	added.Title = "changed"
+	got, err := fake.Get(ctx, added.ThreatModelID)
+	require.Nil(t, err)
+	got.Threats[0].Title = "changed"
+
+	// then the fake is unchanged
+	got, err = fake.Get(ctx, added.ThreatModelID)
+	require.Nil(t, err)
+	require.Equal(t, "", got.Title)
+	require.Equal(t, "spoofing", got.Threats[0].Title)
+}
+
+func TestQuery(t *testing.T) {
+	var tests = []struct {
+		name           string
+		query          *m.ThreatModelQuery
+		expected       []*m.ThreatModel
+		expectedSingle *m.ThreatModel
+		expectedError  error // from QuerySingle
+	}{
+		{
+			"should match every threat model for an empty query",
+			&m.ThreatModelQuery{},
+			[]*m.ThreatModel{threatModel1, threatModel2, threatModel3},
+			nil,
+			ErrMultipleThreatModels,
+		},
+		{
+			"should match by data flow diagram",
+			&m.ThreatModelQuery{DataFlowDiagramID: m.NewDataFlowDiagramIDPPtr("dfd-2")},
+			[]*m.ThreatModel{threatModel3},
+			threatModel3,
+			nil,
+		},
+		{
+			"should match by every field set",
+			&m.ThreatModelQuery{DataFlowDiagramID: m.NewDataFlowDiagramIDPPtr("dfd-1"), Title: m.String("search")},
+			[]*m.ThreatModel{threatModel2},
+			threatModel2,
+			nil,
+		},
+		{
+			"should match nothing",
+			&m.ThreatModelQuery{Title: m.String("billing")},
+			[]*m.ThreatModel{},
+			nil,
+			service.ErrNoSuchThreatModel,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctx := context.Background()
+			fake := NewFakeThreatModelService()
+			fake.Add(threatModel1, threatModel2, threatModel3)
+
+			// when
+			result, err := fake.Query(ctx, test.query)
+			single, singleErr := fake.QuerySingle(ctx, test.query)
+
+			// then
+			require.Nil(t, err)
+			require.Equal(t, test.expected, result)
+			require.Equal(t, test.expectedSingle, single)
+			require.Equal(t, test.expectedError, singleErr)
+		})
+	}
+}
+
+func TestErrorInjection(t *testing.T) {
+	ctx := context.Background()
+	errBoom := errors.New("boom")
+
+	fake := NewFakeThreatModelService()
+	fake.Add(threatModel1)
+
+	// FailNextWith fails calls in turn, then stops
+	fake.FailNextWith(MethodGet, errBoom)
+	fake.FailNextWith(MethodGet, service.ErrNoSuchThreatModel)
+
+	_, err := fake.Get(ctx, threatModel1.ThreatModelID)
+	require.Equal(t, errBoom, err)
+	_, err = fake.Get(ctx, threatModel1.ThreatModelID)
+	require.Equal(t, service.ErrNoSuchThreatModel, err)
+	_, err = fake.Get(ctx, threatModel1.ThreatModelID)
+	require.Nil(t, err)
+
+	// FailWith fails every call, leaving the fake unchanged
+	fake.FailWith(MethodDelete, errBoom)
+	require.Equal(t, errBoom, fake.Delete(ctx, threatModel1.ThreatModelID))
+	require.Equal(t, errBoom, fake.Delete(ctx, threatModel1.ThreatModelID))
+	_, err = fake.Get(ctx, threatModel1.ThreatModelID)
+	require.Nil(t, err)
+
+	// hooks see every call, and may call the fake
+	var methods []Method
+	fake.SetHook(func(ctx context.Context, method Method) error {
+		methods = append(methods, method)
+		if method == MethodCreate {
+			_, err := fake.GetAll(ctx)
+			return err
+		}
+		return nil
+	})
+
+	_, err = fake.Create(ctx, m.ThreatModelParams{Title: m.String("new")})
+	require.Nil(t, err)
+	require.Equal(t, []Method{MethodCreate, MethodGetAll}, methods)
+
+	require.Equal(t, 4, fake.Calls(MethodGet))
+	require.Equal(t, 2, fake.Calls(MethodDelete))
+
+	// Reset clears everything
+	fake.Reset()
+	require.Equal(t, service.ErrNoSuchThreatModel, fake.Delete(ctx, threatModel1.ThreatModelID))
+	require.Equal(t, 1, fake.Calls(MethodDelete))
+}
+
+func TestConcurrentUse(t *testing.T) {
+	// given
+	ctx := context.Background()
+	fake := NewFakeThreatModelService()
+
+	// when
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			created, err := fake.Create(ctx, m.ThreatModelParams{Title: m.String(fmt.Sprintf("tm %d", i))})
+			require.Nil(t, err)
+
+			_, err = fake.Update(ctx, created.ThreatModelID, m.ThreatModelParams{Description: m.String("updated")})
+			require.Nil(t, err)
+
+			_, err = fake.GetAll(ctx)
+			require.Nil(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	// then
+	result, err := fake.Query(ctx, &m.ThreatModelQuery{})
+	require.Nil(t, err)
+	require.Len(t, result, 50)
+	require.Equal(t, 50, fake.Calls(MethodCreate))
+}
+
+func TestContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewFakeThreatModelService().GetAll(ctx)
+	require.Equal(t, context.Canceled, err)
+}
+
+func TestFakeThreatModelIDChecker(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeThreatModelService()
+	fake.Add(threatModel1)
+
+	checker := idchecker.NewDefaultIDChecker(idchecker.IDCheckerForTypes{NewFakeThreatModelIDChecker(fake)})
+
+	exists, err := checker.CheckID(ctx, threatModel1.ThreatModelID)
+	require.Nil(t, err)
+	require.True(t, exists)
+
+	exists, err = checker.CheckID(ctx, m.NewThreatModelIDPPtr("tm-404"))
+	require.Nil(t, err)
+	require.False(t, exists)
+
+	fake.FailWith(MethodGet, errors.New("boom"))
+	_, err = checker.CheckID(ctx, threatModel1.ThreatModelID)
+	require.NotNil(t, err)
+}
+
+func TestFakeThreatModelIDCheckerBatch(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeThreatModelService()
+	fake.Add(threatModel1, threatModel2)
+
+	checker := NewFakeThreatModelIDChecker(fake)
+
+	exists, err := checker.CheckIDs(ctx, []any{threatModel2.ThreatModelID, m.NewThreatModelIDPPtr("tm-404"), &threatModel1.ThreatModelID})
+	require.Nil(t, err)
+	require.Equal(t, []bool{true, false, true}, exists)
+	require.Equal(t, 1, fake.Calls(MethodGetMany))
+	require.Equal(t, 0, fake.Calls(MethodGet))
+}
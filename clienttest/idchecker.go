@@ -0,0 +1,57 @@
+package clienttest
+
+import (
+	"context"
+	"errors"
+
+	m "github.com/jtyers/tmaas-model"
+
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+// FakeThreatModelIDChecker checks threat model IDs against a
+// FakeThreatModelService, as client.ClientThreatModelIDChecker does
+// against the API.
+type FakeThreatModelIDChecker struct {
+	fake *FakeThreatModelService
+}
+
+func NewFakeThreatModelIDChecker(fake *FakeThreatModelService) *FakeThreatModelIDChecker {
+	return &FakeThreatModelIDChecker{fake}
+}
+
+var _ service.BatchIDCheckerForType = (*FakeThreatModelIDChecker)(nil)
+
+func (c *FakeThreatModelIDChecker) CanHandle(id any) bool {
+	switch id.(type) {
+	case m.ThreatModelID, *m.ThreatModelID:
+		return true
+	}
+	return false
+}
+
+func (c *FakeThreatModelIDChecker) CheckID(ctx context.Context, id any) (bool, error) {
+	var idStruct m.ThreatModelID
+	switch id := id.(type) {
+	case m.ThreatModelID:
+		idStruct = id
+	case *m.ThreatModelID:
+		idStruct = *id
+	}
+
+	_, err := c.fake.Get(ctx, idStruct)
+	if err == nil {
+		return true, nil
+
+	} else if errors.Is(err, service.ErrNoSuchThreatModel) {
+		return false, nil
+
+	} else {
+		return false, err
+	}
+}
+
+// CheckIDs checks IDs with the fake's GetMany, as the client uses the API's batch get.
+func (c *FakeThreatModelIDChecker) CheckIDs(ctx context.Context, ids []any) ([]bool, error) {
+	return service.CheckThreatModelIDs(ctx, c.fake.GetMany, ids)
+}
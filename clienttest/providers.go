@@ -0,0 +1,18 @@
+package clienttest
+
+import (
+	"github.com/google/wire"
+
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+// FakeThreatModelServiceProviderSet provides what
+// client.ThreatModelServiceClientMinimalProviderSet does, backed by a
+// FakeThreatModelService. Tests can be given the *FakeThreatModelService
+// to add threat models and inject errors.
+var FakeThreatModelServiceProviderSet = wire.NewSet(
+	NewFakeThreatModelService,
+	wire.Bind(new(service.ThreatModelService), new(*FakeThreatModelService)),
+
+	NewFakeThreatModelIDChecker,
+)
@@ -0,0 +1,21 @@
+// Command tmadmin runs administrative tasks directly against the Threat
+// Model API's Datastore, such as data migrations. It is run by operators,
+// with credentials for the API's project, rather than through the API.
+//
+// The project is read from --project, or PROJECT_ID as for the API.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := newRootCommand().ExecuteContext(ctx); err != nil {
+		os.Exit(1)
+	}
+}
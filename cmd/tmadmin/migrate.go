@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+)
+
+func newMigrateTenantsCommand(o *options) *cobra.Command {
+	var defaultTenant string
+
+	cmd := &cobra.Command{
+		Use:   "migrate-tenants",
+		Short: "Move threat models and their comments, tags and projects into tenant namespaces",
+		Long: `Move entities written outside tenant namespaces into them: those written
+before tenants were introduced move to the default tenant, and threat
+models written with their tenant in their kind move to that tenant.
+
+Run this before deploying a release that reads threat models from tenant
+namespaces, and again once it is deployed to move any written meanwhile.
+It is safe to rerun.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var tenantID auth.TenantID
+			if defaultTenant != "" {
+				var err error
+				if tenantID, err = auth.ParseTenantID(defaultTenant); err != nil {
+					return fmt.Errorf("error parsing --default-tenant: %v", err)
+				}
+			}
+
+			client, cleanup, err := o.client(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			moved, err := dao.MigrateToTenantNamespaces(cmd.Context(), client, tenantID)
+
+			kinds := []string{}
+			for kind := range moved {
+				kinds = append(kinds, kind)
+			}
+			sort.Strings(kinds)
+
+			for _, kind := range kinds {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: moved %d\n", kind, moved[kind])
+			}
+
+			if err != nil {
+				return fmt.Errorf("error migrating to tenant namespaces: %v", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&defaultTenant, "default-tenant", os.Getenv("DEFAULT_TENANT_ID"), "tenant of entities written before tenants were introduced (default $DEFAULT_TENANT_ID)")
+
+	return cmd
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	gdatastore "cloud.google.com/go/datastore"
+	"github.com/jtyers/tmaas-service-dao/datastore"
+	"github.com/spf13/cobra"
+
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+)
+
+var ErrNoProject = errors.New("no project: set PROJECT_ID or --project")
+
+// options are the flags common to every command.
+type options struct {
+	projectID string
+}
+
+func newRootCommand() *cobra.Command {
+	o := &options{}
+
+	cmd := &cobra.Command{
+		Use:          "tmadmin",
+		Short:        "Run administrative tasks against the Threat Model API's Datastore",
+		SilenceUsage: true,
+	}
+
+	cmd.PersistentFlags().StringVar(&o.projectID, "project", os.Getenv("PROJECT_ID"), "Google Cloud project of the Datastore (default $PROJECT_ID)")
+
+	cmd.AddCommand(
+		newMigrateTenantsCommand(o),
+		newRebuildSearchIndexCommand(o),
+	)
+
+	return cmd
+}
+
+// client returns a Datastore client for the configured project. The
+// returned cleanup function closes it.
+func (o *options) client(ctx context.Context) (*gdatastore.Client, func(), error) {
+	if o.projectID == "" {
+		return nil, nil, ErrNoProject
+	}
+
+	return dao.NewDatastoreClient(ctx, datastore.DatastoreConfiguration{ProjectID: o.projectID, DatastoreKeyKind: dao.DatastoreKeyKind})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jtyers/tmaas-service-dao/datastore"
+	"github.com/jtyers/tmaas-service-util/id"
+	"github.com/spf13/cobra"
+
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+func newRebuildSearchIndexCommand(o *options) *cobra.Command {
+	var tenant string
+
+	cmd := &cobra.Command{
+		Use:   "rebuild-search-index",
+		Short: "Rebuild the search index of every tenant, or of one, from its threat models",
+		Long: `Rebuild the search index from the threat models in Datastore. The API
+keeps the index up to date as threat models are written, so this is only
+needed after restoring data, migrating, or changing how threat models are
+indexed. Searches made while the index is rebuilt may miss threat models.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			var tenants []auth.TenantID
+			if tenant != "" {
+				tenantID, err := auth.ParseTenantID(tenant)
+				if err != nil {
+					return fmt.Errorf("error parsing --tenant: %v", err)
+				}
+				tenants = append(tenants, tenantID)
+			}
+
+			client, cleanup, err := o.client(ctx)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			if tenants == nil {
+				if tenants, err = dao.ListTenants(ctx, client); err != nil {
+					return err
+				}
+			}
+
+			threatModelDao, err := dao.NewThreatModelDao(client,
+				id.NewDefaultRandomIDProvider(dao.NewThreatModelRandomIDProviderPrefix()),
+				datastore.DatastoreConfiguration{ProjectID: o.projectID, DatastoreKeyKind: dao.DatastoreKeyKind},
+				dao.NewThreatModelIDCreator(),
+			)
+			if err != nil {
+				return err
+			}
+
+			// Rebuild records no audit events, so there is no auditor
+			searchService := service.NewIndexingThreatModelSearchService(threatModelDao, dao.NewDatastoreSearchIndex(client), service.NewProjectAccessChecker(dao.NewDatastoreProjectDao(client)), nil)
+
+			for _, tenantID := range tenants {
+				tenantCtx := auth.WithIdentity(ctx, &auth.Identity{ServiceAccountName: "tmadmin", TenantID: tenantID})
+
+				n, err := searchService.Rebuild(tenantCtx)
+				if err != nil {
+					return fmt.Errorf("error rebuilding the search index of tenant %s: %v", tenantID, err)
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: indexed %d\n", tenantID, n)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&tenant, "tenant", "", "rebuild only the index of this tenant")
+
+	return cmd
+}
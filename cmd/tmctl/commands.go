@@ -0,0 +1,324 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ErrNothingToUpdate = errors.New("nothing to update: set at least one of --title, --description or --dfd")
+	ErrNoQuery         = errors.New("no query: set at least one of --title or --dfd")
+)
+
+func newListCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List all threat models visible to you",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+
+			threatModels, err := c.GetAll(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("error listing threat models: %v", err)
+			}
+
+			return writeThreatModels(cmd.OutOrStdout(), o.output, threatModels)
+		},
+	}
+}
+
+func newGetCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get ID",
+		Short: "Get a threat model",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+
+			threatModel, err := c.Get(cmd.Context(), m.NewThreatModelIDP(args[0]))
+			if err != nil {
+				return fmt.Errorf("error getting threat model %s: %v", args[0], err)
+			}
+
+			return writeThreatModel(cmd.OutOrStdout(), o.output, threatModel)
+		},
+	}
+}
+
+// paramsFlags are the flags that set ThreatModelParams.
+type paramsFlags struct {
+	title       string
+	description string
+	dfd         string
+}
+
+func (f *paramsFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.title, "title", "", "title of the threat model")
+	cmd.Flags().StringVar(&f.description, "description", "", "description of the threat model")
+	cmd.Flags().StringVar(&f.dfd, "dfd", "", "ID of the data flow diagram the threat model covers")
+}
+
+// params returns ThreatModelParams with only the flags given on the
+// command line set, so that updates leave other fields alone.
+func (f *paramsFlags) params(cmd *cobra.Command) (m.ThreatModelParams, bool) {
+	params := m.ThreatModelParams{}
+	set := false
+
+	if cmd.Flags().Changed("title") {
+		params.Title = m.String(f.title)
+		set = true
+	}
+	if cmd.Flags().Changed("description") {
+		params.Description = m.String(f.description)
+		set = true
+	}
+	if cmd.Flags().Changed("dfd") {
+		params.DataFlowDiagramID = m.NewDataFlowDiagramIDPPtr(f.dfd)
+		set = true
+	}
+
+	return params, set
+}
+
+func newCreateCommand(o *options) *cobra.Command {
+	f := &paramsFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a threat model",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+
+			params, _ := f.params(cmd)
+			threatModel, err := c.Create(cmd.Context(), params)
+			if err != nil {
+				return fmt.Errorf("error creating threat model: %v", err)
+			}
+
+			return writeThreatModel(cmd.OutOrStdout(), o.output, threatModel)
+		},
+	}
+
+	f.register(cmd)
+	cmd.MarkFlagRequired("title")
+	cmd.MarkFlagRequired("dfd")
+	return cmd
+}
+
+func newUpdateCommand(o *options) *cobra.Command {
+	f := &paramsFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "update ID",
+		Short: "Update a threat model's title, description or data flow diagram",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params, ok := f.params(cmd)
+			if !ok {
+				return ErrNothingToUpdate
+			}
+
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+
+			threatModel, err := c.Update(cmd.Context(), m.NewThreatModelIDP(args[0]), params)
+			if err != nil {
+				return fmt.Errorf("error updating threat model %s: %v", args[0], err)
+			}
+
+			return writeThreatModel(cmd.OutOrStdout(), o.output, threatModel)
+		},
+	}
+
+	f.register(cmd)
+	return cmd
+}
+
+func newDeleteCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete ID...",
+		Short: "Delete threat models",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+
+			for _, id := range args {
+				if err := c.Delete(cmd.Context(), m.NewThreatModelIDP(id)); err != nil {
+					return fmt.Errorf("error deleting threat model %s: %v", id, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "deleted %s\n", id)
+			}
+
+			return nil
+		},
+	}
+}
+
+func newQueryCommand(o *options) *cobra.Command {
+	var title, dfd string
+
+	cmd := &cobra.Command{
+		Use:   "query",
+		Short: "List the threat models matching a title or data flow diagram",
+		// the REST API cannot query threat models, so all are fetched and
+		// filtered here
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if title == "" && dfd == "" {
+				return ErrNoQuery
+			}
+
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+
+			threatModels, err := c.GetAll(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("error querying threat models: %v", err)
+			}
+
+			result := []*m.ThreatModel{}
+			for _, threatModel := range threatModels {
+				if title != "" && threatModel.Title != title {
+					continue
+				}
+				if dfd != "" && threatModel.DataFlowDiagramID.String() != dfd {
+					continue
+				}
+				result = append(result, threatModel)
+			}
+
+			return writeThreatModels(cmd.OutOrStdout(), o.output, result)
+		},
+	}
+
+	cmd.Flags().StringVar(&title, "title", "", "only list threat models with exactly this title")
+	cmd.Flags().StringVar(&dfd, "dfd", "", "only list threat models of this data flow diagram")
+	return cmd
+}
+
+func newExportCommand(o *options) *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "export [ID...]",
+		Short: "Export threat models, or all threat models visible to you, as JSON or YAML",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// a table cannot be imported again
+			output := o.output
+			if output == OutputTable {
+				output = OutputJSON
+			}
+
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+
+			var threatModels []*m.ThreatModel
+			if len(args) == 0 {
+				threatModels, err = c.GetAll(cmd.Context())
+				if err != nil {
+					return fmt.Errorf("error exporting threat models: %v", err)
+				}
+
+			} else {
+				for _, id := range args {
+					threatModel, err := c.Get(cmd.Context(), m.NewThreatModelIDP(id))
+					if err != nil {
+						return fmt.Errorf("error exporting threat model %s: %v", id, err)
+					}
+					threatModels = append(threatModels, threatModel)
+				}
+			}
+
+			w := cmd.OutOrStdout()
+			if file != "" {
+				f, err := os.Create(file)
+				if err != nil {
+					return fmt.Errorf("error creating %s: %v", file, err)
+				}
+				defer f.Close()
+				w = f
+			}
+
+			return writeThreatModels(w, output, threatModels)
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "file to write to (default stdout)")
+	return cmd
+}
+
+func newImportCommand(o *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "import FILE",
+		Short: "Create threat models from a JSON or YAML export, or - for stdin",
+		Long: "Create threat models from a JSON or YAML export, or - for stdin.\n\n" +
+			"Each threat model is created anew, with a new ID. Threats are not imported, " +
+			"as the API does not accept them when creating threat models.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var r io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("error opening %s: %v", args[0], err)
+				}
+				defer f.Close()
+				r = f
+			}
+
+			threatModels, err := readThreatModels(r)
+			if err != nil {
+				return err
+			}
+
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+
+			created := []*m.ThreatModel{}
+			for _, threatModel := range threatModels {
+				if len(threatModel.Threats) > 0 {
+					fmt.Fprintf(cmd.ErrOrStderr(), "warning: not importing %d threats of %s\n", len(threatModel.Threats), threatModel.ThreatModelID.String())
+				}
+
+				result, err := c.Create(cmd.Context(), m.ThreatModelParams{
+					DataFlowDiagramID: m.NewDataFlowDiagramIDPPtr(threatModel.DataFlowDiagramID.String()),
+					Title:             m.String(threatModel.Title),
+					Description:       m.String(threatModel.Description),
+				})
+				if err != nil {
+					return fmt.Errorf("error importing threat model %s: %v", threatModel.ThreatModelID.String(), err)
+				}
+				created = append(created, result)
+			}
+
+			return writeThreatModels(cmd.OutOrStdout(), o.output, created)
+		},
+	}
+}
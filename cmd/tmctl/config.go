@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jtyers/tmaas-threat-model-api/client"
+)
+
+const (
+	configEnv = "TMCTL_CONFIG"
+	urlEnv    = "TMCTL_URL"
+	tokenEnv  = "TMCTL_TOKEN"
+)
+
+var ErrNoToken = errors.New("no token: set token in the config file, TMCTL_TOKEN or --token")
+
+// Config holds the API URL and token used by tmctl.
+type Config struct {
+	URL   string `yaml:"url"`
+	Token string `yaml:"token"`
+}
+
+// defaultConfigPath returns the path of the config file, which need not
+// exist.
+func defaultConfigPath() (string, error) {
+	if path := os.Getenv(configEnv); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("error finding config directory: %v", err)
+	}
+
+	return filepath.Join(dir, "tmctl", "config.yaml"), nil
+}
+
+// loadConfig reads the config file at path, if it exists, then applies
+// the environment. The URL defaults to that used by the API client.
+func loadConfig(path string) (Config, error) {
+	config := Config{URL: client.NewThreatModelServiceClientConfig().BaseURL}
+
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Config{}, fmt.Errorf("error reading config file %s: %v", path, err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(b, &config); err != nil {
+			return Config{}, fmt.Errorf("error parsing config file %s: %v", path, err)
+		}
+	}
+
+	if url := os.Getenv(urlEnv); url != "" {
+		config.URL = url
+	}
+	if token := os.Getenv(tokenEnv); token != "" {
+		config.Token = token
+	}
+
+	return config, nil
+}
@@ -0,0 +1,28 @@
+// Command tmctl manages threat models from the command line, through the
+// Threat Model API.
+//
+// The API URL and token are read from a YAML config file, then from the
+// TMCTL_URL and TMCTL_TOKEN environment variables, then from the --url
+// and --token flags, each overriding the last:
+//
+//	url: https://threatmodel.api.threatplane.io/
+//	token: <a Firebase ID token or service account token>
+//
+// The config file is read from --config, $TMCTL_CONFIG, or
+// tmctl/config.yaml in the user's config directory.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := newRootCommand().ExecuteContext(ctx); err != nil {
+		os.Exit(1)
+	}
+}
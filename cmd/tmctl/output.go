@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	m "github.com/jtyers/tmaas-model"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+var ErrInvalidOutput = errors.New("output must be one of table, json or yaml")
+
+// writeThreatModels writes threatModels to w in the given output format.
+func writeThreatModels(w io.Writer, output string, threatModels []*m.ThreatModel) error {
+	switch output {
+	case OutputTable:
+		return writeTable(w, threatModels)
+	case OutputJSON, OutputYAML:
+		return writeData(w, output, threatModels)
+	default:
+		return ErrInvalidOutput
+	}
+}
+
+// writeThreatModel writes a single threat model, as an object rather than
+// a list.
+func writeThreatModel(w io.Writer, output string, threatModel *m.ThreatModel) error {
+	if output == OutputTable {
+		return writeTable(w, []*m.ThreatModel{threatModel})
+	}
+	return writeData(w, output, threatModel)
+}
+
+func writeTable(w io.Writer, threatModels []*m.ThreatModel) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTITLE\tDATA FLOW DIAGRAM\tTHREATS")
+
+	for _, threatModel := range threatModels {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n",
+			threatModel.ThreatModelID.String(),
+			strings.ReplaceAll(threatModel.Title, "\t", " "),
+			threatModel.DataFlowDiagramID.String(),
+			len(threatModel.Threats),
+		)
+	}
+
+	return tw.Flush()
+}
+
+// writeData writes v as JSON or YAML. YAML is converted from JSON, so
+// that both use the API's field names.
+func writeData(w io.Writer, output string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding output: %v", err)
+	}
+
+	if output == OutputJSON {
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return fmt.Errorf("error encoding output: %v", err)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(generic); err != nil {
+		return fmt.Errorf("error encoding output: %v", err)
+	}
+	return enc.Close()
+}
+
+// readThreatModels reads a list of threat models, as written by
+// writeThreatModels, in JSON or YAML.
+func readThreatModels(r io.Reader) ([]*m.ThreatModel, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML is a superset of JSON, so this reads either
+	var generic interface{}
+	if err := yaml.Unmarshal(b, &generic); err != nil {
+		return nil, fmt.Errorf("error parsing threat models: %v", err)
+	}
+
+	b, err = json.Marshal(generic)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing threat models: %v", err)
+	}
+
+	var threatModels []*m.ThreatModel
+	if err := json.Unmarshal(b, &threatModels); err != nil {
+		return nil, fmt.Errorf("error parsing threat models: %v", err)
+	}
+
+	return threatModels, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	serviceutil "github.com/jtyers/tmaas-service-util"
+	"github.com/spf13/cobra"
+
+	"github.com/jtyers/tmaas-threat-model-api/client"
+)
+
+// options are the flags common to every command.
+type options struct {
+	configPath string
+	url        string
+	token      string
+	output     string
+}
+
+func newRootCommand() *cobra.Command {
+	o := &options{}
+
+	cmd := &cobra.Command{
+		Use:          "tmctl",
+		Short:        "Manage threat models through the Threat Model API",
+		SilenceUsage: true,
+	}
+
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&o.configPath, "config", "", "config file (default $TMCTL_CONFIG, or tmctl/config.yaml in the user config directory)")
+	flags.StringVar(&o.url, "url", "", "base URL of the Threat Model API (overrides config and TMCTL_URL)")
+	flags.StringVar(&o.token, "token", "", "token to call the API with (overrides config and TMCTL_TOKEN)")
+	flags.StringVarP(&o.output, "output", "o", OutputTable, "output format: table, json or yaml")
+
+	cmd.AddCommand(
+		newListCommand(o),
+		newGetCommand(o),
+		newCreateCommand(o),
+		newUpdateCommand(o),
+		newDeleteCommand(o),
+		newQueryCommand(o),
+		newExportCommand(o),
+		newImportCommand(o),
+	)
+
+	return cmd
+}
+
+// client returns an API client configured from the config file,
+// environment and flags.
+func (o *options) client() (*client.ThreatModelServiceClient, error) {
+	path := o.configPath
+	if path == "" {
+		var err error
+		if path, err = defaultConfigPath(); err != nil {
+			return nil, err
+		}
+	}
+
+	config, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.url != "" {
+		config.URL = o.url
+	}
+	if o.token != "" {
+		config.Token = o.token
+	}
+	if config.Token == "" {
+		return nil, ErrNoToken
+	}
+
+	baseURL := serviceutil.EnsureSuffix(config.URL, "/")
+	api, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing URL %s: %v", config.URL, err)
+	}
+
+	return client.NewThreatModelServiceClient(client.ThreatModelServiceClientConfig{
+		BaseURL:   baseURL,
+		Transport: &tokenTransport{token: config.Token, api: api},
+	}), nil
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	m "github.com/jtyers/tmaas-model"
+)
+
+const testToken = "s3cret"
+
+// fakeAPI serves the threat model routes used by the API client from
+// memory, rejecting requests without testToken.
+type fakeAPI struct {
+	mu           sync.Mutex
+	threatModels map[string]*m.ThreatModel
+	nextID       int
+}
+
+func newFakeAPI(threatModels ...*m.ThreatModel) *fakeAPI {
+	api := &fakeAPI{threatModels: map[string]*m.ThreatModel{}}
+	for _, threatModel := range threatModels {
+		api.threatModels[threatModel.ThreatModelID.String()] = threatModel
+	}
+	return api
+}
+
+func (a *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/v1/threatmodel"), "/")
+
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		result := []*m.ThreatModel{}
+		for _, threatModel := range a.threatModels {
+			result = append(result, threatModel)
+		}
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].ThreatModelID.String() < result[j].ThreatModelID.String()
+		})
+		json.NewEncoder(w).Encode(result)
+
+	case id == "" && r.Method == http.MethodPut:
+		var params m.ThreatModelParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		a.nextID++
+		threatModel := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP(fmt.Sprintf("tm-new-%d", a.nextID))}
+		applyParams(threatModel, params)
+		a.threatModels[threatModel.ThreatModelID.String()] = threatModel
+		json.NewEncoder(w).Encode(threatModel)
+
+	case a.threatModels[id] == nil:
+		w.WriteHeader(http.StatusNotFound)
+
+	case r.Method == http.MethodGet:
+		json.NewEncoder(w).Encode(a.threatModels[id])
+
+	case r.Method == http.MethodPatch:
+		var params m.ThreatModelParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		applyParams(a.threatModels[id], params)
+		json.NewEncoder(w).Encode(a.threatModels[id])
+
+	case r.Method == http.MethodDelete:
+		delete(a.threatModels, id)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func applyParams(threatModel *m.ThreatModel, params m.ThreatModelParams) {
+	if params.Title != nil {
+		threatModel.Title = *params.Title
+	}
+	if params.Description != nil {
+		threatModel.Description = *params.Description
+	}
+	if params.DataFlowDiagramID != nil {
+		threatModel.DataFlowDiagramID = *params.DataFlowDiagramID
+	}
+}
+
+func testThreatModels() []*m.ThreatModel {
+	return []*m.ThreatModel{
+		{
+			ThreatModelID:     m.NewThreatModelIDP("tm-1"),
+			DataFlowDiagramID: m.NewDataFlowDiagramIDP("dfd-1"),
+			Title:             "payments",
+			Threats:           []*m.Threat{{Title: "spoofing"}},
+		},
+		{
+			ThreatModelID:     m.NewThreatModelIDP("tm-2"),
+			DataFlowDiagramID: m.NewDataFlowDiagramIDP("dfd-2"),
+			Title:             "search",
+		},
+	}
+}
+
+// runTmctl runs tmctl against a server for api with args, returning its
+// output. The token is given in the environment unless args set one.
+func runTmctl(t *testing.T, api *fakeAPI, stdin string, args ...string) (string, error) {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	// never read the developer's own config
+	t.Setenv(configEnv, filepath.Join(t.TempDir(), "missing.yaml"))
+	t.Setenv(urlEnv, server.URL)
+	t.Setenv(tokenEnv, testToken)
+
+	var stdout bytes.Buffer
+	cmd := newRootCommand()
+	cmd.SetArgs(args)
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+
+	err := cmd.Execute()
+	return stdout.String(), err
+}
+
+func TestCommands(t *testing.T) {
+	var tests = []struct {
+		name           string
+		args           []string
+		expectedOutput string
+		partialOutput  bool // expectedOutput need only appear in the output
+		expectedError  string
+		expectedTitles map[string]string // titles of the API's threat models afterwards, if not nil
+	}{
+		{
+			name: "should list threat models as a table",
+			args: []string{"list"},
+			expectedOutput: "ID    TITLE     DATA FLOW DIAGRAM  THREATS\n" +
+				"tm-1  payments  dfd-1              1\n" +
+				"tm-2  search    dfd-2              0\n",
+		},
+		{
+			name:           "should get a threat model as YAML",
+			args:           []string{"get", "tm-2", "-o", "yaml"},
+			expectedOutput: "Title: search\n",
+			partialOutput:  true,
+		},
+		{
+			name:          "should report threat models that do not exist",
+			args:          []string{"get", "tm-404"},
+			expectedError: "error getting threat model tm-404: no such threat model",
+		},
+		{
+			name:           "should create a threat model",
+			args:           []string{"create", "--title", "billing", "--dfd", "dfd-3"},
+			expectedOutput: "ID        TITLE    DATA FLOW DIAGRAM  THREATS\ntm-new-1  billing  dfd-3              0\n",
+			expectedTitles: map[string]string{"tm-1": "payments", "tm-2": "search", "tm-new-1": "billing"},
+		},
+		{
+			name:           "should update only the flags given",
+			args:           []string{"update", "tm-1", "--title", "payments v2"},
+			expectedOutput: "ID    TITLE        DATA FLOW DIAGRAM  THREATS\ntm-1  payments v2  dfd-1              1\n",
+		},
+		{
+			name:          "should not update without flags",
+			args:          []string{"update", "tm-1"},
+			expectedError: ErrNothingToUpdate.Error(),
+		},
+		{
+			name:           "should delete threat models",
+			args:           []string{"delete", "tm-1", "tm-2"},
+			expectedOutput: "deleted tm-1\ndeleted tm-2\n",
+			expectedTitles: map[string]string{},
+		},
+		{
+			name:           "should query threat models by data flow diagram",
+			args:           []string{"query", "--dfd", "dfd-2"},
+			expectedOutput: "ID    TITLE   DATA FLOW DIAGRAM  THREATS\ntm-2  search  dfd-2              0\n",
+		},
+		{
+			name:          "should not query without flags",
+			args:          []string{"query"},
+			expectedError: ErrNoQuery.Error(),
+		},
+		{
+			name:          "should reject unknown output formats",
+			args:          []string{"list", "-o", "xml"},
+			expectedError: ErrInvalidOutput.Error(),
+		},
+		{
+			name:          "should report bad tokens",
+			args:          []string{"list", "--token", "wrong"},
+			expectedError: "error listing threat models: unauthorized (HTTP 401)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			api := newFakeAPI(testThreatModels()...)
+
+			// when
+			output, err := runTmctl(t, api, "", test.args...)
+
+			// then
+			if test.expectedError != "" {
+				require.NotNil(t, err)
+				require.Contains(t, err.Error(), test.expectedError)
+				return
+			}
+
+			require.Nil(t, err)
+			if test.partialOutput {
+				require.Contains(t, output, test.expectedOutput)
+			} else {
+				require.Equal(t, test.expectedOutput, output)
+			}
+
+			if test.expectedTitles != nil {
+				titles := map[string]string{}
+				for id, threatModel := range api.threatModels {
+					titles[id] = threatModel.Title
+				}
+				require.Equal(t, test.expectedTitles, titles)
+			}
+		})
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	for _, output := range []string{OutputJSON, OutputYAML} {
+		t.Run(output, func(t *testing.T) {
+			// given
+			file := filepath.Join(t.TempDir(), "export")
+			from := newFakeAPI(testThreatModels()...)
+			to := newFakeAPI()
+
+			// when
+			_, err := runTmctl(t, from, "", "export", "-o", output, "-f", file)
+			require.Nil(t, err)
+
+			export, err := os.ReadFile(file)
+			require.Nil(t, err)
+
+			_, err = runTmctl(t, to, string(export), "import", "-")
+			require.Nil(t, err)
+
+			// then
+			require.Len(t, to.threatModels, 2)
+			require.Equal(t, "payments", to.threatModels["tm-new-1"].Title)
+			require.Equal(t, m.NewDataFlowDiagramIDP("dfd-1"), to.threatModels["tm-new-1"].DataFlowDiagramID)
+			require.Equal(t, "search", to.threatModels["tm-new-2"].Title)
+		})
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	// given
+	api := newFakeAPI(testThreatModels()...)
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.Nil(t, os.WriteFile(path, []byte("url: "+server.URL+"\ntoken: "+testToken+"\n"), 0600))
+
+	t.Setenv(configEnv, path)
+	t.Setenv(urlEnv, "")
+	t.Setenv(tokenEnv, "")
+
+	var stdout bytes.Buffer
+	cmd := newRootCommand()
+	cmd.SetArgs([]string{"get", "tm-1", "-o", "json"})
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+
+	// when
+	err := cmd.Execute()
+
+	// then
+	require.Nil(t, err)
+	require.Contains(t, stdout.String(), `"Title": "payments"`)
+}
+
+func TestNoToken(t *testing.T) {
+	// given
+	t.Setenv(configEnv, filepath.Join(t.TempDir(), "missing.yaml"))
+	t.Setenv(tokenEnv, "")
+
+	cmd := newRootCommand()
+	cmd.SetArgs([]string{"list"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+
+	// when
+	err := cmd.Execute()
+
+	// then
+	require.Equal(t, ErrNoToken, err)
+}
+
+func TestTokenNotSentToOtherHosts(t *testing.T) {
+	// given an API that redirects to another host
+	var authorization []string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(m.ThreatModel{Title: "payments"})
+	}))
+	defer other.Close()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer "+testToken, r.Header.Get("Authorization"))
+		http.Redirect(w, r, other.URL+r.URL.Path, http.StatusTemporaryRedirect)
+	}))
+	defer api.Close()
+
+	t.Setenv(configEnv, filepath.Join(t.TempDir(), "missing.yaml"))
+	t.Setenv(urlEnv, api.URL)
+	t.Setenv(tokenEnv, testToken)
+
+	var stdout bytes.Buffer
+	cmd := newRootCommand()
+	cmd.SetArgs([]string{"get", "tm-1", "-o", "json"})
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+
+	// when
+	err := cmd.Execute()
+
+	// then the redirect is followed without the token
+	require.Nil(t, err)
+	require.Contains(t, stdout.String(), `"Title": "payments"`)
+	require.Equal(t, []string{""}, authorization)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// tokenTransport adds the caller's token to each request to the API, and
+// to no other host, so that the token is not sent on where the API
+// redirects to another host.
+type tokenTransport struct {
+	token string
+	api   *url.URL
+	base  http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if req.URL.Scheme != t.api.Scheme || req.URL.Host != t.api.Host {
+		return base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the request they are given
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", bearer(t.token))
+
+	return base.RoundTrip(req)
+}
+
+func bearer(token string) string {
+	if strings.HasPrefix(token, "Bearer ") {
+		return token
+	}
+	return "Bearer " + token
+}
@@ -0,0 +1,392 @@
+package dao
+
+//go:generate mockgen -source=$GOFILE -destination=${GOFILE}_mocks.go -package $GOPACKAGE
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	gdatastore "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"google.golang.org/api/iterator"
+)
+
+var (
+	ErrInvalidPageToken = errors.New("invalid page token")
+)
+
+// AuditDao stores audit records. It is deliberately append-only: there is
+// no way to change or remove a record once written, other than linking it
+// into its resource's hash chain.
+//
+// Records are appended unlinked, so that writing them never contends on a
+// chain head, and linked afterwards by Link.
+//
+// Queries combining an equality filter with a time range need composite
+// indexes on (ResourceID, -Time), (Actor, -Time) and
+// (ResourceID, Actor, -Time); GetChain needs one on
+// (ResourceType, ResourceID, Sequence), and GetUnlinked one on
+// (Pending, Time).
+type AuditDao interface {
+	// Append records, generating their AuditRecordIDs. Records acting on a
+	// resource are left to be linked into its hash chain by Link.
+	Append(ctx context.Context, records []tm.AuditRecord) error
+
+	// Retrieve the tenants that may have audit records.
+	GetTenants(ctx context.Context) ([]auth.TenantID, error)
+
+	// Retrieve up to limit records not yet linked into their resource's
+	// hash chain, oldest first.
+	GetUnlinked(ctx context.Context, limit int) ([]*tm.AuditRecord, error)
+
+	// Count the records not yet linked into their resource's hash chain.
+	CountUnlinked(ctx context.Context) (int, error)
+
+	// Link records of a single resource onto the end of its hash chain, in
+	// the order given, skipping any already linked, hashing them with key.
+	// At most MaxAuditLinkRecords may be linked at once.
+	Link(ctx context.Context, records []*tm.AuditRecord, key tm.AuditKey) error
+
+	// Retrieve a page of records matching a query, newest first.
+	Query(ctx context.Context, q tm.AuditQuery) (*tm.AuditPage, error)
+
+	// Retrieve the linked records of a resource's hash chain in sequence
+	// order, along with its head. The head is nil if no record has been
+	// linked.
+	GetChain(ctx context.Context, resourceType string, resourceID string) ([]*tm.AuditRecord, *AuditChainHead, error)
+}
+
+// MaxAuditLinkRecords is the most records Link will link at once: they are
+// written in one commit along with the chain head.
+const MaxAuditLinkRecords = maxMutations - 1
+
+// AuditChainHead records the last link of a resource's hash chain. It is
+// written in the same transaction as the records linked, so records removed
+// from the end of the chain are detectable too.
+type AuditChainHead struct {
+	Sequence int64
+	Hash     string `datastore:",noindex"`
+}
+
+// auditEntity is the Datastore representation of an AuditRecord.
+type auditEntity struct {
+	Time         time.Time
+	Actor        string
+	ActorType    string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Outcome      string
+	StatusCode   int
+	RequestID    string
+	Detail       string `datastore:",noindex"`
+	Sequence     int64
+	PreviousHash string `datastore:",noindex"`
+	Hash         string `datastore:",noindex"`
+
+	// true until the record is linked into its resource's hash chain
+	Pending bool
+}
+
+type DatastoreAuditDao struct {
+	client *gdatastore.Client
+}
+
+var _ AuditDao = (*DatastoreAuditDao)(nil)
+
+func NewDatastoreAuditDao(client *gdatastore.Client) *DatastoreAuditDao {
+	return &DatastoreAuditDao{client}
+}
+
+func (d *DatastoreAuditDao) Append(ctx context.Context, records []tm.AuditRecord) error {
+	for start := 0; start < len(records); start += maxMutations {
+		end := start + maxMutations
+		if end > len(records) {
+			end = len(records)
+		}
+
+		keys := make([]*gdatastore.Key, 0, end-start)
+		entities := make([]*auditEntity, 0, end-start)
+		for _, record := range records[start:end] {
+			record.AuditRecordID = tm.AuditRecordIDPrefix + uuid.NewString()
+
+			// Datastore keeps times to the microsecond, so truncate now in
+			// order that the hash still matches when the record is read back
+			record.Time = record.Time.UTC().Truncate(time.Microsecond)
+
+			key, err := tenantKey(ctx, AuditDatastoreKeyKind, record.AuditRecordID)
+			if err != nil {
+				return err
+			}
+
+			entity := auditToEntity(record)
+			entity.Pending = record.ResourceID != ""
+
+			keys = append(keys, key)
+			entities = append(entities, entity)
+		}
+
+		if _, err := d.client.PutMulti(ctx, keys, entities); err != nil {
+			return fmt.Errorf("error appending audit records: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (d *DatastoreAuditDao) GetTenants(ctx context.Context) ([]auth.TenantID, error) {
+	return ListTenants(ctx, d.client)
+}
+
+func (d *DatastoreAuditDao) CountUnlinked(ctx context.Context) (int, error) {
+	q, err := tenantQuery(ctx, AuditDatastoreKeyKind)
+	if err != nil {
+		return 0, err
+	}
+	q = q.FilterField("Pending", "=", true)
+
+	n, err := d.client.Count(ctx, q)
+	if err != nil {
+		return 0, fmt.Errorf("error counting unlinked audit records: %v", err)
+	}
+	return n, nil
+}
+
+func (d *DatastoreAuditDao) GetUnlinked(ctx context.Context, limit int) ([]*tm.AuditRecord, error) {
+	q, err := tenantQuery(ctx, AuditDatastoreKeyKind)
+	if err != nil {
+		return nil, err
+	}
+	q = q.FilterField("Pending", "=", true).
+		Order("Time").
+		Limit(limit)
+
+	entities := []auditEntity{}
+	keys, err := d.client.GetAll(ctx, q, &entities)
+	if err != nil {
+		return nil, fmt.Errorf("error getting unlinked audit records: %v", err)
+	}
+
+	result := make([]*tm.AuditRecord, len(entities))
+	for i, e := range entities {
+		result[i] = auditFromEntity(keys[i].Name, e)
+	}
+
+	return result, nil
+}
+
+func (d *DatastoreAuditDao) Link(ctx context.Context, records []*tm.AuditRecord, key tm.AuditKey) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if len(records) > MaxAuditLinkRecords {
+		return fmt.Errorf("at most %d audit records may be linked at once", MaxAuditLinkRecords)
+	}
+
+	resourceType, resourceID := records[0].ResourceType, records[0].ResourceID
+
+	keys := make([]*gdatastore.Key, len(records))
+	for i, record := range records {
+		if record.ResourceType != resourceType || record.ResourceID != resourceID {
+			return fmt.Errorf("audit records of %s and %s cannot be linked together",
+				auditChainName(resourceType, resourceID), auditChainName(record.ResourceType, record.ResourceID))
+		}
+
+		key, err := tenantKey(ctx, AuditDatastoreKeyKind, record.AuditRecordID)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+
+	headKey, err := tenantKey(ctx, AuditChainDatastoreKeyKind, auditChainName(resourceType, resourceID))
+	if err != nil {
+		return err
+	}
+
+	_, err = d.client.RunInTransaction(ctx, func(tx *gdatastore.Transaction) error {
+		// re-read the records, as another writer may have linked them
+		// since they were retrieved
+		stored := make([]auditEntity, len(keys))
+		if err := tx.GetMulti(keys, stored); err != nil {
+			return err
+		}
+
+		head := AuditChainHead{}
+		err := tx.Get(headKey, &head)
+		if err != nil && err != gdatastore.ErrNoSuchEntity {
+			return err
+		}
+
+		linkedKeys := []*gdatastore.Key{}
+		linked := []*auditEntity{}
+		for i, e := range stored {
+			if !e.Pending {
+				continue
+			}
+
+			record := auditFromEntity(keys[i].Name, e)
+			record.Sequence = head.Sequence + 1
+			record.PreviousHash = head.Hash
+			record.Hash = record.ComputeHash(key)
+
+			head = AuditChainHead{record.Sequence, record.Hash}
+			linkedKeys = append(linkedKeys, keys[i])
+			linked = append(linked, auditToEntity(*record))
+		}
+
+		if len(linked) == 0 {
+			return nil
+		}
+
+		if _, err := tx.PutMulti(linkedKeys, linked); err != nil {
+			return err
+		}
+		_, err = tx.Put(headKey, &head)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error linking audit records of %s: %v", auditChainName(resourceType, resourceID), err)
+	}
+
+	return nil
+}
+
+func (d *DatastoreAuditDao) GetChain(ctx context.Context, resourceType string, resourceID string) ([]*tm.AuditRecord, *AuditChainHead, error) {
+	headKey, err := tenantKey(ctx, AuditChainDatastoreKeyKind, auditChainName(resourceType, resourceID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var head *AuditChainHead
+	h := AuditChainHead{}
+	err = d.client.Get(ctx, headKey, &h)
+	if err == nil {
+		head = &h
+	} else if err != gdatastore.ErrNoSuchEntity {
+		return nil, nil, fmt.Errorf("error getting audit chain head: %v", err)
+	}
+
+	q, err := tenantQuery(ctx, AuditDatastoreKeyKind)
+	if err != nil {
+		return nil, nil, err
+	}
+	// records not yet linked have no sequence
+	q = q.FilterField("ResourceType", "=", resourceType).
+		FilterField("ResourceID", "=", resourceID).
+		FilterField("Sequence", ">", 0).
+		Order("Sequence")
+
+	entities := []auditEntity{}
+	keys, err := d.client.GetAll(ctx, q, &entities)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting audit chain: %v", err)
+	}
+
+	result := make([]*tm.AuditRecord, len(entities))
+	for i, e := range entities {
+		result[i] = auditFromEntity(keys[i].Name, e)
+	}
+
+	return result, head, nil
+}
+
+func auditChainName(resourceType string, resourceID string) string {
+	return resourceType + "/" + resourceID
+}
+
+func (d *DatastoreAuditDao) Query(ctx context.Context, q tm.AuditQuery) (*tm.AuditPage, error) {
+	query, err := tenantQuery(ctx, AuditDatastoreKeyKind)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.ResourceID != "" {
+		query = query.FilterField("ResourceID", "=", q.ResourceID)
+	}
+	if q.Actor != "" {
+		query = query.FilterField("Actor", "=", q.Actor)
+	}
+	if q.From != nil {
+		query = query.FilterField("Time", ">=", *q.From)
+	}
+	if q.To != nil {
+		query = query.FilterField("Time", "<", *q.To)
+	}
+	query = query.Order("-Time").Limit(q.PageSize)
+
+	if q.PageToken != "" {
+		cursor, err := gdatastore.DecodeCursor(q.PageToken)
+		if err != nil {
+			return nil, ErrInvalidPageToken
+		}
+		query = query.Start(cursor)
+	}
+
+	result := &tm.AuditPage{Records: []*tm.AuditRecord{}}
+
+	it := d.client.Run(ctx, query)
+	for {
+		e := auditEntity{}
+		key, err := it.Next(&e)
+		if err == iterator.Done {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("error querying audit records: %v", err)
+		}
+
+		result.Records = append(result.Records, auditFromEntity(key.Name, e))
+	}
+
+	// a full page means there may be more
+	if len(result.Records) == q.PageSize {
+		cursor, err := it.Cursor()
+		if err != nil {
+			return nil, fmt.Errorf("error querying audit records: %v", err)
+		}
+		result.NextPageToken = cursor.String()
+	}
+
+	return result, nil
+}
+
+func auditToEntity(r tm.AuditRecord) *auditEntity {
+	return &auditEntity{
+		Time:         r.Time,
+		Actor:        r.Actor,
+		ActorType:    r.ActorType,
+		Action:       r.Action,
+		ResourceType: r.ResourceType,
+		ResourceID:   r.ResourceID,
+		Outcome:      string(r.Outcome),
+		StatusCode:   r.StatusCode,
+		RequestID:    r.RequestID,
+		Detail:       r.Detail,
+		Sequence:     r.Sequence,
+		PreviousHash: r.PreviousHash,
+		Hash:         r.Hash,
+	}
+}
+
+func auditFromEntity(id string, e auditEntity) *tm.AuditRecord {
+	return &tm.AuditRecord{
+		AuditRecordID: id,
+		Time:          e.Time,
+		Actor:         e.Actor,
+		ActorType:     e.ActorType,
+		Action:        e.Action,
+		ResourceType:  e.ResourceType,
+		ResourceID:    e.ResourceID,
+		Outcome:       tm.AuditOutcome(e.Outcome),
+		StatusCode:    e.StatusCode,
+		RequestID:     e.RequestID,
+		Detail:        e.Detail,
+		Sequence:      e.Sequence,
+		PreviousHash:  e.PreviousHash,
+		Hash:          e.Hash,
+	}
+}
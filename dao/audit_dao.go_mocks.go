@@ -0,0 +1,141 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: audit_dao.go
+
+// Package dao is a generated GoMock package.
+package dao
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	auth "github.com/jtyers/tmaas-threat-model-api/auth"
+	model "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+// MockAuditDao is a mock of AuditDao interface.
+type MockAuditDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditDaoMockRecorder
+}
+
+// MockAuditDaoMockRecorder is the mock recorder for MockAuditDao.
+type MockAuditDaoMockRecorder struct {
+	mock *MockAuditDao
+}
+
+// NewMockAuditDao creates a new mock instance.
+func NewMockAuditDao(ctrl *gomock.Controller) *MockAuditDao {
+	mock := &MockAuditDao{ctrl: ctrl}
+	mock.recorder = &MockAuditDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditDao) EXPECT() *MockAuditDaoMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockAuditDao) Append(ctx context.Context, records []model.AuditRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", ctx, records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockAuditDaoMockRecorder) Append(ctx, records interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockAuditDao)(nil).Append), ctx, records)
+}
+
+// CountUnlinked mocks base method.
+func (m *MockAuditDao) CountUnlinked(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnlinked", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnlinked indicates an expected call of CountUnlinked.
+func (mr *MockAuditDaoMockRecorder) CountUnlinked(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnlinked", reflect.TypeOf((*MockAuditDao)(nil).CountUnlinked), ctx)
+}
+
+// GetChain mocks base method.
+func (m *MockAuditDao) GetChain(ctx context.Context, resourceType, resourceID string) ([]*model.AuditRecord, *AuditChainHead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChain", ctx, resourceType, resourceID)
+	ret0, _ := ret[0].([]*model.AuditRecord)
+	ret1, _ := ret[1].(*AuditChainHead)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetChain indicates an expected call of GetChain.
+func (mr *MockAuditDaoMockRecorder) GetChain(ctx, resourceType, resourceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChain", reflect.TypeOf((*MockAuditDao)(nil).GetChain), ctx, resourceType, resourceID)
+}
+
+// GetTenants mocks base method.
+func (m *MockAuditDao) GetTenants(ctx context.Context) ([]auth.TenantID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenants", ctx)
+	ret0, _ := ret[0].([]auth.TenantID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenants indicates an expected call of GetTenants.
+func (mr *MockAuditDaoMockRecorder) GetTenants(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenants", reflect.TypeOf((*MockAuditDao)(nil).GetTenants), ctx)
+}
+
+// GetUnlinked mocks base method.
+func (m *MockAuditDao) GetUnlinked(ctx context.Context, limit int) ([]*model.AuditRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnlinked", ctx, limit)
+	ret0, _ := ret[0].([]*model.AuditRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnlinked indicates an expected call of GetUnlinked.
+func (mr *MockAuditDaoMockRecorder) GetUnlinked(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnlinked", reflect.TypeOf((*MockAuditDao)(nil).GetUnlinked), ctx, limit)
+}
+
+// Link mocks base method.
+func (m *MockAuditDao) Link(ctx context.Context, records []*model.AuditRecord, key model.AuditKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Link", ctx, records, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Link indicates an expected call of Link.
+func (mr *MockAuditDaoMockRecorder) Link(ctx, records, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Link", reflect.TypeOf((*MockAuditDao)(nil).Link), ctx, records, key)
+}
+
+// Query mocks base method.
+func (m *MockAuditDao) Query(ctx context.Context, q model.AuditQuery) (*model.AuditPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, q)
+	ret0, _ := ret[0].(*model.AuditPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockAuditDaoMockRecorder) Query(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockAuditDao)(nil).Query), ctx, q)
+}
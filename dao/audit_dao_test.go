@@ -0,0 +1,88 @@
+package dao
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jtyers/tmaas-threat-model-api/dao/datastoretest"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatastoreAuditDaoLinksAppendedRecords(t *testing.T) {
+	// given
+	ctx := inTenant("acme")
+	auditDao := NewDatastoreAuditDao(datastoretest.NewClient(t))
+	start := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	key := tm.AuditKey("0123456789abcdef0123456789abcdef")
+
+	record := func(minute int, resourceID string) tm.AuditRecord {
+		return tm.AuditRecord{
+			Time:         start.Add(time.Duration(minute) * time.Minute),
+			Action:       "threatmodel.get",
+			ResourceType: "threatmodel",
+			ResourceID:   resourceID,
+			Outcome:      tm.AuditOutcomeSuccess,
+		}
+	}
+
+	require.Nil(t, auditDao.Append(ctx, []tm.AuditRecord{record(1, "tm-1"), record(2, "tm-2"), record(3, ""), record(4, "tm-1")}))
+
+	// when
+	unlinked, err := auditDao.GetUnlinked(ctx, 10)
+
+	// then records acting on no resource have no chain to be linked into
+	require.Nil(t, err)
+	require.Len(t, unlinked, 3)
+	require.Equal(t, []string{"tm-1", "tm-2", "tm-1"}, []string{unlinked[0].ResourceID, unlinked[1].ResourceID, unlinked[2].ResourceID})
+
+	n, err := auditDao.CountUnlinked(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 3, n)
+
+	records, head, err := auditDao.GetChain(ctx, "threatmodel", "tm-1")
+	require.Nil(t, err)
+	require.Empty(t, records)
+	require.Nil(t, head)
+
+	// when linked, twice over as if by two writers
+	tm1 := []*tm.AuditRecord{unlinked[0], unlinked[2]}
+	require.Nil(t, auditDao.Link(ctx, tm1, key))
+	require.Nil(t, auditDao.Link(ctx, tm1, key))
+
+	// then
+	records, head, err = auditDao.GetChain(ctx, "threatmodel", "tm-1")
+	require.Nil(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, []int64{1, 2}, []int64{records[0].Sequence, records[1].Sequence})
+	require.Equal(t, "", records[0].PreviousHash)
+	require.Equal(t, records[0].Hash, records[1].PreviousHash)
+	for _, r := range records {
+		require.Equal(t, r.ComputeHash(key), r.Hash)
+	}
+	require.Equal(t, &AuditChainHead{Sequence: 2, Hash: records[1].Hash}, head)
+
+	unlinked, err = auditDao.GetUnlinked(ctx, 10)
+	require.Nil(t, err)
+	require.Len(t, unlinked, 1)
+	require.Equal(t, "tm-2", unlinked[0].ResourceID)
+
+	n, err = auditDao.CountUnlinked(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 1, n)
+}
+
+func TestDatastoreAuditDaoRefusesToLinkRecordsOfSeveralResources(t *testing.T) {
+	// given
+	ctx := inTenant("acme")
+	auditDao := NewDatastoreAuditDao(datastoretest.NewClient(t))
+
+	// when
+	err := auditDao.Link(ctx, []*tm.AuditRecord{
+		{AuditRecordID: "aud-1", ResourceType: "threatmodel", ResourceID: "tm-1"},
+		{AuditRecordID: "aud-2", ResourceType: "threatmodel", ResourceID: "tm-2"},
+	}, tm.AuditKey("0123456789abcdef0123456789abcdef"))
+
+	// then
+	require.NotNil(t, err)
+}
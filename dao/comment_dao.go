@@ -0,0 +1,216 @@
+package dao
+
+//go:generate mockgen -source=$GOFILE -destination=${GOFILE}_mocks.go -package $GOPACKAGE
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	gdatastore "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	m "github.com/jtyers/tmaas-model"
+	servicedao "github.com/jtyers/tmaas-service-dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+// CommentDao stores comments on threat models and their threats.
+type CommentDao interface {
+	// Retrieve a Comment by ID, returning servicedao.ErrNoSuchDocument if
+	// it does not exist.
+	Get(ctx context.Context, id tm.CommentID) (*tm.Comment, error)
+
+	// Retrieve all comments on a threat model (including those on its
+	// threats), oldest first.
+	GetAllForThreatModel(ctx context.Context, threatModelID m.ThreatModelID) ([]*tm.Comment, error)
+
+	// Create a comment. The CommentID is generated by the DAO.
+	Create(ctx context.Context, comment tm.Comment) (*tm.Comment, error)
+
+	// Replace an existing comment.
+	Update(ctx context.Context, comment tm.Comment) (*tm.Comment, error)
+
+	// Delete a comment by ID.
+	Delete(ctx context.Context, id tm.CommentID) error
+}
+
+// commentEntity is the Datastore representation of a Comment. IDs are
+// stored as plain strings so they can be indexed and queried on.
+type commentEntity struct {
+	ThreatModelID   string
+	ThreatID        string
+	ParentCommentID string
+	Author          string
+	Body            string `datastore:",noindex"`
+	Mentions        []string
+	Resolved        bool
+	ResolvedBy      string
+	ResolvedAt      time.Time
+	Deleted         bool
+	Created         time.Time
+	Updated         time.Time
+}
+
+type DatastoreCommentDao struct {
+	client *gdatastore.Client
+}
+
+var _ CommentDao = (*DatastoreCommentDao)(nil)
+
+func NewDatastoreCommentDao(client *gdatastore.Client) *DatastoreCommentDao {
+	return &DatastoreCommentDao{client}
+}
+
+func (d *DatastoreCommentDao) key(ctx context.Context, id tm.CommentID) (*gdatastore.Key, error) {
+	return tenantKey(ctx, CommentDatastoreKeyKind, id.String())
+}
+
+func (d *DatastoreCommentDao) Get(ctx context.Context, id tm.CommentID) (*tm.Comment, error) {
+	key, err := d.key(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	e := commentEntity{}
+	err = d.client.Get(ctx, key, &e)
+	if err != nil {
+		if err == gdatastore.ErrNoSuchEntity {
+			return nil, servicedao.ErrNoSuchDocument
+		}
+		return nil, fmt.Errorf("error getting comment %s: %v", id, err)
+	}
+
+	return commentFromEntity(id, e), nil
+}
+
+func (d *DatastoreCommentDao) GetAllForThreatModel(ctx context.Context, threatModelID m.ThreatModelID) ([]*tm.Comment, error) {
+	q, err := tenantQuery(ctx, CommentDatastoreKeyKind)
+	if err != nil {
+		return nil, err
+	}
+	q = q.FilterField("ThreatModelID", "=", threatModelID.String())
+
+	entities := []commentEntity{}
+	keys, err := d.client.GetAll(ctx, q, &entities)
+	if err != nil {
+		return nil, fmt.Errorf("error querying comments for %s: %v", threatModelID, err)
+	}
+
+	result := make([]*tm.Comment, len(keys))
+	for i, key := range keys {
+		result[i] = commentFromEntity(tm.CommentID(key.Name), entities[i])
+	}
+
+	// sort here rather than in the query, so we don't need a composite index
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Created.Before(result[j].Created)
+	})
+
+	return result, nil
+}
+
+func (d *DatastoreCommentDao) Create(ctx context.Context, comment tm.Comment) (*tm.Comment, error) {
+	comment.CommentID = tm.CommentID(tm.CommentIDPrefix + uuid.NewString())
+
+	key, err := d.key(ctx, comment.CommentID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = d.client.Put(ctx, key, commentToEntity(comment))
+	if err != nil {
+		return nil, fmt.Errorf("error creating comment: %v", err)
+	}
+
+	return &comment, nil
+}
+
+func (d *DatastoreCommentDao) Update(ctx context.Context, comment tm.Comment) (*tm.Comment, error) {
+	key, err := d.key(ctx, comment.CommentID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = d.client.Put(ctx, key, commentToEntity(comment))
+	if err != nil {
+		return nil, fmt.Errorf("error updating comment %s: %v", comment.CommentID, err)
+	}
+
+	return &comment, nil
+}
+
+func (d *DatastoreCommentDao) Delete(ctx context.Context, id tm.CommentID) error {
+	key, err := d.key(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = d.client.Delete(ctx, key)
+	if err != nil {
+		return fmt.Errorf("error deleting comment %s: %v", id, err)
+	}
+
+	return nil
+}
+
+func commentToEntity(c tm.Comment) *commentEntity {
+	e := &commentEntity{
+		ThreatModelID: c.ThreatModelID.String(),
+		ThreatID:      c.ThreatID,
+		Author:        string(c.Author),
+		Body:          c.Body,
+		Resolved:      c.Resolved,
+		Deleted:       c.Deleted,
+		Created:       c.Created,
+		Updated:       c.Updated,
+	}
+
+	if c.ParentCommentID != nil {
+		e.ParentCommentID = c.ParentCommentID.String()
+	}
+	if c.ResolvedBy != nil {
+		e.ResolvedBy = string(*c.ResolvedBy)
+	}
+	if c.ResolvedAt != nil {
+		e.ResolvedAt = *c.ResolvedAt
+	}
+	for _, mention := range c.Mentions {
+		e.Mentions = append(e.Mentions, string(mention))
+	}
+
+	return e
+}
+
+func commentFromEntity(id tm.CommentID, e commentEntity) *tm.Comment {
+	c := &tm.Comment{
+		CommentID:     id,
+		ThreatModelID: m.NewThreatModelIDP(e.ThreatModelID),
+		ThreatID:      e.ThreatID,
+		Author:        m.UserID(e.Author),
+		Body:          e.Body,
+		Mentions:      []m.UserID{},
+		Resolved:      e.Resolved,
+		Deleted:       e.Deleted,
+		Created:       e.Created,
+		Updated:       e.Updated,
+	}
+
+	if e.ParentCommentID != "" {
+		parentID := tm.CommentID(e.ParentCommentID)
+		c.ParentCommentID = &parentID
+	}
+	if e.ResolvedBy != "" {
+		resolvedBy := m.UserID(e.ResolvedBy)
+		c.ResolvedBy = &resolvedBy
+	}
+	if !e.ResolvedAt.IsZero() {
+		resolvedAt := e.ResolvedAt
+		c.ResolvedAt = &resolvedAt
+	}
+	for _, mention := range e.Mentions {
+		c.Mentions = append(c.Mentions, m.UserID(mention))
+	}
+
+	return c
+}
@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: comment_dao.go
+
+// Package dao is a generated GoMock package.
+package dao
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/jtyers/tmaas-model"
+	model0 "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+// MockCommentDao is a mock of CommentDao interface.
+type MockCommentDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentDaoMockRecorder
+}
+
+// MockCommentDaoMockRecorder is the mock recorder for MockCommentDao.
+type MockCommentDaoMockRecorder struct {
+	mock *MockCommentDao
+}
+
+// NewMockCommentDao creates a new mock instance.
+func NewMockCommentDao(ctrl *gomock.Controller) *MockCommentDao {
+	mock := &MockCommentDao{ctrl: ctrl}
+	mock.recorder = &MockCommentDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommentDao) EXPECT() *MockCommentDaoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockCommentDao) Create(ctx context.Context, comment model0.Comment) (*model0.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, comment)
+	ret0, _ := ret[0].(*model0.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCommentDaoMockRecorder) Create(ctx, comment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCommentDao)(nil).Create), ctx, comment)
+}
+
+// Delete mocks base method.
+func (m *MockCommentDao) Delete(ctx context.Context, id model0.CommentID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCommentDaoMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCommentDao)(nil).Delete), ctx, id)
+}
+
+// Get mocks base method.
+func (m *MockCommentDao) Get(ctx context.Context, id model0.CommentID) (*model0.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*model0.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockCommentDaoMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCommentDao)(nil).Get), ctx, id)
+}
+
+// GetAllForThreatModel mocks base method.
+func (m *MockCommentDao) GetAllForThreatModel(ctx context.Context, threatModelID model.ThreatModelID) ([]*model0.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllForThreatModel", ctx, threatModelID)
+	ret0, _ := ret[0].([]*model0.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllForThreatModel indicates an expected call of GetAllForThreatModel.
+func (mr *MockCommentDaoMockRecorder) GetAllForThreatModel(ctx, threatModelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllForThreatModel", reflect.TypeOf((*MockCommentDao)(nil).GetAllForThreatModel), ctx, threatModelID)
+}
+
+// Update mocks base method.
+func (m *MockCommentDao) Update(ctx context.Context, comment model0.Comment) (*model0.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, comment)
+	ret0, _ := ret[0].(*model0.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCommentDaoMockRecorder) Update(ctx, comment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCommentDao)(nil).Update), ctx, comment)
+}
@@ -3,4 +3,21 @@ package dao
 // We gather ID prefixes in one file to make it easy to spot duplicates
 var (
 	DatastoreKeyKind = "threat-model"
+
+	CommentDatastoreKeyKind = "threat-model-comment"
+
+	TagsDatastoreKeyKind = "threat-model-tags"
+
+	ProjectDatastoreKeyKind = "project"
+
+	ThreatModelProjectDatastoreKeyKind = "threat-model-project"
+
+	SearchDatastoreKeyKind = "threat-model-search"
+
+	AuditDatastoreKeyKind      = "audit"
+	AuditChainDatastoreKeyKind = "audit-chain"
+
+	OutboxDatastoreKeyKind      = "threat-model-outbox"
+	OutboxHeadDatastoreKeyKind  = "threat-model-outbox-head"
+	OutboxLeaseDatastoreKeyKind = "threat-model-outbox-lease"
 )
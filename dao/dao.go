@@ -3,11 +3,21 @@ package dao
 //go:generate mockgen -source=$GOFILE -destination=${GOFILE}_mocks.go -package $GOPACKAGE
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
 	gdatastore "cloud.google.com/go/datastore"
 	m "github.com/jtyers/tmaas-model"
 	servicedao "github.com/jtyers/tmaas-service-dao"
 	"github.com/jtyers/tmaas-service-dao/datastore"
 	"github.com/jtyers/tmaas-service-util/id"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+)
+
+var (
+	ErrMultipleThreatModels = errors.New("more than one threat model matches the query")
 )
 
 // ThreatModelDao is needed because wire does not directly support
@@ -22,14 +32,361 @@ type ThreatModelDao interface {
 	//  2. checkout task_HOSPENG-4373-gomock-generics
 	//  3. run `go install ./...`
 	servicedao.IDTypedDao[m.ThreatModelID, m.ThreatModel, m.ThreatModelParams, *m.ThreatModelQuery]
+
+	// Retrieve the threat models with the given IDs in one call, in the
+	// order given. IDs with no threat model are skipped.
+	GetMany(ctx context.Context, ids []m.ThreatModelID) ([]*m.ThreatModel, error)
+
+	// Retrieve the tags on a threat model, which are empty if none have been set.
+	GetTags(ctx context.Context, id m.ThreatModelID) ([]string, error)
+
+	// Replace the tags on a threat model.
+	SetTags(ctx context.Context, id m.ThreatModelID, tags []string) error
+
+	// Add tags to those on a threat model in one transaction, returning
+	// the tags it then carries, sorted, or ErrTooManyTags if that would be
+	// more than maxTags.
+	AddTags(ctx context.Context, id m.ThreatModelID, tags []string, maxTags int) ([]string, error)
+
+	// Remove tags from those on a threat model in one transaction,
+	// returning the tags it then carries.
+	RemoveTags(ctx context.Context, id m.ThreatModelID, tags []string) ([]string, error)
+
+	// Retrieve the IDs of threat models carrying any (or, if matchAll is
+	// true, all) of the given tags.
+	QueryIDsByTags(ctx context.Context, tags []string, matchAll bool) ([]m.ThreatModelID, error)
+
+	// Retrieve the tags of every threat model carrying any, keyed by
+	// threat model ID.
+	GetAllTags(ctx context.Context) (map[m.ThreatModelID][]string, error)
+
+	// Create, update or delete a threat model, appending the record built
+	// by record to the outbox in the same transaction, so that the record
+	// is written if and only if the change is.
+	CreateWithOutbox(ctx context.Context, params m.ThreatModelParams, record OutboxRecordFunc) (*m.ThreatModel, error)
+	UpdateWithOutbox(ctx context.Context, id m.ThreatModelID, params m.ThreatModelParams, record OutboxRecordFunc) (*m.ThreatModel, error)
+	DeleteWithOutbox(ctx context.Context, id m.ThreatModelID, record OutboxRecordFunc) error
+
+	// Count the threat models of the tenant in ctx.
+	Count(ctx context.Context) (int, error)
+
+	// Retrieve the tenants that may have threat models, for jobs that run
+	// across every tenant.
+	GetTenants(ctx context.Context) ([]auth.TenantID, error)
 }
 
 func (ThreatModelIDCreator) Zero() m.ThreatModelID {
 	return m.NewThreatModelID("")
 }
 
-func NewThreatModelDao(client *gdatastore.Client, randomIDProvider id.RandomIDProvider, config datastore.DatastoreConfiguration, idCreator ThreatModelIDCreator) (ThreatModelDao, error) {
-	var errorMappings map[error]error
+// DatastoreThreatModelDao stores threat models, named by ID, and their
+// tags in a separate kind (keyed by threat model ID) since m.ThreatModel
+// has no field for them.
+//
+// The generic Datastore DAO cannot place entities in namespaces, so unlike
+// the other DAOs of this API's services this one uses the Datastore client
+// directly, storing m.ThreatModel as the generic DAO does. Like every DAO
+// here, its keys and queries are built by tenantKey and tenantQuery, so
+// threat models of other tenants are unreachable.
+type DatastoreThreatModelDao struct {
+	client           *gdatastore.Client
+	kind             string
+	randomIDProvider id.RandomIDProvider
+	idCreator        ThreatModelIDCreator
+}
+
+var _ ThreatModelDao = (*DatastoreThreatModelDao)(nil)
+
+func NewThreatModelDao(client *gdatastore.Client, randomIDProvider id.RandomIDProvider, config datastore.DatastoreConfiguration, idCreator ThreatModelIDCreator) (*DatastoreThreatModelDao, error) {
+	return &DatastoreThreatModelDao{
+		client:           client,
+		kind:             config.DatastoreKeyKind,
+		randomIDProvider: randomIDProvider,
+		idCreator:        idCreator,
+	}, nil
+}
+
+func (d *DatastoreThreatModelDao) key(ctx context.Context, id m.ThreatModelID) (*gdatastore.Key, error) {
+	return tenantKey(ctx, d.kind, id.String())
+}
+
+// setID sets the ID of a threat model loaded from key, which names it, so
+// that it is correct however the stored ID field came to be written.
+func (d *DatastoreThreatModelDao) setID(threatModel *m.ThreatModel, key *gdatastore.Key) {
+	threatModel.ThreatModelID = d.idCreator.Create(key.Name)
+}
+
+func (d *DatastoreThreatModelDao) Get(ctx context.Context, id m.ThreatModelID) (*m.ThreatModel, error) {
+	key, err := d.key(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	threatModel := &m.ThreatModel{}
+	err = d.client.Get(ctx, key, threatModel)
+	if err != nil {
+		if err == gdatastore.ErrNoSuchEntity {
+			return nil, servicedao.ErrNoSuchDocument
+		}
+		return nil, fmt.Errorf("error getting threat model %s: %v", id, err)
+	}
+
+	d.setID(threatModel, key)
+	return threatModel, nil
+}
+
+// maxGetMulti is the most keys Datastore will look up in one GetMulti.
+const maxGetMulti = 1000
+
+func (d *DatastoreThreatModelDao) GetMany(ctx context.Context, ids []m.ThreatModelID) ([]*m.ThreatModel, error) {
+	result := []*m.ThreatModel{}
+	for start := 0; start < len(ids); start += maxGetMulti {
+		end := start + maxGetMulti
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		keys := make([]*gdatastore.Key, end-start)
+		for i, id := range ids[start:end] {
+			key, err := d.key(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			keys[i] = key
+		}
+
+		entities := make([]m.ThreatModel, len(keys))
+		err := d.client.GetMulti(ctx, keys, entities)
+
+		errs, isMultiError := err.(gdatastore.MultiError)
+		if err != nil && !isMultiError {
+			return nil, fmt.Errorf("error getting threat models: %v", err)
+		}
+
+		for i := range entities {
+			if isMultiError && errs[i] != nil {
+				if errs[i] == gdatastore.ErrNoSuchEntity {
+					continue
+				}
+				return nil, fmt.Errorf("error getting threat model %s: %v", ids[start+i], errs[i])
+			}
+			d.setID(&entities[i], keys[i])
+			result = append(result, &entities[i])
+		}
+	}
+
+	return result, nil
+}
+
+func (d *DatastoreThreatModelDao) GetAll(ctx context.Context) ([]*m.ThreatModel, error) {
+	q, err := tenantQuery(ctx, d.kind)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.getAll(ctx, q)
+}
+
+func (d *DatastoreThreatModelDao) Count(ctx context.Context) (int, error) {
+	q, err := tenantQuery(ctx, d.kind)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := d.client.Count(ctx, q)
+	if err != nil {
+		return 0, fmt.Errorf("error counting threat models: %v", err)
+	}
+	return n, nil
+}
+
+func (d *DatastoreThreatModelDao) GetTenants(ctx context.Context) ([]auth.TenantID, error) {
+	return ListTenants(ctx, d.client)
+}
+
+func (d *DatastoreThreatModelDao) getAll(ctx context.Context, q *gdatastore.Query) ([]*m.ThreatModel, error) {
+	entities := []m.ThreatModel{}
+	keys, err := d.client.GetAll(ctx, q, &entities)
+	if err != nil {
+		return nil, fmt.Errorf("error querying threat models: %v", err)
+	}
+
+	result := make([]*m.ThreatModel, len(entities))
+	for i := range entities {
+		d.setID(&entities[i], keys[i])
+		result[i] = &entities[i]
+	}
+	return result, nil
+}
+
+// exactQuery returns a query for the threat models matching every field set
+// in query.
+func (d *DatastoreThreatModelDao) exactQuery(ctx context.Context, query *m.ThreatModelQuery) (*gdatastore.Query, error) {
+	q, err := tenantQuery(ctx, d.kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if query == nil {
+		return q, nil
+	}
+	return filterExact(q, reflect.ValueOf(*query))
+}
+
+// filterExact adds an equality filter to q for each non-nil pointer field of
+// the struct query, on the property of the same name. Structs (such as IDs)
+// are saved as nested entities, so are filtered on each of their
+// properties.
+func filterExact(q *gdatastore.Query, query reflect.Value) (*gdatastore.Query, error) {
+	for i := 0; i < query.NumField(); i++ {
+		field := query.Field(i)
+		if field.Kind() != reflect.Pointer || field.IsNil() {
+			continue
+		}
+
+		name := query.Type().Field(i).Name
+		value := field.Elem()
+
+		if value.Kind() != reflect.Struct {
+			q = q.FilterField(name, "=", basicValue(value))
+			continue
+		}
+
+		properties, err := gdatastore.SaveStruct(field.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("error building filter on %s: %v", name, err)
+		}
+		for _, p := range properties {
+			q = q.FilterField(name+"."+p.Name, "=", p.Value)
+		}
+	}
+
+	return q, nil
+}
+
+// basicValue converts v, which may be of a named type such as m.UserID, to
+// the basic type Datastore accepts as a filter value.
+func basicValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	return v.Interface()
+}
+
+func (d *DatastoreThreatModelDao) QueryExact(ctx context.Context, query *m.ThreatModelQuery) ([]*m.ThreatModel, error) {
+	q, err := d.exactQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.getAll(ctx, q)
+}
+
+// QueryExactSingle returns the one threat model matching query, or
+// servicedao.ErrNoSuchDocument if none do, or ErrMultipleThreatModels if
+// more than one does.
+func (d *DatastoreThreatModelDao) QueryExactSingle(ctx context.Context, query *m.ThreatModelQuery) (*m.ThreatModel, error) {
+	q, err := d.exactQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := d.getAll(ctx, q.Limit(2))
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(result) {
+	case 0:
+		return nil, servicedao.ErrNoSuchDocument
+	case 1:
+		return result[0], nil
+	}
+	return nil, ErrMultipleThreatModels
+}
+
+// queryIDs returns the IDs of the threat models matching query.
+func (d *DatastoreThreatModelDao) queryIDs(ctx context.Context, query *m.ThreatModelQuery) ([]m.ThreatModelID, error) {
+	q, err := d.exactQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := d.client.GetAll(ctx, q.KeysOnly(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error querying threat models: %v", err)
+	}
+
+	result := make([]m.ThreatModelID, len(keys))
+	for i, key := range keys {
+		result[i] = d.idCreator.Create(key.Name)
+	}
+	return result, nil
+}
+
+func (d *DatastoreThreatModelDao) Create(ctx context.Context, params m.ThreatModelParams) (*m.ThreatModel, error) {
+	return d.CreateWithOutbox(ctx, params, nil)
+}
+
+func (d *DatastoreThreatModelDao) Update(ctx context.Context, id m.ThreatModelID, params m.ThreatModelParams) (*m.ThreatModel, error) {
+	return d.UpdateWithOutbox(ctx, id, params, nil)
+}
+
+func (d *DatastoreThreatModelDao) UpdateWhereExact(ctx context.Context, queryExact *m.ThreatModelQuery, params m.ThreatModelParams) ([]*m.ThreatModel, error) {
+	ids, err := d.queryIDs(ctx, queryExact)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*m.ThreatModel{}
+	for _, id := range ids {
+		updated, err := d.Update(ctx, id, params)
+		if err == servicedao.ErrNoSuchDocument {
+			continue // deleted since the query
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, updated)
+	}
+
+	return result, nil
+}
+
+func (d *DatastoreThreatModelDao) UpdateWhereExactSingle(ctx context.Context, queryExact *m.ThreatModelQuery, params m.ThreatModelParams) (*m.ThreatModel, error) {
+	threatModel, err := d.QueryExactSingle(ctx, queryExact)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Update(ctx, threatModel.ThreatModelID, params)
+}
+
+// Delete a threat model along with its tags and its membership of a
+// project.
+func (d *DatastoreThreatModelDao) Delete(ctx context.Context, id m.ThreatModelID) error {
+	return d.DeleteWithOutbox(ctx, id, nil)
+}
+
+func (d *DatastoreThreatModelDao) DeleteWhere(ctx context.Context, query *m.ThreatModelQuery) error {
+	ids, err := d.queryIDs(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		err := d.Delete(ctx, id)
+		if err != nil && err != servicedao.ErrNoSuchDocument {
+			return err
+		}
+	}
 
-	return datastore.NewDatastoreDao[m.ThreatModelID, m.ThreatModel, m.ThreatModelParams, *m.ThreatModelQuery](client, randomIDProvider, config, idCreator, errorMappings)
+	return nil
 }
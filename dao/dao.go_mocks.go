@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	model "github.com/jtyers/tmaas-model"
+	auth "github.com/jtyers/tmaas-threat-model-api/auth"
 )
 
 // MockThreatModelDao is a mock of ThreatModelDao interface.
@@ -35,6 +36,36 @@ func (m *MockThreatModelDao) EXPECT() *MockThreatModelDaoMockRecorder {
 	return m.recorder
 }
 
+// AddTags mocks base method.
+func (m *MockThreatModelDao) AddTags(ctx context.Context, id model.ThreatModelID, tags []string, maxTags int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTags", ctx, id, tags, maxTags)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTags indicates an expected call of AddTags.
+func (mr *MockThreatModelDaoMockRecorder) AddTags(ctx, id, tags, maxTags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTags", reflect.TypeOf((*MockThreatModelDao)(nil).AddTags), ctx, id, tags, maxTags)
+}
+
+// Count mocks base method.
+func (m *MockThreatModelDao) Count(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockThreatModelDaoMockRecorder) Count(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockThreatModelDao)(nil).Count), ctx)
+}
+
 // Create mocks base method.
 func (m *MockThreatModelDao) Create(ctx context.Context, params model.ThreatModelParams) (*model.ThreatModel, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +81,21 @@ func (mr *MockThreatModelDaoMockRecorder) Create(ctx, params interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockThreatModelDao)(nil).Create), ctx, params)
 }
 
+// CreateWithOutbox mocks base method.
+func (m *MockThreatModelDao) CreateWithOutbox(ctx context.Context, params model.ThreatModelParams, record OutboxRecordFunc) (*model.ThreatModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithOutbox", ctx, params, record)
+	ret0, _ := ret[0].(*model.ThreatModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWithOutbox indicates an expected call of CreateWithOutbox.
+func (mr *MockThreatModelDaoMockRecorder) CreateWithOutbox(ctx, params, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithOutbox", reflect.TypeOf((*MockThreatModelDao)(nil).CreateWithOutbox), ctx, params, record)
+}
+
 // Delete mocks base method.
 func (m *MockThreatModelDao) Delete(ctx context.Context, id model.ThreatModelID) error {
 	m.ctrl.T.Helper()
@@ -78,6 +124,20 @@ func (mr *MockThreatModelDaoMockRecorder) DeleteWhere(ctx, query interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWhere", reflect.TypeOf((*MockThreatModelDao)(nil).DeleteWhere), ctx, query)
 }
 
+// DeleteWithOutbox mocks base method.
+func (m *MockThreatModelDao) DeleteWithOutbox(ctx context.Context, id model.ThreatModelID, record OutboxRecordFunc) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWithOutbox", ctx, id, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWithOutbox indicates an expected call of DeleteWithOutbox.
+func (mr *MockThreatModelDaoMockRecorder) DeleteWithOutbox(ctx, id, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWithOutbox", reflect.TypeOf((*MockThreatModelDao)(nil).DeleteWithOutbox), ctx, id, record)
+}
+
 // Get mocks base method.
 func (m *MockThreatModelDao) Get(ctx context.Context, id model.ThreatModelID) (*model.ThreatModel, error) {
 	m.ctrl.T.Helper()
@@ -108,6 +168,66 @@ func (mr *MockThreatModelDaoMockRecorder) GetAll(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockThreatModelDao)(nil).GetAll), ctx)
 }
 
+// GetAllTags mocks base method.
+func (m *MockThreatModelDao) GetAllTags(ctx context.Context) (map[model.ThreatModelID][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllTags", ctx)
+	ret0, _ := ret[0].(map[model.ThreatModelID][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllTags indicates an expected call of GetAllTags.
+func (mr *MockThreatModelDaoMockRecorder) GetAllTags(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTags", reflect.TypeOf((*MockThreatModelDao)(nil).GetAllTags), ctx)
+}
+
+// GetMany mocks base method.
+func (m *MockThreatModelDao) GetMany(ctx context.Context, ids []model.ThreatModelID) ([]*model.ThreatModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMany", ctx, ids)
+	ret0, _ := ret[0].([]*model.ThreatModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMany indicates an expected call of GetMany.
+func (mr *MockThreatModelDaoMockRecorder) GetMany(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMany", reflect.TypeOf((*MockThreatModelDao)(nil).GetMany), ctx, ids)
+}
+
+// GetTags mocks base method.
+func (m *MockThreatModelDao) GetTags(ctx context.Context, id model.ThreatModelID) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTags", ctx, id)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTags indicates an expected call of GetTags.
+func (mr *MockThreatModelDaoMockRecorder) GetTags(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTags", reflect.TypeOf((*MockThreatModelDao)(nil).GetTags), ctx, id)
+}
+
+// GetTenants mocks base method.
+func (m *MockThreatModelDao) GetTenants(ctx context.Context) ([]auth.TenantID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenants", ctx)
+	ret0, _ := ret[0].([]auth.TenantID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenants indicates an expected call of GetTenants.
+func (mr *MockThreatModelDaoMockRecorder) GetTenants(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenants", reflect.TypeOf((*MockThreatModelDao)(nil).GetTenants), ctx)
+}
+
 // QueryExact mocks base method.
 func (m *MockThreatModelDao) QueryExact(ctx context.Context, query *model.ThreatModelQuery) ([]*model.ThreatModel, error) {
 	m.ctrl.T.Helper()
@@ -138,6 +258,50 @@ func (mr *MockThreatModelDaoMockRecorder) QueryExactSingle(ctx, query interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryExactSingle", reflect.TypeOf((*MockThreatModelDao)(nil).QueryExactSingle), ctx, query)
 }
 
+// QueryIDsByTags mocks base method.
+func (m *MockThreatModelDao) QueryIDsByTags(ctx context.Context, tags []string, matchAll bool) ([]model.ThreatModelID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryIDsByTags", ctx, tags, matchAll)
+	ret0, _ := ret[0].([]model.ThreatModelID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryIDsByTags indicates an expected call of QueryIDsByTags.
+func (mr *MockThreatModelDaoMockRecorder) QueryIDsByTags(ctx, tags, matchAll interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryIDsByTags", reflect.TypeOf((*MockThreatModelDao)(nil).QueryIDsByTags), ctx, tags, matchAll)
+}
+
+// RemoveTags mocks base method.
+func (m *MockThreatModelDao) RemoveTags(ctx context.Context, id model.ThreatModelID, tags []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTags", ctx, id, tags)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveTags indicates an expected call of RemoveTags.
+func (mr *MockThreatModelDaoMockRecorder) RemoveTags(ctx, id, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTags", reflect.TypeOf((*MockThreatModelDao)(nil).RemoveTags), ctx, id, tags)
+}
+
+// SetTags mocks base method.
+func (m *MockThreatModelDao) SetTags(ctx context.Context, id model.ThreatModelID, tags []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTags", ctx, id, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTags indicates an expected call of SetTags.
+func (mr *MockThreatModelDaoMockRecorder) SetTags(ctx, id, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTags", reflect.TypeOf((*MockThreatModelDao)(nil).SetTags), ctx, id, tags)
+}
+
 // Update mocks base method.
 func (m *MockThreatModelDao) Update(ctx context.Context, id model.ThreatModelID, params model.ThreatModelParams) (*model.ThreatModel, error) {
 	m.ctrl.T.Helper()
@@ -182,3 +346,18 @@ func (mr *MockThreatModelDaoMockRecorder) UpdateWhereExactSingle(ctx, queryExact
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWhereExactSingle", reflect.TypeOf((*MockThreatModelDao)(nil).UpdateWhereExactSingle), ctx, queryExact, params)
 }
+
+// UpdateWithOutbox mocks base method.
+func (m *MockThreatModelDao) UpdateWithOutbox(ctx context.Context, id model.ThreatModelID, params model.ThreatModelParams, record OutboxRecordFunc) (*model.ThreatModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWithOutbox", ctx, id, params, record)
+	ret0, _ := ret[0].(*model.ThreatModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWithOutbox indicates an expected call of UpdateWithOutbox.
+func (mr *MockThreatModelDaoMockRecorder) UpdateWithOutbox(ctx, id, params, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWithOutbox", reflect.TypeOf((*MockThreatModelDao)(nil).UpdateWithOutbox), ctx, id, params, record)
+}
@@ -0,0 +1,51 @@
+package dao
+
+import (
+	"testing"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-service-dao/datastore"
+	"github.com/jtyers/tmaas-service-util/id"
+	"github.com/jtyers/tmaas-threat-model-api/dao/datastoretest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThreatModelDaoSetsIDsFromKeys(t *testing.T) {
+	// given a threat model stored without its ID, as by a hand-written
+	// migration
+	ctx := inTenant("acme")
+	client := datastoretest.NewClient(t)
+	threatModelDao, err := NewThreatModelDao(client, id.NewDefaultRandomIDProvider(NewThreatModelRandomIDProviderPrefix()), datastore.DatastoreConfiguration{DatastoreKeyKind: DatastoreKeyKind}, ThreatModelIDCreator{})
+	require.Nil(t, err)
+
+	threatModelID := m.NewThreatModelIDP("tm-1")
+	key, err := tenantKey(ctx, DatastoreKeyKind, threatModelID.String())
+	require.Nil(t, err)
+	_, err = client.Put(ctx, key, &m.ThreatModel{Title: "Payments gateway"})
+	require.Nil(t, err)
+	require.Nil(t, threatModelDao.SetTags(ctx, threatModelID, []string{"pci"}))
+
+	expected := &m.ThreatModel{ThreatModelID: threatModelID, Title: "Payments gateway"}
+
+	// when
+	got, getErr := threatModelDao.Get(ctx, threatModelID)
+	many, manyErr := threatModelDao.GetMany(ctx, []m.ThreatModelID{threatModelID})
+	all, allErr := threatModelDao.GetAll(ctx)
+	updated, updateErr := threatModelDao.Update(ctx, threatModelID, m.ThreatModelParams{})
+	tagged, taggedErr := threatModelDao.QueryIDsByTags(ctx, []string{"pci"}, false)
+	allTags, allTagsErr := threatModelDao.GetAllTags(ctx)
+
+	// then
+	require.Nil(t, getErr)
+	require.Equal(t, expected, got)
+	require.Nil(t, manyErr)
+	require.Equal(t, []*m.ThreatModel{expected}, many)
+	require.Nil(t, allErr)
+	require.Equal(t, []*m.ThreatModel{expected}, all)
+	require.Nil(t, updateErr)
+	require.Equal(t, expected, updated)
+	require.Nil(t, taggedErr)
+	require.Equal(t, []m.ThreatModelID{threatModelID}, tagged)
+	require.Nil(t, allTagsErr)
+	require.Equal(t, map[m.ThreatModelID][]string{threatModelID: {"pci"}}, allTags)
+}
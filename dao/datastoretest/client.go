@@ -0,0 +1,48 @@
+package datastoretest
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	gdatastore "cloud.google.com/go/datastore"
+	"google.golang.org/api/option"
+	pb "google.golang.org/genproto/googleapis/datastore/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// ProjectID is the project of clients returned by NewClient.
+const ProjectID = "datastoretest"
+
+// NewClient starts a Server and returns a client connected to it. Both are
+// closed when t ends.
+func NewClient(t testing.TB) *gdatastore.Client {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+
+	srv := grpc.NewServer()
+	pb.RegisterDatastoreServer(srv, NewServer())
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("error connecting to fake Datastore: %v", err)
+	}
+
+	client, err := gdatastore.NewClient(context.Background(), ProjectID, option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("error creating Datastore client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
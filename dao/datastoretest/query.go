@@ -0,0 +1,428 @@
+package datastoretest
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	pb "google.golang.org/genproto/googleapis/datastore/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	keyProperty = "__key__"
+
+	namespaceKind = "__namespace__"
+	kindKind      = "__kind__"
+)
+
+// query returns the entities in namespace matching q, in order, ignoring
+// its cursors, offset and limit. s.mu must be held.
+func (s *Server) query(namespace string, q *pb.Query) ([]*pb.Entity, error) {
+	if len(q.Kind) > 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "at most one kind may be queried")
+	}
+
+	kind := ""
+	if len(q.Kind) == 1 {
+		kind = q.Kind[0].Name
+	}
+
+	var candidates []*pb.Entity
+	switch kind {
+	case namespaceKind:
+		candidates = s.namespaces()
+	case kindKind:
+		candidates = s.kinds(namespace)
+	default:
+		for _, entity := range s.entities {
+			path := entity.Key.Path
+			if entity.Key.GetPartitionId().GetNamespaceId() == namespace && (kind == "" || path[len(path)-1].Kind == kind) {
+				candidates = append(candidates, entity)
+			}
+		}
+	}
+
+	results := []*pb.Entity{}
+	for _, entity := range candidates {
+		ok, err := matches(entity, q.Filter)
+		if err != nil {
+			return nil, err
+		}
+		if ok && hasOrderProperties(entity, q.Order) {
+			results = append(results, entity)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return compareEntities(results[i], results[j], q.Order) < 0
+	})
+
+	return results, nil
+}
+
+// namespaces returns an entity for each namespace holding entities, keyed
+// as Datastore keys them: by name, or for the default namespace, by ID 1.
+func (s *Server) namespaces() []*pb.Entity {
+	seen := map[string]bool{}
+	result := []*pb.Entity{}
+
+	for _, entity := range s.entities {
+		ns := entity.Key.GetPartitionId().GetNamespaceId()
+		if seen[ns] {
+			continue
+		}
+		seen[ns] = true
+
+		element := &pb.Key_PathElement{Kind: namespaceKind, IdType: &pb.Key_PathElement_Name{Name: ns}}
+		if ns == "" {
+			element.IdType = &pb.Key_PathElement_Id{Id: 1}
+		}
+		result = append(result, &pb.Entity{Key: &pb.Key{Path: []*pb.Key_PathElement{element}}})
+	}
+
+	return result
+}
+
+// kinds returns an entity for each kind in namespace, keyed by name.
+func (s *Server) kinds(namespace string) []*pb.Entity {
+	seen := map[string]bool{}
+	result := []*pb.Entity{}
+
+	for _, entity := range s.entities {
+		if entity.Key.GetPartitionId().GetNamespaceId() != namespace {
+			continue
+		}
+
+		path := entity.Key.Path
+		kind := path[len(path)-1].Kind
+		if seen[kind] {
+			continue
+		}
+		seen[kind] = true
+
+		result = append(result, &pb.Entity{Key: &pb.Key{
+			PartitionId: &pb.PartitionId{NamespaceId: namespace},
+			Path:        []*pb.Key_PathElement{{Kind: kindKind, IdType: &pb.Key_PathElement_Name{Name: kind}}},
+		}})
+	}
+
+	return result
+}
+
+func matches(entity *pb.Entity, filter *pb.Filter) (bool, error) {
+	if filter == nil {
+		return true, nil
+	}
+
+	switch f := filter.FilterType.(type) {
+	case *pb.Filter_CompositeFilter:
+		if f.CompositeFilter.Op != pb.CompositeFilter_AND {
+			return false, status.Errorf(codes.Unimplemented, "only AND filters are supported")
+		}
+
+		for _, sub := range f.CompositeFilter.Filters {
+			ok, err := matches(entity, sub)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case *pb.Filter_PropertyFilter:
+		return matchesProperty(entity, f.PropertyFilter)
+	}
+
+	return false, status.Errorf(codes.InvalidArgument, "unknown filter %T", filter.FilterType)
+}
+
+func matchesProperty(entity *pb.Entity, f *pb.PropertyFilter) (bool, error) {
+	if f.Op == pb.PropertyFilter_HAS_ANCESTOR {
+		ancestor := f.Value.GetKeyValue()
+		if ancestor == nil {
+			return false, status.Errorf(codes.InvalidArgument, "ancestor filters need a key")
+		}
+		return isAncestor(ancestor, entity.Key), nil
+	}
+
+	for _, v := range indexedValues(entity, f.Property.Name) {
+		c, comparable := compareValues(v, f.Value)
+		if !comparable {
+			continue
+		}
+
+		var ok bool
+		switch f.Op {
+		case pb.PropertyFilter_EQUAL:
+			ok = c == 0
+		case pb.PropertyFilter_NOT_EQUAL:
+			ok = c != 0
+		case pb.PropertyFilter_LESS_THAN:
+			ok = c < 0
+		case pb.PropertyFilter_LESS_THAN_OR_EQUAL:
+			ok = c <= 0
+		case pb.PropertyFilter_GREATER_THAN:
+			ok = c > 0
+		case pb.PropertyFilter_GREATER_THAN_OR_EQUAL:
+			ok = c >= 0
+		default:
+			return false, status.Errorf(codes.Unimplemented, "unsupported operator %v", f.Op)
+		}
+
+		if ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// isAncestor returns true if ancestor is key, or one of its parents.
+func isAncestor(ancestor, key *pb.Key) bool {
+	if ancestor.GetPartitionId().GetNamespaceId() != key.GetPartitionId().GetNamespaceId() || len(ancestor.Path) > len(key.Path) {
+		return false
+	}
+
+	prefix := &pb.Key{PartitionId: key.PartitionId, Path: key.Path[:len(ancestor.Path)]}
+	return keyString(prefix) == keyString(ancestor)
+}
+
+// indexedValues returns the indexed values of the named property of
+// entity, with arrays expanded into their elements. Names with dots refer
+// to the properties of entity values.
+func indexedValues(entity *pb.Entity, name string) []*pb.Value {
+	if name == keyProperty {
+		return []*pb.Value{{ValueType: &pb.Value_KeyValue{KeyValue: entity.Key}}}
+	}
+
+	first, rest, nested := strings.Cut(name, ".")
+	if !nested {
+		return indexed(entity.Properties[name])
+	}
+
+	result := []*pb.Value{}
+	for _, v := range indexed(entity.Properties[first]) {
+		if e := v.GetEntityValue(); e != nil {
+			result = append(result, indexedValues(e, rest)...)
+		}
+	}
+	return result
+}
+
+func indexed(v *pb.Value) []*pb.Value {
+	if v == nil || v.ExcludeFromIndexes {
+		return nil
+	}
+
+	if array, ok := v.ValueType.(*pb.Value_ArrayValue); ok {
+		result := []*pb.Value{}
+		for _, element := range array.ArrayValue.Values {
+			result = append(result, indexed(element)...)
+		}
+		return result
+	}
+
+	return []*pb.Value{v}
+}
+
+func hasOrderProperties(entity *pb.Entity, orders []*pb.PropertyOrder) bool {
+	for _, order := range orders {
+		if len(indexedValues(entity, order.Property.Name)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// compareEntities orders entities by orders, then by key. As in Datastore,
+// an ascending order uses the least of a property's values, and a
+// descending order the greatest.
+func compareEntities(a, b *pb.Entity, orders []*pb.PropertyOrder) int {
+	for _, order := range orders {
+		descending := order.Direction == pb.PropertyOrder_DESCENDING
+
+		c := compareValuesForOrder(
+			extreme(indexedValues(a, order.Property.Name), descending),
+			extreme(indexedValues(b, order.Property.Name), descending),
+		)
+		if descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	return compareKeys(a.Key, b.Key)
+}
+
+// extreme returns the greatest of values if greatest is true, otherwise the
+// least.
+func extreme(values []*pb.Value, greatest bool) *pb.Value {
+	var result *pb.Value
+	for _, v := range values {
+		c := compareValuesForOrder(v, result)
+		if result == nil || (greatest && c > 0) || (!greatest && c < 0) {
+			result = v
+		}
+	}
+	return result
+}
+
+// typeRank orders values of different types, as Datastore does.
+func typeRank(v *pb.Value) int {
+	switch v.ValueType.(type) {
+	case *pb.Value_NullValue:
+		return 0
+	case *pb.Value_IntegerValue, *pb.Value_TimestampValue:
+		return 1
+	case *pb.Value_BooleanValue:
+		return 2
+	case *pb.Value_StringValue, *pb.Value_BlobValue:
+		return 3
+	case *pb.Value_DoubleValue:
+		return 4
+	case *pb.Value_GeoPointValue:
+		return 5
+	case *pb.Value_KeyValue:
+		return 6
+	}
+	return 7
+}
+
+func compareValuesForOrder(a, b *pb.Value) int {
+	if a == nil || b == nil {
+		return 0
+	}
+
+	if c, comparable := compareValues(a, b); comparable {
+		return c
+	}
+	return typeRank(a) - typeRank(b)
+}
+
+// compareValues compares two values of the same type, returning false if
+// their types differ.
+func compareValues(a, b *pb.Value) (int, bool) {
+	switch av := a.ValueType.(type) {
+	case *pb.Value_NullValue:
+		_, ok := b.ValueType.(*pb.Value_NullValue)
+		return 0, ok
+
+	case *pb.Value_BooleanValue:
+		bv, ok := b.ValueType.(*pb.Value_BooleanValue)
+		if !ok {
+			return 0, false
+		}
+		return compareBools(av.BooleanValue, bv.BooleanValue), true
+
+	case *pb.Value_IntegerValue:
+		bv, ok := b.ValueType.(*pb.Value_IntegerValue)
+		if !ok {
+			return 0, false
+		}
+		return compareInts(av.IntegerValue, bv.IntegerValue), true
+
+	case *pb.Value_DoubleValue:
+		bv, ok := b.ValueType.(*pb.Value_DoubleValue)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case av.DoubleValue < bv.DoubleValue:
+			return -1, true
+		case av.DoubleValue > bv.DoubleValue:
+			return 1, true
+		}
+		return 0, true
+
+	case *pb.Value_TimestampValue:
+		bv, ok := b.ValueType.(*pb.Value_TimestampValue)
+		if !ok {
+			return 0, false
+		}
+		if c := compareInts(av.TimestampValue.Seconds, bv.TimestampValue.Seconds); c != 0 {
+			return c, true
+		}
+		return compareInts(int64(av.TimestampValue.Nanos), int64(bv.TimestampValue.Nanos)), true
+
+	case *pb.Value_StringValue:
+		bv, ok := b.ValueType.(*pb.Value_StringValue)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(av.StringValue, bv.StringValue), true
+
+	case *pb.Value_BlobValue:
+		bv, ok := b.ValueType.(*pb.Value_BlobValue)
+		if !ok {
+			return 0, false
+		}
+		return bytes.Compare(av.BlobValue, bv.BlobValue), true
+
+	case *pb.Value_KeyValue:
+		bv, ok := b.ValueType.(*pb.Value_KeyValue)
+		if !ok {
+			return 0, false
+		}
+		return compareKeys(av.KeyValue, bv.KeyValue), true
+	}
+
+	return 0, false
+}
+
+// compareKeys orders keys by namespace, then path, where numeric IDs
+// precede names.
+func compareKeys(a, b *pb.Key) int {
+	if c := strings.Compare(a.GetPartitionId().GetNamespaceId(), b.GetPartitionId().GetNamespaceId()); c != 0 {
+		return c
+	}
+
+	for i := 0; i < len(a.Path) && i < len(b.Path); i++ {
+		ae, be := a.Path[i], b.Path[i]
+
+		if c := strings.Compare(ae.Kind, be.Kind); c != 0 {
+			return c
+		}
+
+		_, aNamed := ae.IdType.(*pb.Key_PathElement_Name)
+		_, bNamed := be.IdType.(*pb.Key_PathElement_Name)
+		switch {
+		case aNamed && bNamed:
+			if c := strings.Compare(ae.GetName(), be.GetName()); c != 0 {
+				return c
+			}
+		case !aNamed && !bNamed:
+			if c := compareInts(ae.GetId(), be.GetId()); c != 0 {
+				return c
+			}
+		case aNamed:
+			return 1
+		default:
+			return -1
+		}
+	}
+
+	return len(a.Path) - len(b.Path)
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,366 @@
+// Package datastoretest provides an in-memory Datastore, served over gRPC
+// to a real Datastore client, for testing DAOs without the emulator.
+//
+// It supports lookups, commits, transactions (with optimistic concurrency,
+// so conflicting transactions are retried as in Datastore), queries with
+// property, composite and ancestor filters, orders, limits, offsets and
+// cursors, count aggregations, and the __namespace__ and __kind__ metadata
+// queries. GQL and projections other than keys-only are not supported.
+//
+// As in Datastore, properties excluded from indexes cannot be filtered or
+// ordered on, and entities lacking a filtered or ordered property are not
+// returned by the query.
+package datastoretest
+
+import (
+	"context"
+	"encoding/binary"
+	"strconv"
+	"strings"
+	"sync"
+
+	pb "google.golang.org/genproto/googleapis/datastore/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Server is an in-memory Datastore. It is safe for concurrent use.
+type Server struct {
+	pb.UnimplementedDatastoreServer
+
+	mu       sync.Mutex
+	entities map[string]*pb.Entity
+
+	// the version of each key, kept after deletion so that transactions
+	// which read a key before it was deleted fail to commit
+	versions map[string]int64
+	version  int64
+
+	// the versions read by each open transaction, keyed by transaction ID
+	transactions  map[string]map[string]int64
+	transactionID int
+
+	nextID int64
+}
+
+var _ pb.DatastoreServer = (*Server)(nil)
+
+func NewServer() *Server {
+	return &Server{
+		entities:     map[string]*pb.Entity{},
+		versions:     map[string]int64{},
+		transactions: map[string]map[string]int64{},
+		nextID:       1,
+	}
+}
+
+// keyString returns a string identifying key (ignoring the project), for
+// use as a map key.
+func keyString(key *pb.Key) string {
+	var b strings.Builder
+	b.WriteString(key.GetPartitionId().GetNamespaceId())
+
+	for _, e := range key.Path {
+		b.WriteString("\x00")
+		b.WriteString(e.Kind)
+		b.WriteString("\x00")
+
+		if name, ok := e.IdType.(*pb.Key_PathElement_Name); ok {
+			b.WriteString("n" + name.Name)
+		} else {
+			b.WriteString("i" + strconv.FormatInt(e.GetId(), 10))
+		}
+	}
+
+	return b.String()
+}
+
+// incomplete returns true if the last element of key has neither ID nor name.
+func incomplete(key *pb.Key) bool {
+	if len(key.Path) == 0 {
+		return true
+	}
+	return key.Path[len(key.Path)-1].IdType == nil
+}
+
+func (s *Server) Lookup(ctx context.Context, req *pb.LookupRequest) (*pb.LookupResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reads, err := s.transactionReads(req.ReadOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.LookupResponse{}
+	for _, key := range req.Keys {
+		if incomplete(key) {
+			return nil, status.Errorf(codes.InvalidArgument, "incomplete key %v", key)
+		}
+
+		k := keyString(key)
+		if reads != nil {
+			reads[k] = s.versions[k]
+		}
+
+		if entity, ok := s.entities[k]; ok {
+			resp.Found = append(resp.Found, &pb.EntityResult{Entity: proto.Clone(entity).(*pb.Entity), Version: s.versions[k]})
+		} else {
+			resp.Missing = append(resp.Missing, &pb.EntityResult{Entity: &pb.Entity{Key: key}, Version: s.version})
+		}
+	}
+
+	return resp, nil
+}
+
+// transactionReads returns the versions read by the transaction named by
+// options, or nil if there is none.
+func (s *Server) transactionReads(options *pb.ReadOptions) (map[string]int64, error) {
+	id := options.GetTransaction()
+	if id == nil {
+		return nil, nil
+	}
+
+	reads, ok := s.transactions[string(id)]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "no such transaction")
+	}
+	return reads, nil
+}
+
+func (s *Server) BeginTransaction(ctx context.Context, req *pb.BeginTransactionRequest) (*pb.BeginTransactionResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.transactionID++
+	id := strconv.Itoa(s.transactionID)
+	s.transactions[id] = map[string]int64{}
+
+	return &pb.BeginTransactionResponse{Transaction: []byte(id)}, nil
+}
+
+func (s *Server) Rollback(ctx context.Context, req *pb.RollbackRequest) (*pb.RollbackResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.transactions, string(req.Transaction))
+	return &pb.RollbackResponse{}, nil
+}
+
+func (s *Server) Commit(ctx context.Context, req *pb.CommitRequest) (*pb.CommitResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Mode == pb.CommitRequest_TRANSACTIONAL {
+		id := string(req.GetTransaction())
+
+		reads, ok := s.transactions[id]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "no such transaction")
+		}
+		delete(s.transactions, id)
+
+		for k, version := range reads {
+			if s.versions[k] != version {
+				return nil, status.Errorf(codes.Aborted, "too much contention on these datastore entities")
+			}
+		}
+	}
+
+	// check every mutation before applying any, so that commits are atomic
+	for _, mutation := range req.Mutations {
+		switch op := mutation.Operation.(type) {
+		case *pb.Mutation_Insert:
+			if !incomplete(op.Insert.Key) {
+				if _, exists := s.entities[keyString(op.Insert.Key)]; exists {
+					return nil, status.Errorf(codes.AlreadyExists, "entity already exists")
+				}
+			}
+		case *pb.Mutation_Update:
+			if _, exists := s.entities[keyString(op.Update.Key)]; !exists {
+				return nil, status.Errorf(codes.NotFound, "no entity to update")
+			}
+		case *pb.Mutation_Upsert, *pb.Mutation_Delete:
+		default:
+			return nil, status.Errorf(codes.Unimplemented, "unsupported mutation %T", op)
+		}
+	}
+
+	s.version++
+
+	resp := &pb.CommitResponse{}
+	for _, mutation := range req.Mutations {
+		result := &pb.MutationResult{Version: s.version}
+
+		var entity *pb.Entity
+		switch op := mutation.Operation.(type) {
+		case *pb.Mutation_Insert:
+			entity = op.Insert
+		case *pb.Mutation_Update:
+			entity = op.Update
+		case *pb.Mutation_Upsert:
+			entity = op.Upsert
+		case *pb.Mutation_Delete:
+			k := keyString(op.Delete)
+			delete(s.entities, k)
+			s.versions[k] = s.version
+		}
+
+		if entity != nil {
+			entity = proto.Clone(entity).(*pb.Entity)
+			if incomplete(entity.Key) {
+				s.allocateID(entity.Key)
+				result.Key = entity.Key
+			}
+
+			k := keyString(entity.Key)
+			s.entities[k] = entity
+			s.versions[k] = s.version
+		}
+
+		resp.MutationResults = append(resp.MutationResults, result)
+	}
+
+	return resp, nil
+}
+
+// allocateID completes key with a new numeric ID.
+func (s *Server) allocateID(key *pb.Key) {
+	key.Path[len(key.Path)-1].IdType = &pb.Key_PathElement_Id{Id: s.nextID}
+	s.nextID++
+}
+
+func (s *Server) AllocateIds(ctx context.Context, req *pb.AllocateIdsRequest) (*pb.AllocateIdsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := &pb.AllocateIdsResponse{}
+	for _, key := range req.Keys {
+		key = proto.Clone(key).(*pb.Key)
+		s.allocateID(key)
+		resp.Keys = append(resp.Keys, key)
+	}
+
+	return resp, nil
+}
+
+func (s *Server) RunQuery(ctx context.Context, req *pb.RunQueryRequest) (*pb.RunQueryResponse, error) {
+	q := req.GetQuery()
+	if q == nil {
+		return nil, status.Errorf(codes.Unimplemented, "GQL queries are not supported")
+	}
+
+	keysOnly, err := isKeysOnly(q)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	results, err := s.query(req.GetPartitionId().GetNamespaceId(), q)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	start := decodeCursor(q.StartCursor)
+	if start > len(results) {
+		start = len(results)
+	}
+
+	skipped := int(q.Offset)
+	if start+skipped > len(results) {
+		skipped = len(results) - start
+	}
+	start += skipped
+
+	end := len(results)
+	if q.Limit != nil && start+int(q.Limit.Value) < end {
+		end = start + int(q.Limit.Value)
+	}
+
+	batch := &pb.QueryResultBatch{
+		EntityResultType: pb.EntityResult_FULL,
+		SkippedResults:   int32(skipped),
+		EndCursor:        encodeCursor(end),
+		MoreResults:      pb.QueryResultBatch_NO_MORE_RESULTS,
+	}
+	if skipped > 0 {
+		batch.SkippedCursor = encodeCursor(start)
+	}
+	if end < len(results) {
+		batch.MoreResults = pb.QueryResultBatch_MORE_RESULTS_AFTER_LIMIT
+	}
+	if keysOnly {
+		batch.EntityResultType = pb.EntityResult_KEY_ONLY
+	}
+
+	for i, entity := range results[start:end] {
+		if keysOnly {
+			entity = &pb.Entity{Key: entity.Key}
+		}
+		batch.EntityResults = append(batch.EntityResults, &pb.EntityResult{
+			Entity: entity,
+			Cursor: encodeCursor(start + i + 1),
+		})
+	}
+
+	return &pb.RunQueryResponse{Batch: batch, Query: q}, nil
+}
+
+func (s *Server) RunAggregationQuery(ctx context.Context, req *pb.RunAggregationQueryRequest) (*pb.RunAggregationQueryResponse, error) {
+	aq := req.GetAggregationQuery()
+	if aq == nil || aq.GetNestedQuery() == nil {
+		return nil, status.Errorf(codes.Unimplemented, "GQL queries are not supported")
+	}
+
+	s.mu.Lock()
+	results, err := s.query(req.GetPartitionId().GetNamespaceId(), aq.GetNestedQuery())
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	properties := map[string]*pb.Value{}
+	for _, aggregation := range aq.Aggregations {
+		count := aggregation.GetCount()
+		if count == nil {
+			return nil, status.Errorf(codes.Unimplemented, "only count aggregations are supported")
+		}
+
+		n := int64(len(results))
+		if upTo := count.GetUpTo(); upTo != nil && upTo.Value < n {
+			n = upTo.Value
+		}
+		properties[aggregation.Alias] = &pb.Value{ValueType: &pb.Value_IntegerValue{IntegerValue: n}}
+	}
+
+	return &pb.RunAggregationQueryResponse{
+		Batch: &pb.AggregationResultBatch{
+			AggregationResults: []*pb.AggregationResult{{AggregateProperties: properties}},
+			MoreResults:        pb.QueryResultBatch_NO_MORE_RESULTS,
+		},
+	}, nil
+}
+
+// isKeysOnly returns true if q projects only the key, and an error if it
+// projects anything else.
+func isKeysOnly(q *pb.Query) (bool, error) {
+	if len(q.Projection) == 0 {
+		return false, nil
+	}
+	if len(q.Projection) == 1 && q.Projection[0].GetProperty().GetName() == keyProperty {
+		return true, nil
+	}
+	return false, status.Errorf(codes.Unimplemented, "only keys-only projections are supported")
+}
+
+// Cursors are the position in the query's results of the next result.
+func encodeCursor(position int) []byte {
+	return binary.AppendUvarint(nil, uint64(position))
+}
+
+func decodeCursor(cursor []byte) int {
+	position, _ := binary.Uvarint(cursor)
+	return int(position)
+}
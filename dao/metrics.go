@@ -0,0 +1,196 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	m "github.com/jtyers/tmaas-model"
+	servicedao "github.com/jtyers/tmaas-service-dao"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	"github.com/jtyers/tmaas-threat-model-api/tracing"
+)
+
+// InstrumentedThreatModelDao decorates a ThreatModelDao, recording the
+// latency and errors of each method, and a span for each call. Missing
+// documents are an expected outcome rather than an error, so are not
+// counted as errors.
+type InstrumentedThreatModelDao struct {
+	next    ThreatModelDao
+	metrics *metrics.Metrics
+}
+
+var _ ThreatModelDao = (*InstrumentedThreatModelDao)(nil)
+
+func NewInstrumentedThreatModelDao(next *DatastoreThreatModelDao, metrics *metrics.Metrics) *InstrumentedThreatModelDao {
+	return &InstrumentedThreatModelDao{next, metrics}
+}
+
+// instrument starts a span for a call to method, returning the span's
+// context and a function to call with the outcome once the call is done.
+func (d *InstrumentedThreatModelDao) instrument(ctx context.Context, method string) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "ThreatModelDao."+method)
+
+	return ctx, func(err error) {
+		if err == servicedao.ErrNoSuchDocument {
+			err = nil
+		}
+		metrics.ObserveOperation(d.metrics.DaoDuration, d.metrics.DaoErrors, method, start, err)
+		tracing.End(span, err)
+	}
+}
+
+func (d *InstrumentedThreatModelDao) Get(ctx context.Context, id m.ThreatModelID) (*m.ThreatModel, error) {
+	ctx, done := d.instrument(ctx, "Get")
+	result, err := d.next.Get(ctx, id)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) GetMany(ctx context.Context, ids []m.ThreatModelID) ([]*m.ThreatModel, error) {
+	ctx, done := d.instrument(ctx, "GetMany")
+	result, err := d.next.GetMany(ctx, ids)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) GetAll(ctx context.Context) ([]*m.ThreatModel, error) {
+	ctx, done := d.instrument(ctx, "GetAll")
+	result, err := d.next.GetAll(ctx)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) Count(ctx context.Context) (int, error) {
+	ctx, done := d.instrument(ctx, "Count")
+	result, err := d.next.Count(ctx)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) GetTenants(ctx context.Context) ([]auth.TenantID, error) {
+	ctx, done := d.instrument(ctx, "GetTenants")
+	result, err := d.next.GetTenants(ctx)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) QueryExact(ctx context.Context, query *m.ThreatModelQuery) ([]*m.ThreatModel, error) {
+	ctx, done := d.instrument(ctx, "QueryExact")
+	result, err := d.next.QueryExact(ctx, query)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) QueryExactSingle(ctx context.Context, query *m.ThreatModelQuery) (*m.ThreatModel, error) {
+	ctx, done := d.instrument(ctx, "QueryExactSingle")
+	result, err := d.next.QueryExactSingle(ctx, query)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) Create(ctx context.Context, params m.ThreatModelParams) (*m.ThreatModel, error) {
+	ctx, done := d.instrument(ctx, "Create")
+	result, err := d.next.Create(ctx, params)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) Update(ctx context.Context, id m.ThreatModelID, params m.ThreatModelParams) (*m.ThreatModel, error) {
+	ctx, done := d.instrument(ctx, "Update")
+	result, err := d.next.Update(ctx, id, params)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) UpdateWhereExact(ctx context.Context, queryExact *m.ThreatModelQuery, params m.ThreatModelParams) ([]*m.ThreatModel, error) {
+	ctx, done := d.instrument(ctx, "UpdateWhereExact")
+	result, err := d.next.UpdateWhereExact(ctx, queryExact, params)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) UpdateWhereExactSingle(ctx context.Context, queryExact *m.ThreatModelQuery, params m.ThreatModelParams) (*m.ThreatModel, error) {
+	ctx, done := d.instrument(ctx, "UpdateWhereExactSingle")
+	result, err := d.next.UpdateWhereExactSingle(ctx, queryExact, params)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) Delete(ctx context.Context, id m.ThreatModelID) error {
+	ctx, done := d.instrument(ctx, "Delete")
+	err := d.next.Delete(ctx, id)
+	done(err)
+	return err
+}
+
+func (d *InstrumentedThreatModelDao) DeleteWhere(ctx context.Context, query *m.ThreatModelQuery) error {
+	ctx, done := d.instrument(ctx, "DeleteWhere")
+	err := d.next.DeleteWhere(ctx, query)
+	done(err)
+	return err
+}
+
+func (d *InstrumentedThreatModelDao) GetTags(ctx context.Context, id m.ThreatModelID) ([]string, error) {
+	ctx, done := d.instrument(ctx, "GetTags")
+	result, err := d.next.GetTags(ctx, id)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) SetTags(ctx context.Context, id m.ThreatModelID, tags []string) error {
+	ctx, done := d.instrument(ctx, "SetTags")
+	err := d.next.SetTags(ctx, id, tags)
+	done(err)
+	return err
+}
+
+func (d *InstrumentedThreatModelDao) AddTags(ctx context.Context, id m.ThreatModelID, tags []string, maxTags int) ([]string, error) {
+	ctx, done := d.instrument(ctx, "AddTags")
+	result, err := d.next.AddTags(ctx, id, tags, maxTags)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) RemoveTags(ctx context.Context, id m.ThreatModelID, tags []string) ([]string, error) {
+	ctx, done := d.instrument(ctx, "RemoveTags")
+	result, err := d.next.RemoveTags(ctx, id, tags)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) QueryIDsByTags(ctx context.Context, tags []string, matchAll bool) ([]m.ThreatModelID, error) {
+	ctx, done := d.instrument(ctx, "QueryIDsByTags")
+	result, err := d.next.QueryIDsByTags(ctx, tags, matchAll)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) GetAllTags(ctx context.Context) (map[m.ThreatModelID][]string, error) {
+	ctx, done := d.instrument(ctx, "GetAllTags")
+	result, err := d.next.GetAllTags(ctx)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) CreateWithOutbox(ctx context.Context, params m.ThreatModelParams, record OutboxRecordFunc) (*m.ThreatModel, error) {
+	ctx, done := d.instrument(ctx, "CreateWithOutbox")
+	result, err := d.next.CreateWithOutbox(ctx, params, record)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) UpdateWithOutbox(ctx context.Context, id m.ThreatModelID, params m.ThreatModelParams, record OutboxRecordFunc) (*m.ThreatModel, error) {
+	ctx, done := d.instrument(ctx, "UpdateWithOutbox")
+	result, err := d.next.UpdateWithOutbox(ctx, id, params, record)
+	done(err)
+	return result, err
+}
+
+func (d *InstrumentedThreatModelDao) DeleteWithOutbox(ctx context.Context, id m.ThreatModelID, record OutboxRecordFunc) error {
+	ctx, done := d.instrument(ctx, "DeleteWithOutbox")
+	err := d.next.DeleteWithOutbox(ctx, id, record)
+	done(err)
+	return err
+}
@@ -0,0 +1,156 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	gdatastore "cloud.google.com/go/datastore"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+)
+
+var (
+	ErrNoDefaultTenant = errors.New("entities without a tenant were found, but no default tenant was given")
+)
+
+// untenantedKinds are the kinds written to the default namespace before
+// tenants were introduced.
+var untenantedKinds = map[string]bool{
+	DatastoreKeyKind:                   true,
+	CommentDatastoreKeyKind:            true,
+	TagsDatastoreKeyKind:               true,
+	ProjectDatastoreKeyKind:            true,
+	ThreatModelProjectDatastoreKeyKind: true,
+}
+
+// migrationBatchSize is the most entities moved at once. Each is a put and
+// a delete, and Datastore allows 500 mutations in one commit.
+const migrationBatchSize = 250
+
+// MigrateToTenantNamespaces moves entities written outside tenant
+// namespaces into them, returning the number moved of each kind:
+//
+//   - those of untenantedKinds in the default namespace, written before
+//     tenants were introduced, move to the namespace of defaultTenant
+//   - threat models written with the tenant in their kind (eg
+//     "tenant-acme/threat-model"), as the first release with tenants did,
+//     move to the namespace of that tenant
+//
+// Entities keep their keys but for namespace and kind. An entity whose new
+// key is already taken is not copied, as it was either moved by an earlier
+// run or written since, but is still removed from its old key. Migration
+// may therefore be rerun, for instance after it fails part way.
+func MigrateToTenantNamespaces(ctx context.Context, client *gdatastore.Client, defaultTenant auth.TenantID) (map[string]int, error) {
+	kindKeys, err := client.GetAll(ctx, gdatastore.NewQuery("__kind__").KeysOnly(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error listing kinds: %v", err)
+	}
+
+	result := map[string]int{}
+
+	for _, kindKey := range kindKeys {
+		kind := kindKey.Name
+
+		var tenantID auth.TenantID
+		newKind := kind
+
+		if ns, tenantKind, ok := strings.Cut(kind, "/"); ok {
+			t, isTenant := auth.TenantIDFromNamespace(ns)
+			if !isTenant {
+				continue
+			}
+			tenantID, newKind = t, tenantKind
+
+		} else if untenantedKinds[kind] {
+			if defaultTenant == "" {
+				return result, ErrNoDefaultTenant
+			}
+			tenantID = defaultTenant
+
+		} else {
+			continue
+		}
+
+		n, err := moveKind(ctx, client, kind, newKind, tenantID.Namespace())
+		result[kind] = n
+		if err != nil {
+			return result, fmt.Errorf("error moving %s to tenant %s: %v", kind, tenantID, err)
+		}
+	}
+
+	return result, nil
+}
+
+// moveKind moves every entity of kind in the default namespace to newKind
+// in namespace, returning the number moved.
+func moveKind(ctx context.Context, client *gdatastore.Client, kind, newKind, namespace string) (int, error) {
+	moved := 0
+
+	for {
+		// moved entities are deleted, so each batch starts from the first
+		// entity remaining
+		var entities []gdatastore.PropertyList
+		keys, err := client.GetAll(ctx, gdatastore.NewQuery(kind).Limit(migrationBatchSize), &entities)
+		if err != nil {
+			return moved, err
+		}
+		if len(keys) == 0 {
+			return moved, nil
+		}
+
+		newKeys := make([]*gdatastore.Key, len(keys))
+		for i, key := range keys {
+			newKeys[i] = moveKey(key, newKind, namespace)
+		}
+
+		_, err = client.RunInTransaction(ctx, func(tx *gdatastore.Transaction) error {
+			existing := make([]gdatastore.PropertyList, len(newKeys))
+			err := tx.GetMulti(newKeys, existing)
+
+			errs, isMultiError := err.(gdatastore.MultiError)
+			if err != nil && !isMultiError {
+				return err
+			}
+
+			var putKeys []*gdatastore.Key
+			var putEntities []gdatastore.PropertyList
+			for i := range newKeys {
+				if isMultiError && errs[i] == gdatastore.ErrNoSuchEntity {
+					putKeys = append(putKeys, newKeys[i])
+					putEntities = append(putEntities, entities[i])
+				} else if isMultiError && errs[i] != nil {
+					return errs[i]
+				}
+			}
+
+			if len(putKeys) > 0 {
+				if _, err := tx.PutMulti(putKeys, putEntities); err != nil {
+					return err
+				}
+			}
+
+			return tx.DeleteMulti(keys)
+		})
+		if err != nil {
+			return moved, err
+		}
+
+		moved += len(keys)
+	}
+}
+
+// moveKey returns key with the given kind, in namespace along with its
+// ancestors.
+func moveKey(key *gdatastore.Key, kind, namespace string) *gdatastore.Key {
+	var parent *gdatastore.Key
+	if key.Parent != nil {
+		parent = moveKey(key.Parent, key.Parent.Kind, namespace)
+	}
+
+	moved := *key
+	moved.Kind = kind
+	moved.Parent = parent
+	moved.Namespace = namespace
+	return &moved
+}
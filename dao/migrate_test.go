@@ -0,0 +1,96 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	gdatastore "cloud.google.com/go/datastore"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-service-dao/datastore"
+	"github.com/jtyers/tmaas-service-util/id"
+	"github.com/jtyers/tmaas-threat-model-api/dao/datastoretest"
+	"github.com/stretchr/testify/require"
+)
+
+type otherEntity struct {
+	Name string
+}
+
+func TestMigrateToTenantNamespaces(t *testing.T) {
+	// given
+	ctx := context.Background()
+	client := datastoretest.NewClient(t)
+
+	legacy := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-legacy"), Title: "from before tenants"}
+	acmeModel := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-acme"), Title: "acme's model"}
+	moved := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-moved"), Title: "moved and since updated"}
+
+	puts := []struct {
+		key    *gdatastore.Key
+		entity interface{}
+	}{
+		{gdatastore.NameKey(DatastoreKeyKind, "tm-legacy", nil), legacy},
+		{gdatastore.NameKey(TagsDatastoreKeyKind, "tm-legacy", nil), &tagsEntity{[]string{"pci"}}},
+		{gdatastore.NameKey("tenant-acme/"+DatastoreKeyKind, "tm-acme", nil), acmeModel},
+		{gdatastore.NameKey("tenant-acme/"+DatastoreKeyKind, "tm-moved", nil), &m.ThreatModel{ThreatModelID: moved.ThreatModelID, Title: "stale"}},
+		{gdatastore.NameKey("other", "o-1", nil), &otherEntity{"not ours"}},
+	}
+	for _, p := range puts {
+		_, err := client.Put(ctx, p.key, p.entity)
+		require.Nil(t, err)
+	}
+
+	// moved by an earlier run, and updated since
+	_, err := client.Put(ctx, &gdatastore.Key{Kind: DatastoreKeyKind, Name: "tm-moved", Namespace: "tenant-acme"}, moved)
+	require.Nil(t, err)
+
+	// when
+	result, err := MigrateToTenantNamespaces(ctx, client, "initech")
+	rerunResult, rerunErr := MigrateToTenantNamespaces(ctx, client, "initech")
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, map[string]int{
+		DatastoreKeyKind:                  1,
+		TagsDatastoreKeyKind:              1,
+		"tenant-acme/" + DatastoreKeyKind: 2,
+	}, result)
+
+	require.Nil(t, rerunErr)
+	require.Empty(t, rerunResult)
+
+	dao, err := NewThreatModelDao(client, id.NewDefaultRandomIDProvider(NewThreatModelRandomIDProviderPrefix()), datastore.DatastoreConfiguration{DatastoreKeyKind: DatastoreKeyKind}, ThreatModelIDCreator{})
+	require.Nil(t, err)
+
+	initechAll, err := dao.GetAll(inTenant("initech"))
+	require.Nil(t, err)
+	require.Equal(t, []*m.ThreatModel{legacy}, initechAll)
+
+	initechTags, err := dao.GetTags(inTenant("initech"), legacy.ThreatModelID)
+	require.Nil(t, err)
+	require.Equal(t, []string{"pci"}, initechTags)
+
+	acmeAll, err := dao.GetAll(inTenant("acme"))
+	require.Nil(t, err)
+	require.Equal(t, []*m.ThreatModel{acmeModel, moved}, acmeAll)
+
+	kinds, err := client.GetAll(ctx, gdatastore.NewQuery("__kind__").KeysOnly(), nil)
+	require.Nil(t, err)
+	require.Len(t, kinds, 1)
+	require.Equal(t, "other", kinds[0].Name)
+}
+
+func TestMigrateToTenantNamespacesNeedsDefaultTenant(t *testing.T) {
+	// given
+	ctx := context.Background()
+	client := datastoretest.NewClient(t)
+
+	_, err := client.Put(ctx, gdatastore.NameKey(DatastoreKeyKind, "tm-legacy", nil), &m.ThreatModel{Title: "from before tenants"})
+	require.Nil(t, err)
+
+	// when
+	_, err = MigrateToTenantNamespaces(ctx, client, "")
+
+	// then
+	require.Equal(t, ErrNoDefaultTenant, err)
+}
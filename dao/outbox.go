@@ -0,0 +1,211 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	gdatastore "cloud.google.com/go/datastore"
+	m "github.com/jtyers/tmaas-model"
+	servicedao "github.com/jtyers/tmaas-service-dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+var (
+	ErrPreconditionFailed = errors.New("threat model has changed since the ETag given")
+)
+
+type ifMatchContextKey struct{}
+
+// WithIfMatch returns a copy of ctx making updates made with it apply only
+// if the threat model's ETag (see tm.ThreatModelETag) is still etag, as
+// checked in the update's transaction; otherwise they fail with
+// ErrPreconditionFailed.
+func WithIfMatch(ctx context.Context, etag string) context.Context {
+	return context.WithValue(ctx, ifMatchContextKey{}, etag)
+}
+
+func ifMatchFromContext(ctx context.Context) string {
+	etag, _ := ctx.Value(ifMatchContextKey{}).(string)
+	return etag
+}
+
+// CreateWithOutbox creates a threat model, as Create does. Create is
+// CreateWithOutbox without a record.
+func (d *DatastoreThreatModelDao) CreateWithOutbox(ctx context.Context, params m.ThreatModelParams, record OutboxRecordFunc) (*m.ThreatModel, error) {
+	threatModel := &m.ThreatModel{ThreatModelID: d.idCreator.Create(d.randomIDProvider.Generate())}
+	applyParams(threatModel, params)
+
+	key, err := d.key(ctx, threatModel.ThreatModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = d.client.RunInTransaction(ctx, func(tx *gdatastore.Transaction) error {
+		if _, err := tx.Put(key, threatModel); err != nil {
+			return err
+		}
+		return appendOutboxRecord(ctx, tx, threatModel, record)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating threat model: %v", err)
+	}
+
+	return threatModel, nil
+}
+
+// UpdateWithOutbox updates a threat model, as Update does, if ctx carries
+// no ETag from WithIfMatch or the threat model still matches it. Update is
+// UpdateWithOutbox without a record.
+func (d *DatastoreThreatModelDao) UpdateWithOutbox(ctx context.Context, id m.ThreatModelID, params m.ThreatModelParams, record OutboxRecordFunc) (*m.ThreatModel, error) {
+	key, err := d.key(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated *m.ThreatModel
+	_, err = d.client.RunInTransaction(ctx, func(tx *gdatastore.Transaction) error {
+		threatModel, err := d.getInTransaction(tx, key)
+		if err != nil {
+			return err
+		}
+
+		if ifMatch := ifMatchFromContext(ctx); ifMatch != "" {
+			etag, err := tm.ThreatModelETag(threatModel)
+			if err != nil {
+				return err
+			}
+			if etag != ifMatch {
+				return ErrPreconditionFailed
+			}
+		}
+
+		applyParams(threatModel, params)
+
+		if _, err := tx.Put(key, threatModel); err != nil {
+			return err
+		}
+
+		updated = threatModel
+		return appendOutboxRecord(ctx, tx, threatModel, record)
+	})
+	if errors.Is(err, gdatastore.ErrNoSuchEntity) {
+		return nil, servicedao.ErrNoSuchDocument
+	}
+	if errors.Is(err, ErrPreconditionFailed) {
+		return nil, ErrPreconditionFailed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error updating threat model %s: %v", id, err)
+	}
+
+	return updated, nil
+}
+
+// DeleteWithOutbox deletes a threat model, as Delete does, along with its
+// tags, its membership of a project and its comments. Delete is
+// DeleteWithOutbox without a record.
+//
+// Comments are deleted once the threat model is, in batches of at most
+// maxMutations, as there may be too many to delete in its transaction.
+func (d *DatastoreThreatModelDao) DeleteWithOutbox(ctx context.Context, id m.ThreatModelID, record OutboxRecordFunc) error {
+	key, err := d.key(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	tagsKey, err := d.tagsKey(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	projectKey, err := tenantKey(ctx, ThreatModelProjectDatastoreKeyKind, id.String())
+	if err != nil {
+		return err
+	}
+
+	_, err = d.client.RunInTransaction(ctx, func(tx *gdatastore.Transaction) error {
+		// read first, so that deleting a missing threat model is reported,
+		// and its record can carry the threat model as it was
+		threatModel, err := d.getInTransaction(tx, key)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.DeleteMulti([]*gdatastore.Key{key, tagsKey, projectKey}); err != nil {
+			return err
+		}
+
+		return appendOutboxRecord(ctx, tx, threatModel, record)
+	})
+	if errors.Is(err, gdatastore.ErrNoSuchEntity) {
+		return servicedao.ErrNoSuchDocument
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting threat model %s: %v", id, err)
+	}
+
+	return d.deleteComments(ctx, id)
+}
+
+// deleteComments deletes every comment on a threat model, including those
+// on its threats.
+func (d *DatastoreThreatModelDao) deleteComments(ctx context.Context, id m.ThreatModelID) error {
+	q, err := tenantQuery(ctx, CommentDatastoreKeyKind)
+	if err != nil {
+		return err
+	}
+	q = q.FilterField("ThreatModelID", "=", id.String()).KeysOnly()
+
+	keys, err := d.client.GetAll(ctx, q, nil)
+	if err != nil {
+		return fmt.Errorf("error listing comments of threat model %s: %v", id, err)
+	}
+
+	for start := 0; start < len(keys); start += maxMutations {
+		end := start + maxMutations
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		if err := d.client.DeleteMulti(ctx, keys[start:end]); err != nil {
+			return fmt.Errorf("error deleting comments of threat model %s: %v", id, err)
+		}
+	}
+
+	return nil
+}
+
+// getInTransaction reads the threat model with the given key, setting its
+// ID from the key.
+func (d *DatastoreThreatModelDao) getInTransaction(tx *gdatastore.Transaction, key *gdatastore.Key) (*m.ThreatModel, error) {
+	threatModel := &m.ThreatModel{}
+	if err := tx.Get(key, threatModel); err != nil {
+		return nil, err
+	}
+
+	d.setID(threatModel, key)
+	return threatModel, nil
+}
+
+// applyParams sets each field of threatModel given in params (that is, each
+// non-nil pointer field of params) to the value given, leaving those not
+// given unchanged, as the generic DAO maps params onto documents.
+func applyParams(threatModel *m.ThreatModel, params m.ThreatModelParams) {
+	target := reflect.ValueOf(threatModel).Elem()
+	source := reflect.ValueOf(params)
+
+	for i := 0; i < source.NumField(); i++ {
+		field := source.Field(i)
+		if field.Kind() != reflect.Pointer || field.IsNil() {
+			continue
+		}
+
+		targetField := target.FieldByName(source.Type().Field(i).Name)
+		if !targetField.IsValid() || !targetField.CanSet() || !field.Elem().Type().AssignableTo(targetField.Type()) {
+			continue
+		}
+		targetField.Set(field.Elem())
+	}
+}
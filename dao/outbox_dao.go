@@ -0,0 +1,259 @@
+package dao
+
+//go:generate mockgen -source=$GOFILE -destination=${GOFILE}_mocks.go -package $GOPACKAGE
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gdatastore "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	m "github.com/jtyers/tmaas-model"
+	servicedao "github.com/jtyers/tmaas-service-dao"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+// the name of the lease entity in each tenant's namespace
+const outboxLeaseName = "relay"
+
+// OutboxRecordFunc builds the outbox record of a change to a threat model,
+// given the threat model as changed (or, if deleted, as it was) and the
+// record's position among those of the threat model. It is called within
+// the transaction making the change, possibly more than once if the
+// transaction is retried.
+type OutboxRecordFunc func(threatModel *m.ThreatModel, sequence int64) (*tm.OutboxRecord, error)
+
+// OutboxDao reads and updates the records in the outbox, which are written
+// by ThreatModelDao alongside each change to a threat model.
+//
+// GetPending needs a composite index on (Pending, ThreatModelID, Sequence).
+type OutboxDao interface {
+	// Retrieve the tenants that may have records in the outbox.
+	GetTenants(ctx context.Context) ([]auth.TenantID, error)
+
+	// Retrieve up to limit records not yet published, ordered by threat
+	// model and then by sequence.
+	GetPending(ctx context.Context, limit int) ([]*tm.OutboxRecord, error)
+
+	// Retrieve the record of a threat model with the given sequence, sent
+	// or not, returning servicedao.ErrNoSuchDocument if there is none.
+	GetRecord(ctx context.Context, threatModelID m.ThreatModelID, sequence int64) (*tm.OutboxRecord, error)
+
+	// Update a record after an attempt to publish it.
+	Update(ctx context.Context, record *tm.OutboxRecord) error
+
+	// Take or renew the lease on publishing the records of the tenant in
+	// ctx, returning false if another holder has it. Leases lapse after
+	// ttl unless renewed.
+	AcquireLease(ctx context.Context, holder string, ttl time.Duration) (bool, error)
+}
+
+// outboxEntity is the Datastore representation of an OutboxRecord.
+type outboxEntity struct {
+	ThreatModelID string
+	Type          string
+	Time          time.Time
+	Sequence      int64
+	Payload       []byte `datastore:",noindex"`
+	Attempts      int    `datastore:",noindex"`
+	LastError     string `datastore:",noindex"`
+	NextAttemptAt time.Time
+	Pending       bool
+	SentAt        time.Time
+}
+
+// outboxHead records the sequence of the last outbox record of a threat
+// model. It outlives the threat model, so sequences are never reused.
+type outboxHead struct {
+	Sequence int64
+}
+
+// outboxLease records which relay may publish a tenant's records.
+type outboxLease struct {
+	Holder string
+	Expiry time.Time
+}
+
+type DatastoreOutboxDao struct {
+	client *gdatastore.Client
+}
+
+var _ OutboxDao = (*DatastoreOutboxDao)(nil)
+
+func NewDatastoreOutboxDao(client *gdatastore.Client) *DatastoreOutboxDao {
+	return &DatastoreOutboxDao{client}
+}
+
+func (d *DatastoreOutboxDao) GetTenants(ctx context.Context) ([]auth.TenantID, error) {
+	return ListTenants(ctx, d.client)
+}
+
+func (d *DatastoreOutboxDao) GetPending(ctx context.Context, limit int) ([]*tm.OutboxRecord, error) {
+	q, err := tenantQuery(ctx, OutboxDatastoreKeyKind)
+	if err != nil {
+		return nil, err
+	}
+	q = q.FilterField("Pending", "=", true).
+		Order("ThreatModelID").
+		Order("Sequence").
+		Limit(limit)
+
+	entities := []outboxEntity{}
+	keys, err := d.client.GetAll(ctx, q, &entities)
+	if err != nil {
+		return nil, fmt.Errorf("error getting pending outbox records: %v", err)
+	}
+
+	result := make([]*tm.OutboxRecord, len(entities))
+	for i, e := range entities {
+		result[i] = outboxFromEntity(keys[i].Name, e)
+	}
+
+	return result, nil
+}
+
+func (d *DatastoreOutboxDao) GetRecord(ctx context.Context, threatModelID m.ThreatModelID, sequence int64) (*tm.OutboxRecord, error) {
+	q, err := tenantQuery(ctx, OutboxDatastoreKeyKind)
+	if err != nil {
+		return nil, err
+	}
+	q = q.FilterField("ThreatModelID", "=", threatModelID.String()).
+		FilterField("Sequence", "=", sequence).
+		Limit(1)
+
+	entities := []outboxEntity{}
+	keys, err := d.client.GetAll(ctx, q, &entities)
+	if err != nil {
+		return nil, fmt.Errorf("error getting outbox record %d of %s: %v", sequence, threatModelID, err)
+	}
+	if len(keys) == 0 {
+		return nil, servicedao.ErrNoSuchDocument
+	}
+
+	return outboxFromEntity(keys[0].Name, entities[0]), nil
+}
+
+func (d *DatastoreOutboxDao) Update(ctx context.Context, record *tm.OutboxRecord) error {
+	key, err := tenantKey(ctx, OutboxDatastoreKeyKind, record.OutboxRecordID)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.client.Put(ctx, key, outboxToEntity(*record))
+	if err != nil {
+		return fmt.Errorf("error updating outbox record %s: %v", record.OutboxRecordID, err)
+	}
+
+	return nil
+}
+
+func (d *DatastoreOutboxDao) AcquireLease(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	key, err := tenantKey(ctx, OutboxLeaseDatastoreKeyKind, outboxLeaseName)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	_, err = d.client.RunInTransaction(ctx, func(tx *gdatastore.Transaction) error {
+		now := time.Now()
+
+		lease := outboxLease{}
+		err := tx.Get(key, &lease)
+		if err != nil && err != gdatastore.ErrNoSuchEntity {
+			return err
+		}
+
+		acquired = lease.Holder == holder || !lease.Expiry.After(now)
+		if !acquired {
+			return nil
+		}
+
+		_, err = tx.Put(key, &outboxLease{holder, now.Add(ttl)})
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("error acquiring outbox lease: %v", err)
+	}
+
+	return acquired, nil
+}
+
+// appendOutboxRecord appends the record built by recordFunc for a change to
+// threatModel to the outbox, as part of tx. It appends nothing if recordFunc
+// is nil.
+func appendOutboxRecord(ctx context.Context, tx *gdatastore.Transaction, threatModel *m.ThreatModel, recordFunc OutboxRecordFunc) error {
+	if recordFunc == nil {
+		return nil
+	}
+
+	headKey, err := tenantKey(ctx, OutboxHeadDatastoreKeyKind, threatModel.ThreatModelID.String())
+	if err != nil {
+		return err
+	}
+
+	head := outboxHead{}
+	err = tx.Get(headKey, &head)
+	if err != nil && err != gdatastore.ErrNoSuchEntity {
+		return err
+	}
+
+	sequence := head.Sequence + 1
+
+	record, err := recordFunc(threatModel, sequence)
+	if err != nil {
+		return err
+	}
+	record.OutboxRecordID = tm.OutboxRecordIDPrefix + uuid.NewString()
+	record.ThreatModelID = threatModel.ThreatModelID
+	record.Sequence = sequence
+
+	key, err := tenantKey(ctx, OutboxDatastoreKeyKind, record.OutboxRecordID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Put(key, outboxToEntity(*record)); err != nil {
+		return err
+	}
+	_, err = tx.Put(headKey, &outboxHead{sequence})
+	return err
+}
+
+func outboxToEntity(r tm.OutboxRecord) *outboxEntity {
+	e := &outboxEntity{
+		ThreatModelID: r.ThreatModelID.String(),
+		Type:          r.Type,
+		Time:          r.Time,
+		Sequence:      r.Sequence,
+		Payload:       r.Payload,
+		Attempts:      r.Attempts,
+		LastError:     r.LastError,
+		NextAttemptAt: r.NextAttemptAt,
+		Pending:       r.SentAt == nil,
+	}
+	if r.SentAt != nil {
+		e.SentAt = *r.SentAt
+	}
+	return e
+}
+
+func outboxFromEntity(id string, e outboxEntity) *tm.OutboxRecord {
+	r := &tm.OutboxRecord{
+		OutboxRecordID: id,
+		ThreatModelID:  m.NewThreatModelIDP(e.ThreatModelID),
+		Type:           e.Type,
+		Time:           e.Time,
+		Sequence:       e.Sequence,
+		Payload:        e.Payload,
+		Attempts:       e.Attempts,
+		LastError:      e.LastError,
+		NextAttemptAt:  e.NextAttemptAt,
+	}
+	if !e.Pending {
+		sentAt := e.SentAt
+		r.SentAt = &sentAt
+	}
+	return r
+}
@@ -0,0 +1,113 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: outbox_dao.go
+
+// Package dao is a generated GoMock package.
+package dao
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/jtyers/tmaas-model"
+	auth "github.com/jtyers/tmaas-threat-model-api/auth"
+	model0 "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+// MockOutboxDao is a mock of OutboxDao interface.
+type MockOutboxDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxDaoMockRecorder
+}
+
+// MockOutboxDaoMockRecorder is the mock recorder for MockOutboxDao.
+type MockOutboxDaoMockRecorder struct {
+	mock *MockOutboxDao
+}
+
+// NewMockOutboxDao creates a new mock instance.
+func NewMockOutboxDao(ctrl *gomock.Controller) *MockOutboxDao {
+	mock := &MockOutboxDao{ctrl: ctrl}
+	mock.recorder = &MockOutboxDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxDao) EXPECT() *MockOutboxDaoMockRecorder {
+	return m.recorder
+}
+
+// AcquireLease mocks base method.
+func (m *MockOutboxDao) AcquireLease(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireLease", ctx, holder, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireLease indicates an expected call of AcquireLease.
+func (mr *MockOutboxDaoMockRecorder) AcquireLease(ctx, holder, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireLease", reflect.TypeOf((*MockOutboxDao)(nil).AcquireLease), ctx, holder, ttl)
+}
+
+// GetPending mocks base method.
+func (m *MockOutboxDao) GetPending(ctx context.Context, limit int) ([]*model0.OutboxRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPending", ctx, limit)
+	ret0, _ := ret[0].([]*model0.OutboxRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPending indicates an expected call of GetPending.
+func (mr *MockOutboxDaoMockRecorder) GetPending(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPending", reflect.TypeOf((*MockOutboxDao)(nil).GetPending), ctx, limit)
+}
+
+// GetRecord mocks base method.
+func (m *MockOutboxDao) GetRecord(ctx context.Context, threatModelID model.ThreatModelID, sequence int64) (*model0.OutboxRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecord", ctx, threatModelID, sequence)
+	ret0, _ := ret[0].(*model0.OutboxRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecord indicates an expected call of GetRecord.
+func (mr *MockOutboxDaoMockRecorder) GetRecord(ctx, threatModelID, sequence interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecord", reflect.TypeOf((*MockOutboxDao)(nil).GetRecord), ctx, threatModelID, sequence)
+}
+
+// GetTenants mocks base method.
+func (m *MockOutboxDao) GetTenants(ctx context.Context) ([]auth.TenantID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenants", ctx)
+	ret0, _ := ret[0].([]auth.TenantID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenants indicates an expected call of GetTenants.
+func (mr *MockOutboxDaoMockRecorder) GetTenants(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenants", reflect.TypeOf((*MockOutboxDao)(nil).GetTenants), ctx)
+}
+
+// Update mocks base method.
+func (m *MockOutboxDao) Update(ctx context.Context, record *model0.OutboxRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, record)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockOutboxDaoMockRecorder) Update(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockOutboxDao)(nil).Update), ctx, record)
+}
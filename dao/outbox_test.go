@@ -0,0 +1,138 @@
+package dao
+
+import (
+	"testing"
+
+	m "github.com/jtyers/tmaas-model"
+	servicedao "github.com/jtyers/tmaas-service-dao"
+	"github.com/jtyers/tmaas-service-dao/datastore"
+	"github.com/jtyers/tmaas-service-util/id"
+	"github.com/jtyers/tmaas-threat-model-api/dao/datastoretest"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/stretchr/testify/require"
+)
+
+// recordOf returns an OutboxRecordFunc building records of the given type.
+func recordOf(eventType string) OutboxRecordFunc {
+	return func(threatModel *m.ThreatModel, sequence int64) (*tm.OutboxRecord, error) {
+		return &tm.OutboxRecord{Type: eventType, Payload: []byte(threatModel.Title)}, nil
+	}
+}
+
+func TestThreatModelDaoWritesWithOutbox(t *testing.T) {
+	// given
+	ctx := inTenant("acme")
+	client := datastoretest.NewClient(t)
+	threatModelDao, err := NewThreatModelDao(client, id.NewDefaultRandomIDProvider(NewThreatModelRandomIDProviderPrefix()), datastore.DatastoreConfiguration{DatastoreKeyKind: DatastoreKeyKind}, ThreatModelIDCreator{})
+	require.Nil(t, err)
+	outboxDao := NewDatastoreOutboxDao(client)
+	projectDao := NewDatastoreProjectDao(client)
+
+	dfdID := m.NewDataFlowDiagramIDP("dfd-1")
+
+	// when created
+	created, err := threatModelDao.CreateWithOutbox(ctx, m.ThreatModelParams{
+		DataFlowDiagramID: &dfdID,
+		Title:             m.String("Payments gateway"),
+		Description:       m.String("Takes card payments"),
+	}, recordOf(tm.ThreatModelCreated))
+
+	// then every field given is stored
+	require.Nil(t, err)
+	expected := &m.ThreatModel{ThreatModelID: created.ThreatModelID, DataFlowDiagramID: dfdID, Title: "Payments gateway", Description: "Takes card payments"}
+	require.Equal(t, expected, created)
+
+	stored, err := threatModelDao.Get(ctx, created.ThreatModelID)
+	require.Nil(t, err)
+	require.Equal(t, expected, stored)
+
+	// when updated
+	updated, err := threatModelDao.UpdateWithOutbox(ctx, created.ThreatModelID, m.ThreatModelParams{Title: m.String("Card gateway")}, recordOf(tm.ThreatModelUpdated))
+
+	// then fields not given are unchanged
+	require.Nil(t, err)
+	expected.Title = "Card gateway"
+	require.Equal(t, expected, updated)
+
+	// when updated with the ETag it had before, and then with its own
+	etag, err := tm.ThreatModelETag(updated)
+	require.Nil(t, err)
+
+	_, staleErr := threatModelDao.UpdateWithOutbox(WithIfMatch(ctx, `"stale"`), created.ThreatModelID, m.ThreatModelParams{Title: m.String("Stale gateway")}, recordOf(tm.ThreatModelUpdated))
+	current, currentErr := threatModelDao.UpdateWithOutbox(WithIfMatch(ctx, etag), created.ThreatModelID, m.ThreatModelParams{}, nil)
+
+	// then only the update made with its current ETag is applied
+	require.Equal(t, ErrPreconditionFailed, staleErr)
+	require.Nil(t, currentErr)
+	require.Equal(t, expected, current)
+
+	// when deleted, along with its tags, project membership and comments
+	projectID := tm.ProjectID("prj-1")
+	require.Nil(t, threatModelDao.SetTags(ctx, created.ThreatModelID, []string{"pci"}))
+	require.Nil(t, projectDao.SetThreatModelProject(ctx, created.ThreatModelID, &projectID))
+
+	commentDao := NewDatastoreCommentDao(client)
+	for i := 0; i < maxMutations+1; i++ {
+		_, err := commentDao.Create(ctx, tm.Comment{ThreatModelID: created.ThreatModelID, Body: "Looks good"})
+		require.Nil(t, err)
+	}
+	other, err := commentDao.Create(ctx, tm.Comment{ThreatModelID: m.NewThreatModelIDP("tm-other"), Body: "Unrelated"})
+	require.Nil(t, err)
+
+	err = threatModelDao.DeleteWithOutbox(ctx, created.ThreatModelID, recordOf(tm.ThreatModelDeleted))
+
+	// then
+	require.Nil(t, err)
+
+	_, err = threatModelDao.Get(ctx, created.ThreatModelID)
+	require.Equal(t, servicedao.ErrNoSuchDocument, err)
+
+	tags, err := threatModelDao.GetTags(ctx, created.ThreatModelID)
+	require.Nil(t, err)
+	require.Empty(t, tags)
+
+	project, err := projectDao.GetThreatModelProject(ctx, created.ThreatModelID)
+	require.Nil(t, err)
+	require.Nil(t, project)
+
+	comments, err := commentDao.GetAllForThreatModel(ctx, created.ThreatModelID)
+	require.Nil(t, err)
+	require.Empty(t, comments)
+
+	_, err = commentDao.Get(ctx, other.CommentID)
+	require.Nil(t, err)
+
+	// and each change has its record, in order
+	records, err := outboxDao.GetPending(ctx, 10)
+	require.Nil(t, err)
+	require.Len(t, records, 3)
+	for i, eventType := range []string{tm.ThreatModelCreated, tm.ThreatModelUpdated, tm.ThreatModelDeleted} {
+		require.Equal(t, eventType, records[i].Type)
+		require.Equal(t, int64(i+1), records[i].Sequence)
+		require.Equal(t, created.ThreatModelID, records[i].ThreatModelID)
+	}
+	require.Equal(t, "Card gateway", string(records[2].Payload))
+}
+
+func TestThreatModelDaoReportsMissingThreatModelsWithOutbox(t *testing.T) {
+	// given
+	ctx := inTenant("acme")
+	client := datastoretest.NewClient(t)
+	threatModelDao, err := NewThreatModelDao(client, id.NewDefaultRandomIDProvider(NewThreatModelRandomIDProviderPrefix()), datastore.DatastoreConfiguration{DatastoreKeyKind: DatastoreKeyKind}, ThreatModelIDCreator{})
+	require.Nil(t, err)
+
+	missing := m.NewThreatModelIDP("tm-missing")
+
+	// when
+	_, updateErr := threatModelDao.UpdateWithOutbox(ctx, missing, m.ThreatModelParams{Title: m.String("Card gateway")}, recordOf(tm.ThreatModelUpdated))
+	deleteErr := threatModelDao.DeleteWithOutbox(ctx, missing, recordOf(tm.ThreatModelDeleted))
+
+	// then
+	require.Equal(t, servicedao.ErrNoSuchDocument, updateErr)
+	require.Equal(t, servicedao.ErrNoSuchDocument, deleteErr)
+
+	// and nothing is recorded for changes that did not happen
+	records, err := NewDatastoreOutboxDao(client).GetPending(ctx, 10)
+	require.Nil(t, err)
+	require.Empty(t, records)
+}
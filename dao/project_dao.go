@@ -0,0 +1,308 @@
+package dao
+
+//go:generate mockgen -source=$GOFILE -destination=${GOFILE}_mocks.go -package $GOPACKAGE
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gdatastore "cloud.google.com/go/datastore"
+	"github.com/google/uuid"
+	m "github.com/jtyers/tmaas-model"
+	servicedao "github.com/jtyers/tmaas-service-dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+// ProjectDao stores projects, and which project each threat model
+// belongs to.
+type ProjectDao interface {
+	// Retrieve a Project by ID, returning servicedao.ErrNoSuchDocument if
+	// it does not exist.
+	Get(ctx context.Context, id tm.ProjectID) (*tm.Project, error)
+
+	// Retrieve all projects.
+	GetAll(ctx context.Context) ([]*tm.Project, error)
+
+	// Create a project. The ProjectID is generated by the DAO.
+	Create(ctx context.Context, project tm.Project) (*tm.Project, error)
+
+	// Replace an existing project.
+	Update(ctx context.Context, project tm.Project) (*tm.Project, error)
+
+	// Delete a project by ID.
+	Delete(ctx context.Context, id tm.ProjectID) error
+
+	// Retrieve the project a threat model belongs to, or nil if it is not
+	// in a project.
+	GetThreatModelProject(ctx context.Context, threatModelID m.ThreatModelID) (*tm.ProjectID, error)
+
+	// Retrieve the projects of many threat models, keyed by threat model
+	// ID. Threat models not in a project are left out.
+	GetThreatModelProjects(ctx context.Context, threatModelIDs []m.ThreatModelID) (map[m.ThreatModelID]tm.ProjectID, error)
+
+	// Move a threat model into a project, or out of all projects if
+	// projectID is nil.
+	SetThreatModelProject(ctx context.Context, threatModelID m.ThreatModelID, projectID *tm.ProjectID) error
+
+	// Retrieve the IDs of the threat models directly within a project.
+	GetThreatModelIDs(ctx context.Context, projectID tm.ProjectID) ([]m.ThreatModelID, error)
+}
+
+// projectEntity is the Datastore representation of a Project.
+type projectEntity struct {
+	ParentProjectID string
+	Name            string
+	Description     string `datastore:",noindex"`
+	Members         []projectMemberEntity
+	Created         time.Time
+	Updated         time.Time
+}
+
+type projectMemberEntity struct {
+	UserID string
+	Role   string
+}
+
+// threatModelProjectEntity records the project a threat model belongs to,
+// keyed by threat model ID, since m.ThreatModel has no field for it.
+type threatModelProjectEntity struct {
+	ProjectID string
+}
+
+type DatastoreProjectDao struct {
+	client *gdatastore.Client
+}
+
+var _ ProjectDao = (*DatastoreProjectDao)(nil)
+
+func NewDatastoreProjectDao(client *gdatastore.Client) *DatastoreProjectDao {
+	return &DatastoreProjectDao{client}
+}
+
+func (d *DatastoreProjectDao) key(ctx context.Context, id tm.ProjectID) (*gdatastore.Key, error) {
+	return tenantKey(ctx, ProjectDatastoreKeyKind, id.String())
+}
+
+func (d *DatastoreProjectDao) threatModelKey(ctx context.Context, id m.ThreatModelID) (*gdatastore.Key, error) {
+	return tenantKey(ctx, ThreatModelProjectDatastoreKeyKind, id.String())
+}
+
+func (d *DatastoreProjectDao) Get(ctx context.Context, id tm.ProjectID) (*tm.Project, error) {
+	key, err := d.key(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	e := projectEntity{}
+	err = d.client.Get(ctx, key, &e)
+	if err != nil {
+		if err == gdatastore.ErrNoSuchEntity {
+			return nil, servicedao.ErrNoSuchDocument
+		}
+		return nil, fmt.Errorf("error getting project %s: %v", id, err)
+	}
+
+	return projectFromEntity(id, e), nil
+}
+
+func (d *DatastoreProjectDao) GetAll(ctx context.Context) ([]*tm.Project, error) {
+	q, err := tenantQuery(ctx, ProjectDatastoreKeyKind)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := []projectEntity{}
+	keys, err := d.client.GetAll(ctx, q, &entities)
+	if err != nil {
+		return nil, fmt.Errorf("error querying projects: %v", err)
+	}
+
+	result := make([]*tm.Project, len(keys))
+	for i, key := range keys {
+		result[i] = projectFromEntity(tm.ProjectID(key.Name), entities[i])
+	}
+
+	return result, nil
+}
+
+func (d *DatastoreProjectDao) Create(ctx context.Context, project tm.Project) (*tm.Project, error) {
+	project.ProjectID = tm.ProjectID(tm.ProjectIDPrefix + uuid.NewString())
+
+	key, err := d.key(ctx, project.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = d.client.Put(ctx, key, projectToEntity(project))
+	if err != nil {
+		return nil, fmt.Errorf("error creating project: %v", err)
+	}
+
+	return &project, nil
+}
+
+func (d *DatastoreProjectDao) Update(ctx context.Context, project tm.Project) (*tm.Project, error) {
+	key, err := d.key(ctx, project.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = d.client.Put(ctx, key, projectToEntity(project))
+	if err != nil {
+		return nil, fmt.Errorf("error updating project %s: %v", project.ProjectID, err)
+	}
+
+	return &project, nil
+}
+
+func (d *DatastoreProjectDao) Delete(ctx context.Context, id tm.ProjectID) error {
+	key, err := d.key(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = d.client.Delete(ctx, key)
+	if err != nil {
+		return fmt.Errorf("error deleting project %s: %v", id, err)
+	}
+
+	return nil
+}
+
+func (d *DatastoreProjectDao) GetThreatModelProject(ctx context.Context, threatModelID m.ThreatModelID) (*tm.ProjectID, error) {
+	key, err := d.threatModelKey(ctx, threatModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	e := threatModelProjectEntity{}
+	err = d.client.Get(ctx, key, &e)
+	if err != nil {
+		if err == gdatastore.ErrNoSuchEntity {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting project for %s: %v", threatModelID, err)
+	}
+
+	projectID := tm.ProjectID(e.ProjectID)
+	return &projectID, nil
+}
+
+func (d *DatastoreProjectDao) GetThreatModelProjects(ctx context.Context, threatModelIDs []m.ThreatModelID) (map[m.ThreatModelID]tm.ProjectID, error) {
+	result := map[m.ThreatModelID]tm.ProjectID{}
+
+	for start := 0; start < len(threatModelIDs); start += maxGetMulti {
+		end := start + maxGetMulti
+		if end > len(threatModelIDs) {
+			end = len(threatModelIDs)
+		}
+
+		keys := make([]*gdatastore.Key, end-start)
+		for i, id := range threatModelIDs[start:end] {
+			key, err := d.threatModelKey(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			keys[i] = key
+		}
+
+		entities := make([]threatModelProjectEntity, len(keys))
+		err := d.client.GetMulti(ctx, keys, entities)
+
+		errs, isMultiError := err.(gdatastore.MultiError)
+		if err != nil && !isMultiError {
+			return nil, fmt.Errorf("error getting projects of threat models: %v", err)
+		}
+
+		for i, id := range threatModelIDs[start:end] {
+			if isMultiError && errs[i] != nil {
+				if errs[i] == gdatastore.ErrNoSuchEntity {
+					continue
+				}
+				return nil, fmt.Errorf("error getting project for %s: %v", id, errs[i])
+			}
+			result[id] = tm.ProjectID(entities[i].ProjectID)
+		}
+	}
+
+	return result, nil
+}
+
+func (d *DatastoreProjectDao) SetThreatModelProject(ctx context.Context, threatModelID m.ThreatModelID, projectID *tm.ProjectID) error {
+	key, err := d.threatModelKey(ctx, threatModelID)
+	if err != nil {
+		return err
+	}
+
+	if projectID == nil {
+		err = d.client.Delete(ctx, key)
+	} else {
+		_, err = d.client.Put(ctx, key, &threatModelProjectEntity{projectID.String()})
+	}
+
+	if err != nil {
+		return fmt.Errorf("error setting project for %s: %v", threatModelID, err)
+	}
+
+	return nil
+}
+
+func (d *DatastoreProjectDao) GetThreatModelIDs(ctx context.Context, projectID tm.ProjectID) ([]m.ThreatModelID, error) {
+	q, err := tenantQuery(ctx, ThreatModelProjectDatastoreKeyKind)
+	if err != nil {
+		return nil, err
+	}
+	q = q.FilterField("ProjectID", "=", projectID.String()).KeysOnly()
+
+	keys, err := d.client.GetAll(ctx, q, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error querying threat models in %s: %v", projectID, err)
+	}
+
+	result := make([]m.ThreatModelID, len(keys))
+	for i, key := range keys {
+		result[i] = m.NewThreatModelIDP(key.Name)
+	}
+
+	return result, nil
+}
+
+func projectToEntity(p tm.Project) projectEntity {
+	e := projectEntity{
+		Name:        p.Name,
+		Description: p.Description,
+		Created:     p.Created,
+		Updated:     p.Updated,
+	}
+
+	if p.ParentProjectID != nil {
+		e.ParentProjectID = p.ParentProjectID.String()
+	}
+	for _, member := range p.Members {
+		e.Members = append(e.Members, projectMemberEntity{string(member.UserID), string(member.Role)})
+	}
+
+	return e
+}
+
+func projectFromEntity(id tm.ProjectID, e projectEntity) *tm.Project {
+	p := &tm.Project{
+		ProjectID:   id,
+		Name:        e.Name,
+		Description: e.Description,
+		Members:     []tm.ProjectMember{},
+		Created:     e.Created,
+		Updated:     e.Updated,
+	}
+
+	if e.ParentProjectID != "" {
+		parentID := tm.ProjectID(e.ParentProjectID)
+		p.ParentProjectID = &parentID
+	}
+	for _, member := range e.Members {
+		p.Members = append(p.Members, tm.ProjectMember{UserID: m.UserID(member.UserID), Role: tm.ProjectRole(member.Role)})
+	}
+
+	return p
+}
@@ -0,0 +1,170 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: project_dao.go
+
+// Package dao is a generated GoMock package.
+package dao
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/jtyers/tmaas-model"
+	model0 "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+// MockProjectDao is a mock of ProjectDao interface.
+type MockProjectDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockProjectDaoMockRecorder
+}
+
+// MockProjectDaoMockRecorder is the mock recorder for MockProjectDao.
+type MockProjectDaoMockRecorder struct {
+	mock *MockProjectDao
+}
+
+// NewMockProjectDao creates a new mock instance.
+func NewMockProjectDao(ctrl *gomock.Controller) *MockProjectDao {
+	mock := &MockProjectDao{ctrl: ctrl}
+	mock.recorder = &MockProjectDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProjectDao) EXPECT() *MockProjectDaoMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockProjectDao) Create(ctx context.Context, project model0.Project) (*model0.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, project)
+	ret0, _ := ret[0].(*model0.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockProjectDaoMockRecorder) Create(ctx, project interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockProjectDao)(nil).Create), ctx, project)
+}
+
+// Delete mocks base method.
+func (m *MockProjectDao) Delete(ctx context.Context, id model0.ProjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockProjectDaoMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockProjectDao)(nil).Delete), ctx, id)
+}
+
+// Get mocks base method.
+func (m *MockProjectDao) Get(ctx context.Context, id model0.ProjectID) (*model0.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*model0.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockProjectDaoMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockProjectDao)(nil).Get), ctx, id)
+}
+
+// GetAll mocks base method.
+func (m *MockProjectDao) GetAll(ctx context.Context) ([]*model0.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]*model0.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockProjectDaoMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockProjectDao)(nil).GetAll), ctx)
+}
+
+// GetThreatModelIDs mocks base method.
+func (m *MockProjectDao) GetThreatModelIDs(ctx context.Context, projectID model0.ProjectID) ([]model.ThreatModelID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetThreatModelIDs", ctx, projectID)
+	ret0, _ := ret[0].([]model.ThreatModelID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetThreatModelIDs indicates an expected call of GetThreatModelIDs.
+func (mr *MockProjectDaoMockRecorder) GetThreatModelIDs(ctx, projectID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetThreatModelIDs", reflect.TypeOf((*MockProjectDao)(nil).GetThreatModelIDs), ctx, projectID)
+}
+
+// GetThreatModelProject mocks base method.
+func (m *MockProjectDao) GetThreatModelProject(ctx context.Context, threatModelID model.ThreatModelID) (*model0.ProjectID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetThreatModelProject", ctx, threatModelID)
+	ret0, _ := ret[0].(*model0.ProjectID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetThreatModelProject indicates an expected call of GetThreatModelProject.
+func (mr *MockProjectDaoMockRecorder) GetThreatModelProject(ctx, threatModelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetThreatModelProject", reflect.TypeOf((*MockProjectDao)(nil).GetThreatModelProject), ctx, threatModelID)
+}
+
+// GetThreatModelProjects mocks base method.
+func (m *MockProjectDao) GetThreatModelProjects(ctx context.Context, threatModelIDs []model.ThreatModelID) (map[model.ThreatModelID]model0.ProjectID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetThreatModelProjects", ctx, threatModelIDs)
+	ret0, _ := ret[0].(map[model.ThreatModelID]model0.ProjectID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetThreatModelProjects indicates an expected call of GetThreatModelProjects.
+func (mr *MockProjectDaoMockRecorder) GetThreatModelProjects(ctx, threatModelIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetThreatModelProjects", reflect.TypeOf((*MockProjectDao)(nil).GetThreatModelProjects), ctx, threatModelIDs)
+}
+
+// SetThreatModelProject mocks base method.
+func (m *MockProjectDao) SetThreatModelProject(ctx context.Context, threatModelID model.ThreatModelID, projectID *model0.ProjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetThreatModelProject", ctx, threatModelID, projectID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetThreatModelProject indicates an expected call of SetThreatModelProject.
+func (mr *MockProjectDaoMockRecorder) SetThreatModelProject(ctx, threatModelID, projectID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetThreatModelProject", reflect.TypeOf((*MockProjectDao)(nil).SetThreatModelProject), ctx, threatModelID, projectID)
+}
+
+// Update mocks base method.
+func (m *MockProjectDao) Update(ctx context.Context, project model0.Project) (*model0.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, project)
+	ret0, _ := ret[0].(*model0.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockProjectDaoMockRecorder) Update(ctx, project interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockProjectDao)(nil).Update), ctx, project)
+}
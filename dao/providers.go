@@ -5,11 +5,17 @@ package dao
 // cannot use this as wire stumbles on the generics
 
 import (
+	"context"
+
+	gdatastore "cloud.google.com/go/datastore"
 	"github.com/google/wire"
 	m "github.com/jtyers/tmaas-model"
 	"github.com/jtyers/tmaas-service-dao/datastore"
 	util "github.com/jtyers/tmaas-service-util"
 	"github.com/jtyers/tmaas-service-util/id"
+	"github.com/jtyers/tmaas-service-util/log"
+
+	"github.com/jtyers/tmaas-threat-model-api/search"
 )
 
 func NewDatastoreConfig() datastore.DatastoreConfiguration {
@@ -19,15 +25,50 @@ func NewDatastoreConfig() datastore.DatastoreConfiguration {
 	}
 }
 
+// NewDatastoreClient creates the Datastore client shared by every DAO. The
+// returned cleanup function closes it.
+func NewDatastoreClient(ctx context.Context, c datastore.DatastoreConfiguration) (*gdatastore.Client, func(), error) {
+	client, err := datastore.NewDatastoreClient(ctx, c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cleanup := func() {
+		if err := client.Close(); err != nil {
+			log.Errorf("error closing Datastore client: %v", err)
+		}
+	}
+
+	return client, cleanup, nil
+}
+
 func NewThreatModelRandomIDProviderPrefix() id.RandomIDProviderPrefix {
 	return id.RandomIDProviderPrefix(m.ThreatModelIDPrefix)
 }
 
 var ThreatModelDaoProviderSet = wire.NewSet(
-	datastore.DaoProviderSet,
+	datastore.NewContext,
+	NewDatastoreClient,
 
 	NewThreatModelRandomIDProviderPrefix,
+	wire.Bind(new(ThreatModelDao), new(*InstrumentedThreatModelDao)),
+	NewInstrumentedThreatModelDao,
 	NewThreatModelDao,
 	NewThreatModelIDCreator,
 	NewDatastoreConfig,
+
+	wire.Bind(new(CommentDao), new(*DatastoreCommentDao)),
+	NewDatastoreCommentDao,
+
+	wire.Bind(new(ProjectDao), new(*DatastoreProjectDao)),
+	NewDatastoreProjectDao,
+
+	wire.Bind(new(AuditDao), new(*DatastoreAuditDao)),
+	NewDatastoreAuditDao,
+
+	wire.Bind(new(OutboxDao), new(*DatastoreOutboxDao)),
+	NewDatastoreOutboxDao,
+
+	wire.Bind(new(search.Index), new(*DatastoreSearchIndex)),
+	NewDatastoreSearchIndex,
 )
@@ -0,0 +1,246 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	gdatastore "cloud.google.com/go/datastore"
+
+	"github.com/jtyers/tmaas-threat-model-api/search"
+)
+
+const (
+	// maxIndexedTermBytes is the longest term indexed. Datastore cannot
+	// index longer strings, and nobody searches for them.
+	maxIndexedTermBytes = 1500
+
+	// maxSearchCandidates is the most documents containing every query term
+	// that are ranked. Queries matching more should be narrowed.
+	maxSearchCandidates = 1000
+
+	// maxMutations is the most puts or deletes Datastore allows in one commit.
+	maxMutations = 500
+)
+
+// searchEntity is an analysed document. Terms is indexed, so documents
+// containing every query term can be found with equality filters, and the
+// number containing each term counted; Weights holds the weight of the term
+// at the same position in Terms.
+type searchEntity struct {
+	Terms   []string
+	Weights []float64 `datastore:",noindex"`
+
+	// JSON of the document's fields, for building snippets
+	Fields []byte `datastore:",noindex"`
+}
+
+// DatastoreSearchIndex is a search.Index kept in Datastore, in the
+// namespace of each tenant, so every instance of the API shares it.
+type DatastoreSearchIndex struct {
+	client *gdatastore.Client
+}
+
+var _ search.Index = (*DatastoreSearchIndex)(nil)
+
+func NewDatastoreSearchIndex(client *gdatastore.Client) *DatastoreSearchIndex {
+	return &DatastoreSearchIndex{client: client}
+}
+
+func newSearchEntity(fields []search.Field) (*searchEntity, error) {
+	doc := search.Analyse(fields)
+
+	fieldsJSON, err := json.Marshal(doc.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	entity := &searchEntity{Terms: []string{}, Weights: []float64{}, Fields: fieldsJSON}
+	for term := range doc.Weights {
+		if len(term) <= maxIndexedTermBytes {
+			entity.Terms = append(entity.Terms, term)
+		}
+	}
+	sort.Strings(entity.Terms)
+
+	for _, term := range entity.Terms {
+		entity.Weights = append(entity.Weights, doc.Weights[term])
+	}
+
+	return entity, nil
+}
+
+func (e *searchEntity) document() (search.Document, error) {
+	doc := search.Document{Weights: map[string]float64{}}
+	if err := json.Unmarshal(e.Fields, &doc.Fields); err != nil {
+		return doc, err
+	}
+
+	for i, term := range e.Terms {
+		if i < len(e.Weights) {
+			doc.Weights[term] = e.Weights[i]
+		}
+	}
+
+	return doc, nil
+}
+
+func (x *DatastoreSearchIndex) Index(ctx context.Context, id string, fields []search.Field) error {
+	key, err := tenantKey(ctx, SearchDatastoreKeyKind, id)
+	if err != nil {
+		return err
+	}
+
+	entity, err := newSearchEntity(fields)
+	if err != nil {
+		return fmt.Errorf("error analysing %s: %v", id, err)
+	}
+
+	if _, err := x.client.Put(ctx, key, entity); err != nil {
+		return fmt.Errorf("error indexing %s: %v", id, err)
+	}
+	return nil
+}
+
+func (x *DatastoreSearchIndex) Remove(ctx context.Context, id string) error {
+	key, err := tenantKey(ctx, SearchDatastoreKeyKind, id)
+	if err != nil {
+		return err
+	}
+
+	if err := x.client.Delete(ctx, key); err != nil {
+		return fmt.Errorf("error un-indexing %s: %v", id, err)
+	}
+	return nil
+}
+
+// Reset deletes every document of the tenant, then indexes docs. It is not
+// atomic: searches made meanwhile may miss documents.
+func (x *DatastoreSearchIndex) Reset(ctx context.Context, docs map[string][]search.Field) error {
+	q, err := tenantQuery(ctx, SearchDatastoreKeyKind)
+	if err != nil {
+		return err
+	}
+
+	oldKeys, err := x.client.GetAll(ctx, q.KeysOnly(), nil)
+	if err != nil {
+		return fmt.Errorf("error listing indexed documents: %v", err)
+	}
+
+	for start := 0; start < len(oldKeys); start += maxMutations {
+		end := start + maxMutations
+		if end > len(oldKeys) {
+			end = len(oldKeys)
+		}
+
+		if err := x.client.DeleteMulti(ctx, oldKeys[start:end]); err != nil {
+			return fmt.Errorf("error clearing index: %v", err)
+		}
+	}
+
+	ids := make([]string, 0, len(docs))
+	for id := range docs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for start := 0; start < len(ids); start += maxMutations {
+		end := start + maxMutations
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		keys := make([]*gdatastore.Key, 0, end-start)
+		entities := make([]*searchEntity, 0, end-start)
+		for _, id := range ids[start:end] {
+			key, err := tenantKey(ctx, SearchDatastoreKeyKind, id)
+			if err != nil {
+				return err
+			}
+
+			entity, err := newSearchEntity(docs[id])
+			if err != nil {
+				return fmt.Errorf("error analysing %s: %v", id, err)
+			}
+
+			keys = append(keys, key)
+			entities = append(entities, entity)
+		}
+
+		if _, err := x.client.PutMulti(ctx, keys, entities); err != nil {
+			return fmt.Errorf("error indexing: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (x *DatastoreSearchIndex) Count(ctx context.Context) (int, error) {
+	q, err := tenantQuery(ctx, SearchDatastoreKeyKind)
+	if err != nil {
+		return 0, err
+	}
+
+	return x.count(ctx, q)
+}
+
+// count returns the number of documents matching q. It counts keys, as
+// the client's RunAggregationQuery is not forwarded to Datastore in the
+// version we depend on.
+func (x *DatastoreSearchIndex) count(ctx context.Context, q *gdatastore.Query) (int, error) {
+	n, err := x.client.Count(ctx, q)
+	if err != nil {
+		return 0, fmt.Errorf("error counting indexed documents: %v", err)
+	}
+	return n, nil
+}
+
+func (x *DatastoreSearchIndex) Search(ctx context.Context, query string, limit int) ([]search.Hit, error) {
+	queryTerms := search.Terms(query)
+	if len(queryTerms) == 0 {
+		return []search.Hit{}, nil
+	}
+
+	q, err := tenantQuery(ctx, SearchDatastoreKeyKind)
+	if err != nil {
+		return nil, err
+	}
+
+	docCount, err := x.count(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	docFreqs := map[string]int{}
+	candidates := q
+	for _, term := range queryTerms {
+		if len(term) > maxIndexedTermBytes {
+			return []search.Hit{}, nil
+		}
+
+		docFreqs[term], err = x.count(ctx, q.FilterField("Terms", "=", term))
+		if err != nil {
+			return nil, err
+		}
+
+		candidates = candidates.FilterField("Terms", "=", term)
+	}
+
+	var entities []searchEntity
+	keys, err := x.client.GetAll(ctx, candidates.Limit(maxSearchCandidates), &entities)
+	if err != nil {
+		return nil, fmt.Errorf("error searching: %v", err)
+	}
+
+	docs := map[string]search.Document{}
+	for i, key := range keys {
+		doc, err := entities[i].document()
+		if err != nil {
+			return nil, fmt.Errorf("error reading indexed document %s: %v", key.Name, err)
+		}
+		docs[key.Name] = doc
+	}
+
+	return search.Rank(docs, queryTerms, docCount, docFreqs, limit), nil
+}
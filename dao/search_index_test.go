@@ -0,0 +1,172 @@
+package dao
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/dao/datastoretest"
+	"github.com/jtyers/tmaas-threat-model-api/search"
+	"github.com/stretchr/testify/require"
+)
+
+func hitIDs(hits []search.Hit) []string {
+	result := []string{}
+	for _, hit := range hits {
+		result = append(result, hit.ID)
+	}
+	return result
+}
+
+func TestDatastoreSearchIndexSearch(t *testing.T) {
+	docs := map[string][]search.Field{
+		"tm-1": {
+			{Path: "title", Text: "Payments gateway"},
+			{Path: "threats[0].description", Text: "An attacker replays a card authorisation"},
+		},
+		"tm-2": {
+			{Path: "title", Text: "Card vault"},
+			{Path: "description", Text: "Stores card data for the payments team"},
+		},
+		"tm-3": {
+			{Path: "title", Text: "Marketing site"},
+			{Path: "dataFlowDiagramId", Text: "payments"},
+		},
+		"tm-4": {
+			{Path: "title", Text: "Grid telemetry " + strings.Repeat("x", maxIndexedTermBytes+1)},
+		},
+	}
+
+	var tests = []struct {
+		name        string
+		query       string
+		limit       int
+		expectedIDs []string
+	}{
+		{
+			"should rank title matches above other fields",
+			"payments",
+			0,
+			[]string{"tm-1", "tm-2"},
+		},
+		{
+			"should require all terms to match",
+			"card payments gateway",
+			0,
+			[]string{"tm-1"},
+		},
+		{
+			"should apply limit",
+			"payments",
+			1,
+			[]string{"tm-1"},
+		},
+		{
+			"should not match ID fields",
+			"marketing payments",
+			0,
+			[]string{},
+		},
+		{
+			"should index documents with terms too long to index",
+			"grid",
+			0,
+			[]string{"tm-4"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctx := inTenant("acme")
+			index := NewDatastoreSearchIndex(datastoretest.NewClient(t))
+			require.Nil(t, index.Reset(ctx, docs))
+
+			// when
+			hits, err := index.Search(ctx, test.query, test.limit)
+
+			// then
+			require.Nil(t, err)
+			require.Equal(t, test.expectedIDs, hitIDs(hits))
+		})
+	}
+}
+
+func TestDatastoreSearchIndexSnippets(t *testing.T) {
+	// given
+	ctx := inTenant("acme")
+	index := NewDatastoreSearchIndex(datastoretest.NewClient(t))
+	require.Nil(t, index.Index(ctx, "tm-1", []search.Field{{Path: "title", Text: "SQL injection in the login form"}}))
+
+	// when
+	hits, err := index.Search(ctx, "injection", 0)
+
+	// then
+	require.Nil(t, err)
+	require.Len(t, hits, 1)
+	require.Equal(t, []search.Snippet{{Field: "title", Fragment: "SQL <mark>injection</mark> in the login form"}}, hits[0].Snippets)
+}
+
+func TestDatastoreSearchIndexIsSharedAndIsolatesTenants(t *testing.T) {
+	// given two instances of the API sharing a Datastore
+	client := datastoretest.NewClient(t)
+	instance1, instance2 := NewDatastoreSearchIndex(client), NewDatastoreSearchIndex(client)
+	acme, globex := inTenant("acme"), inTenant("globex")
+
+	// when
+	require.Nil(t, instance1.Index(acme, "tm-1", []search.Field{{Path: "title", Text: "Old title"}}))
+	require.Nil(t, instance1.Index(globex, "tm-2", []search.Field{{Path: "title", Text: "Old title"}}))
+	require.Nil(t, instance2.Index(acme, "tm-1", []search.Field{{Path: "title", Text: "New title"}}))
+
+	// then
+	for _, index := range []search.Index{instance1, instance2} {
+		hits, err := index.Search(acme, "title", 0)
+		require.Nil(t, err)
+		require.Equal(t, []string{"tm-1"}, hitIDs(hits))
+
+		hits, err = index.Search(acme, "old", 0)
+		require.Nil(t, err)
+		require.Empty(t, hits)
+
+		n, err := index.Count(acme)
+		require.Nil(t, err)
+		require.Equal(t, 1, n)
+	}
+
+	// when removed
+	require.Nil(t, instance2.Remove(acme, "tm-1"))
+
+	// then
+	hits, err := instance1.Search(acme, "new", 0)
+	require.Nil(t, err)
+	require.Empty(t, hits)
+
+	hits, err = instance1.Search(globex, "old", 0)
+	require.Nil(t, err)
+	require.Equal(t, []string{"tm-2"}, hitIDs(hits))
+
+	_, err = instance1.Search(context.Background(), "old", 0)
+	require.Equal(t, auth.ErrNoTenant, err)
+}
+
+func TestDatastoreSearchIndexReset(t *testing.T) {
+	// given
+	ctx := inTenant("acme")
+	index := NewDatastoreSearchIndex(datastoretest.NewClient(t))
+	require.Nil(t, index.Index(ctx, "tm-stale", []search.Field{{Path: "title", Text: "Deleted model"}}))
+
+	// when
+	err := index.Reset(ctx, map[string][]search.Field{"tm-1": {{Path: "title", Text: "Payments gateway"}}})
+
+	// then
+	require.Nil(t, err)
+
+	n, err := index.Count(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 1, n)
+
+	hits, err := index.Search(ctx, "deleted", 0)
+	require.Nil(t, err)
+	require.Empty(t, hits)
+}
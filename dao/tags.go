@@ -0,0 +1,222 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	gdatastore "cloud.google.com/go/datastore"
+	m "github.com/jtyers/tmaas-model"
+)
+
+var (
+	ErrTooManyTags = errors.New("too many tags")
+)
+
+// tagsEntity holds the tags for one threat model. Tags is a multi-valued
+// property, and so is indexed per value, allowing both "any" queries (one
+// query per tag) and "all" queries (one equality filter per tag).
+type tagsEntity struct {
+	Tags []string
+}
+
+func (d *DatastoreThreatModelDao) tagsKey(ctx context.Context, id m.ThreatModelID) (*gdatastore.Key, error) {
+	return tenantKey(ctx, TagsDatastoreKeyKind, id.String())
+}
+
+func (d *DatastoreThreatModelDao) GetTags(ctx context.Context, id m.ThreatModelID) ([]string, error) {
+	key, err := d.tagsKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	e := tagsEntity{}
+	err = d.client.Get(ctx, key, &e)
+	if err != nil {
+		if err == gdatastore.ErrNoSuchEntity {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("error getting tags for %s: %v", id, err)
+	}
+
+	if e.Tags == nil {
+		return []string{}, nil
+	}
+	return e.Tags, nil
+}
+
+func (d *DatastoreThreatModelDao) SetTags(ctx context.Context, id m.ThreatModelID, tags []string) error {
+	key, err := d.tagsKey(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		err := d.client.Delete(ctx, key)
+		if err != nil && err != gdatastore.ErrNoSuchEntity {
+			return fmt.Errorf("error deleting tags for %s: %v", id, err)
+		}
+		return nil
+	}
+
+	_, err = d.client.Put(ctx, key, &tagsEntity{tags})
+	if err != nil {
+		return fmt.Errorf("error setting tags for %s: %v", id, err)
+	}
+
+	return nil
+}
+
+func (d *DatastoreThreatModelDao) AddTags(ctx context.Context, id m.ThreatModelID, tags []string, maxTags int) ([]string, error) {
+	result, err := d.updateTags(ctx, id, func(current []string) ([]string, error) {
+		seen := map[string]bool{}
+		updated := []string{}
+		for _, tag := range append(current, tags...) {
+			if !seen[tag] {
+				seen[tag] = true
+				updated = append(updated, tag)
+			}
+		}
+
+		if len(updated) > maxTags {
+			return nil, ErrTooManyTags
+		}
+		return updated, nil
+	})
+	if err == ErrTooManyTags {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error adding tags to %s: %v", id, err)
+	}
+
+	return result, nil
+}
+
+func (d *DatastoreThreatModelDao) RemoveTags(ctx context.Context, id m.ThreatModelID, tags []string) ([]string, error) {
+	remove := map[string]bool{}
+	for _, tag := range tags {
+		remove[tag] = true
+	}
+
+	result, err := d.updateTags(ctx, id, func(current []string) ([]string, error) {
+		updated := []string{}
+		for _, tag := range current {
+			if !remove[tag] {
+				updated = append(updated, tag)
+			}
+		}
+		return updated, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error removing tags from %s: %v", id, err)
+	}
+
+	return result, nil
+}
+
+// updateTags replaces the tags on a threat model with those returned by
+// update, given its current tags, reading and writing them in one
+// transaction so that concurrent changes are not lost. The tags written are
+// sorted, and the entity is deleted if none are left.
+func (d *DatastoreThreatModelDao) updateTags(ctx context.Context, id m.ThreatModelID, update func(current []string) ([]string, error)) ([]string, error) {
+	key, err := d.tagsKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	_, err = d.client.RunInTransaction(ctx, func(tx *gdatastore.Transaction) error {
+		e := tagsEntity{}
+		if err := tx.Get(key, &e); err != nil && err != gdatastore.ErrNoSuchEntity {
+			return err
+		}
+
+		updated, err := update(e.Tags)
+		if err != nil {
+			return err
+		}
+		sort.Strings(updated)
+		result = updated
+
+		if len(updated) == 0 {
+			return tx.Delete(key)
+		}
+
+		_, err = tx.Put(key, &tagsEntity{updated})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (d *DatastoreThreatModelDao) QueryIDsByTags(ctx context.Context, tags []string, matchAll bool) ([]m.ThreatModelID, error) {
+	if len(tags) == 0 {
+		return []m.ThreatModelID{}, nil
+	}
+
+	base, err := tenantQuery(ctx, TagsDatastoreKeyKind)
+	if err != nil {
+		return nil, err
+	}
+	base = base.KeysOnly()
+
+	var queries []*gdatastore.Query
+	if matchAll {
+		q := base
+		for _, tag := range tags {
+			q = q.FilterField("Tags", "=", tag)
+		}
+		queries = append(queries, q)
+
+	} else {
+		for _, tag := range tags {
+			queries = append(queries, base.FilterField("Tags", "=", tag))
+		}
+	}
+
+	result := []m.ThreatModelID{}
+	seen := map[string]bool{}
+
+	for _, q := range queries {
+		keys, err := d.client.GetAll(ctx, q, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error querying tags: %v", err)
+		}
+
+		for _, key := range keys {
+			if !seen[key.Name] {
+				seen[key.Name] = true
+				result = append(result, d.idCreator.Create(key.Name))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (d *DatastoreThreatModelDao) GetAllTags(ctx context.Context) (map[m.ThreatModelID][]string, error) {
+	q, err := tenantQuery(ctx, TagsDatastoreKeyKind)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := []tagsEntity{}
+	keys, err := d.client.GetAll(ctx, q, &entities)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tags: %v", err)
+	}
+
+	result := map[m.ThreatModelID][]string{}
+	for i, key := range keys {
+		if len(entities[i].Tags) > 0 {
+			result[d.idCreator.Create(key.Name)] = entities[i].Tags
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,65 @@
+package dao
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-service-dao/datastore"
+	"github.com/jtyers/tmaas-service-util/id"
+	"github.com/jtyers/tmaas-threat-model-api/dao/datastoretest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThreatModelDaoAddsAndRemovesTags(t *testing.T) {
+	// given
+	ctx := inTenant("acme")
+	threatModelDao, err := NewThreatModelDao(datastoretest.NewClient(t), id.NewDefaultRandomIDProvider(NewThreatModelRandomIDProviderPrefix()), datastore.DatastoreConfiguration{DatastoreKeyKind: DatastoreKeyKind}, ThreatModelIDCreator{})
+	require.Nil(t, err)
+
+	threatModelID := m.NewThreatModelIDP("tm-1")
+	require.Nil(t, threatModelDao.SetTags(ctx, threatModelID, []string{"pci"}))
+
+	// when tags are added concurrently, by no more writers than attempts
+	// each transaction is given, so that every writer gets to commit
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := threatModelDao.AddTags(ctx, threatModelID, []string{fmt.Sprintf("tag-%d", i)}, 10)
+			require.Nil(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	// then none are lost
+	tags, err := threatModelDao.GetTags(ctx, threatModelID)
+	require.Nil(t, err)
+	require.Equal(t, []string{"pci", "tag-0", "tag-1", "tag-2"}, tags)
+
+	// when a tag already carried is added
+	tags, err = threatModelDao.AddTags(ctx, threatModelID, []string{"pci"}, 4)
+
+	// then nothing changes
+	require.Nil(t, err)
+	require.Equal(t, []string{"pci", "tag-0", "tag-1", "tag-2"}, tags)
+
+	// when more tags are added than allowed
+	_, err = threatModelDao.AddTags(ctx, threatModelID, []string{"tag-3"}, 4)
+
+	// then
+	require.Equal(t, ErrTooManyTags, err)
+
+	// when every tag is removed
+	tags, err = threatModelDao.RemoveTags(ctx, threatModelID, []string{"pci", "tag-0", "tag-1", "tag-2"})
+
+	// then
+	require.Nil(t, err)
+	require.Empty(t, tags)
+
+	ids, err := threatModelDao.QueryIDsByTags(ctx, []string{"pci"}, false)
+	require.Nil(t, err)
+	require.Empty(t, ids)
+}
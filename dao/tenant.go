@@ -0,0 +1,53 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	gdatastore "cloud.google.com/go/datastore"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+)
+
+// Every key and query in this package is built by tenantKey or
+// tenantQuery, which place it in the namespace of the tenant in ctx.
+// There is no way to build one without a tenant, so no entity can be
+// read or written outside the caller's tenant. (The exceptions are
+// ListTenants, which reads no entities, and MigrateToTenantNamespaces.)
+
+func tenantKey(ctx context.Context, kind string, name string) (*gdatastore.Key, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := gdatastore.NameKey(kind, name, nil)
+	key.Namespace = tenantID.Namespace()
+	return key, nil
+}
+
+func tenantQuery(ctx context.Context, kind string) (*gdatastore.Query, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return gdatastore.NewQuery(kind).Namespace(tenantID.Namespace()), nil
+}
+
+// ListTenants returns the tenants with a namespace in Datastore, for jobs
+// that run across every tenant.
+func ListTenants(ctx context.Context, client *gdatastore.Client) ([]auth.TenantID, error) {
+	keys, err := client.GetAll(ctx, gdatastore.NewQuery("__namespace__").KeysOnly(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error listing namespaces: %v", err)
+	}
+
+	result := []auth.TenantID{}
+	for _, key := range keys {
+		if tenantID, ok := auth.TenantIDFromNamespace(key.Name); ok {
+			result = append(result, tenantID)
+		}
+	}
+
+	return result, nil
+}
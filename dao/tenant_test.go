@@ -0,0 +1,127 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	gdatastore "cloud.google.com/go/datastore"
+	m "github.com/jtyers/tmaas-model"
+	servicedao "github.com/jtyers/tmaas-service-dao"
+	"github.com/jtyers/tmaas-service-dao/datastore"
+	"github.com/jtyers/tmaas-service-util/id"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/dao/datastoretest"
+	"github.com/stretchr/testify/require"
+)
+
+func inTenant(tenantID auth.TenantID) context.Context {
+	return auth.WithIdentity(context.Background(), &auth.Identity{UserID: "u-1234", TenantID: tenantID})
+}
+
+func TestTenantKey(t *testing.T) {
+	var tests = []struct {
+		name              string
+		ctx               context.Context
+		expectedNamespace string
+		expectedError     error
+	}{
+		{
+			"should place keys in the tenant's namespace",
+			inTenant("acme"),
+			"tenant-acme",
+			nil,
+		},
+		{
+			"should refuse contexts without a tenant",
+			auth.WithIdentity(context.Background(), &auth.Identity{UserID: "u-1234"}),
+			"",
+			auth.ErrNoTenant,
+		},
+		{
+			"should refuse contexts without an identity",
+			context.Background(),
+			"",
+			auth.ErrNoTenant,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := tenantKey(test.ctx, CommentDatastoreKeyKind, "cmt-1")
+
+			require.Equal(t, test.expectedError, err)
+			if test.expectedError == nil {
+				require.Equal(t, test.expectedNamespace, key.Namespace)
+				require.Equal(t, CommentDatastoreKeyKind, key.Kind)
+				require.Equal(t, "cmt-1", key.Name)
+			}
+
+			_, err = tenantQuery(test.ctx, CommentDatastoreKeyKind)
+			require.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestThreatModelDaoIsolatesTenants(t *testing.T) {
+	// given
+	client := datastoretest.NewClient(t)
+	dao, err := NewThreatModelDao(client, id.NewDefaultRandomIDProvider(NewThreatModelRandomIDProviderPrefix()), datastore.DatastoreConfiguration{DatastoreKeyKind: DatastoreKeyKind}, ThreatModelIDCreator{})
+	require.Nil(t, err)
+
+	acme, globex := inTenant("acme"), inTenant("globex")
+	dfdID := m.NewDataFlowDiagramIDP("dfd-1")
+
+	created, err := dao.Create(acme, m.ThreatModelParams{Title: m.String("acme's model"), DataFlowDiagramID: &dfdID})
+	require.Nil(t, err)
+	require.Nil(t, dao.SetTags(acme, created.ThreatModelID, []string{"pci"}))
+
+	// when
+	acmeResult, acmeErr := dao.Get(acme, created.ThreatModelID)
+	globexResult, globexErr := dao.Get(globex, created.ThreatModelID)
+	globexMany, globexManyErr := dao.GetMany(globex, []m.ThreatModelID{created.ThreatModelID})
+	globexAll, globexAllErr := dao.GetAll(globex)
+	globexQuery, globexQueryErr := dao.QueryExact(globex, &m.ThreatModelQuery{DataFlowDiagramID: &dfdID})
+	globexTagged, globexTaggedErr := dao.QueryIDsByTags(globex, []string{"pci"}, false)
+	globexTags, globexTagsErr := dao.GetAllTags(globex)
+	_, globexUpdateErr := dao.Update(globex, created.ThreatModelID, m.ThreatModelParams{Title: m.String("globex's model")})
+	globexDeleteErr := dao.Delete(globex, created.ThreatModelID)
+	_, noTenantErr := dao.Get(context.Background(), created.ThreatModelID)
+
+	// then
+	require.Nil(t, acmeErr)
+	require.Equal(t, created, acmeResult)
+
+	require.Equal(t, servicedao.ErrNoSuchDocument, globexErr)
+	require.Nil(t, globexResult)
+	require.Nil(t, globexManyErr)
+	require.Empty(t, globexMany)
+	require.Nil(t, globexAllErr)
+	require.Empty(t, globexAll)
+	require.Nil(t, globexQueryErr)
+	require.Empty(t, globexQuery)
+	require.Nil(t, globexTaggedErr)
+	require.Empty(t, globexTagged)
+	require.Nil(t, globexTagsErr)
+	require.Empty(t, globexTags)
+	require.Equal(t, servicedao.ErrNoSuchDocument, globexUpdateErr)
+	require.Equal(t, servicedao.ErrNoSuchDocument, globexDeleteErr)
+	require.Equal(t, auth.ErrNoTenant, noTenantErr)
+
+	// acme's model is untouched by globex's attempts
+	acmeAll, err := dao.GetAll(acme)
+	require.Nil(t, err)
+	require.Equal(t, []*m.ThreatModel{created}, acmeAll)
+
+	acmeQuery, err := dao.QueryExact(acme, &m.ThreatModelQuery{DataFlowDiagramID: &dfdID})
+	require.Nil(t, err)
+	require.Equal(t, []*m.ThreatModel{created}, acmeQuery)
+
+	acmeTagged, err := dao.QueryIDsByTags(acme, []string{"pci"}, false)
+	require.Nil(t, err)
+	require.Equal(t, []m.ThreatModelID{created.ThreatModelID}, acmeTagged)
+
+	// and its entity is held in acme's namespace
+	keys, err := client.GetAll(context.Background(), gdatastore.NewQuery(DatastoreKeyKind).Namespace(auth.TenantID("acme").Namespace()).KeysOnly(), nil)
+	require.Nil(t, err)
+	require.Len(t, keys, 1)
+}
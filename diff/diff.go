@@ -0,0 +1,247 @@
+// Package diff compares two snapshots of a threat model, producing a
+// field-level list of changes, or a unified diff for reading as text.
+//
+// Snapshots are compared in their JSON form, as search does, so that new
+// fields on the threat model (and its threats, mitigations and so on) are
+// compared without changes here.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	m "github.com/jtyers/tmaas-model"
+)
+
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+// Change is a difference between two snapshots.
+type Change struct {
+	// The path of the field that differs, eg "title", or
+	// "threats[title=Spoofing].description" for a field of a list item.
+	// List items are identified by an ID or title field if they have one,
+	// and by index otherwise, eg "threats[2]".
+	Path string `json:"path"`
+
+	Type ChangeType `json:"type"`
+
+	// The value before and after the change. Old is empty for added
+	// fields, and New for removed ones.
+	Old any `json:"old,omitempty"`
+	New any `json:"new,omitempty"`
+}
+
+// Compare returns the changes that turn from into to, ordered by field
+// name, with list items in the order they appear. If either snapshot is
+// nil, the other is reported as added or removed whole.
+func Compare(from *m.ThreatModel, to *m.ThreatModel) ([]Change, error) {
+	return CompareValues(from, to)
+}
+
+// CompareValues returns the changes that turn from into to, as they would
+// be serialised to JSON.
+func CompareValues(from any, to any) ([]Change, error) {
+	fromJSON, err := toGeneric(from)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding from: %v", err)
+	}
+	toJSON, err := toGeneric(to)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding to: %v", err)
+	}
+
+	changes := []Change{}
+	compare("", fromJSON, toJSON, &changes)
+	return changes, nil
+}
+
+func toGeneric(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic any
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+func compare(path string, from any, to any, changes *[]Change) {
+	switch {
+	case from == nil && to == nil:
+		return
+
+	case from == nil:
+		*changes = append(*changes, Change{Path: path, Type: ChangeAdded, New: to})
+		return
+
+	case to == nil:
+		*changes = append(*changes, Change{Path: path, Type: ChangeRemoved, Old: from})
+		return
+	}
+
+	fromMap, fromIsMap := from.(map[string]any)
+	toMap, toIsMap := to.(map[string]any)
+	if fromIsMap && toIsMap {
+		compareMaps(path, fromMap, toMap, changes)
+		return
+	}
+
+	fromList, fromIsList := from.([]any)
+	toList, toIsList := to.([]any)
+	if fromIsList && toIsList {
+		compareLists(path, fromList, toList, changes)
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, Change{Path: path, Type: ChangeChanged, Old: from, New: to})
+	}
+}
+
+func compareMaps(path string, from map[string]any, to map[string]any, changes *[]Change) {
+	// sort keys so that changes are in a stable order
+	keys := []string{}
+	for k := range from {
+		keys = append(keys, k)
+	}
+	for k := range to {
+		if _, ok := from[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		compare(childPath(path, k), from[k], to[k], changes)
+	}
+}
+
+// compareLists matches the items of from and to by key if they have one,
+// or else by index, and compares those matched. Moving a keyed item within
+// a list is not a change.
+func compareLists(path string, from []any, to []any, changes *[]Change) {
+	key := listKey(from, to)
+	if key == "" {
+		for i := 0; i < len(from) || i < len(to); i++ {
+			var f, t any
+			if i < len(from) {
+				f = from[i]
+			}
+			if i < len(to) {
+				t = to[i]
+			}
+			compare(fmt.Sprintf("%s[%d]", path, i), f, t, changes)
+		}
+		return
+	}
+
+	toByKey := map[any]any{}
+	for _, item := range to {
+		toByKey[item.(map[string]any)[key]] = item
+	}
+
+	fromKeys := map[any]bool{}
+	for _, item := range from {
+		k := item.(map[string]any)[key]
+		fromKeys[k] = true
+		compare(fmt.Sprintf("%s[%s=%v]", path, key, k), item, toByKey[k], changes)
+	}
+
+	for _, item := range to {
+		k := item.(map[string]any)[key]
+		if !fromKeys[k] {
+			compare(fmt.Sprintf("%s[%s=%v]", path, key, k), nil, item, changes)
+		}
+	}
+}
+
+// listKey returns the field identifying the items of from and to: the
+// first field, in order of preference, that every item has a distinct
+// string value for. An ID field is preferred, then a title or name. It
+// returns "" if there is no such field, or the lists hold other than
+// objects.
+func listKey(from []any, to []any) string {
+	candidates := map[string]int{}
+
+	for _, list := range [][]any{from, to} {
+		for _, item := range list {
+			obj, ok := item.(map[string]any)
+			if !ok {
+				return ""
+			}
+
+			for k, v := range obj {
+				if s, ok := v.(string); ok && s != "" {
+					candidates[k]++
+				}
+			}
+		}
+	}
+
+	total := len(from) + len(to)
+	keys := []string{}
+	for k, count := range candidates {
+		if count == total && unique(k, from) && unique(k, to) {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		pi, pj := keyPreference(keys[i]), keyPreference(keys[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return keys[i] < keys[j]
+	})
+
+	if len(keys) == 0 || keyPreference(keys[0]) == noPreference {
+		return ""
+	}
+	return keys[0]
+}
+
+const noPreference = 3
+
+func keyPreference(k string) int {
+	lower := strings.ToLower(k)
+	switch {
+	case strings.HasSuffix(lower, "id"):
+		return 0
+	case lower == "title":
+		return 1
+	case lower == "name":
+		return 2
+	}
+	return noPreference
+}
+
+func unique(key string, list []any) bool {
+	seen := map[any]bool{}
+	for _, item := range list {
+		v := item.(map[string]any)[key]
+		if seen[v] {
+			return false
+		}
+		seen[v] = true
+	}
+	return true
+}
+
+func childPath(path string, k string) string {
+	if path == "" {
+		return k
+	}
+	return path + "." + k
+}
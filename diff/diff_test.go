@@ -0,0 +1,116 @@
+package diff
+
+import (
+	"testing"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/stretchr/testify/require"
+)
+
+type mitigation struct {
+	Description string `json:"description"`
+}
+
+type threat struct {
+	ThreatID    string       `json:"threatId,omitempty"`
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	Mitigations []mitigation `json:"mitigations,omitempty"`
+}
+
+type doc struct {
+	Title   string   `json:"title"`
+	Tags    []string `json:"tags,omitempty"`
+	Threats []threat `json:"threats,omitempty"`
+}
+
+func TestCompareValues(t *testing.T) {
+	var tests = []struct {
+		name     string
+		from     any
+		to       any
+		expected []Change
+	}{
+		{
+			"should find no changes between equal snapshots",
+			doc{Title: "Payments", Threats: []threat{{Title: "Spoofing"}}},
+			doc{Title: "Payments", Threats: []threat{{Title: "Spoofing"}}},
+			[]Change{},
+		},
+		{
+			"should find changed fields",
+			doc{Title: "Payments"},
+			doc{Title: "Billing"},
+			[]Change{{Path: "title", Type: ChangeChanged, Old: "Payments", New: "Billing"}},
+		},
+		{
+			"should find added and removed fields",
+			doc{Title: "Payments", Tags: []string{"pci"}},
+			doc{Title: "Payments", Threats: []threat{{Title: "Spoofing"}}},
+			[]Change{
+				{Path: "tags", Type: ChangeRemoved, Old: []any{"pci"}},
+				{Path: "threats", Type: ChangeAdded, New: []any{map[string]any{"title": "Spoofing"}}},
+			},
+		},
+		{
+			"should match list items by ID, ignoring their order",
+			doc{Threats: []threat{{ThreatID: "t-1", Title: "Spoofing"}, {ThreatID: "t-2", Title: "Tampering"}}},
+			doc{Threats: []threat{{ThreatID: "t-2", Title: "Tampering"}, {ThreatID: "t-1", Title: "Spoofing identity"}}},
+			[]Change{
+				{Path: "threats[threatId=t-1].title", Type: ChangeChanged, Old: "Spoofing", New: "Spoofing identity"},
+			},
+		},
+		{
+			"should match list items by title, reporting whole items added and removed",
+			doc{Threats: []threat{
+				{Title: "Spoofing", Mitigations: []mitigation{{Description: "MFA"}}},
+				{Title: "Tampering"},
+			}},
+			doc{Threats: []threat{
+				{Title: "Spoofing", Mitigations: []mitigation{{Description: "MFA"}, {Description: "Rate limiting"}}},
+				{Title: "Repudiation", Description: "No audit trail"},
+			}},
+			[]Change{
+				{Path: "threats[title=Spoofing].mitigations[1]", Type: ChangeAdded, New: map[string]any{"description": "Rate limiting"}},
+				{Path: "threats[title=Tampering]", Type: ChangeRemoved, Old: map[string]any{"title": "Tampering"}},
+				{Path: "threats[title=Repudiation]", Type: ChangeAdded, New: map[string]any{"title": "Repudiation", "description": "No audit trail"}},
+			},
+		},
+		{
+			"should match list items by index when they have no unique key",
+			doc{Threats: []threat{{Title: "Spoofing"}, {Title: "Spoofing"}}},
+			doc{Threats: []threat{{Title: "Spoofing"}, {Title: "Tampering"}}},
+			[]Change{
+				{Path: "threats[1].title", Type: ChangeChanged, Old: "Spoofing", New: "Tampering"},
+			},
+		},
+		{
+			"should report snapshots added whole",
+			nil,
+			doc{Title: "Payments"},
+			[]Change{{Path: "", Type: ChangeAdded, New: map[string]any{"title": "Payments"}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes, err := CompareValues(test.from, test.to)
+
+			require.Nil(t, err)
+			require.Equal(t, test.expected, changes)
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	from := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-1"), Title: "Payments"}
+	to := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-1"), Title: "Billing"}
+
+	changes, err := Compare(from, to)
+
+	require.Nil(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, ChangeChanged, changes[0].Type)
+	require.Equal(t, "Payments", changes[0].Old)
+	require.Equal(t, "Billing", changes[0].New)
+}
@@ -0,0 +1,285 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	m "github.com/jtyers/tmaas-model"
+)
+
+// the number of unchanged lines shown around each change
+const unifiedContext = 3
+
+// Unified renders the differences between from and to as a unified diff of
+// their indented JSON, labelled with fromLabel and toLabel. It returns ""
+// if they do not differ.
+func Unified(from *m.ThreatModel, to *m.ThreatModel, fromLabel string, toLabel string) (string, error) {
+	return UnifiedValues(from, to, fromLabel, toLabel)
+}
+
+// UnifiedValues renders the differences between from and to as a unified
+// diff of their indented JSON, where nil renders as no lines at all.
+func UnifiedValues(from any, to any, fromLabel string, toLabel string) (string, error) {
+	fromLines, err := jsonLines(from)
+	if err != nil {
+		return "", fmt.Errorf("error encoding from: %v", err)
+	}
+	toLines, err := jsonLines(to)
+	if err != nil {
+		return "", fmt.Errorf("error encoding to: %v", err)
+	}
+
+	edits := diffLines(fromLines, toLines)
+
+	b := strings.Builder{}
+	for _, h := range hunks(edits) {
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromLabel, toLabel)
+		}
+		writeHunk(&b, edits[h.start:h.end])
+	}
+
+	return b.String(), nil
+}
+
+func jsonLines(v any) ([]string, error) {
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil() {
+		return []string{}, nil
+	}
+
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(b), "\n"), nil
+}
+
+// edit is a line of a unified diff: unchanged (' '), removed ('-') or
+// added ('+'). fromLine and toLine are the number of lines of each side
+// before it.
+type edit struct {
+	op       byte
+	text     string
+	fromLine int
+	toLine   int
+}
+
+// diffLines returns a shortest edit script turning a into b, found with
+// the linear space refinement of Myers' algorithm: memory grows with the
+// number of lines, not with the lines times the number of edits.
+func diffLines(a []string, b []string) []edit {
+	d := &differ{a: a, b: b}
+	d.diff(0, len(a), 0, len(b))
+	return d.edits
+}
+
+// differ collects the edits turning a into b, in order.
+type differ struct {
+	a, b  []string
+	edits []edit
+}
+
+// diff appends the edits turning a[x0:x1] into b[y0:y1].
+func (d *differ) diff(x0, x1, y0, y1 int) {
+	// lines common to the start or end of both need no searching
+	for x0 < x1 && y0 < y1 && d.a[x0] == d.b[y0] {
+		d.edits = append(d.edits, edit{' ', d.a[x0], x0, y0})
+		x0++
+		y0++
+	}
+	suffix := 0
+	for x0 < x1-suffix && y0 < y1-suffix && d.a[x1-suffix-1] == d.b[y1-suffix-1] {
+		suffix++
+	}
+	x1, y1 = x1-suffix, y1-suffix
+
+	switch {
+	case x0 == x1:
+		for y := y0; y < y1; y++ {
+			d.edits = append(d.edits, edit{'+', d.b[y], x0, y})
+		}
+
+	case y0 == y1:
+		for x := x0; x < x1; x++ {
+			d.edits = append(d.edits, edit{'-', d.a[x], x, y0})
+		}
+
+	default:
+		if x, y, ok := d.split(x0, x1, y0, y1); ok {
+			d.diff(x0, x, y0, y)
+			d.diff(x, x1, y, y1)
+			break
+		}
+
+		// no way through was found, which should not happen; replace the
+		// lines rather than fail
+		for x := x0; x < x1; x++ {
+			d.edits = append(d.edits, edit{'-', d.a[x], x, y0})
+		}
+		for y := y0; y < y1; y++ {
+			d.edits = append(d.edits, edit{'+', d.b[y], x1, y})
+		}
+	}
+
+	for i := 0; i < suffix; i++ {
+		d.edits = append(d.edits, edit{' ', d.a[x1+i], x1 + i, y1 + i})
+	}
+}
+
+// split returns a point that a shortest edit script turning a[x0:x1] into
+// b[y0:y1] passes through, near its middle. It searches forwards from the
+// start and backwards from the end at once, until the two searches meet.
+func (d *differ) split(x0, x1, y0, y1 int) (int, int, bool) {
+	n, m := x1-x0, y1-y0
+	delta := n - m
+	odd := delta%2 != 0
+
+	// forward[offset+k] is the furthest x reached forwards on diagonal k,
+	// and backward[offset+k] that reached backwards, counted from the end,
+	// or -1 if the diagonal has not been reached
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	// diagonals are dropped from the search once they leave the grid
+	forwardStart, forwardEnd, backwardStart, backwardEnd := 0, 0, 0, 0
+
+	for dist := 0; dist < maxD; dist++ {
+		for k := -dist + forwardStart; k <= dist-forwardEnd; k += 2 {
+			i := offset + k
+
+			var x int
+			if k == -dist || (k != dist && forward[i-1] < forward[i+1]) {
+				x = forward[i+1] // down: insert from b
+			} else {
+				x = forward[i-1] + 1 // right: delete from a
+			}
+			y := x - k
+
+			for x < n && y < m && d.a[x0+x] == d.b[y0+y] {
+				x++
+				y++
+			}
+			forward[i] = x
+
+			switch {
+			case x > n:
+				forwardEnd += 2
+			case y > m:
+				forwardStart += 2
+			case odd:
+				j := offset + delta - k
+				if j >= 0 && j < len(backward) && backward[j] != -1 && x >= n-backward[j] {
+					return x0 + x, y0 + y, true
+				}
+			}
+		}
+
+		for k := -dist + backwardStart; k <= dist-backwardEnd; k += 2 {
+			i := offset + k
+
+			var x int
+			if k == -dist || (k != dist && backward[i-1] < backward[i+1]) {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+			y := x - k
+
+			for x < n && y < m && d.a[x1-x-1] == d.b[y1-y-1] {
+				x++
+				y++
+			}
+			backward[i] = x
+
+			switch {
+			case x > n:
+				backwardEnd += 2
+			case y > m:
+				backwardStart += 2
+			case !odd:
+				j := offset + delta - k
+				if j >= 0 && j < len(forward) && forward[j] != -1 && forward[j] >= n-x {
+					return x0 + forward[j], y0 + forward[j] - (j - offset), true
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+type hunk struct {
+	start, end int
+}
+
+// hunks groups the changes in edits with unifiedContext lines around them,
+// merging groups whose context would overlap.
+func hunks(edits []edit) []hunk {
+	result := []hunk{}
+
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+
+		start := i - unifiedContext
+		if start < 0 {
+			start = 0
+		}
+
+		end := i + 1
+		for j := end; j < len(edits); j++ {
+			if edits[j].op != ' ' {
+				end = j + 1
+			} else if j-end >= 2*unifiedContext {
+				break
+			}
+		}
+
+		end += unifiedContext
+		if end > len(edits) {
+			end = len(edits)
+		}
+
+		result = append(result, hunk{start, end})
+		i = end
+	}
+
+	return result
+}
+
+func writeHunk(b *strings.Builder, edits []edit) {
+	fromCount, toCount := 0, 0
+	for _, e := range edits {
+		if e.op != '+' {
+			fromCount++
+		}
+		if e.op != '-' {
+			toCount++
+		}
+	}
+
+	// ranges start at the first line shown, or for empty ranges, the line
+	// before them
+	fromStart, toStart := edits[0].fromLine, edits[0].toLine
+	if fromCount > 0 {
+		fromStart++
+	}
+	if toCount > 0 {
+		toStart++
+	}
+
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", fromStart, fromCount, toStart, toCount)
+	for _, e := range edits {
+		fmt.Fprintf(b, "%c%s\n", e.op, e.text)
+	}
+}
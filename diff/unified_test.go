@@ -0,0 +1,143 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnifiedValues(t *testing.T) {
+	var tests = []struct {
+		name     string
+		from     any
+		to       any
+		expected string
+	}{
+		{
+			"should render nothing for equal snapshots",
+			doc{Title: "Payments"},
+			doc{Title: "Payments"},
+			"",
+		},
+		{
+			"should render changes with context",
+			doc{Title: "Payments", Threats: []threat{{Title: "Spoofing"}, {Title: "Tampering"}}},
+			doc{Title: "Billing", Threats: []threat{{Title: "Spoofing"}, {Title: "Tampering"}, {Title: "Repudiation"}}},
+			`--- r1
++++ r2
+@@ -1,11 +1,14 @@
+ {
+-  "title": "Payments",
++  "title": "Billing",
+   "threats": [
+     {
+       "title": "Spoofing"
+     },
+     {
+       "title": "Tampering"
++    },
++    {
++      "title": "Repudiation"
+     }
+   ]
+ }
+`,
+		},
+		{
+			"should split distant changes into hunks",
+			doc{Title: "Payments", Tags: []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"}},
+			doc{Title: "Billing", Tags: []string{"a", "b", "c", "d", "e", "f", "g", "h", "j"}},
+			`--- r1
++++ r2
+@@ -1,5 +1,5 @@
+ {
+-  "title": "Payments",
++  "title": "Billing",
+   "tags": [
+     "a",
+     "b",
+@@ -9,6 +9,6 @@
+     "f",
+     "g",
+     "h",
+-    "i"
++    "j"
+   ]
+ }
+`,
+		},
+		{
+			"should render added snapshots",
+			nil,
+			doc{Title: "Payments"},
+			`--- r1
++++ r2
+@@ -0,0 +1,3 @@
++{
++  "title": "Payments"
++}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := UnifiedValues(test.from, test.to, "r1", "r2")
+
+			require.Nil(t, err)
+			require.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestUnified(t *testing.T) {
+	from := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-1"), Title: "Payments"}
+	to := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-1"), Title: "Billing"}
+
+	result, err := Unified(from, to, "tm-1@1", "tm-1@2")
+
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(result, "--- tm-1@1\n+++ tm-1@2\n@@ "), result)
+	require.Contains(t, result, "\n-")
+	require.Contains(t, result, "Payments")
+	require.Contains(t, result, "\n+")
+	require.Contains(t, result, "Billing")
+}
+
+func TestDiffLinesFindsShortestScriptForLongInputs(t *testing.T) {
+	// given every other line changed, in inputs long enough that keeping
+	// every round of the search would take hundreds of megabytes
+	from := make([]string, 5000)
+	to := make([]string, len(from))
+	for i := range from {
+		from[i] = fmt.Sprintf("line %d", i)
+		to[i] = from[i]
+		if i%2 == 0 {
+			to[i] = fmt.Sprintf("changed %d", i)
+		}
+	}
+
+	// when
+	edits := diffLines(from, to)
+
+	// then the script turns from into to, changing only what changed
+	var fromResult, toResult []string
+	changes := 0
+	for _, e := range edits {
+		if e.op != '+' {
+			fromResult = append(fromResult, e.text)
+		}
+		if e.op != '-' {
+			toResult = append(toResult, e.text)
+		}
+		if e.op != ' ' {
+			changes++
+		}
+	}
+	require.Equal(t, from, fromResult)
+	require.Equal(t, to, toResult)
+	require.Equal(t, len(from), changes)
+}
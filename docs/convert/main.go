@@ -0,0 +1,50 @@
+// Command convert converts the Swagger 2.0 document written by swag to
+// OpenAPI 3.
+//
+//	go run ./convert swagger.json openapi.json
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+)
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintf(os.Stderr, "usage: %s <swagger.json> <openapi.json>\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	if err := convert(os.Args[1], os.Args[2]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func convert(in, out string) error {
+	b, err := os.ReadFile(in)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", in, err)
+	}
+
+	var doc2 openapi2.T
+	if err := json.Unmarshal(b, &doc2); err != nil {
+		return fmt.Errorf("error parsing %s: %v", in, err)
+	}
+
+	doc3, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		return fmt.Errorf("error converting %s to OpenAPI 3: %v", in, err)
+	}
+
+	b, err = json.MarshalIndent(doc3, "", "    ")
+	if err != nil {
+		return fmt.Errorf("error encoding OpenAPI 3 document: %v", err)
+	}
+
+	return os.WriteFile(out, append(b, '\n'), 0644)
+}
@@ -0,0 +1,25 @@
+// Package docs holds the OpenAPI document describing the API, generated
+// from the swag annotations in main.go and on the handlers, along with a
+// page rendering it with Redoc.
+//
+// swag only writes Swagger 2.0, so the document is converted to OpenAPI 3
+// after generation. Regenerate both after changing an annotation.
+package docs
+
+//go:generate swag init -g main.go -d .. -o . --outputTypes json --parseDependency --parseDepth 2
+//go:generate go run ./convert swagger.json openapi.json
+
+import (
+	_ "embed"
+)
+
+// OpenAPI is the OpenAPI 3 document, as JSON.
+//
+//go:embed openapi.json
+var OpenAPI []byte
+
+// Redoc is an HTML page rendering the document at "openapi.json", relative
+// to the page.
+//
+//go:embed redoc.html
+var Redoc []byte
@@ -0,0 +1,49 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	util "github.com/jtyers/tmaas-service-util"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+// Config configures the consumption of data flow diagram events.
+type Config struct {
+	// The Google Cloud project holding the subscription.
+	ProjectID string
+
+	// The Pub/Sub subscription data flow diagram events are pulled from.
+	// Events are not consumed if empty.
+	Subscription string
+}
+
+// NewConfig reads the subscription to consume data flow diagram events
+// from DFD_EVENTS_SUBSCRIPTION, and its project from PROJECT_ID, as used
+// for Datastore.
+func NewConfig() Config {
+	config := Config{
+		Subscription: util.GetEnvWithDefault("DFD_EVENTS_SUBSCRIPTION", ""),
+	}
+
+	if config.Subscription != "" {
+		config.ProjectID = util.GetEnv("PROJECT_ID")
+	}
+
+	return config
+}
+
+// NewSubscriber returns a PubSubSubscriber for the subscription in config,
+// or nil if there is none.
+func NewSubscriber(ctx context.Context, config Config) (Subscriber, error) {
+	if config.Subscription == "" {
+		return nil, nil
+	}
+
+	service, err := pubsub.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Pub/Sub client: %v", err)
+	}
+
+	return NewPubSubSubscriber(service, config.ProjectID, config.Subscription), nil
+}
@@ -0,0 +1,25 @@
+package events
+
+import (
+	"context"
+)
+
+// Consumer passes the messages received by a Subscriber to a Handler.
+type Consumer struct {
+	subscriber Subscriber
+	handler    Handler
+}
+
+// NewConsumer returns a Consumer, or nil if there is no subscriber (and so
+// nothing to consume).
+func NewConsumer(subscriber Subscriber, handler Handler) *Consumer {
+	if subscriber == nil {
+		return nil
+	}
+	return &Consumer{subscriber: subscriber, handler: handler}
+}
+
+// Run handles messages until ctx is done.
+func (c *Consumer) Run(ctx context.Context) error {
+	return c.subscriber.Receive(ctx, c.handler)
+}
@@ -0,0 +1,18 @@
+package events
+
+// Types of DataFlowDiagramEvent.
+const (
+	// A data flow diagram was changed.
+	DataFlowDiagramUpdated = "dfd.updated"
+
+	// A data flow diagram was deleted.
+	DataFlowDiagramDeleted = "dfd.deleted"
+)
+
+// DataFlowDiagramEvent is the JSON payload of a lifecycle event published
+// by the data flow diagram API.
+type DataFlowDiagramEvent struct {
+	Type              string `json:"type"`
+	DataFlowDiagramID string `json:"dataFlowDiagramId"`
+	TenantID          string `json:"tenantId"`
+}
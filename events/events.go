@@ -0,0 +1,51 @@
+// Package events consumes events published by other services, such as the
+// lifecycle events of data flow diagrams published by the DFD API, and
+// publishes events for them to consume. Messages are received through a
+// Subscriber and sent through a Publisher, which are backed by Pub/Sub in
+// production and by a MemoryBus in tests.
+package events
+
+//go:generate mockgen -source=$GOFILE -destination=${GOFILE}_mocks.go -package $GOPACKAGE
+
+import (
+	"context"
+)
+
+// Message is a message sent to or received from a message bus.
+type Message struct {
+	ID         string
+	Data       []byte
+	Attributes map[string]string
+
+	// Messages with the same ordering key are delivered in the order
+	// published.
+	OrderingKey string
+}
+
+// Handler handles messages. Messages it fails to handle are redelivered,
+// so it must be safe to handle a message more than once, and it should
+// only fail if a later attempt might succeed.
+type Handler interface {
+	Handle(ctx context.Context, msg *Message) error
+}
+
+// HandlerFunc adapts a function to a Handler.
+type HandlerFunc func(ctx context.Context, msg *Message) error
+
+func (f HandlerFunc) Handle(ctx context.Context, msg *Message) error {
+	return f(ctx, msg)
+}
+
+// Subscriber receives messages from a subscription on a message bus.
+type Subscriber interface {
+	// Receive passes each message received to handler until ctx is done.
+	// Messages are acknowledged if handler succeeds, and redelivered
+	// otherwise. It returns nil once ctx is done.
+	Receive(ctx context.Context, handler Handler) error
+}
+
+// Publisher publishes messages to a topic on a message bus.
+type Publisher interface {
+	// Publish sends msg, returning once the bus has accepted it.
+	Publish(ctx context.Context, msg *Message) error
+}
@@ -0,0 +1,121 @@
+package events
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultRedeliveryDelay is how long a MemoryBus waits before redelivering
+// a message its handler failed to handle.
+const DefaultRedeliveryDelay = 10 * time.Millisecond
+
+// MemoryBus is a Publisher and Subscriber that delivers the messages
+// published to it, in the order published. It is intended for tests,
+// standing in for Pub/Sub.
+type MemoryBus struct {
+	RedeliveryDelay time.Duration
+
+	mu     sync.Mutex
+	queue  []*Message
+	nextID int
+	acked  []*Message
+	ready  chan struct{} // signalled when a message is queued
+}
+
+var _ Publisher = (*MemoryBus)(nil)
+var _ Subscriber = (*MemoryBus)(nil)
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{
+		RedeliveryDelay: DefaultRedeliveryDelay,
+		ready:           make(chan struct{}, 1),
+	}
+}
+
+// Publish queues msg for delivery, giving it an ID if it has none.
+func (b *MemoryBus) Publish(ctx context.Context, msg *Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if msg.ID == "" {
+		b.nextID++
+		msg.ID = strconv.Itoa(b.nextID)
+	}
+
+	b.queue = append(b.queue, msg)
+	b.signal()
+
+	return nil
+}
+
+// Acked returns the messages handled successfully so far, in the order
+// they were handled.
+func (b *MemoryBus) Acked() []*Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*Message{}, b.acked...)
+}
+
+// Pending returns the number of messages not yet handled successfully,
+// including any awaiting redelivery.
+func (b *MemoryBus) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.queue)
+}
+
+func (b *MemoryBus) Receive(ctx context.Context, handler Handler) error {
+	for {
+		msg := b.next()
+		if msg == nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-b.ready:
+				continue
+			}
+		}
+
+		if err := handler.Handle(ctx, msg); err != nil {
+			// left at the head of the queue, so delivered again after a
+			// delay (and before any later message)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(b.RedeliveryDelay):
+				continue
+			}
+		}
+
+		b.ack(msg)
+	}
+}
+
+// next returns the message at the head of the queue, or nil if it is empty.
+func (b *MemoryBus) next() *Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.queue) == 0 {
+		return nil
+	}
+	return b.queue[0]
+}
+
+func (b *MemoryBus) ack(msg *Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.queue = b.queue[1:]
+	b.acked = append(b.acked, msg)
+}
+
+// signal wakes Receive if it is waiting; b.mu must be held.
+func (b *MemoryBus) signal() {
+	select {
+	case b.ready <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,82 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBus(t *testing.T) {
+	// given
+	bus := NewMemoryBus()
+	bus.RedeliveryDelay = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mu := sync.Mutex{}
+	delivered := []string{}
+
+	// fails the first delivery of "b", which should be redelivered before
+	// "c" is delivered
+	handler := HandlerFunc(func(ctx context.Context, msg *Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		delivered = append(delivered, string(msg.Data))
+		if string(msg.Data) == "b" && len(delivered) == 2 {
+			return errors.New("failed")
+		}
+		if len(delivered) == 4 {
+			cancel()
+		}
+		return nil
+	})
+
+	bus.Publish(ctx, &Message{Data: []byte("a")})
+	bus.Publish(ctx, &Message{Data: []byte("b")})
+	bus.Publish(ctx, &Message{Data: []byte("c")})
+
+	// when
+	err := bus.Receive(ctx, handler)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, []string{"a", "b", "b", "c"}, delivered)
+	require.Equal(t, 0, bus.Pending())
+
+	acked := bus.Acked()
+	require.Len(t, acked, 3)
+	require.Equal(t, []string{"1", "2", "3"}, []string{acked[0].ID, acked[1].ID, acked[2].ID})
+}
+
+func TestMemoryBusWaitsForMessages(t *testing.T) {
+	// given
+	bus := NewMemoryBus()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := make(chan *Message)
+	go func() {
+		_ = bus.Receive(ctx, HandlerFunc(func(ctx context.Context, msg *Message) error {
+			received <- msg
+			return nil
+		}))
+	}()
+
+	// when
+	bus.Publish(ctx, &Message{Data: []byte("a")})
+
+	// then
+	select {
+	case msg := <-received:
+		require.Equal(t, "a", string(msg.Data))
+	case <-ctx.Done():
+		t.Fatal("message not received")
+	}
+}
@@ -0,0 +1,11 @@
+package events
+
+import (
+	"github.com/google/wire"
+)
+
+var EventsProviderSet = wire.NewSet(
+	NewConfig,
+	NewSubscriber,
+	NewConsumer,
+)
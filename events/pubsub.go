@@ -0,0 +1,177 @@
+package events
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/jtyers/tmaas-service-util/log"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+const (
+	// the most messages to pull at once
+	maxPullMessages = 10
+
+	// how long to wait before pulling again after a failed pull, doubling
+	// up to maxPullBackoff while pulls keep failing
+	minPullBackoff = 100 * time.Millisecond
+	maxPullBackoff = 30 * time.Second
+)
+
+// PubSubSubscriber is a Subscriber that pulls messages from a Pub/Sub
+// subscription.
+type PubSubSubscriber struct {
+	service *pubsub.Service
+
+	// the full name of the subscription, as
+	// "projects/{project}/subscriptions/{subscription}"
+	subscription string
+}
+
+var _ Subscriber = (*PubSubSubscriber)(nil)
+
+func NewPubSubSubscriber(service *pubsub.Service, projectID string, subscription string) *PubSubSubscriber {
+	return &PubSubSubscriber{
+		service:      service,
+		subscription: fmt.Sprintf("projects/%s/subscriptions/%s", projectID, subscription),
+	}
+}
+
+func (s *PubSubSubscriber) Receive(ctx context.Context, handler Handler) error {
+	backoff := minPullBackoff
+
+	for ctx.Err() == nil {
+		received, err := s.pull(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+
+			log.Errorf("error pulling from %s, retrying in %v: %v", s.subscription, backoff, err)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(backoff):
+			}
+
+			if backoff *= 2; backoff > maxPullBackoff {
+				backoff = maxPullBackoff
+			}
+			continue
+		}
+		backoff = minPullBackoff
+
+		s.handle(ctx, handler, received)
+	}
+
+	return nil
+}
+
+func (s *PubSubSubscriber) pull(ctx context.Context) ([]*pubsub.ReceivedMessage, error) {
+	resp, err := s.service.Projects.Subscriptions.
+		Pull(s.subscription, &pubsub.PullRequest{MaxMessages: maxPullMessages}).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.ReceivedMessages, nil
+}
+
+// handle passes each of received to handler, then acknowledges those
+// handled and nacks the rest so they are redelivered straight away.
+func (s *PubSubSubscriber) handle(ctx context.Context, handler Handler, received []*pubsub.ReceivedMessage) {
+	var ackIDs, nackIDs []string
+
+	for _, rm := range received {
+		msg, err := fromPubSubMessage(rm.Message)
+		if err != nil {
+			// could never be handled, so acknowledged rather than
+			// redelivered forever
+			log.Errorf("error decoding message %s from %s, dropping it: %v", rm.Message.MessageId, s.subscription, err)
+			ackIDs = append(ackIDs, rm.AckId)
+			continue
+		}
+
+		if err := handler.Handle(ctx, msg); err != nil {
+			log.Errorf("error handling message %s from %s, it will be redelivered: %v", msg.ID, s.subscription, err)
+			nackIDs = append(nackIDs, rm.AckId)
+			continue
+		}
+
+		ackIDs = append(ackIDs, rm.AckId)
+	}
+
+	// ctx may be done by now, but these calls are quick and save messages
+	// already handled being delivered again
+	ctx = context.Background()
+
+	if len(ackIDs) > 0 {
+		_, err := s.service.Projects.Subscriptions.
+			Acknowledge(s.subscription, &pubsub.AcknowledgeRequest{AckIds: ackIDs}).
+			Context(ctx).
+			Do()
+		if err != nil {
+			// the messages will be redelivered once their ack deadline
+			// passes, which handlers must tolerate anyway
+			log.Errorf("error acknowledging %d messages from %s: %v", len(ackIDs), s.subscription, err)
+		}
+	}
+
+	if len(nackIDs) > 0 {
+		_, err := s.service.Projects.Subscriptions.
+			ModifyAckDeadline(s.subscription, &pubsub.ModifyAckDeadlineRequest{AckIds: nackIDs, AckDeadlineSeconds: 0}).
+			Context(ctx).
+			Do()
+		if err != nil {
+			log.Errorf("error nacking %d messages from %s: %v", len(nackIDs), s.subscription, err)
+		}
+	}
+}
+
+func fromPubSubMessage(pm *pubsub.PubsubMessage) (*Message, error) {
+	data, err := base64.StdEncoding.DecodeString(pm.Data)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding data: %v", err)
+	}
+
+	return &Message{ID: pm.MessageId, Data: data, Attributes: pm.Attributes, OrderingKey: pm.OrderingKey}, nil
+}
+
+// PubSubPublisher is a Publisher that publishes messages to a Pub/Sub
+// topic. The topic's subscriptions must enable message ordering for
+// ordering keys to take effect.
+type PubSubPublisher struct {
+	service *pubsub.Service
+
+	// the full name of the topic, as "projects/{project}/topics/{topic}"
+	topic string
+}
+
+var _ Publisher = (*PubSubPublisher)(nil)
+
+func NewPubSubPublisher(service *pubsub.Service, projectID string, topic string) *PubSubPublisher {
+	return &PubSubPublisher{
+		service: service,
+		topic:   fmt.Sprintf("projects/%s/topics/%s", projectID, topic),
+	}
+}
+
+func (p *PubSubPublisher) Publish(ctx context.Context, msg *Message) error {
+	_, err := p.service.Projects.Topics.
+		Publish(p.topic, &pubsub.PublishRequest{Messages: []*pubsub.PubsubMessage{{
+			Data:        base64.StdEncoding.EncodeToString(msg.Data),
+			Attributes:  msg.Attributes,
+			OrderingKey: msg.OrderingKey,
+		}}}).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return fmt.Errorf("error publishing to %s: %v", p.topic, err)
+	}
+
+	return nil
+}
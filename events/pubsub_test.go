@@ -0,0 +1,187 @@
+package events
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+// fakePubSub serves a Pub/Sub subscription holding messages, recording
+// the messages acknowledged and nacked.
+type fakePubSub struct {
+	t *testing.T
+
+	mu       sync.Mutex
+	messages []*pubsub.ReceivedMessage
+	acked    []string
+	nacked   []string
+
+	// called once every message has been pulled
+	drained func()
+}
+
+func (f *fakePubSub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	require.True(f.t, strings.HasPrefix(r.URL.Path, "/v1/projects/my-project/subscriptions/dfd-events:"), r.URL.Path)
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, ":pull"):
+		if len(f.messages) == 0 {
+			f.drained()
+			// Pub/Sub returns no messages once none are left
+			w.Write([]byte(`{}`))
+			return
+		}
+
+		json.NewEncoder(w).Encode(&pubsub.PullResponse{ReceivedMessages: f.messages})
+		f.messages = nil
+
+	case strings.HasSuffix(r.URL.Path, ":acknowledge"):
+		req := pubsub.AcknowledgeRequest{}
+		require.Nil(f.t, json.NewDecoder(r.Body).Decode(&req))
+		f.acked = append(f.acked, req.AckIds...)
+		w.Write([]byte(`{}`))
+
+	case strings.HasSuffix(r.URL.Path, ":modifyAckDeadline"):
+		req := pubsub.ModifyAckDeadlineRequest{}
+		require.Nil(f.t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(f.t, int64(0), req.AckDeadlineSeconds)
+		f.nacked = append(f.nacked, req.AckIds...)
+		w.Write([]byte(`{}`))
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestPubSubSubscriber(t *testing.T) {
+	// given
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	encode := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+
+	fake := &fakePubSub{
+		t: t,
+		messages: []*pubsub.ReceivedMessage{
+			{AckId: "ack-1", Message: &pubsub.PubsubMessage{MessageId: "1", Data: encode("ok"), Attributes: map[string]string{"a": "b"}}},
+			{AckId: "ack-2", Message: &pubsub.PubsubMessage{MessageId: "2", Data: encode("fail")}},
+			{AckId: "ack-3", Message: &pubsub.PubsubMessage{MessageId: "3", Data: "not base64!"}},
+		},
+		drained: cancel,
+	}
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	service, err := pubsub.NewService(ctx,
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(server.Client()),
+	)
+	require.Nil(t, err)
+
+	subscriber := NewPubSubSubscriber(service, "my-project", "dfd-events")
+
+	handled := []*Message{}
+	handler := HandlerFunc(func(ctx context.Context, msg *Message) error {
+		handled = append(handled, msg)
+		if string(msg.Data) == "fail" {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	// when
+	err = subscriber.Receive(ctx, handler)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, []*Message{
+		{ID: "1", Data: []byte("ok"), Attributes: map[string]string{"a": "b"}},
+		{ID: "2", Data: []byte("fail")},
+	}, handled)
+
+	// undecodable messages are acknowledged, as they would never succeed
+	require.Equal(t, []string{"ack-1", "ack-3"}, fake.acked)
+	require.Equal(t, []string{"ack-2"}, fake.nacked)
+}
+
+func TestPubSubPublisher(t *testing.T) {
+	// given
+	ctx := context.Background()
+
+	var published *pubsub.PublishRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/projects/my-project/topics/threat-model-events:publish", r.URL.Path)
+
+		published = &pubsub.PublishRequest{}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(published))
+
+		w.Write([]byte(`{"messageIds": ["1"]}`))
+	}))
+	defer server.Close()
+
+	service, err := pubsub.NewService(ctx,
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(server.Client()),
+	)
+	require.Nil(t, err)
+
+	publisher := NewPubSubPublisher(service, "my-project", "threat-model-events")
+
+	// when
+	err = publisher.Publish(ctx, &Message{
+		Data:        []byte("hello"),
+		Attributes:  map[string]string{"a": "b"},
+		OrderingKey: "acme/tm-1",
+	})
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, []*pubsub.PubsubMessage{{
+		Data:        base64.StdEncoding.EncodeToString([]byte("hello")),
+		Attributes:  map[string]string{"a": "b"},
+		OrderingKey: "acme/tm-1",
+	}}, published.Messages)
+}
+
+func TestPubSubPublisherFailure(t *testing.T) {
+	// given
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": {"code": 503, "message": "unavailable"}}`, http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	service, err := pubsub.NewService(ctx,
+		option.WithEndpoint(server.URL),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(server.Client()),
+	)
+	require.Nil(t, err)
+
+	publisher := NewPubSubPublisher(service, "my-project", "threat-model-events")
+
+	// when
+	err = publisher.Publish(ctx, &Message{Data: []byte("hello")})
+
+	// then
+	require.NotNil(t, err)
+}
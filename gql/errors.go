@@ -0,0 +1,70 @@
+package gql
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	apierrors "github.com/jtyers/tmaas-api-util/errors"
+	"github.com/jtyers/tmaas-service-util/log"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+// Error codes, reported in the "code" extension of errors.
+const (
+	CodeNotFound        = "NOT_FOUND"
+	CodeUnauthenticated = "UNAUTHENTICATED"
+	CodeForbidden       = "FORBIDDEN"
+	CodeBadRequest      = "BAD_REQUEST"
+	CodeInternal        = "INTERNAL_SERVER_ERROR"
+)
+
+// errorCodes map errors to codes, as NewRouter maps them to HTTP status
+// codes.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{service.ErrNoSuchThreatModel, CodeNotFound},
+	{apierrors.ErrUnauthorized, CodeUnauthenticated},
+	{auth.ErrNoIdentity, CodeUnauthenticated},
+	{auth.ErrTenantHeader, CodeBadRequest},
+	{auth.ErrInvalidTenant, CodeBadRequest},
+	{auth.ErrNoTenant, CodeForbidden},
+	{auth.ErrPermissionDenied, CodeForbidden},
+	{service.ErrNoSuchProject, CodeNotFound},
+	{service.ErrInsufficientProjectRole, CodeForbidden},
+	{service.ErrAdminRequired, CodeForbidden},
+}
+
+// Error is an error returned by a resolver, along with its code.
+type Error struct {
+	Message string
+	Code    string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
+
+// toError converts err to an Error. Errors with no code of their own are
+// logged and reported as internal errors, without detail.
+func toError(err error) error {
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return &Error{err.Error(), e.code}
+		}
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return &Error{err.Error(), CodeBadRequest}
+	}
+
+	log.Errorf("error resolving GraphQL field: %v", err)
+	return &Error{"internal error", CodeInternal}
+}
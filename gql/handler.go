@@ -0,0 +1,59 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+// the largest request body accepted
+const maxRequestBytes = 1 << 20
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Handler executes GraphQL requests against the schema.
+type Handler struct {
+	schema *graphql.Schema
+	dfd    DataFlowDiagramService
+}
+
+func NewHandler(schema *graphql.Schema, dfd DataFlowDiagramService) *Handler {
+	return &Handler{schema: schema, dfd: dfd}
+}
+
+// Serve the GraphQL request r. Resolvers are given ctx, which should carry
+// the caller's identity and tokens, as for REST handlers.
+func (h *Handler) Serve(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	var req request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		http.Error(w, "request body must be a JSON GraphQL request", http.StatusBadRequest)
+		return
+	}
+
+	ctx = withRequestHeader(ctx, r.Header)
+	ctx = withDataFlowDiagramLoader(ctx, NewDataFlowDiagramLoader(h.dfd))
+
+	response := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+type requestHeaderContextKey struct{}
+
+func withRequestHeader(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, requestHeaderContextKey{}, header)
+}
+
+// requestHeaderFromContext returns the headers of the request being
+// resolved, from which resolvers check permissions.
+func requestHeaderFromContext(ctx context.Context) http.Header {
+	header, _ := ctx.Value(requestHeaderContextKey{}).(http.Header)
+	return header
+}
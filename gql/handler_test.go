@@ -0,0 +1,322 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jtyers/tmaas-api-util/combo"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-service-util/requestor"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+var (
+	testUser = &m.AuthenticationInfo{UserID: "u-1234", Roles: []m.Role{m.RoleUser}}
+
+	testThreatModel = &m.ThreatModel{
+		ThreatModelID:     m.NewThreatModelIDP("tm-1234"),
+		DataFlowDiagramID: m.NewDataFlowDiagramIDP("dfd-1234"),
+		Title:             "my-first-threatModel",
+		Threats:           []*m.Threat{{Title: "spoofing", Description: "spoofed requests"}},
+	}
+)
+
+// fakeDataFlowDiagramService serves diagrams from a map, as the DFD API
+// would, counting the gets of each.
+type fakeDataFlowDiagramService struct {
+	mu       sync.Mutex
+	diagrams map[m.DataFlowDiagramID]*m.DataFlowDiagram
+	gets     map[m.DataFlowDiagramID]int
+}
+
+func (f *fakeDataFlowDiagramService) Get(ctx context.Context, id m.DataFlowDiagramID) (*m.DataFlowDiagram, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.gets == nil {
+		f.gets = map[m.DataFlowDiagramID]int{}
+	}
+	f.gets[id]++
+
+	dfd, ok := f.diagrams[id]
+	if !ok {
+		return nil, requestor.ErrRequestFailed{StatusCode: http.StatusNotFound}
+	}
+	return dfd, nil
+}
+
+// noopAuditor discards audit events.
+type noopAuditor struct{}
+
+func (noopAuditor) Record(ctx context.Context, record tm.AuditRecord) {}
+
+type response struct {
+	Data   map[string]interface{}
+	Errors []struct {
+		Message    string
+		Extensions map[string]interface{}
+	}
+}
+
+// execute query against a Handler, returning the decoded response
+func execute(t *testing.T, token m.AuthenticationToken, ts service.ThreatModelService, accessChecker service.ThreatModelAccessChecker, dfd DataFlowDiagramService, query string) response {
+	ctrl := gomock.NewController(t)
+	comboFactory := combo.NewMockComboMiddlewareFactoryWithTokensAndPermissions(ctrl, token, combo.ServiceAccountPermissionsJson(`{}`))
+	permissionChecker := auth.NewPermissionChecker(comboFactory, auth.NewStaticIdentityExtractor(&auth.Identity{UserID: "u-1234", TenantID: "acme"}))
+
+	schema, err := NewSchema(NewResolver(ts, accessChecker, permissionChecker, noopAuditor{}))
+	require.Nil(t, err)
+
+	body, err := json.Marshal(map[string]interface{}{"query": query})
+	require.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+
+	NewHandler(schema, dfd).Serve(r.Context(), w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var result response
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &result))
+	return result
+}
+
+func TestThreatModel(t *testing.T) {
+	var tests = []struct {
+		name          string
+		token         m.AuthenticationToken
+		accessErr     error
+		serviceResult *m.ThreatModel
+		serviceErr    error
+		expectedCode  string
+		expectedData  string
+	}{
+		{
+			"should get existing threat model with its diagram and threats",
+			testUser,
+			nil,
+			testThreatModel,
+			nil,
+			"",
+			`{"threatModel":{"id":"tm-1234","title":"my-first-threatModel","dataFlowDiagram":{"id":"dfd-1234","title":"my-dfd"},"threats":[{"title":"spoofing"}]}}`,
+		},
+		{
+			"should return null for non-existent threat model",
+			testUser,
+			nil,
+			nil,
+			service.ErrNoSuchThreatModel,
+			"",
+			`{"threatModel":null}`,
+		},
+		{
+			"should return FORBIDDEN if the caller's project role is too low",
+			testUser,
+			service.ErrInsufficientProjectRole,
+			nil,
+			nil,
+			CodeForbidden,
+			`{"threatModel":null}`,
+		},
+		{
+			"should return INTERNAL_SERVER_ERROR without detail for other errors",
+			testUser,
+			nil,
+			nil,
+			errors.New("datastore is on fire"),
+			CodeInternal,
+			`{"threatModel":null}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// given
+			svc := service.NewMockThreatModelService(ctrl)
+			accessChecker := service.NewMockThreatModelAccessChecker(ctrl)
+			dfd := &fakeDataFlowDiagramService{diagrams: map[m.DataFlowDiagramID]*m.DataFlowDiagram{
+				testThreatModel.DataFlowDiagramID: {DataFlowDiagramID: testThreatModel.DataFlowDiagramID, Title: "my-dfd"},
+			}}
+
+			accessChecker.EXPECT().CheckThreatModelAccess(gomock.Any(), testThreatModel.ThreatModelID, tm.ProjectRoleViewer).Return(test.accessErr)
+			if test.serviceResult != nil || test.serviceErr != nil {
+				svc.EXPECT().Get(gomock.Any(), testThreatModel.ThreatModelID).Return(test.serviceResult, test.serviceErr)
+			}
+
+			// when
+			result := execute(t, test.token, svc, accessChecker, dfd,
+				`{ threatModel(id: "tm-1234") { id title dataFlowDiagram { id title } threats { title } } }`)
+
+			// then
+			data, err := json.Marshal(result.Data)
+			require.Nil(t, err)
+			require.JSONEq(t, test.expectedData, string(data))
+
+			if test.expectedCode == "" {
+				require.Empty(t, result.Errors)
+			} else {
+				require.Len(t, result.Errors, 1)
+				require.Equal(t, test.expectedCode, result.Errors[0].Extensions["code"])
+				require.NotContains(t, result.Errors[0].Message, "on fire")
+			}
+		})
+	}
+}
+
+func TestThreatModelsBatchesDataFlowDiagrams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// given
+	svc := service.NewMockThreatModelService(ctrl)
+	dfd := &fakeDataFlowDiagramService{diagrams: map[m.DataFlowDiagramID]*m.DataFlowDiagram{
+		m.NewDataFlowDiagramIDP("dfd-1"): {DataFlowDiagramID: m.NewDataFlowDiagramIDP("dfd-1"), Title: "one"},
+		m.NewDataFlowDiagramIDP("dfd-2"): {DataFlowDiagramID: m.NewDataFlowDiagramIDP("dfd-2"), Title: "two"},
+	}}
+
+	svc.EXPECT().GetAll(gomock.Any()).Return([]*m.ThreatModel{
+		{ThreatModelID: m.NewThreatModelIDP("tm-1"), DataFlowDiagramID: m.NewDataFlowDiagramIDP("dfd-1")},
+		{ThreatModelID: m.NewThreatModelIDP("tm-2"), DataFlowDiagramID: m.NewDataFlowDiagramIDP("dfd-2")},
+		{ThreatModelID: m.NewThreatModelIDP("tm-3"), DataFlowDiagramID: m.NewDataFlowDiagramIDP("dfd-1")},
+		{ThreatModelID: m.NewThreatModelIDP("tm-4"), DataFlowDiagramID: m.NewDataFlowDiagramIDP("dfd-deleted")},
+		{ThreatModelID: m.NewThreatModelIDP("tm-5"), DataFlowDiagramID: m.NewDataFlowDiagramIDP("dfd-2")},
+	}, nil)
+
+	// tm-5 is in a project the caller has no role on
+	accessChecker := service.NewMockThreatModelAccessChecker(ctrl)
+	accessChecker.EXPECT().FilterThreatModelAccess(gomock.Any(), gomock.Len(5), tm.ProjectRoleViewer).Return([]m.ThreatModelID{
+		m.NewThreatModelIDP("tm-1"), m.NewThreatModelIDP("tm-2"), m.NewThreatModelIDP("tm-3"), m.NewThreatModelIDP("tm-4"),
+	}, nil)
+
+	// when
+	result := execute(t, testUser, svc, accessChecker, dfd, `{ threatModels { id dataFlowDiagram { title } } }`)
+
+	// then
+	require.Empty(t, result.Errors)
+
+	data, err := json.Marshal(result.Data)
+	require.Nil(t, err)
+	require.JSONEq(t, `{"threatModels":[
+		{"id":"tm-1","dataFlowDiagram":{"title":"one"}},
+		{"id":"tm-2","dataFlowDiagram":{"title":"two"}},
+		{"id":"tm-3","dataFlowDiagram":{"title":"one"}},
+		{"id":"tm-4","dataFlowDiagram":null}
+	]}`, string(data))
+
+	// each diagram is fetched once, missing diagrams included
+	require.Equal(t, map[m.DataFlowDiagramID]int{
+		m.NewDataFlowDiagramIDP("dfd-1"):       1,
+		m.NewDataFlowDiagramIDP("dfd-2"):       1,
+		m.NewDataFlowDiagramIDP("dfd-deleted"): 1,
+	}, dfd.gets)
+}
+
+func TestMutations(t *testing.T) {
+	var tests = []struct {
+		name         string
+		token        m.AuthenticationToken
+		query        string
+		setup        func(svc *service.MockThreatModelService, accessChecker *service.MockThreatModelAccessChecker)
+		expectedCode string
+		expectedData string
+	}{
+		{
+			"should create threat model",
+			testUser,
+			`mutation { createThreatModel(input: {dataFlowDiagramId: "dfd-1234", title: "my-first-threatModel"}) { id } }`,
+			func(svc *service.MockThreatModelService, accessChecker *service.MockThreatModelAccessChecker) {
+				svc.EXPECT().Create(gomock.Any(), m.ThreatModelParams{
+					DataFlowDiagramID: m.NewDataFlowDiagramIDPPtr("dfd-1234"),
+					Title:             m.String("my-first-threatModel"),
+				}).Return(testThreatModel, nil)
+			},
+			"",
+			`{"createThreatModel":{"id":"tm-1234"}}`,
+		},
+		{
+			"should not create threat model for unauthenticated callers",
+			nil,
+			`mutation { createThreatModel(input: {title: "my-first-threatModel"}) { id } }`,
+			func(svc *service.MockThreatModelService, accessChecker *service.MockThreatModelAccessChecker) {},
+			CodeUnauthenticated,
+			`null`,
+		},
+		{
+			"should update threat model for editors",
+			testUser,
+			`mutation { updateThreatModel(id: "tm-1234", input: {title: "renamed"}) { id } }`,
+			func(svc *service.MockThreatModelService, accessChecker *service.MockThreatModelAccessChecker) {
+				accessChecker.EXPECT().CheckThreatModelAccess(gomock.Any(), testThreatModel.ThreatModelID, tm.ProjectRoleEditor).Return(nil)
+				svc.EXPECT().Update(gomock.Any(), testThreatModel.ThreatModelID, m.ThreatModelParams{Title: m.String("renamed")}).Return(testThreatModel, nil)
+			},
+			"",
+			`{"updateThreatModel":{"id":"tm-1234"}}`,
+		},
+		{
+			"should not delete threat model for viewers",
+			testUser,
+			`mutation { deleteThreatModel(id: "tm-1234") }`,
+			func(svc *service.MockThreatModelService, accessChecker *service.MockThreatModelAccessChecker) {
+				accessChecker.EXPECT().CheckThreatModelAccess(gomock.Any(), testThreatModel.ThreatModelID, tm.ProjectRoleEditor).Return(service.ErrInsufficientProjectRole)
+			},
+			CodeForbidden,
+			`null`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// given
+			svc := service.NewMockThreatModelService(ctrl)
+			accessChecker := service.NewMockThreatModelAccessChecker(ctrl)
+			test.setup(svc, accessChecker)
+
+			// when
+			result := execute(t, test.token, svc, accessChecker, &fakeDataFlowDiagramService{}, test.query)
+
+			// then
+			data, err := json.Marshal(result.Data)
+			require.Nil(t, err)
+			require.JSONEq(t, test.expectedData, string(data))
+
+			if test.expectedCode == "" {
+				require.Empty(t, result.Errors)
+			} else {
+				require.Len(t, result.Errors, 1)
+				require.Equal(t, test.expectedCode, result.Errors[0].Extensions["code"])
+			}
+		})
+	}
+}
+
+func TestBadRequest(t *testing.T) {
+	// given
+	schema, err := NewSchema(NewResolver(nil, nil, nil, nil))
+	require.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("not json"))
+
+	// when
+	NewHandler(schema, nil).Serve(r.Context(), w, r)
+
+	// then
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,86 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/graph-gophers/dataloader"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-service-util/requestor"
+	"golang.org/x/sync/errgroup"
+)
+
+// DataFlowDiagramService is the part of the DFD client used to load
+// diagrams.
+type DataFlowDiagramService interface {
+	Get(ctx context.Context, id m.DataFlowDiagramID) (*m.DataFlowDiagram, error)
+}
+
+// maxConcurrentDataFlowDiagramGets is the most diagrams fetched from the
+// DFD API at once.
+const maxConcurrentDataFlowDiagramGets = 8
+
+// DataFlowDiagramLoader batches the loading of data flow diagrams. Loads
+// made in the same short window are fetched together from the DFD API,
+// and each diagram is fetched only once. A loader is created for
+// each GraphQL request, so nothing is cached between requests.
+type DataFlowDiagramLoader struct {
+	loader *dataloader.Loader
+}
+
+func NewDataFlowDiagramLoader(dfd DataFlowDiagramService) *DataFlowDiagramLoader {
+	return &DataFlowDiagramLoader{dataloader.NewBatchedLoader(batchLoadDataFlowDiagrams(dfd))}
+}
+
+// Load a data flow diagram, returning nil if it does not exist or is not
+// visible to the caller.
+func (l *DataFlowDiagramLoader) Load(ctx context.Context, id m.DataFlowDiagramID) (*m.DataFlowDiagram, error) {
+	v, err := l.loader.Load(ctx, dataloader.StringKey(id.String()))()
+	if err != nil {
+		return nil, err
+	}
+
+	dfd, _ := v.(*m.DataFlowDiagram)
+	return dfd, nil
+}
+
+// batchLoadDataFlowDiagrams fetches each diagram by ID, several at once,
+// as the DFD API has no call to fetch several by ID. Each result depends
+// only on its own key, however many keys are loaded together.
+func batchLoadDataFlowDiagrams(dfd DataFlowDiagramService) dataloader.BatchFunc {
+	return func(ctx context.Context, keys dataloader.Keys) []*dataloader.Result {
+		results := make([]*dataloader.Result, len(keys))
+
+		var g errgroup.Group
+		g.SetLimit(maxConcurrentDataFlowDiagramGets)
+
+		for i, key := range keys {
+			i, key := i, key
+			g.Go(func() error {
+				diagram, err := dfd.Get(ctx, m.NewDataFlowDiagramIDP(key.String()))
+
+				var reqErr requestor.ErrRequestFailed
+				if errors.As(err, &reqErr) && reqErr.StatusCode == http.StatusNotFound {
+					diagram, err = nil, nil
+				}
+
+				results[i] = &dataloader.Result{Data: diagram, Error: err}
+				return nil
+			})
+		}
+
+		g.Wait()
+		return results
+	}
+}
+
+type dataFlowDiagramLoaderContextKey struct{}
+
+func withDataFlowDiagramLoader(ctx context.Context, loader *DataFlowDiagramLoader) context.Context {
+	return context.WithValue(ctx, dataFlowDiagramLoaderContextKey{}, loader)
+}
+
+func dataFlowDiagramLoaderFromContext(ctx context.Context) *DataFlowDiagramLoader {
+	return ctx.Value(dataFlowDiagramLoaderContextKey{}).(*DataFlowDiagramLoader)
+}
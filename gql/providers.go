@@ -0,0 +1,13 @@
+package gql
+
+import (
+	"github.com/google/wire"
+	dfdclient "github.com/jtyers/tmaas-dfd-api/client"
+)
+
+var GraphQLProviderSet = wire.NewSet(
+	NewResolver,
+	NewSchema,
+	NewHandler,
+	wire.Bind(new(DataFlowDiagramService), new(*dfdclient.DataFlowDiagramServiceClient)),
+)
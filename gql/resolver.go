@@ -0,0 +1,154 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+// Resolver resolves the Query and Mutation types.
+//
+// Each operation applies the same permission and project role checks as
+// the equivalent REST route. The GraphQL route itself requires
+// PermissionReadOwnThreatModels, so only stricter checks are made here.
+type Resolver struct {
+	threatModelService service.ThreatModelService
+	accessChecker      service.ThreatModelAccessChecker
+	permissionChecker  *auth.PermissionChecker
+	auditor            service.Auditor
+}
+
+func NewResolver(ts service.ThreatModelService, accessChecker service.ThreatModelAccessChecker, permissionChecker *auth.PermissionChecker, auditor service.Auditor) *Resolver {
+	return &Resolver{
+		threatModelService: ts,
+		accessChecker:      accessChecker,
+		permissionChecker:  permissionChecker,
+		auditor:            auditor,
+	}
+}
+
+type threatModelInput struct {
+	DataFlowDiagramID *graphql.ID
+	Title             *string
+	Description       *string
+}
+
+func (i threatModelInput) params() m.ThreatModelParams {
+	params := m.ThreatModelParams{
+		Title:       i.Title,
+		Description: i.Description,
+	}
+
+	if i.DataFlowDiagramID != nil {
+		params.DataFlowDiagramID = m.NewDataFlowDiagramIDPPtr(string(*i.DataFlowDiagramID))
+	}
+
+	return params
+}
+
+// requirePermission checks the caller holds p, and if userOnly, that they
+// are a user.
+func (r *Resolver) requirePermission(ctx context.Context, p m.Permission, userOnly bool) error {
+	_, err := r.permissionChecker.Check(ctx, requestHeaderFromContext(ctx), p, userOnly)
+	return err
+}
+
+func (r *Resolver) ThreatModel(ctx context.Context, args struct{ ID graphql.ID }) (*threatModelResolver, error) {
+	threatModelID := m.NewThreatModelIDP(string(args.ID))
+
+	if err := r.accessChecker.CheckThreatModelAccess(ctx, threatModelID, tm.ProjectRoleViewer); err != nil {
+		return nil, toError(err)
+	}
+
+	threatModel, err := r.threatModelService.Get(ctx, threatModelID)
+	if err == service.ErrNoSuchThreatModel {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return &threatModelResolver{threatModel}, nil
+}
+
+func (r *Resolver) ThreatModels(ctx context.Context, args struct {
+	DataFlowDiagramID *graphql.ID
+	Title             *string
+}) ([]*threatModelResolver, error) {
+	if err := r.requirePermission(ctx, m.PermissionReadOwnThreatModels, true); err != nil {
+		return nil, toError(err)
+	}
+
+	var q *m.ThreatModelQuery
+	if args.DataFlowDiagramID != nil || args.Title != nil {
+		q = &m.ThreatModelQuery{Title: args.Title}
+		if args.DataFlowDiagramID != nil {
+			q.DataFlowDiagramID = m.NewDataFlowDiagramIDPPtr(string(*args.DataFlowDiagramID))
+		}
+	}
+
+	threatModels, err := service.ListThreatModels(ctx, r.threatModelService, r.accessChecker, r.auditor, q)
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	result := make([]*threatModelResolver, 0, len(threatModels))
+	for _, threatModel := range threatModels {
+		result = append(result, &threatModelResolver{threatModel})
+	}
+
+	return result, nil
+}
+
+func (r *Resolver) CreateThreatModel(ctx context.Context, args struct{ Input threatModelInput }) (*threatModelResolver, error) {
+	if err := r.requirePermission(ctx, m.PermissionReadOwnThreatModels, true); err != nil {
+		return nil, toError(err)
+	}
+
+	threatModel, err := r.threatModelService.Create(ctx, args.Input.params())
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return &threatModelResolver{threatModel}, nil
+}
+
+func (r *Resolver) UpdateThreatModel(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input threatModelInput
+}) (*threatModelResolver, error) {
+	threatModelID := m.NewThreatModelIDP(string(args.ID))
+
+	if err := r.accessChecker.CheckThreatModelAccess(ctx, threatModelID, tm.ProjectRoleEditor); err != nil {
+		return nil, toError(err)
+	}
+
+	threatModel, err := r.threatModelService.Update(ctx, threatModelID, args.Input.params())
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return &threatModelResolver{threatModel}, nil
+}
+
+func (r *Resolver) DeleteThreatModel(ctx context.Context, args struct{ ID graphql.ID }) (graphql.ID, error) {
+	if err := r.requirePermission(ctx, m.PermissionEditOwnThreatModels, true); err != nil {
+		return "", toError(err)
+	}
+
+	threatModelID := m.NewThreatModelIDP(string(args.ID))
+
+	if err := r.accessChecker.CheckThreatModelAccess(ctx, threatModelID, tm.ProjectRoleEditor); err != nil {
+		return "", toError(err)
+	}
+
+	if err := r.threatModelService.Delete(ctx, threatModelID); err != nil {
+		return "", toError(err)
+	}
+
+	return args.ID, nil
+}
@@ -0,0 +1,17 @@
+// Package gql serves threat models over GraphQL, along with their data flow
+// diagrams and threats, so that a page can be assembled in one request.
+package gql
+
+import (
+	_ "embed"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaString string
+
+// NewSchema parses the schema, binding it to resolver.
+func NewSchema(resolver *Resolver) (*graphql.Schema, error) {
+	return graphql.ParseSchema(schemaString, resolver)
+}
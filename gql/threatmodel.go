@@ -0,0 +1,84 @@
+package gql
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+	m "github.com/jtyers/tmaas-model"
+)
+
+type threatModelResolver struct {
+	threatModel *m.ThreatModel
+}
+
+func (r *threatModelResolver) ID() graphql.ID {
+	return graphql.ID(r.threatModel.ThreatModelID.String())
+}
+
+func (r *threatModelResolver) Title() string {
+	return r.threatModel.Title
+}
+
+func (r *threatModelResolver) Description() string {
+	return r.threatModel.Description
+}
+
+func (r *threatModelResolver) DataFlowDiagramID() *graphql.ID {
+	if r.threatModel.DataFlowDiagramID.String() == "" {
+		return nil
+	}
+
+	id := graphql.ID(r.threatModel.DataFlowDiagramID.String())
+	return &id
+}
+
+// DataFlowDiagram is loaded through the request's DataFlowDiagramLoader, so
+// that the diagrams of every threat model in a response are fetched
+// together.
+func (r *threatModelResolver) DataFlowDiagram(ctx context.Context) (*dataFlowDiagramResolver, error) {
+	if r.threatModel.DataFlowDiagramID.String() == "" {
+		return nil, nil
+	}
+
+	dfd, err := dataFlowDiagramLoaderFromContext(ctx).Load(ctx, r.threatModel.DataFlowDiagramID)
+	if err != nil {
+		return nil, toError(err)
+	}
+	if dfd == nil {
+		return nil, nil
+	}
+
+	return &dataFlowDiagramResolver{dfd}, nil
+}
+
+func (r *threatModelResolver) Threats() []*threatResolver {
+	result := make([]*threatResolver, 0, len(r.threatModel.Threats))
+	for _, threat := range r.threatModel.Threats {
+		result = append(result, &threatResolver{threat})
+	}
+	return result
+}
+
+type dataFlowDiagramResolver struct {
+	dfd *m.DataFlowDiagram
+}
+
+func (r *dataFlowDiagramResolver) ID() graphql.ID {
+	return graphql.ID(r.dfd.DataFlowDiagramID.String())
+}
+
+func (r *dataFlowDiagramResolver) Title() string {
+	return r.dfd.Title
+}
+
+type threatResolver struct {
+	threat *m.Threat
+}
+
+func (r *threatResolver) Title() string {
+	return r.threat.Title
+}
+
+func (r *threatResolver) Description() string {
+	return r.threat.Description
+}
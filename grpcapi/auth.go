@@ -0,0 +1,83 @@
+package grpcapi
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+	m "github.com/jtyers/tmaas-model"
+	pb "github.com/jtyers/tmaas-threat-model-api/api/threatmodel/v1"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	RequestIDMetadata = "x-request-id"
+)
+
+// the permission each method requires, as for the equivalent REST route
+var methodPermissions = map[string]struct {
+	permission m.Permission
+
+	// Refuse service accounts, as StrictUserPermission does.
+	userOnly bool
+}{
+	pb.ThreatModelService_Get_FullMethodName:    {m.PermissionReadOwnThreatModels, false},
+	pb.ThreatModelService_GetAll_FullMethodName: {m.PermissionReadOwnThreatModels, true},
+	pb.ThreatModelService_Query_FullMethodName:  {m.PermissionReadOwnThreatModels, true},
+	pb.ThreatModelService_Create_FullMethodName: {m.PermissionReadOwnThreatModels, true},
+	pb.ThreatModelService_Update_FullMethodName: {m.PermissionReadOwnThreatModels, false},
+	pb.ThreatModelService_Delete_FullMethodName: {m.PermissionEditOwnThreatModels, true},
+	pb.ThreatModelService_List_FullMethodName:   {m.PermissionReadOwnThreatModels, true},
+}
+
+// as for REST, request IDs supplied by callers are kept if they look sane
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:/+=-]{1,128}$`)
+
+// Authenticator verifies the token sent by gRPC callers, checks they hold
+// the permission of the method called and derives their identity. The
+// call's metadata are treated as the headers of a REST request.
+type Authenticator struct {
+	permissionChecker *auth.PermissionChecker
+}
+
+func NewAuthenticator(permissionChecker *auth.PermissionChecker) *Authenticator {
+	return &Authenticator{permissionChecker: permissionChecker}
+}
+
+// Authenticate the caller of fullMethod, returning a copy of ctx carrying
+// their identity and the call's request ID.
+func (a *Authenticator) Authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	p, ok := methodPermissions[fullMethod]
+	if !ok {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	header := http.Header{}
+	for key, values := range md {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+
+	identity, err := a.permissionChecker.Check(ctx, header, p.permission, p.userOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	requestID := header.Get(RequestIDMetadata)
+	if !validRequestID.MatchString(requestID) {
+		requestID = uuid.NewString()
+	}
+
+	ctx = auth.WithIdentity(ctx, identity)
+	ctx = service.WithRequestID(ctx, requestID)
+
+	return ctx, nil
+}
@@ -0,0 +1,22 @@
+package grpcapi
+
+import (
+	util "github.com/jtyers/tmaas-service-util"
+)
+
+type Config struct {
+	// The address to serve gRPC on. gRPC is not served if empty.
+	Addr string
+}
+
+// NewConfig reads the port to serve gRPC on from GRPC_PORT. It is separate
+// from PORT, which serves REST.
+func NewConfig() Config {
+	config := Config{}
+
+	if port := util.GetEnvWithDefault("GRPC_PORT", ""); port != "" {
+		config.Addr = ":" + port
+	}
+
+	return config
+}
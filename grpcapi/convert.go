@@ -0,0 +1,63 @@
+package grpcapi
+
+import (
+	m "github.com/jtyers/tmaas-model"
+	pb "github.com/jtyers/tmaas-threat-model-api/api/threatmodel/v1"
+)
+
+func toProto(threatModel *m.ThreatModel) *pb.ThreatModel {
+	result := &pb.ThreatModel{
+		ThreatModelId:     threatModel.ThreatModelID.String(),
+		DataFlowDiagramId: threatModel.DataFlowDiagramID.String(),
+		Title:             threatModel.Title,
+		Description:       threatModel.Description,
+	}
+
+	for _, threat := range threatModel.Threats {
+		result.Threats = append(result.Threats, &pb.Threat{
+			Title:       threat.Title,
+			Description: threat.Description,
+		})
+	}
+
+	return result
+}
+
+func toProtoList(threatModels []*m.ThreatModel) *pb.ThreatModels {
+	result := &pb.ThreatModels{ThreatModels: make([]*pb.ThreatModel, 0, len(threatModels))}
+
+	for _, threatModel := range threatModels {
+		result.ThreatModels = append(result.ThreatModels, toProto(threatModel))
+	}
+
+	return result
+}
+
+func paramsFromProto(params *pb.ThreatModelParams) m.ThreatModelParams {
+	if params == nil {
+		return m.ThreatModelParams{}
+	}
+
+	result := m.ThreatModelParams{
+		Title:       params.Title,
+		Description: params.Description,
+	}
+
+	if params.DataFlowDiagramId != nil {
+		result.DataFlowDiagramID = m.NewDataFlowDiagramIDPPtr(*params.DataFlowDiagramId)
+	}
+
+	return result
+}
+
+func queryFromProto(req *pb.QueryThreatModelsRequest) *m.ThreatModelQuery {
+	result := &m.ThreatModelQuery{
+		Title: req.Title,
+	}
+
+	if req.DataFlowDiagramId != nil {
+		result.DataFlowDiagramID = m.NewDataFlowDiagramIDPPtr(*req.DataFlowDiagramId)
+	}
+
+	return result
+}
@@ -0,0 +1,57 @@
+package grpcapi
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	apierrors "github.com/jtyers/tmaas-api-util/errors"
+	"github.com/jtyers/tmaas-service-util/log"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorCodes map errors to status codes, as NewRouter maps them to HTTP
+// status codes.
+var errorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{service.ErrNoSuchThreatModel, codes.NotFound},
+	{apierrors.ErrUnauthorized, codes.Unauthenticated},
+	{auth.ErrNoIdentity, codes.Unauthenticated},
+	{auth.ErrTenantHeader, codes.InvalidArgument},
+	{auth.ErrInvalidTenant, codes.InvalidArgument},
+	{auth.ErrNoTenant, codes.PermissionDenied},
+	{auth.ErrPermissionDenied, codes.PermissionDenied},
+	{service.ErrNoSuchProject, codes.NotFound},
+	{service.ErrInsufficientProjectRole, codes.PermissionDenied},
+	{service.ErrAdminRequired, codes.PermissionDenied},
+}
+
+// toStatus converts err to a gRPC status error. Errors with no status code
+// of their own are logged and reported as internal errors, without detail.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return status.Error(e.code, err.Error())
+		}
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	log.Errorf("error handling gRPC call: %v", err)
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,123 @@
+// Package grpcapi serves the threat model API over gRPC, for internal
+// services that would rather use generated stubs than REST.
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/jtyers/tmaas-service-util/log"
+	pb "github.com/jtyers/tmaas-threat-model-api/api/threatmodel/v1"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// NewGRPCServer creates a gRPC server for ThreatModelServer, which
+// measures, traces, authenticates and rate limits every call, and maps
+// errors to status codes, as NewRouter does for REST requests.
+func NewGRPCServer(server *ThreatModelServer, authenticator *Authenticator, rateLimiter *RateLimiter, metrics *metrics.Metrics, tracerProvider trace.TracerProvider) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			MetricsUnaryInterceptor(metrics),
+			TracingUnaryInterceptor(tracerProvider),
+			UnaryInterceptor(authenticator, rateLimiter),
+		),
+		grpc.ChainStreamInterceptor(
+			MetricsStreamInterceptor(metrics),
+			TracingStreamInterceptor(tracerProvider),
+			StreamInterceptor(authenticator, rateLimiter),
+		),
+	)
+
+	pb.RegisterThreatModelServiceServer(srv, server)
+
+	return srv
+}
+
+// UnaryInterceptor authenticates and rate limits each call.
+func UnaryInterceptor(authenticator *Authenticator, rateLimiter *RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticator.Authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+
+		if err := rateLimiter.Allow(ctx, info.FullMethod); err != nil {
+			return nil, toStatus(err)
+		}
+
+		resp, err := handler(ctx, req)
+		return resp, toStatus(err)
+	}
+}
+
+// StreamInterceptor authenticates and rate limits each streaming call.
+func StreamInterceptor(authenticator *Authenticator, rateLimiter *RateLimiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticator.Authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return toStatus(err)
+		}
+
+		if err := rateLimiter.Allow(ctx, info.FullMethod); err != nil {
+			return toStatus(err)
+		}
+
+		return toStatus(handler(srv, &serverStream{ss, ctx}))
+	}
+}
+
+// serverStream replaces the context of a stream, eg with one carrying the
+// caller's identity.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// Run serves gRPC on config.Addr until ctx is done, as Serve does.
+func Run(ctx context.Context, config Config, srv *grpc.Server, shutdownTimeout time.Duration) error {
+	listener, err := net.Listen("tcp", config.Addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %v", config.Addr, err)
+	}
+
+	return Serve(ctx, listener, srv, shutdownTimeout)
+}
+
+// Serve gRPC on listener until ctx is done. It then stops accepting calls
+// and waits up to shutdownTimeout for in-flight calls to complete.
+func Serve(ctx context.Context, listener net.Listener, srv *grpc.Server, shutdownTimeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("error while serving gRPC: %v", err)
+
+	case <-ctx.Done():
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		log.Warnf("gRPC calls still in flight after %v, stopping anyway", shutdownTimeout)
+		srv.Stop()
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsUnaryInterceptor counts calls and records their latency by method
+// and status code, as MetricsMiddleware does for REST requests. It should
+// run first, so that it covers the time spent in every other interceptor.
+func MetricsUnaryInterceptor(m *metrics.Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		observeCall(m, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// MetricsStreamInterceptor is MetricsUnaryInterceptor for streaming calls.
+func MetricsStreamInterceptor(m *metrics.Metrics) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		err := handler(srv, ss)
+
+		observeCall(m, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func observeCall(m *metrics.Metrics, fullMethod string, start time.Time, err error) {
+	// only registered methods reach interceptors, so fullMethod is bounded
+	code := status.Code(err).String()
+
+	m.GRPCRequests.WithLabelValues(fullMethod, code).Inc()
+	m.GRPCRequestDuration.WithLabelValues(fullMethod, code).Observe(time.Since(start).Seconds())
+}
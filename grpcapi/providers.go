@@ -0,0 +1,12 @@
+package grpcapi
+
+import (
+	"github.com/google/wire"
+)
+
+var GRPCProviderSet = wire.NewSet(
+	NewThreatModelServer,
+	NewAuthenticator,
+	NewRateLimiter,
+	NewGRPCServer,
+)
@@ -0,0 +1,70 @@
+package grpcapi
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	RetryAfterMetadata = "retry-after"
+)
+
+// RateLimiter limits the rate of calls of each caller, as the REST API's
+// RateLimiter limits requests. Calls take tokens from the same buckets as
+// requests, so that a caller's limits hold across both APIs.
+type RateLimiter struct {
+	store  ratelimit.Store
+	config ratelimit.Config
+}
+
+func NewRateLimiter(store ratelimit.Store, config ratelimit.Config) *RateLimiter {
+	return &RateLimiter{store, config}
+}
+
+// Allow rejects calls over the caller's method or default limit, or their
+// limit on the permission the method requires, with ResourceExhausted and
+// a retry-after header giving the seconds until the caller may try again.
+// ctx must carry the caller's identity.
+func (rl *RateLimiter) Allow(ctx context.Context, fullMethod string) error {
+	identity, err := auth.IdentityFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	caller := "id:" + identity.String()
+
+	key, limit := caller, rl.config.Default
+	if routeLimit, ok := rl.config.Routes[fullMethod]; ok {
+		key, limit = caller+":"+fullMethod, routeLimit
+	}
+	if err := rl.take(ctx, key, limit); err != nil {
+		return err
+	}
+
+	if p, ok := methodPermissions[fullMethod]; ok {
+		if limit, ok := rl.config.Permissions[string(p.permission)]; ok {
+			return rl.take(ctx, caller+":permission:"+string(p.permission), limit)
+		}
+	}
+
+	return nil
+}
+
+func (rl *RateLimiter) take(ctx context.Context, key string, limit ratelimit.Limit) error {
+	allowed, retryAfter := ratelimit.Allow(ctx, rl.store, key, limit)
+	if allowed {
+		return nil
+	}
+
+	// the header is best effort: it cannot be set once a stream has sent one
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RetryAfterMetadata, strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))))
+
+	return status.Error(codes.ResourceExhausted, "too many requests")
+}
@@ -0,0 +1,121 @@
+package grpcapi
+
+import (
+	"context"
+
+	m "github.com/jtyers/tmaas-model"
+	pb "github.com/jtyers/tmaas-threat-model-api/api/threatmodel/v1"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ThreatModelServer implements the gRPC ThreatModelService over the same
+// ThreatModelService as the REST API. Callers are authenticated by the
+// interceptors of NewGRPCServer before any method is called.
+type ThreatModelServer struct {
+	pb.UnimplementedThreatModelServiceServer
+
+	threatModelService service.ThreatModelService
+	accessChecker      service.ThreatModelAccessChecker
+	auditor            service.Auditor
+}
+
+var _ pb.ThreatModelServiceServer = (*ThreatModelServer)(nil)
+
+func NewThreatModelServer(ts service.ThreatModelService, accessChecker service.ThreatModelAccessChecker, auditor service.Auditor) *ThreatModelServer {
+	return &ThreatModelServer{threatModelService: ts, accessChecker: accessChecker, auditor: auditor}
+}
+
+func (s *ThreatModelServer) Get(ctx context.Context, req *pb.GetThreatModelRequest) (*pb.ThreatModel, error) {
+	threatModelID := m.NewThreatModelIDP(req.ThreatModelId)
+
+	if err := s.accessChecker.CheckThreatModelAccess(ctx, threatModelID, tm.ProjectRoleViewer); err != nil {
+		return nil, err
+	}
+
+	threatModel, err := s.threatModelService.Get(ctx, threatModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toProto(threatModel), nil
+}
+
+func (s *ThreatModelServer) GetAll(ctx context.Context, req *pb.GetAllThreatModelsRequest) (*pb.ThreatModels, error) {
+	threatModels, err := service.ListThreatModels(ctx, s.threatModelService, s.accessChecker, s.auditor, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return toProtoList(threatModels), nil
+}
+
+func (s *ThreatModelServer) Query(ctx context.Context, req *pb.QueryThreatModelsRequest) (*pb.ThreatModels, error) {
+	threatModels, err := service.ListThreatModels(ctx, s.threatModelService, s.accessChecker, s.auditor, queryFromProto(req))
+	if err != nil {
+		return nil, err
+	}
+
+	return toProtoList(threatModels), nil
+}
+
+func (s *ThreatModelServer) Create(ctx context.Context, req *pb.CreateThreatModelRequest) (*pb.ThreatModel, error) {
+	threatModel, err := s.threatModelService.Create(ctx, paramsFromProto(req.Params))
+	if err != nil {
+		return nil, err
+	}
+
+	return toProto(threatModel), nil
+}
+
+func (s *ThreatModelServer) Update(ctx context.Context, req *pb.UpdateThreatModelRequest) (*pb.ThreatModel, error) {
+	threatModelID := m.NewThreatModelIDP(req.ThreatModelId)
+
+	if err := s.accessChecker.CheckThreatModelAccess(ctx, threatModelID, tm.ProjectRoleEditor); err != nil {
+		return nil, err
+	}
+
+	threatModel, err := s.threatModelService.Update(ctx, threatModelID, paramsFromProto(req.Params))
+	if err != nil {
+		return nil, err
+	}
+
+	return toProto(threatModel), nil
+}
+
+func (s *ThreatModelServer) Delete(ctx context.Context, req *pb.DeleteThreatModelRequest) (*emptypb.Empty, error) {
+	threatModelID := m.NewThreatModelIDP(req.ThreatModelId)
+
+	if err := s.accessChecker.CheckThreatModelAccess(ctx, threatModelID, tm.ProjectRoleEditor); err != nil {
+		return nil, err
+	}
+
+	if err := s.threatModelService.Delete(ctx, threatModelID); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *ThreatModelServer) List(req *pb.QueryThreatModelsRequest, stream pb.ThreatModelService_ListServer) error {
+	ctx := stream.Context()
+
+	var q *m.ThreatModelQuery
+	if req.DataFlowDiagramId != nil || req.Title != nil {
+		q = queryFromProto(req)
+	}
+
+	threatModels, err := service.ListThreatModels(ctx, s.threatModelService, s.accessChecker, s.auditor, q)
+	if err != nil {
+		return err
+	}
+
+	for _, threatModel := range threatModels {
+		if err := stream.Send(toProto(threatModel)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,324 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/jtyers/tmaas-api-util/combo"
+	m "github.com/jtyers/tmaas-model"
+	pb "github.com/jtyers/tmaas-threat-model-api/api/threatmodel/v1"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/ratelimit"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+var (
+	testIdentity = &auth.Identity{UserID: "u-1234", TenantID: "acme"}
+
+	testThreatModel = &m.ThreatModel{
+		ThreatModelID:     m.NewThreatModelIDP("tm-1234"),
+		DataFlowDiagramID: m.NewDataFlowDiagramIDP("dfd-1234"),
+		Title:             "my-first-threatModel",
+		Threats:           []*m.Threat{{Title: "spoofing", Description: "spoofed requests"}},
+	}
+
+	testThreatModelProto = &pb.ThreatModel{
+		ThreatModelId:     "tm-1234",
+		DataFlowDiagramId: "dfd-1234",
+		Title:             "my-first-threatModel",
+		Threats:           []*pb.Threat{{Title: "spoofing", Description: "spoofed requests"}},
+	}
+)
+
+// noopAuditor discards audit events.
+type noopAuditor struct{}
+
+func (noopAuditor) Record(ctx context.Context, record tm.AuditRecord) {}
+
+// createClient serves a ThreatModelServer in memory, returning a client of
+// it.
+func createClient(t *testing.T, token m.AuthenticationToken, ts service.ThreatModelService, accessChecker service.ThreatModelAccessChecker) pb.ThreatModelServiceClient {
+	return createClientWith(t, token, ts, accessChecker, ratelimit.Config{}, metrics.NewMetrics(), trace.NewNoopTracerProvider())
+}
+
+// createClientWith is createClient with the given rate limits, metrics and
+// tracer provider.
+func createClientWith(t *testing.T, token m.AuthenticationToken, ts service.ThreatModelService, accessChecker service.ThreatModelAccessChecker, limits ratelimit.Config, metrics *metrics.Metrics, tracerProvider trace.TracerProvider) pb.ThreatModelServiceClient {
+	ctrl := gomock.NewController(t)
+	comboFactory := combo.NewMockComboMiddlewareFactoryWithTokensAndPermissions(ctrl, token, combo.ServiceAccountPermissionsJson(`{}`))
+
+	authenticator := NewAuthenticator(auth.NewPermissionChecker(comboFactory, auth.NewStaticIdentityExtractor(testIdentity)))
+	rateLimiter := NewRateLimiter(ratelimit.NewMemoryStore(), limits)
+	srv := NewGRPCServer(NewThreatModelServer(ts, accessChecker, noopAuditor{}), authenticator, rateLimiter, metrics, tracerProvider)
+
+	listener := bufconn.Listen(1024 * 1024)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, listener, srv, time.Second)
+	}()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.Nil(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		require.Nil(t, <-served)
+	})
+
+	return pb.NewThreatModelServiceClient(conn)
+}
+
+func TestGet(t *testing.T) {
+	var tests = []struct {
+		name          string
+		token         m.AuthenticationToken
+		accessErr     error
+		serviceResult *m.ThreatModel
+		serviceErr    error
+		expectedCode  codes.Code
+		expectedBody  *pb.ThreatModel
+	}{
+		{
+			"should get existing threat model",
+			&m.AuthenticationInfo{UserID: "u-1234", Roles: []m.Role{m.RoleUser}},
+			nil,
+			testThreatModel,
+			nil,
+			codes.OK,
+			testThreatModelProto,
+		},
+		{
+			"should return NotFound for non-existent threat model",
+			&m.AuthenticationInfo{UserID: "u-1234", Roles: []m.Role{m.RoleUser}},
+			nil,
+			nil,
+			service.ErrNoSuchThreatModel,
+			codes.NotFound,
+			nil,
+		},
+		{
+			"should return PermissionDenied if the caller's project role is too low",
+			&m.AuthenticationInfo{UserID: "u-1234", Roles: []m.Role{m.RoleUser}},
+			service.ErrInsufficientProjectRole,
+			nil,
+			nil,
+			codes.PermissionDenied,
+			nil,
+		},
+		{
+			"should return Unauthenticated for unauthenticated callers",
+			nil,
+			nil,
+			nil,
+			nil,
+			codes.Unauthenticated,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// given
+			svc := service.NewMockThreatModelService(ctrl)
+			accessChecker := service.NewMockThreatModelAccessChecker(ctrl)
+
+			if test.token != nil {
+				accessChecker.EXPECT().CheckThreatModelAccess(gomock.Any(), testThreatModel.ThreatModelID, tm.ProjectRoleViewer).DoAndReturn(
+					func(ctx context.Context, id m.ThreatModelID, role tm.ProjectRole) error {
+						identity, err := auth.IdentityFromContext(ctx)
+						require.Nil(t, err)
+						require.Equal(t, testIdentity, identity)
+						require.Equal(t, "req-1234", service.RequestIDFromContext(ctx))
+
+						return test.accessErr
+					})
+			}
+			if test.serviceResult != nil || test.serviceErr != nil {
+				svc.EXPECT().Get(gomock.Any(), testThreatModel.ThreatModelID).Return(test.serviceResult, test.serviceErr)
+			}
+
+			client := createClient(t, test.token, svc, accessChecker)
+			ctx := metadata.AppendToOutgoingContext(context.Background(), RequestIDMetadata, "req-1234")
+
+			// when
+			result, err := client.Get(ctx, &pb.GetThreatModelRequest{ThreatModelId: "tm-1234"})
+
+			// then
+			require.Equal(t, test.expectedCode, status.Code(err))
+			if test.expectedBody != nil {
+				require.Equal(t, test.expectedBody.String(), result.String())
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// given
+	svc := service.NewMockThreatModelService(ctrl)
+
+	svc.EXPECT().Create(gomock.Any(), m.ThreatModelParams{
+		DataFlowDiagramID: m.NewDataFlowDiagramIDPPtr("dfd-1234"),
+		Title:             m.String("my-first-threatModel"),
+	}).Return(testThreatModel, nil)
+
+	client := createClient(t, &m.AuthenticationInfo{UserID: "u-1234", Roles: []m.Role{m.RoleUser}}, svc, nil)
+
+	// when
+	result, err := client.Create(context.Background(), &pb.CreateThreatModelRequest{
+		Params: &pb.ThreatModelParams{
+			DataFlowDiagramId: m.String("dfd-1234"),
+			Title:             m.String("my-first-threatModel"),
+		},
+	})
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, testThreatModelProto.String(), result.String())
+}
+
+func TestList(t *testing.T) {
+	other := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-5678"), Title: "another"}
+	hidden := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-9012"), Title: "another, in a project the caller has no role on"}
+
+	var tests = []struct {
+		name    string
+		request *pb.QueryThreatModelsRequest
+		query   *m.ThreatModelQuery // GetAll is expected if nil
+	}{
+		{
+			"should stream all visible threat models without filters",
+			&pb.QueryThreatModelsRequest{},
+			nil,
+		},
+		{
+			"should stream matching visible threat models with filters",
+			&pb.QueryThreatModelsRequest{Title: m.String("another")},
+			&m.ThreatModelQuery{Title: m.String("another")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// given
+			svc := service.NewMockThreatModelService(ctrl)
+
+			accessChecker := service.NewMockThreatModelAccessChecker(ctrl)
+
+			if test.query == nil {
+				svc.EXPECT().GetAll(gomock.Any()).Return([]*m.ThreatModel{testThreatModel, other, hidden}, nil)
+			} else {
+				svc.EXPECT().Query(gomock.Any(), test.query).Return([]*m.ThreatModel{testThreatModel, other, hidden}, nil)
+			}
+			accessChecker.EXPECT().FilterThreatModelAccess(gomock.Any(), []m.ThreatModelID{testThreatModel.ThreatModelID, other.ThreatModelID, hidden.ThreatModelID}, tm.ProjectRoleViewer).
+				Return([]m.ThreatModelID{testThreatModel.ThreatModelID, other.ThreatModelID}, nil)
+
+			client := createClient(t, &m.AuthenticationInfo{UserID: "u-1234", Roles: []m.Role{m.RoleUser}}, svc, accessChecker)
+
+			// when
+			stream, err := client.List(context.Background(), test.request)
+			require.Nil(t, err)
+
+			var ids []string
+			for {
+				threatModel, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				require.Nil(t, err)
+				ids = append(ids, threatModel.ThreatModelId)
+			}
+
+			// then
+			require.Equal(t, []string{"tm-1234", "tm-5678"}, ids)
+		})
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// given
+	svc := service.NewMockThreatModelService(ctrl)
+	accessChecker := service.NewMockThreatModelAccessChecker(ctrl)
+
+	accessChecker.EXPECT().CheckThreatModelAccess(gomock.Any(), testThreatModel.ThreatModelID, tm.ProjectRoleViewer).Return(nil)
+	svc.EXPECT().Get(gomock.Any(), testThreatModel.ThreatModelID).Return(testThreatModel, nil)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previousPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previousPropagator)
+
+	callMetrics := metrics.NewMetrics()
+	limits := ratelimit.Config{
+		Default: ratelimit.Limit{Rate: 0.5, Burst: 1},
+	}
+
+	client := createClientWith(t, &m.AuthenticationInfo{UserID: "u-1234", Roles: []m.Role{m.RoleUser}}, svc, accessChecker, limits, callMetrics, tp)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// when
+	_, err := client.Get(ctx, &pb.GetThreatModelRequest{ThreatModelId: "tm-1234"})
+	require.Nil(t, err)
+
+	var header metadata.MD
+	_, err = client.Get(ctx, &pb.GetThreatModelRequest{ThreatModelId: "tm-1234"}, grpc.Header(&header))
+
+	// then the second call is over the limit
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Equal(t, []string{"2"}, header.Get(RetryAfterMetadata))
+
+	// and both are measured
+	require.Equal(t, 1.0, testutil.ToFloat64(callMetrics.GRPCRequests.WithLabelValues(pb.ThreatModelService_Get_FullMethodName, "OK")))
+	require.Equal(t, 1.0, testutil.ToFloat64(callMetrics.GRPCRequests.WithLabelValues(pb.ThreatModelService_Get_FullMethodName, "ResourceExhausted")))
+
+	// and traced, continuing the caller's trace
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		require.Equal(t, pb.ThreatModelService_Get_FullMethodName, span.Name())
+		require.Equal(t, trace.SpanKindServer, span.SpanKind())
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+		require.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	}
+}
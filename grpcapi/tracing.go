@@ -0,0 +1,112 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jtyers/tmaas-threat-model-api/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TracingUnaryInterceptor starts a server span for each call, continuing
+// any trace given in the call's W3C trace context metadata, as
+// TracingMiddleware does for REST requests.
+func TracingUnaryInterceptor(tp trace.TracerProvider) grpc.UnaryServerInterceptor {
+	tracer := tp.Tracer(tracing.TracerName)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := startSpan(ctx, tracer, info.FullMethod)
+
+		resp, err := handler(ctx, req)
+
+		endSpan(span, err)
+		return resp, err
+	}
+}
+
+// TracingStreamInterceptor is TracingUnaryInterceptor for streaming calls.
+func TracingStreamInterceptor(tp trace.TracerProvider) grpc.StreamServerInterceptor {
+	tracer := tp.Tracer(tracing.TracerName)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startSpan(ss.Context(), tracer, info.FullMethod)
+
+		err := handler(srv, &serverStream{ss, ctx})
+
+		endSpan(span, err)
+		return err
+	}
+}
+
+// the status codes of calls that failed through no fault of the caller, as
+// 5xx status codes are for REST requests
+var serverErrorCodes = map[grpccodes.Code]bool{
+	grpccodes.Unknown:          true,
+	grpccodes.DeadlineExceeded: true,
+	grpccodes.Unimplemented:    true,
+	grpccodes.Internal:         true,
+	grpccodes.Unavailable:      true,
+	grpccodes.DataLoss:         true,
+}
+
+func startSpan(ctx context.Context, tracer trace.Tracer, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	// full method names are of the form "/package.Service/Method"
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+
+	return tracer.Start(ctx, fullMethod,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.RPCSystemGRPC,
+			semconv.RPCServiceKey.String(service),
+			semconv.RPCMethodKey.String(method),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	defer span.End()
+
+	code := status.Code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+
+	if err != nil {
+		span.RecordError(err)
+	}
+	if serverErrorCodes[code] {
+		span.SetStatus(codes.Error, code.String())
+	}
+}
+
+// metadataCarrier adapts incoming metadata to a propagation.TextMapCarrier.
+// Metadata keys are lower case, as are the W3C trace context headers.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
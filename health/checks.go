@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	dfdclient "github.com/jtyers/tmaas-dfd-api/client"
+	m "github.com/jtyers/tmaas-model"
+	servicedao "github.com/jtyers/tmaas-service-dao"
+	util "github.com/jtyers/tmaas-service-util"
+	"github.com/jtyers/tmaas-service-util/requestor"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+)
+
+const (
+	CheckDatastore       = "datastore"
+	CheckDataFlowDiagram = "dataFlowDiagramApi"
+
+	DefaultCheckTimeout = 2 * time.Second
+
+	// The tenant and IDs probed by checks; none of them exist.
+	checkTenantID        auth.TenantID = "healthcheck"
+	checkThreatModelID                 = "tm-healthcheck"
+	checkDataFlowDiagram               = "dfd-healthcheck"
+)
+
+// CheckTimeout is how long each readiness check may take.
+type CheckTimeout time.Duration
+
+// NewCheckTimeout reads the check timeout from HEALTH_CHECK_TIMEOUT, a
+// duration such as "2s".
+func NewCheckTimeout() (CheckTimeout, error) {
+	timeout := DefaultCheckTimeout
+
+	if s := util.GetEnvWithDefault("HEALTH_CHECK_TIMEOUT", ""); s != "" {
+		var err error
+		timeout, err = time.ParseDuration(s)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return CheckTimeout(timeout), nil
+}
+
+// NewReadinessChecker checks the dependencies the API cannot serve requests
+// without: Datastore and the DFD API.
+func NewReadinessChecker(timeout CheckTimeout, threatModelDao dao.ThreatModelDao, dfd *dfdclient.DataFlowDiagramServiceClient) *Checker {
+	return NewChecker(time.Duration(timeout),
+		NamedCheck{CheckDatastore, NewDatastoreCheck(threatModelDao)},
+		NamedCheck{CheckDataFlowDiagram, NewDataFlowDiagramCheck(dfd)},
+	)
+}
+
+// NewDatastoreCheck looks up a threat model that does not exist, which
+// succeeds only if Datastore is reachable.
+func NewDatastoreCheck(threatModelDao dao.ThreatModelDao) Check {
+	return CheckFunc(func(ctx context.Context) error {
+		ctx = auth.WithIdentity(ctx, &auth.Identity{ServiceAccountName: "healthcheck", TenantID: checkTenantID})
+
+		_, err := threatModelDao.Get(ctx, m.NewThreatModelIDP(checkThreatModelID))
+		if err == servicedao.ErrNoSuchDocument {
+			return nil
+		}
+		return err
+	})
+}
+
+// DataFlowDiagramGetter is the part of the DFD client used by
+// NewDataFlowDiagramCheck.
+type DataFlowDiagramGetter interface {
+	Get(ctx context.Context, id m.DataFlowDiagramID) (*m.DataFlowDiagram, error)
+}
+
+// NewDataFlowDiagramCheck looks up a DFD that does not exist. Any answer
+// from the DFD API short of a server error means it is up.
+func NewDataFlowDiagramCheck(dfd DataFlowDiagramGetter) Check {
+	return CheckFunc(func(ctx context.Context) error {
+		_, err := dfd.Get(ctx, m.NewDataFlowDiagramIDP(checkDataFlowDiagram))
+		if err == nil {
+			return nil
+		}
+
+		var reqErr requestor.ErrRequestFailed
+		if errors.As(err, &reqErr) {
+			if reqErr.StatusCode >= http.StatusInternalServerError {
+				return err
+			}
+			return nil
+		}
+
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			return err
+		}
+
+		// the API answered, with an error such as the DFD not existing
+		return nil
+	})
+}
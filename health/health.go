@@ -0,0 +1,114 @@
+// Package health runs the dependency checks behind the readiness endpoint.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// Check probes one dependency, returning an error if it is unusable.
+type Check interface {
+	Check(ctx context.Context) error
+}
+
+// CheckFunc adapts a function to a Check.
+type CheckFunc func(ctx context.Context) error
+
+func (f CheckFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// NamedCheck is a Check along with the name it is reported under.
+type NamedCheck struct {
+	Name  string
+	Check Check
+}
+
+// Report is the outcome of running every check.
+type Report struct {
+	// StatusOK if every check passed, otherwise StatusUnavailable.
+	Status string `json:"status"`
+
+	Checks map[string]*CheckResult `json:"checks"`
+}
+
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Checker runs a set of checks concurrently, each with its own timeout.
+type Checker struct {
+	checks  []NamedCheck
+	timeout time.Duration
+}
+
+func NewChecker(timeout time.Duration, checks ...NamedCheck) *Checker {
+	return &Checker{checks, timeout}
+}
+
+// Run every check, returning once all have finished or timed out.
+func (c *Checker) Run(ctx context.Context) *Report {
+	report := &Report{Status: StatusOK, Checks: map[string]*CheckResult{}}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, check := range c.checks {
+		wg.Add(1)
+
+		go func(check NamedCheck) {
+			defer wg.Done()
+
+			result := c.run(ctx, check.Check)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[check.Name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusUnavailable
+			}
+		}(check)
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) *CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	// run the check apart, so that checks ignoring their context still
+	// time out
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := &CheckResult{Status: StatusOK, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+	}
+
+	return result
+}
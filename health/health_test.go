@@ -0,0 +1,159 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	m "github.com/jtyers/tmaas-model"
+	servicedao "github.com/jtyers/tmaas-service-dao"
+	"github.com/jtyers/tmaas-service-util/requestor"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+	"github.com/stretchr/testify/require"
+)
+
+var errTest = errors.New("test error")
+
+func TestChecker(t *testing.T) {
+	ok := CheckFunc(func(ctx context.Context) error { return nil })
+	failing := CheckFunc(func(ctx context.Context) error { return errTest })
+	hanging := CheckFunc(func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	var tests = []struct {
+		name           string
+		checks         []NamedCheck
+		expectedStatus string
+		expectedErrors map[string]string
+	}{
+		{
+			name:           "no checks",
+			expectedStatus: StatusOK,
+			expectedErrors: map[string]string{},
+		},
+		{
+			name:           "all pass",
+			checks:         []NamedCheck{{"a", ok}, {"b", ok}},
+			expectedStatus: StatusOK,
+			expectedErrors: map[string]string{"a": "", "b": ""},
+		},
+		{
+			name:           "one fails",
+			checks:         []NamedCheck{{"a", ok}, {"b", failing}},
+			expectedStatus: StatusUnavailable,
+			expectedErrors: map[string]string{"a": "", "b": errTest.Error()},
+		},
+		{
+			name:           "one times out",
+			checks:         []NamedCheck{{"a", ok}, {"b", hanging}},
+			expectedStatus: StatusUnavailable,
+			expectedErrors: map[string]string{"a": "", "b": context.DeadlineExceeded.Error()},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			checker := NewChecker(50*time.Millisecond, test.checks...)
+
+			// when
+			start := time.Now()
+			report := checker.Run(context.Background())
+
+			// then
+			require.Less(t, time.Since(start), 500*time.Millisecond)
+			require.Equal(t, test.expectedStatus, report.Status)
+			require.Len(t, report.Checks, len(test.expectedErrors))
+
+			for name, expectedError := range test.expectedErrors {
+				result := report.Checks[name]
+				require.NotNil(t, result, name)
+				require.Equal(t, expectedError, result.Error, name)
+
+				if expectedError == "" {
+					require.Equal(t, StatusOK, result.Status, name)
+				} else {
+					require.Equal(t, StatusUnavailable, result.Status, name)
+				}
+			}
+		})
+	}
+}
+
+func TestDatastoreCheck(t *testing.T) {
+	var tests = []struct {
+		name        string
+		daoErr      error
+		expectedErr error
+	}{
+		{"not found", servicedao.ErrNoSuchDocument, nil},
+		{"found", nil, nil},
+		{"dao error", errTest, errTest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			mockDao := dao.NewMockThreatModelDao(ctrl)
+
+			mockDao.EXPECT().Get(gomock.Any(), m.NewThreatModelIDP(checkThreatModelID)).DoAndReturn(
+				func(ctx context.Context, id m.ThreatModelID) (*m.ThreatModel, error) {
+					tenantID, err := auth.TenantIDFromContext(ctx)
+					require.Nil(t, err)
+					require.Equal(t, checkTenantID, tenantID)
+
+					return nil, test.daoErr
+				})
+
+			// when
+			err := NewDatastoreCheck(mockDao).Check(context.Background())
+
+			// then
+			require.Equal(t, test.expectedErr, err)
+		})
+	}
+}
+
+type fakeDataFlowDiagramGetter struct {
+	err error
+}
+
+func (f fakeDataFlowDiagramGetter) Get(ctx context.Context, id m.DataFlowDiagramID) (*m.DataFlowDiagram, error) {
+	return nil, f.err
+}
+
+func TestDataFlowDiagramCheck(t *testing.T) {
+	var tests = []struct {
+		name        string
+		err         error
+		expectFails bool
+	}{
+		{"found", nil, false},
+		{"not found", requestor.ErrRequestFailed{StatusCode: 404}, false},
+		{"forbidden", requestor.ErrRequestFailed{StatusCode: 403}, false},
+		{"server error", requestor.ErrRequestFailed{StatusCode: 502}, true},
+		{"connection refused", &url.Error{Op: "Get", URL: "http://dfd", Err: errTest}, true},
+		{"deadline exceeded", context.DeadlineExceeded, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// when
+			err := NewDataFlowDiagramCheck(fakeDataFlowDiagramGetter{test.err}).Check(context.Background())
+
+			// then
+			if test.expectFails {
+				require.Equal(t, test.err, err)
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
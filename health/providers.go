@@ -0,0 +1,10 @@
+package health
+
+import (
+	"github.com/google/wire"
+)
+
+var HealthProviderSet = wire.NewSet(
+	NewCheckTimeout,
+	NewReadinessChecker,
+)
@@ -0,0 +1,88 @@
+// Package httpclient sends API requests through an http.Client of the
+// caller's choosing, rather than through http.DefaultClient, so that
+// transports can be added to one client without changing every HTTP call
+// made by the process.
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/jtyers/tmaas-service-util/requestor"
+)
+
+// Requestor is a requestor.RequestorWithContext that sends requests
+// through its own http.Client. Responses with a status of 300 or more are
+// returned as a requestor.ErrRequestFailed, as the default requestor
+// returns them.
+type Requestor struct {
+	client *http.Client
+}
+
+var _ requestor.RequestorWithContext = (*Requestor)(nil)
+
+// NewRequestor sends requests through client, or through a client of its
+// own using http.DefaultTransport if client is nil.
+func NewRequestor(client *http.Client) *Requestor {
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &Requestor{client}
+}
+
+func (r *Requestor) GetInto(ctx context.Context, url string, result any) error {
+	_, err := r.do(ctx, http.MethodGet, url, nil, result)
+	return err
+}
+
+func (r *Requestor) PutInto(ctx context.Context, url string, body io.Reader, result any) error {
+	_, err := r.do(ctx, http.MethodPut, url, body, result)
+	return err
+}
+
+func (r *Requestor) PostInto(ctx context.Context, url string, body io.Reader, result any) error {
+	_, err := r.do(ctx, http.MethodPost, url, body, result)
+	return err
+}
+
+func (r *Requestor) PatchInto(ctx context.Context, url string, body io.Reader, result any) error {
+	_, err := r.do(ctx, http.MethodPatch, url, body, result)
+	return err
+}
+
+func (r *Requestor) Delete(ctx context.Context, url string) (*http.Response, error) {
+	return r.do(ctx, http.MethodDelete, url, nil, nil)
+}
+
+// do sends a request, decoding any JSON response body into result.
+func (r *Requestor) do(ctx context.Context, method string, url string, body io.Reader, result any) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return resp, requestor.ErrRequestFailed{StatusCode: resp.StatusCode, Body: string(b)}
+	}
+
+	if result != nil && len(b) > 0 {
+		return resp, json.Unmarshal(b, result)
+	}
+	return resp, nil
+}
@@ -0,0 +1,64 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-service-util/requestor"
+)
+
+// headerTransport sets a header on each request it sends.
+type headerTransport struct {
+	key, value string
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(t.key, t.value)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRequestor(t *testing.T) {
+	// given
+	var headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Get("X-Client"))
+
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("not found"))
+			return
+		}
+		json.NewEncoder(w).Encode(m.ThreatModel{Title: "Payments gateway"})
+	}))
+	defer server.Close()
+
+	defaultTransport := http.DefaultClient.Transport
+	r := NewRequestor(&http.Client{Transport: headerTransport{"X-Client", "tmaas"}})
+
+	// when
+	result := m.ThreatModel{}
+	err := r.PatchInto(context.Background(), server.URL+"/found", strings.NewReader(`{}`), &result)
+
+	// then the response is decoded
+	require.Nil(t, err)
+	require.Equal(t, "Payments gateway", result.Title)
+
+	// when
+	err = r.GetInto(context.Background(), server.URL+"/missing", &result)
+
+	// then failures are returned as the default requestor returns them
+	require.Equal(t, requestor.ErrRequestFailed{StatusCode: http.StatusNotFound, Body: "not found"}, err)
+
+	// and requests went through the client given, leaving
+	// http.DefaultClient alone
+	require.Equal(t, []string{"tmaas", "tmaas"}, headers)
+	require.Equal(t, defaultTransport, http.DefaultClient.Transport)
+}
@@ -13,25 +13,102 @@
 // @BasePath /
 // @query.collection.format multi
 
+// @securityDefinitions.apikey firebase
+// @in header
+// @name Authorization
+// @description A Firebase ID token or service account token, as "Bearer <token>"
+
 package main
 
 import (
-	"net/http"
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
 
-	util "github.com/jtyers/tmaas-service-util"
 	log "github.com/jtyers/tmaas-service-util/log"
+	"github.com/jtyers/tmaas-threat-model-api/grpcapi"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	"github.com/jtyers/tmaas-threat-model-api/server"
+	"github.com/jtyers/tmaas-threat-model-api/web"
+	"golang.org/x/sync/errgroup"
 )
 
 func main() {
 	log.InitialiseLogging()
 
-	port := util.GetEnv("PORT")
-	r, err := InitialiseRouter()
+	config, err := server.NewConfig()
+	if err != nil {
+		log.Fatalf("error while reading server config: %v", err)
+	}
+
+	grpcConfig := grpcapi.NewConfig()
+	metricsConfig := metrics.NewConfig()
+
+	app, cleanup, err := InitialiseApp()
 	if err != nil {
-		log.Fatalf("error while initialising router: %v", err)
+		log.Fatalf("error while initialising app: %v", err)
+	}
+
+	// stop on SIGTERM (as sent on deploys and scale-downs), or on Ctrl-C
+	// when run locally
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	// if any server or consumer fails, stop the rest too
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		return server.Run(ctx, config, app.Handler)
+	})
+
+	if grpcConfig.Addr != "" {
+		g.Go(func() error {
+			return grpcapi.Run(ctx, grpcConfig, app.GRPCServer, config.ShutdownTimeout)
+		})
 	}
 
-	if err := http.ListenAndServe(":"+port, r); err != nil {
-		log.Fatalf("error while serving: %v", err)
+	if metricsConfig.Addr != "" {
+		metricsServerConfig := config
+		metricsServerConfig.Addr = metricsConfig.Addr
+
+		g.Go(func() error {
+			return server.Run(ctx, metricsServerConfig, web.NewMetricsHandler(app.Metrics))
+		})
+
+		g.Go(func() error {
+			return app.ThreatModelCounter.Run(ctx)
+		})
+	}
+
+	if app.DataFlowDiagramEvents != nil {
+		g.Go(func() error {
+			return app.DataFlowDiagramEvents.Run(ctx)
+		})
+	}
+
+	if app.OutboxRelay != nil {
+		g.Go(func() error {
+			return app.OutboxRelay.Run(ctx)
+		})
+	}
+
+	g.Go(func() error {
+		return app.AuditWriter.Run(ctx)
+	})
+
+	err = g.Wait()
+
+	// write the audit records of requests served while shutting down
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	app.AuditWriter.Flush(flushCtx)
+	cancelFlush()
+
+	// close the Datastore client, flush traces and so on, once no more
+	// requests can use them
+	cleanup()
+
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
 }
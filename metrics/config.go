@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	util "github.com/jtyers/tmaas-service-util"
+)
+
+type Config struct {
+	// The address to serve metrics on. Metrics are not served if empty.
+	Addr string
+}
+
+// NewConfig reads the port to serve metrics on from METRICS_PORT. It is
+// separate from PORT, which serves the API, so that metrics can be kept to
+// the internal network.
+func NewConfig() Config {
+	config := Config{}
+
+	if port := util.GetEnvWithDefault("METRICS_PORT", ""); port != "" {
+		config.Addr = ":" + port
+	}
+
+	return config
+}
@@ -0,0 +1,205 @@
+// Package metrics defines the Prometheus metrics exposed by the API.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "threatmodel"
+
+	// The route label of requests matching no route, so that arbitrary
+	// paths cannot blow up the number of series.
+	UnmatchedRoute = "unmatched"
+)
+
+// Metrics holds the collectors of every metric, registered with a registry
+// of their own.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	HTTPRequests        *prometheus.CounterVec
+	HTTPRequestDuration *prometheus.HistogramVec
+
+	GRPCRequests        *prometheus.CounterVec
+	GRPCRequestDuration *prometheus.HistogramVec
+
+	DaoDuration *prometheus.HistogramVec
+	DaoErrors   *prometheus.CounterVec
+
+	ServiceDuration *prometheus.HistogramVec
+	ServiceErrors   *prometheus.CounterVec
+
+	IDCheckDuration *prometheus.HistogramVec
+	IDCheckFailures *prometheus.CounterVec
+
+	CacheRequests *prometheus.CounterVec
+
+	EventsReceived  *prometheus.CounterVec
+	OutboxPublishes *prometheus.CounterVec
+
+	// The number of threat models per tenant, as last counted in the store
+	// by service.ThreatModelCounter.
+	ThreatModels *prometheus.GaugeVec
+
+	// The number of audit records per tenant waiting to be linked into
+	// their hash chains, as last counted by service.AuditWriter.
+	AuditPendingRecords *prometheus.GaugeVec
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		HTTPRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests handled, by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		HTTPRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken to handle HTTP requests, by method, route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+
+		GRPCRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "requests_total",
+			Help:      "gRPC calls handled, by full method name and status code.",
+		}, []string{"method", "code"}),
+		GRPCRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken to handle gRPC calls, by full method name and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+
+		DaoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "dao",
+			Name:      "operation_duration_seconds",
+			Help:      "Time taken by threat model DAO operations, by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		DaoErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "dao",
+			Name:      "operation_errors_total",
+			Help:      "Threat model DAO operations that failed, by method.",
+		}, []string{"method"}),
+
+		ServiceDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "service",
+			Name:      "operation_duration_seconds",
+			Help:      "Time taken by threat model service operations, by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		ServiceErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "service",
+			Name:      "operation_errors_total",
+			Help:      "Threat model service operations that failed, by method.",
+		}, []string{"method"}),
+
+		IDCheckDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "idchecker",
+			Name:      "check_duration_seconds",
+			Help:      "Time taken to check referenced IDs exist, by the kind of ID checked.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"checker"}),
+		IDCheckFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "idchecker",
+			Name:      "check_failures_total",
+			Help:      "ID checks that failed with an error (rather than finding no such ID), by the kind of ID checked.",
+		}, []string{"checker"}),
+
+		CacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "requests_total",
+			Help:      "Threat model cache lookups, by result (hit, miss or error).",
+		}, []string{"result"}),
+
+		EventsReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "events",
+			Name:      "received_total",
+			Help:      "Data flow diagram events received, by type and result (processed, ignored or failed).",
+		}, []string{"type", "result"}),
+
+		OutboxPublishes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "outbox",
+			Name:      "publishes_total",
+			Help:      "Attempts to publish threat model change events from the outbox, by result (sent or failed).",
+		}, []string{"result"}),
+
+		ThreatModels: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "threat_models",
+			Help:      "The number of threat models, by tenant.",
+		}, []string{"tenant"}),
+
+		AuditPendingRecords: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "audit",
+			Name:      "pending_records",
+			Help:      "The number of audit records waiting to be linked into their hash chains, by tenant.",
+		}, []string{"tenant"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+
+		m.HTTPRequests,
+		m.HTTPRequestDuration,
+		m.GRPCRequests,
+		m.GRPCRequestDuration,
+		m.DaoDuration,
+		m.DaoErrors,
+		m.ServiceDuration,
+		m.ServiceErrors,
+		m.IDCheckDuration,
+		m.IDCheckFailures,
+		m.CacheRequests,
+		m.EventsReceived,
+		m.OutboxPublishes,
+		m.ThreatModels,
+		m.AuditPendingRecords,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Registry returns the registry holding the metrics, for tests to gather.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// ObserveOperation records the duration of an operation started at start
+// against method, and counts it as an error if err is non-nil.
+func ObserveOperation(duration *prometheus.HistogramVec, errors *prometheus.CounterVec, method string, start time.Time, err error) {
+	duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		errors.WithLabelValues(method).Inc()
+	}
+}
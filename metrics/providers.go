@@ -0,0 +1,9 @@
+package metrics
+
+import (
+	"github.com/google/wire"
+)
+
+var MetricsProviderSet = wire.NewSet(
+	NewMetrics,
+)
@@ -0,0 +1,150 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	AuditRecordIDPrefix = "aud-"
+)
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+
+	// The caller was not authenticated, or not permitted to do this.
+	AuditOutcomeDenied AuditOutcome = "denied"
+
+	AuditOutcomeFailure AuditOutcome = "failure"
+)
+
+const (
+	AuditActorUser           = "user"
+	AuditActorServiceAccount = "serviceAccount"
+)
+
+// AuditRecord records an action taken by a user or service account. Each
+// request produces a record from the web layer, plus a record for each
+// service-level action it causes; both carry the same RequestID.
+type AuditRecord struct {
+	AuditRecordID string    `json:"auditRecordId"`
+	Time          time.Time `json:"time"`
+
+	// The user ID or service account name of the caller.
+	Actor     string `json:"actor"`
+	ActorType string `json:"actorType"`
+
+	// What was done, eg "threatmodel.update", or for web records the
+	// method and route, eg "PATCH /api/v1/threatmodel/:threatModelID".
+	Action string `json:"action"`
+
+	// The kind and ID of resource acted on, if any, eg "threatmodel" and
+	// "tm-1234".
+	ResourceType string `json:"resourceType,omitempty"`
+	ResourceID   string `json:"resourceId,omitempty"`
+
+	Outcome AuditOutcome `json:"outcome"`
+
+	// The HTTP status code of the response, for web records.
+	StatusCode int `json:"statusCode,omitempty"`
+
+	RequestID string `json:"requestId"`
+
+	// Any further information, such as the error for failed actions.
+	Detail string `json:"detail,omitempty"`
+
+	// Records acting on a resource form a hash chain per resource: each
+	// holds its position in the chain, the hash of the record before it
+	// and its own hash, so that altering or removing a record is
+	// detectable. These are empty for records not acting on a resource,
+	// and for records not yet linked into their resource's chain.
+	Sequence     int64  `json:"sequence,omitempty"`
+	PreviousHash string `json:"previousHash,omitempty"`
+	Hash         string `json:"hash,omitempty"`
+}
+
+// AuditKey is the secret key audit record hashes are computed with, so that
+// a chain cannot be rewritten, and its hashes recomputed, by anyone able to
+// write to the datastore but without the key.
+type AuditKey []byte
+
+// ComputeHash returns the HMAC-SHA256, under key, of every field of the
+// record except Hash itself, hex encoded. Each field is length-prefixed so
+// that no two distinct records share an encoding.
+func (r *AuditRecord) ComputeHash(key AuditKey) string {
+	h := hmac.New(sha256.New, key)
+
+	for _, field := range []string{
+		r.AuditRecordID,
+		r.Time.UTC().Format(time.RFC3339Nano),
+		r.Actor,
+		r.ActorType,
+		r.Action,
+		r.ResourceType,
+		r.ResourceID,
+		string(r.Outcome),
+		strconv.Itoa(r.StatusCode),
+		r.RequestID,
+		r.Detail,
+		strconv.FormatInt(r.Sequence, 10),
+		r.PreviousHash,
+	} {
+		fmt.Fprintf(h, "%d:%s;", len(field), field)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// AuditQuery filters audit records. Empty fields match all records.
+type AuditQuery struct {
+	ResourceID string
+	Actor      string
+
+	// Records from (inclusive) and to (exclusive) these times.
+	From *time.Time
+	To   *time.Time
+
+	PageSize  int
+	PageToken string
+}
+
+// AuditPage is one page of audit records, newest first.
+type AuditPage struct {
+	Records []*AuditRecord `json:"records"`
+
+	// Pass as pageToken to retrieve the next page. Empty on the last page.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+}
+
+// AuditVerification is the result of verifying the audit chain of a
+// resource.
+type AuditVerification struct {
+	ResourceType string `json:"resourceType"`
+	ResourceID   string `json:"resourceId"`
+
+	// True if every link of the chain is intact.
+	Valid bool `json:"valid"`
+
+	// The number of records checked, up to and including any broken link.
+	RecordsChecked int `json:"recordsChecked"`
+
+	// The first broken link, if the chain is not valid.
+	BrokenLink *AuditBrokenLink `json:"brokenLink,omitempty"`
+}
+
+// AuditBrokenLink describes where and why an audit chain failed to verify.
+type AuditBrokenLink struct {
+	// The position in the chain at which verification failed.
+	Sequence int64 `json:"sequence"`
+
+	// The record at that position, or empty if it is missing.
+	AuditRecordID string `json:"auditRecordId,omitempty"`
+
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	m "github.com/jtyers/tmaas-model"
+)
+
+// BatchGetRequest names the threat models to retrieve in one call.
+type BatchGetRequest struct {
+	IDs []m.ThreatModelID `json:"ids" binding:"required"`
+}
+
+// BatchGetResult holds the threat models found by a batch get, in the
+// order they were requested, and the IDs of those that do not exist or
+// are not visible to the caller.
+type BatchGetResult struct {
+	ThreatModels []*m.ThreatModel  `json:"threatModels"`
+	Missing      []m.ThreatModelID `json:"missing"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	m "github.com/jtyers/tmaas-model"
+)
+
+const (
+	CommentIDPrefix = "cmt-"
+)
+
+type CommentID string
+
+func (id CommentID) String() string {
+	return string(id)
+}
+
+// Comment is a remark left by a user on a threat model, or on an
+// individual threat within a threat model. Comments form threads
+// via ParentCommentID; only top-level comments can be resolved.
+type Comment struct {
+	CommentID     CommentID       `json:"commentId"`
+	ThreatModelID m.ThreatModelID `json:"threatModelId"`
+
+	// Set when the comment refers to a specific threat rather than
+	// to the threat model as a whole. Threats have no ID of their own,
+	// so this is the threat's title.
+	ThreatID string `json:"threatId,omitempty"`
+
+	// Set when the comment is a reply to another comment.
+	ParentCommentID *CommentID `json:"parentCommentId,omitempty"`
+
+	Author   m.UserID   `json:"author"`
+	Body     string     `json:"body"`
+	Mentions []m.UserID `json:"mentions"`
+
+	Resolved   bool       `json:"resolved"`
+	ResolvedBy *m.UserID  `json:"resolvedBy,omitempty"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+
+	// Comments with replies are marked as deleted, rather than removed,
+	// so the thread remains intact.
+	Deleted bool `json:"deleted"`
+
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// CommentParams holds the fields a user supplies when creating or
+// editing a comment.
+type CommentParams struct {
+	Body            *string    `json:"body" validate:"required,min=1,max=10000"`
+	ParentCommentID *CommentID `json:"parentCommentId,omitempty"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"github.com/jtyers/tmaas-threat-model-api/diff"
+)
+
+// ThreatModelDiff holds the changes between two versions of a threat
+// model, or between two threat models. From and To describe what was
+// compared: a threat model ID, followed by "@" and a revision number if
+// an earlier revision.
+type ThreatModelDiff struct {
+	From    string        `json:"from"`
+	To      string        `json:"to"`
+	Changes []diff.Change `json:"changes"`
+}
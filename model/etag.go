@@ -0,0 +1,21 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	m "github.com/jtyers/tmaas-model"
+)
+
+// ThreatModelETag returns the strong entity tag of a threat model: the
+// quoted SHA-256 of its JSON encoding, so that it changes whenever any
+// field served does.
+func ThreatModelETag(threatModel *m.ThreatModel) (string, error) {
+	b, err := json.Marshal(threatModel)
+	if err != nil {
+		return "", fmt.Errorf("error encoding threat model: %v", err)
+	}
+
+	return fmt.Sprintf(`"%x"`, sha256.Sum256(b)), nil
+}
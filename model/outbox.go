@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	m "github.com/jtyers/tmaas-model"
+)
+
+const (
+	OutboxRecordIDPrefix = "obx-"
+)
+
+// Types of ThreatModelEvent.
+const (
+	ThreatModelCreated = "threatmodel.created"
+	ThreatModelUpdated = "threatmodel.updated"
+	ThreatModelDeleted = "threatmodel.deleted"
+)
+
+// ThreatModelEvent is the payload of the event published when a threat
+// model changes.
+type ThreatModelEvent struct {
+	Type          string          `json:"type"`
+	ThreatModelID m.ThreatModelID `json:"threatModelId"`
+	TenantID      string          `json:"tenantId"`
+	Time          time.Time       `json:"time"`
+
+	// The position of the event among those of its threat model, starting
+	// at 1, so that consumers can discard duplicates and stale events.
+	Sequence int64 `json:"sequence"`
+
+	// The threat model as of the change, or nil if it was deleted.
+	ThreatModel *m.ThreatModel `json:"threatModel,omitempty"`
+}
+
+// OutboxRecord is an event waiting in the outbox to be published. It is
+// written in the same transaction as the change it describes, so an event
+// is published if and only if its change is committed.
+type OutboxRecord struct {
+	OutboxRecordID string
+	ThreatModelID  m.ThreatModelID
+	Type           string
+	Time           time.Time
+
+	// The position of the record among those of its threat model, which
+	// are published in this order.
+	Sequence int64
+
+	// The JSON encoded ThreatModelEvent.
+	Payload []byte
+
+	// How many times publishing the record has failed, the last error, and
+	// when it may next be attempted.
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+
+	// When the record was published, or nil if it is pending.
+	SentAt *time.Time
+}
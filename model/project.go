@@ -0,0 +1,91 @@
+package model
+
+import (
+	"time"
+
+	m "github.com/jtyers/tmaas-model"
+)
+
+const (
+	ProjectIDPrefix = "prj-"
+)
+
+type ProjectID string
+
+func (id ProjectID) String() string {
+	return string(id)
+}
+
+// ProjectRole is the access a member has to a project. Roles are
+// inherited: a member of a project has the same role on all of its
+// sub-projects, and on the threat models within them.
+type ProjectRole string
+
+const (
+	// May view the project and its threat models.
+	ProjectRoleViewer ProjectRole = "viewer"
+
+	// May also edit the project, create sub-projects and move threat
+	// models into or out of it.
+	ProjectRoleEditor ProjectRole = "editor"
+
+	// May also manage members and delete the project.
+	ProjectRoleOwner ProjectRole = "owner"
+)
+
+var projectRoleRanks = map[ProjectRole]int{
+	ProjectRoleViewer: 1,
+	ProjectRoleEditor: 2,
+	ProjectRoleOwner:  3,
+}
+
+// IsValid returns whether r is one of the defined roles.
+func (r ProjectRole) IsValid() bool {
+	_, ok := projectRoleRanks[r]
+	return ok
+}
+
+// Includes returns whether r grants at least the access of other, eg
+// an editor includes viewer.
+func (r ProjectRole) Includes(other ProjectRole) bool {
+	return projectRoleRanks[r] >= projectRoleRanks[other] && r.IsValid()
+}
+
+// Project is a folder for organising threat models. Projects may be
+// nested via ParentProjectID.
+type Project struct {
+	ProjectID ProjectID `json:"projectId"`
+
+	// Set when the project is a sub-project of another.
+	ParentProjectID *ProjectID `json:"parentProjectId,omitempty"`
+
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// The members given a role directly on this project. Members of
+	// parent projects are not listed, though their roles still apply.
+	Members []ProjectMember `json:"members"`
+
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+type ProjectMember struct {
+	UserID m.UserID    `json:"userId"`
+	Role   ProjectRole `json:"role"`
+}
+
+// ProjectParams holds the fields a user supplies when creating or
+// editing a project. When editing, a ParentProjectID of "" moves the
+// project to the top level.
+type ProjectParams struct {
+	Name            *string    `json:"name" validate:"omitempty,min=1,max=200"`
+	Description     *string    `json:"description" validate:"omitempty,max=10000"`
+	ParentProjectID *ProjectID `json:"parentProjectId,omitempty"`
+}
+
+// ThreatModelProject is the body used to move a threat model into a
+// project, or out of all projects when ProjectID is nil.
+type ThreatModelProject struct {
+	ProjectID *ProjectID `json:"projectId"`
+}
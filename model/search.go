@@ -0,0 +1,25 @@
+package model
+
+import (
+	m "github.com/jtyers/tmaas-model"
+)
+
+// SearchResult is a threat model matching a full-text search.
+type SearchResult struct {
+	ThreatModel *m.ThreatModel `json:"threatModel"`
+
+	// Higher scores indicate better matches. Scores are only meaningful
+	// relative to other results of the same search.
+	Score float64 `json:"score"`
+
+	Snippets []SearchSnippet `json:"snippets"`
+}
+
+// SearchSnippet is an extract of the field that matched a search, with
+// matching terms wrapped in <mark></mark>. The rest of the fragment is
+// HTML-escaped.
+type SearchSnippet struct {
+	// The path to the field, eg "title" or "threats[2].description".
+	Field    string `json:"field"`
+	Fragment string `json:"fragment"`
+}
@@ -0,0 +1,7 @@
+package model
+
+// TagCount reports how many threat models carry a tag.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
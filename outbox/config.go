@@ -0,0 +1,80 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	util "github.com/jtyers/tmaas-service-util"
+	"github.com/jtyers/tmaas-threat-model-api/events"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+const (
+	DefaultPollInterval = time.Second
+	DefaultBatchSize    = 500
+	DefaultLeaseTTL     = 30 * time.Second
+)
+
+// Config configures the relay publishing threat model change events from
+// the outbox.
+type Config struct {
+	// The Google Cloud project holding the topic.
+	ProjectID string
+
+	// The Pub/Sub topic events are published to. Events are not published
+	// if empty, but are still recorded in the outbox, so that they are
+	// published once a topic is configured.
+	Topic string
+
+	// How often to check the outbox for records to publish.
+	PollInterval time.Duration
+
+	// The most records of a tenant to publish per poll.
+	BatchSize int
+
+	// How long a relay keeps the lease on a tenant's records without
+	// renewing it. Another relay may take over once it lapses.
+	LeaseTTL time.Duration
+}
+
+// NewConfig reads the topic to publish to from OUTBOX_TOPIC, its project
+// from PROJECT_ID, and how often to poll the outbox from
+// OUTBOX_POLL_INTERVAL (a duration such as "1s").
+func NewConfig() (Config, error) {
+	config := Config{
+		Topic:        util.GetEnvWithDefault("OUTBOX_TOPIC", ""),
+		PollInterval: DefaultPollInterval,
+		BatchSize:    DefaultBatchSize,
+		LeaseTTL:     DefaultLeaseTTL,
+	}
+
+	if config.Topic != "" {
+		config.ProjectID = util.GetEnv("PROJECT_ID")
+	}
+
+	if interval := util.GetEnvWithDefault("OUTBOX_POLL_INTERVAL", ""); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			return Config{}, fmt.Errorf("error parsing OUTBOX_POLL_INTERVAL %q: must be a positive duration", interval)
+		}
+		config.PollInterval = d
+	}
+
+	return config, nil
+}
+
+// NewPublisher returns a PubSubPublisher for the topic in config, or nil if
+// there is none.
+func NewPublisher(ctx context.Context, config Config) (events.Publisher, error) {
+	if config.Topic == "" {
+		return nil, nil
+	}
+
+	service, err := pubsub.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Pub/Sub client: %v", err)
+	}
+
+	return events.NewPubSubPublisher(service, config.ProjectID, config.Topic), nil
+}
@@ -0,0 +1,11 @@
+package outbox
+
+import (
+	"github.com/google/wire"
+)
+
+var OutboxProviderSet = wire.NewSet(
+	NewConfig,
+	NewPublisher,
+	NewRelay,
+)
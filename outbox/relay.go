@@ -0,0 +1,196 @@
+// Package outbox publishes the threat model change events recorded in the
+// outbox. ThreatModelService records each event in the same transaction as
+// its change, and the Relay publishes them afterwards, so that an event is
+// published if and only if its change is committed.
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-service-util/log"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+	"github.com/jtyers/tmaas-threat-model-api/events"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+const (
+	// how long to wait before publishing a record again after a failure,
+	// doubling up to maxRetryBackoff while failures continue
+	minRetryBackoff = time.Second
+	maxRetryBackoff = 5 * time.Minute
+
+	// the identity the outbox is read as
+	relayServiceAccount = "outbox-relay"
+
+	// Attributes of published messages, so that subscribers can filter
+	// without decoding them.
+	AttributeType          = "type"
+	AttributeTenantID      = "tenantId"
+	AttributeThreatModelID = "threatModelId"
+
+	publishResultSent   = "sent"
+	publishResultFailed = "failed"
+)
+
+// Relay publishes the records in the outbox, and marks them sent.
+//
+// Records are published at least once: a record is published again if
+// marking it sent fails, so consumers should discard events whose sequence
+// they have already seen. Records of the same threat model are published
+// in sequence order, with a threat model's records held back while an
+// earlier one is failing; records of other threat models carry on. Each
+// tenant's records are published by one relay at a time, whichever holds
+// its lease.
+type Relay struct {
+	dao       dao.OutboxDao
+	publisher events.Publisher
+	config    Config
+	metrics   *metrics.Metrics
+
+	// identifies this relay as the holder of leases
+	holder string
+
+	now func() time.Time
+}
+
+// NewRelay returns a Relay, or nil if there is no publisher (and so
+// nowhere to publish to).
+func NewRelay(dao dao.OutboxDao, publisher events.Publisher, config Config, metrics *metrics.Metrics) *Relay {
+	if publisher == nil {
+		return nil
+	}
+
+	return &Relay{
+		dao:       dao,
+		publisher: publisher,
+		config:    config,
+		metrics:   metrics,
+		holder:    uuid.NewString(),
+		now:       time.Now,
+	}
+}
+
+// Run publishes records every PollInterval until ctx is done.
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.RelayOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("error relaying outbox: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce publishes the pending records of each tenant whose lease this
+// relay holds or can take. A failure in one tenant does not hold up the
+// others.
+func (r *Relay) RelayOnce(ctx context.Context) error {
+	tenants, err := r.dao.GetTenants(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, tenantID := range tenants {
+		tenantCtx := auth.WithIdentity(ctx, &auth.Identity{ServiceAccountName: relayServiceAccount, TenantID: tenantID})
+
+		if err := r.relayTenant(tenantCtx, tenantID); err != nil {
+			log.Errorf("error relaying outbox of tenant %s: %v", tenantID, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Relay) relayTenant(ctx context.Context, tenantID auth.TenantID) error {
+	acquired, err := r.dao.AcquireLease(ctx, r.holder, r.config.LeaseTTL)
+	if err != nil || !acquired {
+		return err
+	}
+
+	records, err := r.dao.GetPending(ctx, r.config.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	// threat models with a record not published, whose later records
+	// must wait for it
+	blocked := map[m.ThreatModelID]bool{}
+
+	for _, record := range records {
+		if blocked[record.ThreatModelID] {
+			continue
+		}
+
+		if r.now().Before(record.NextAttemptAt) {
+			blocked[record.ThreatModelID] = true
+			continue
+		}
+
+		if err := r.publish(ctx, tenantID, record); err != nil {
+			log.Errorf("error publishing outbox record %s (attempt %d): %v", record.OutboxRecordID, record.Attempts, err)
+			blocked[record.ThreatModelID] = true
+		}
+	}
+
+	return nil
+}
+
+// publish publishes record, then marks it sent, or records the failure and
+// when to try again.
+func (r *Relay) publish(ctx context.Context, tenantID auth.TenantID, record *tm.OutboxRecord) error {
+	err := r.publisher.Publish(ctx, &events.Message{
+		Data: record.Payload,
+		Attributes: map[string]string{
+			AttributeType:          record.Type,
+			AttributeTenantID:      tenantID.String(),
+			AttributeThreatModelID: record.ThreatModelID.String(),
+		},
+		OrderingKey: tenantID.String() + "/" + record.ThreatModelID.String(),
+	})
+
+	now := r.now()
+
+	if err != nil {
+		r.metrics.OutboxPublishes.WithLabelValues(publishResultFailed).Inc()
+
+		record.Attempts++
+		record.LastError = err.Error()
+		record.NextAttemptAt = now.Add(retryBackoff(record.Attempts))
+
+		if err := r.dao.Update(ctx, record); err != nil {
+			log.Errorf("error recording failure to publish outbox record %s: %v", record.OutboxRecordID, err)
+		}
+		return err
+	}
+
+	r.metrics.OutboxPublishes.WithLabelValues(publishResultSent).Inc()
+
+	record.SentAt = &now
+	return r.dao.Update(ctx, record)
+}
+
+// retryBackoff returns how long to wait before publishing a record again
+// after it has failed attempts times.
+func retryBackoff(attempts int) time.Duration {
+	backoff := minRetryBackoff
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,216 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+	"github.com/jtyers/tmaas-threat-model-api/events"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func record(id string, threatModelID string, sequence int64) *tm.OutboxRecord {
+	return &tm.OutboxRecord{
+		OutboxRecordID: id,
+		ThreatModelID:  m.NewThreatModelIDP(threatModelID),
+		Type:           tm.ThreatModelUpdated,
+		Sequence:       sequence,
+		Payload:        []byte(`{"id": "` + id + `"}`),
+		NextAttemptAt:  now,
+	}
+}
+
+// tenantMatcher matches contexts of the outbox relay acting in a tenant.
+type tenantMatcher auth.TenantID
+
+func inTenant(tenantID auth.TenantID) gomock.Matcher {
+	return tenantMatcher(tenantID)
+}
+
+func (t tenantMatcher) Matches(x any) bool {
+	ctx, ok := x.(context.Context)
+	if !ok {
+		return false
+	}
+
+	identity, err := auth.IdentityFromContext(ctx)
+	return err == nil && identity.TenantID == auth.TenantID(t) && identity.ServiceAccountName == relayServiceAccount
+}
+
+func (t tenantMatcher) String() string {
+	return "is the outbox relay in tenant " + string(t)
+}
+
+func newTestRelay(dao dao.OutboxDao, publisher events.Publisher) *Relay {
+	relay := NewRelay(dao, publisher, Config{BatchSize: 10, LeaseTTL: time.Minute}, metrics.NewMetrics())
+	relay.now = func() time.Time { return now }
+	return relay
+}
+
+func TestRelayPublishesInOrder(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDao := dao.NewMockOutboxDao(ctrl)
+	bus := events.NewMemoryBus()
+	relay := newTestRelay(mockDao, bus)
+
+	records := []*tm.OutboxRecord{
+		record("obx-1", "tm-1", 1),
+		record("obx-2", "tm-1", 2),
+		record("obx-3", "tm-2", 1),
+	}
+
+	mockDao.EXPECT().GetTenants(gomock.Any()).Return([]auth.TenantID{"acme"}, nil)
+	mockDao.EXPECT().AcquireLease(inTenant("acme"), relay.holder, time.Minute).Return(true, nil)
+	mockDao.EXPECT().GetPending(inTenant("acme"), 10).Return(records, nil)
+
+	for _, r := range records {
+		sent := *r
+		sent.SentAt = &now
+		mockDao.EXPECT().Update(inTenant("acme"), &sent).Return(nil)
+	}
+
+	// when
+	err := relay.RelayOnce(context.Background())
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, 3, bus.Pending())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	received := []*events.Message{}
+	_ = bus.Receive(ctx, events.HandlerFunc(func(ctx context.Context, msg *events.Message) error {
+		received = append(received, msg)
+		if len(received) == len(records) {
+			cancel()
+		}
+		return nil
+	}))
+
+	require.Equal(t, []byte(`{"id": "obx-1"}`), received[0].Data)
+	require.Equal(t, []byte(`{"id": "obx-2"}`), received[1].Data)
+	require.Equal(t, []byte(`{"id": "obx-3"}`), received[2].Data)
+
+	require.Equal(t, "acme/tm-1", received[0].OrderingKey)
+	require.Equal(t, map[string]string{
+		AttributeType:          tm.ThreatModelUpdated,
+		AttributeTenantID:      "acme",
+		AttributeThreatModelID: "tm-1",
+	}, received[0].Attributes)
+}
+
+func TestRelayHoldsBackRecordsAfterFailures(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDao := dao.NewMockOutboxDao(ctrl)
+	mockPublisher := events.NewMockPublisher(ctrl)
+	relay := newTestRelay(mockDao, mockPublisher)
+
+	failing := record("obx-1", "tm-1", 1)
+	failing.Attempts = 2
+
+	notDue := record("obx-3", "tm-2", 1)
+	notDue.NextAttemptAt = now.Add(time.Second)
+
+	records := []*tm.OutboxRecord{
+		failing,
+		record("obx-2", "tm-1", 2),
+		notDue,
+		record("obx-4", "tm-2", 2),
+		record("obx-5", "tm-3", 1),
+	}
+
+	mockDao.EXPECT().GetTenants(gomock.Any()).Return([]auth.TenantID{"acme"}, nil)
+	mockDao.EXPECT().AcquireLease(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+	mockDao.EXPECT().GetPending(gomock.Any(), gomock.Any()).Return(records, nil)
+
+	// obx-2 waits for obx-1, and obx-4 for obx-3, so only obx-1 and obx-5
+	// are published
+	publishErr := errors.New("pubsub unavailable")
+	mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, msg *events.Message) error {
+		require.Equal(t, "tm-1", msg.Attributes[AttributeThreatModelID])
+		return publishErr
+	})
+	mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, msg *events.Message) error {
+		require.Equal(t, "tm-3", msg.Attributes[AttributeThreatModelID])
+		return nil
+	})
+
+	failed := *failing
+	failed.Attempts = 3
+	failed.LastError = publishErr.Error()
+	failed.NextAttemptAt = now.Add(4 * time.Second)
+
+	sent := *records[4]
+	sent.SentAt = &now
+
+	gomock.InOrder(
+		mockDao.EXPECT().Update(gomock.Any(), &failed).Return(nil),
+		mockDao.EXPECT().Update(gomock.Any(), &sent).Return(nil),
+	)
+
+	// when
+	err := relay.RelayOnce(context.Background())
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, 1.0, testutil.ToFloat64(relay.metrics.OutboxPublishes.WithLabelValues(publishResultFailed)))
+	require.Equal(t, 1.0, testutil.ToFloat64(relay.metrics.OutboxPublishes.WithLabelValues(publishResultSent)))
+}
+
+func TestRelaySkipsTenantsLeasedElsewhere(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDao := dao.NewMockOutboxDao(ctrl)
+	mockPublisher := events.NewMockPublisher(ctrl)
+	relay := newTestRelay(mockDao, mockPublisher)
+
+	mockDao.EXPECT().GetTenants(gomock.Any()).Return([]auth.TenantID{"acme", "globex"}, nil)
+	mockDao.EXPECT().AcquireLease(inTenant("acme"), gomock.Any(), gomock.Any()).Return(false, nil)
+	mockDao.EXPECT().AcquireLease(inTenant("globex"), gomock.Any(), gomock.Any()).Return(true, nil)
+	mockDao.EXPECT().GetPending(inTenant("globex"), gomock.Any()).Return([]*tm.OutboxRecord{}, nil)
+
+	// when
+	err := relay.RelayOnce(context.Background())
+
+	// then
+	require.Nil(t, err)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	var tests = []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{5, 16 * time.Second},
+		{9, 256 * time.Second},
+		{10, maxRetryBackoff},
+		{1000, maxRetryBackoff},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.expected, retryBackoff(test.attempts), "after %d attempts", test.attempts)
+	}
+}
+
+func TestNewRelayWithoutPublisher(t *testing.T) {
+	require.Nil(t, NewRelay(nil, nil, Config{}, metrics.NewMetrics()))
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	util "github.com/jtyers/tmaas-service-util"
+	"github.com/jtyers/tmaas-service-util/log"
+)
+
+const (
+	redisKeyPrefix = "ratelimit:"
+)
+
+// DefaultLimit applies to routes without a limit of their own unless
+// configured otherwise.
+var DefaultLimit = Limit{Rate: 10, Burst: 20}
+
+// Config sets the limits applied to each caller.
+type Config struct {
+	// The limit on all requests of a caller to routes not in Routes.
+	Default Limit `json:"default"`
+
+	// Limits on requests to particular routes, keyed by method and route
+	// pattern, eg "GET /api/v1/threatmodel", or for gRPC by full method
+	// name, eg "/threatmodel.v1.ThreatModelService/Get". Each caller
+	// has a separate bucket per route listed here.
+	Routes map[string]Limit `json:"routes"`
+
+	// Limits on all requests to routes requiring a permission, keyed by
+	// permission, eg "editOwnThreatModels", applied on top of the route or
+	// default limit. Each caller has a separate bucket per permission
+	// listed here, shared by every route requiring it.
+	Permissions map[string]Limit `json:"permissions"`
+
+	// The address of the Redis server holding buckets, if they are to be
+	// shared between instances. Buckets are held in memory if empty.
+	RedisAddr string `json:"-"`
+}
+
+// NewConfig reads the limits from RATE_LIMITS, a JSON encoded Config, and
+// the Redis address from RATE_LIMIT_REDIS_ADDR.
+func NewConfig() (Config, error) {
+	config := Config{Default: DefaultLimit}
+
+	if limits := util.GetEnvWithDefault("RATE_LIMITS", ""); limits != "" {
+		if err := json.Unmarshal([]byte(limits), &config); err != nil {
+			return Config{}, fmt.Errorf("error parsing RATE_LIMITS: %v", err)
+		}
+	}
+
+	config.RedisAddr = util.GetEnvWithDefault("RATE_LIMIT_REDIS_ADDR", "")
+
+	return config, nil
+}
+
+// NewStore returns a RedisStore if config names a Redis server, otherwise
+// a MemoryStore. The returned cleanup function closes the Redis client.
+func NewStore(config Config) (Store, func()) {
+	if config.RedisAddr != "" {
+		client := redis.NewClient(&redis.Options{Addr: config.RedisAddr})
+
+		cleanup := func() {
+			if err := client.Close(); err != nil {
+				log.Errorf("error closing rate limit Redis client: %v", err)
+			}
+		}
+
+		return NewRedisStore(client, redisKeyPrefix), cleanup
+	}
+	return NewMemoryStore(), func() {}
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// how many calls to Take between sweeps of full buckets
+const memorySweepInterval = 1000
+
+// MemoryStore holds buckets in memory, so limits apply per instance.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	calls   int
+	now     func() time.Time
+}
+
+type memoryBucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*memoryBucket{}, now: time.Now}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	s.calls++
+	if s.calls%memorySweepInterval == 0 {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = refill(b.tokens, b.last, now, limit)
+	b.last = now
+	b.limit = limit
+
+	if b.tokens < 1 {
+		return false, wait(b.tokens, limit), nil
+	}
+
+	b.tokens--
+	return true, 0, nil
+}
+
+// sweep drops buckets that have refilled completely, since a new bucket
+// would be identical; the caller must hold s.mu.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if refill(b.tokens, b.last, now, b.limit) >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package ratelimit
+
+import (
+	"github.com/google/wire"
+)
+
+var RateLimitProviderSet = wire.NewSet(
+	NewConfig,
+	NewStore,
+)
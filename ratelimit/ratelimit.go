@@ -0,0 +1,67 @@
+// Package ratelimit implements token-bucket rate limiting over pluggable
+// stores, so that limits can be shared between instances of the API.
+package ratelimit
+
+//go:generate mockgen -source=$GOFILE -destination=${GOFILE}_mocks.go -package $GOPACKAGE
+
+import (
+	"context"
+	"time"
+
+	"github.com/jtyers/tmaas-service-util/log"
+)
+
+// Limit configures a token bucket, which holds up to Burst tokens and
+// refills at Rate tokens per second. Each request takes one token. The
+// zero Limit is unlimited.
+type Limit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// Unlimited returns true if the limit does not restrict requests.
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// Store holds token buckets.
+type Store interface {
+	// Take a token from the bucket with the given key, creating a full
+	// bucket if there is none. If the bucket is empty, it returns false
+	// along with how long until a token is available.
+	Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error)
+}
+
+// refill returns the tokens in a bucket holding tokens at last, as of now.
+func refill(tokens float64, last time.Time, now time.Time, limit Limit) float64 {
+	if elapsed := now.Sub(last).Seconds(); elapsed > 0 {
+		tokens += elapsed * limit.Rate
+	}
+	if tokens > float64(limit.Burst) {
+		tokens = float64(limit.Burst)
+	}
+	return tokens
+}
+
+// wait returns how long until a bucket holding tokens has a whole token.
+func wait(tokens float64, limit Limit) time.Duration {
+	return time.Duration((1 - tokens) / limit.Rate * float64(time.Second))
+}
+
+// Allow takes a token from the bucket with the given key, returning false
+// along with how long until a token is available if there is none. If the
+// store fails, the request is allowed rather than taking the API down with
+// it.
+func Allow(ctx context.Context, store Store, key string, limit Limit) (bool, time.Duration) {
+	if limit.Unlimited() {
+		return true, 0
+	}
+
+	allowed, retryAfter, err := store.Take(ctx, key, limit)
+	if err != nil {
+		log.Errorf("error applying rate limit to %s: %v", key, err)
+		return true, 0
+	}
+
+	return allowed, retryAfter
+}
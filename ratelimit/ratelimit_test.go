@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+// take is one call to Take, made at an offset from the start of the test.
+type take struct {
+	at                 time.Duration
+	key                string
+	expectedAllowed    bool
+	expectedRetryAfter time.Duration
+}
+
+var storeTests = []struct {
+	name  string
+	limit Limit
+	takes []take
+}{
+	{
+		"should allow bursts up to the limit",
+		Limit{Rate: 1, Burst: 3},
+		[]take{
+			{0, "a", true, 0},
+			{0, "a", true, 0},
+			{0, "a", true, 0},
+			{0, "a", false, time.Second},
+		},
+	},
+	{
+		"should refill at the rate",
+		Limit{Rate: 2, Burst: 1},
+		[]take{
+			{0, "a", true, 0},
+			{250 * time.Millisecond, "a", false, 250 * time.Millisecond},
+			{500 * time.Millisecond, "a", true, 0},
+		},
+	},
+	{
+		"should not refill beyond the burst",
+		Limit{Rate: 1, Burst: 1},
+		[]take{
+			{0, "a", true, 0},
+			{time.Hour, "a", true, 0},
+			{time.Hour, "a", false, time.Second},
+		},
+	},
+	{
+		"should keep a bucket per key",
+		Limit{Rate: 1, Burst: 1},
+		[]take{
+			{0, "a", true, 0},
+			{0, "b", true, 0},
+			{0, "a", false, time.Second},
+		},
+	},
+}
+
+func runStoreTests(t *testing.T, newStore func(now func() time.Time) Store) {
+	for _, test := range storeTests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			start := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+			now := start
+			store := newStore(func() time.Time { return now })
+
+			for i, take := range test.takes {
+				now = start.Add(take.at)
+
+				// when
+				allowed, retryAfter, err := store.Take(context.Background(), take.key, test.limit)
+
+				// then
+				require.Nil(t, err)
+				require.Equal(t, take.expectedAllowed, allowed, "take %d", i)
+				require.InDelta(t, take.expectedRetryAfter, retryAfter, float64(time.Millisecond), "take %d", i)
+			}
+		})
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	runStoreTests(t, func(now func() time.Time) Store {
+		store := NewMemoryStore()
+		store.now = now
+		return store
+	})
+}
+
+func TestRedisStore(t *testing.T) {
+	runStoreTests(t, func(now func() time.Time) Store {
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+
+		store := NewRedisStore(client, "ratelimit:")
+		store.now = now
+		return store
+	})
+}
+
+func TestMemoryStoreSweepsFullBuckets(t *testing.T) {
+	// given
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	limit := Limit{Rate: 1, Burst: 1}
+	store.Take(context.Background(), "a", limit)
+
+	// when
+	now = now.Add(time.Minute)
+	for i := 1; i < memorySweepInterval; i++ {
+		store.Take(context.Background(), "b", limit)
+	}
+
+	// then
+	require.NotContains(t, store.buckets, "a")
+}
@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// takeScript refills and takes from a bucket atomically. Buckets are hashes
+// of the token count and the time they were last refilled, and expire once
+// they would be full again.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens = tonumber(bucket[1]) or burst
+local last = tonumber(bucket[2]) or now
+
+if now > last then
+  tokens = math.min(burst, tokens + (now - last) * rate)
+end
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "last", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore holds buckets in Redis, so limits apply across all instances
+// sharing it. Bucket times come from the instance's clock, so instances
+// should keep their clocks in sync.
+type RedisStore struct {
+	client    redis.UniversalClient
+	keyPrefix string
+	now       func() time.Time
+}
+
+var _ Store = (*RedisStore)(nil)
+
+func NewRedisStore(client redis.UniversalClient, keyPrefix string) *RedisStore {
+	return &RedisStore{client: client, keyPrefix: keyPrefix, now: time.Now}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	now := float64(s.now().UnixMicro()) / 1e6
+
+	result, err := takeScript.Run(ctx, s.client, []string{s.keyPrefix + key},
+		limit.Rate, limit.Burst, strconv.FormatFloat(now, 'f', 6, 64),
+	).Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("error taking rate limit token: %v", err)
+	}
+
+	if len(result) != 2 {
+		return false, 0, fmt.Errorf("error taking rate limit token: unexpected result %v", result)
+	}
+
+	allowed, _ := result[0].(int64)
+	tokensStr, _ := result[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil || math.IsNaN(tokens) {
+		return false, 0, fmt.Errorf("error taking rate limit token: unexpected result %v", result)
+	}
+
+	if allowed != 1 {
+		return false, wait(tokens, limit), nil
+	}
+	return true, 0, nil
+}
@@ -0,0 +1,112 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Field is a piece of text from a document, along with the path of the
+// field it came from, eg "title" or "threats[2].mitigations[0].description".
+type Field struct {
+	Path string
+	Text string
+}
+
+// ExtractFields returns every non-empty string within v, as it would be
+// serialised to JSON. Walking the JSON form, rather than the Go struct,
+// means new fields on the threat model are searchable without changes here.
+func ExtractFields(v any) ([]Field, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic any
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return nil, err
+	}
+
+	result := []Field{}
+	walk("", generic, &result)
+	return result, nil
+}
+
+func walk(path string, v any, result *[]Field) {
+	switch t := v.(type) {
+	case string:
+		if strings.TrimSpace(t) != "" {
+			*result = append(*result, Field{Path: path, Text: t})
+		}
+
+	case []any:
+		for i, item := range t {
+			walk(fmt.Sprintf("%s[%d]", path, i), item, result)
+		}
+
+	case map[string]any:
+		// sort keys so that field order is stable between runs
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			walk(childPath, t[k], result)
+		}
+	}
+}
+
+// fieldName returns the last component of a path, lower-cased and without
+// any index, so "threats[2].Title" yields "title".
+func fieldName(path string) string {
+	return strings.ToLower(lastComponent(path))
+}
+
+func lastComponent(path string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		path = path[i+1:]
+	}
+	if i := strings.Index(path, "["); i >= 0 {
+		path = path[:i]
+	}
+	return path
+}
+
+// idSuffixes end the names of ID fields, in camel or snake case.
+var idSuffixes = []string{"ID", "Id", "IDs", "Ids", "_id", "_ids"}
+
+// isIDField returns true if path names an ID field, such as "id",
+// "threatModelId", "DataFlowDiagramID" or "owner_ids", but not fields
+// merely ending in the letters "id", such as "valid" or "grid".
+func isIDField(path string) bool {
+	name := lastComponent(path)
+
+	switch strings.ToLower(name) {
+	case "id", "ids":
+		return true
+	}
+
+	for _, suffix := range idSuffixes {
+		prefix, ok := strings.CutSuffix(name, suffix)
+		if !ok || prefix == "" {
+			continue
+		}
+
+		// the suffix must start a new word, so "VALID" is not an ID
+		last, _ := utf8.DecodeLastRuneInString(prefix)
+		if suffix[0] == '_' || unicode.IsLower(last) || unicode.IsDigit(last) {
+			return true
+		}
+	}
+
+	return false
+}
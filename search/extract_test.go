@@ -0,0 +1,65 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractFields(t *testing.T) {
+	type mitigation struct {
+		Description string `json:"description"`
+	}
+	type threat struct {
+		Title       string       `json:"title"`
+		Mitigations []mitigation `json:"mitigations"`
+	}
+	type doc struct {
+		Title   string   `json:"title"`
+		Empty   string   `json:"empty"`
+		Count   int      `json:"count"`
+		Threats []threat `json:"threats"`
+	}
+
+	fields, err := ExtractFields(doc{
+		Title: "Payments",
+		Count: 3,
+		Threats: []threat{
+			{Title: "Spoofing"},
+			{Title: "Tampering", Mitigations: []mitigation{{Description: "Sign requests"}}},
+		},
+	})
+
+	require.Nil(t, err)
+	require.Equal(t, []Field{
+		{Path: "threats[0].title", Text: "Spoofing"},
+		{Path: "threats[1].mitigations[0].description", Text: "Sign requests"},
+		{Path: "threats[1].title", Text: "Tampering"},
+		{Path: "title", Text: "Payments"},
+	}, fields)
+}
+
+func TestIsIDField(t *testing.T) {
+	var tests = []struct {
+		path     string
+		expected bool
+	}{
+		{"id", true},
+		{"IDs", true},
+		{"threatModelId", true},
+		{"dataFlowDiagramID", true},
+		{"threats[0].ownerIDs", true},
+		{"owner_ids[1]", true},
+		{"threats[0].valid", false},
+		{"grid", false},
+		{"VALID", false},
+		{"paid", false},
+		{"title", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			require.Equal(t, test.expected, isIDField(test.path))
+		})
+	}
+}
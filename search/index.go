@@ -0,0 +1,237 @@
+package search
+
+import (
+	"context"
+	"html"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// The text either side of a match to include in a snippet, in bytes.
+	snippetRadius = 60
+
+	// The most snippets to return per hit.
+	maxSnippets = 3
+
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// FieldBoosts weights matches according to the field they are found in, so
+// a match in a title ranks above a match deep in a mitigation. Fields not
+// listed have a weight of 1.
+var FieldBoosts = map[string]float64{
+	"title":       3,
+	"name":        3,
+	"description": 1.5,
+}
+
+// Hit is a document matching a search, with highlighted snippets of the
+// text that matched.
+type Hit struct {
+	ID       string
+	Score    float64
+	Snippets []Snippet
+}
+
+// Snippet is an extract from a field of a document, with matching terms
+// wrapped in HighlightStart and HighlightEnd. The rest of the text is
+// HTML-escaped, so snippets are safe to render as HTML.
+type Snippet struct {
+	Field    string
+	Fragment string
+}
+
+// Index is a full-text index of documents, each identified by an ID. Each
+// tenant has its own index, and every method acts on that of the tenant in
+// ctx.
+type Index interface {
+	// Add a document to the index, replacing any existing document with the same ID.
+	Index(ctx context.Context, id string, fields []Field) error
+
+	// Remove a document from the index.
+	Remove(ctx context.Context, id string) error
+
+	// Replace the entire contents of the index.
+	Reset(ctx context.Context, docs map[string][]Field) error
+
+	// Find documents containing all terms in query, best match first.
+	Search(ctx context.Context, query string, limit int) ([]Hit, error)
+
+	// The number of documents in the index.
+	Count(ctx context.Context) (int, error)
+}
+
+// Document is an analysed document, ready to be indexed.
+type Document struct {
+	// Fields are those of the document worth searching, used for snippets.
+	Fields []Field
+
+	// Weights are the boosted frequency of each term within Fields.
+	Weights map[string]float64
+}
+
+// Analyse prepares fields for indexing, dropping IDs, which are not useful
+// search terms, and weighting each term by the boosts of the fields it
+// appears in.
+func Analyse(fields []Field) Document {
+	doc := Document{Fields: []Field{}, Weights: map[string]float64{}}
+
+	for _, field := range fields {
+		if isIDField(field.Path) {
+			continue
+		}
+		doc.Fields = append(doc.Fields, field)
+
+		boost := boostFor(fieldName(field.Path))
+		for _, t := range tokenise(field.Text) {
+			doc.Weights[t.term] += boost
+		}
+	}
+
+	return doc
+}
+
+// Rank scores docs that contain every term of queryTerms using TF-IDF, and
+// returns the best limit of them (or all, if limit is zero) with snippets.
+// docCount is the number of documents in the index, and docFreqs the number
+// containing each term.
+func Rank(docs map[string]Document, queryTerms []string, docCount int, docFreqs map[string]int, limit int) []Hit {
+	result := []Hit{}
+
+	for id, doc := range docs {
+		score := 0.0
+		matched := true
+
+		for _, term := range queryTerms {
+			weight, ok := doc.Weights[term]
+			if !ok {
+				matched = false
+				break
+			}
+			score += weight * math.Log(1+float64(docCount)/float64(docFreqs[term]+1))
+		}
+
+		if matched {
+			result = append(result, Hit{ID: id, Score: score})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+
+	// only build snippets for the hits returned
+	for i := range result {
+		result[i].Snippets = snippets(docs[result[i].ID].Fields, queryTerms)
+	}
+
+	return result
+}
+
+func boostFor(name string) float64 {
+	if boost, ok := FieldBoosts[name]; ok {
+		return boost
+	}
+	return 1
+}
+
+// snippets returns highlighted extracts from the fields containing any of
+// the terms, highest-boosted fields first.
+func snippets(fields []Field, queryTerms []string) []Snippet {
+	wanted := map[string]bool{}
+	for _, term := range queryTerms {
+		wanted[term] = true
+	}
+
+	result := []Snippet{}
+	for _, field := range fields {
+		if fragment, ok := highlight(field.Text, wanted); ok {
+			result = append(result, Snippet{Field: field.Path, Fragment: fragment})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return boostFor(fieldName(result[i].Field)) > boostFor(fieldName(result[j].Field))
+	})
+
+	if len(result) > maxSnippets {
+		result = result[:maxSnippets]
+	}
+
+	return result
+}
+
+// highlight returns an extract of text centred on the first wanted term,
+// with every wanted term in the extract highlighted.
+func highlight(text string, wanted map[string]bool) (string, bool) {
+	tokens := tokenise(text)
+
+	first := -1
+	for i, t := range tokens {
+		if wanted[t.term] {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	// widen the window to the nearest token boundaries, so we don't cut
+	// words (or multi-byte runes) in half
+	start, end := tokens[first].start-snippetRadius, tokens[first].end+snippetRadius
+	if start <= 0 {
+		start = 0
+	} else {
+		for _, t := range tokens {
+			if t.start >= start {
+				start = t.start
+				break
+			}
+		}
+	}
+	if end >= len(text) {
+		end = len(text)
+	} else {
+		for i := len(tokens) - 1; i >= 0; i-- {
+			if tokens[i].end <= end {
+				end = tokens[i].end
+				break
+			}
+		}
+	}
+
+	b := strings.Builder{}
+	if start > 0 {
+		b.WriteString("…")
+	}
+
+	pos := start
+	for _, t := range tokens {
+		if t.start < start || t.end > end || !wanted[t.term] {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:t.start]))
+		b.WriteString(HighlightStart)
+		b.WriteString(html.EscapeString(text[t.start:t.end]))
+		b.WriteString(HighlightEnd)
+		pos = t.end
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+
+	if end < len(text) {
+		b.WriteString("…")
+	}
+
+	return b.String(), true
+}
@@ -0,0 +1,207 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func inTenant(tenantID auth.TenantID) context.Context {
+	return auth.WithIdentity(context.Background(), &auth.Identity{TenantID: tenantID})
+}
+
+// search runs query against index in ctx, failing t on error.
+func search(t *testing.T, ctx context.Context, index Index, query string, limit int) []Hit {
+	hits, err := index.Search(ctx, query, limit)
+	require.Nil(t, err)
+	return hits
+}
+
+func count(t *testing.T, ctx context.Context, index Index) int {
+	n, err := index.Count(ctx)
+	require.Nil(t, err)
+	return n
+}
+
+func hitIDs(hits []Hit) []string {
+	result := []string{}
+	for _, hit := range hits {
+		result = append(result, hit.ID)
+	}
+	return result
+}
+
+func TestMemoryIndexSearch(t *testing.T) {
+	docs := map[string][]Field{
+		"tm-1": {
+			{Path: "title", Text: "Payments gateway"},
+			{Path: "threats[0].description", Text: "An attacker replays a card authorisation"},
+		},
+		"tm-2": {
+			{Path: "title", Text: "Card vault"},
+			{Path: "description", Text: "Stores card data for the payments team"},
+		},
+		"tm-3": {
+			{Path: "title", Text: "Marketing site"},
+			{Path: "dataFlowDiagramId", Text: "payments"},
+		},
+		"tm-4": {
+			{Path: "title", Text: "Grid telemetry"},
+			{Path: "threats[0].valid", Text: "spoofed meter readings"},
+		},
+	}
+
+	var tests = []struct {
+		name        string
+		query       string
+		limit       int
+		expectedIDs []string
+	}{
+		{
+			"should rank title matches above other fields",
+			"payments",
+			0,
+			[]string{"tm-1", "tm-2"},
+		},
+		{
+			"should require all terms to match",
+			"card payments gateway",
+			0,
+			[]string{"tm-1"},
+		},
+		{
+			"should match case-insensitively and ignore punctuation",
+			"CARD, vault!",
+			0,
+			[]string{"tm-2"},
+		},
+		{
+			"should apply limit",
+			"payments",
+			1,
+			[]string{"tm-1"},
+		},
+		{
+			"should not match ID fields",
+			"marketing payments",
+			0,
+			[]string{},
+		},
+		{
+			"should match fields merely ending in id",
+			"spoofed meter",
+			0,
+			[]string{"tm-4"},
+		},
+		{
+			"should return nothing for an empty query",
+			"  ",
+			0,
+			[]string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctx := inTenant("acme")
+			index := NewMemoryIndex()
+			require.Nil(t, index.Reset(ctx, docs))
+
+			// when
+			hits := search(t, ctx, index, test.query, test.limit)
+
+			// then
+			require.Equal(t, test.expectedIDs, hitIDs(hits))
+		})
+	}
+}
+
+func TestMemoryIndexIndexAndRemove(t *testing.T) {
+	ctx := inTenant("acme")
+	index := NewMemoryIndex()
+
+	require.Nil(t, index.Index(ctx, "tm-1", []Field{{Path: "title", Text: "Old title"}}))
+	require.Equal(t, []string{"tm-1"}, hitIDs(search(t, ctx, index, "old", 0)))
+
+	// re-indexing replaces the previous document
+	require.Nil(t, index.Index(ctx, "tm-1", []Field{{Path: "title", Text: "New title"}}))
+	require.Equal(t, []string{}, hitIDs(search(t, ctx, index, "old", 0)))
+	require.Equal(t, []string{"tm-1"}, hitIDs(search(t, ctx, index, "new", 0)))
+	require.Equal(t, 1, count(t, ctx, index))
+
+	require.Nil(t, index.Remove(ctx, "tm-1"))
+	require.Equal(t, []string{}, hitIDs(search(t, ctx, index, "new", 0)))
+	require.Equal(t, 0, count(t, ctx, index))
+}
+
+func TestMemoryIndexIsolatesTenants(t *testing.T) {
+	acme, globex := inTenant("acme"), inTenant("globex")
+	index := NewMemoryIndex()
+
+	require.Nil(t, index.Index(acme, "tm-1", []Field{{Path: "title", Text: "Payments gateway"}}))
+	require.Nil(t, index.Reset(globex, map[string][]Field{"tm-2": {{Path: "title", Text: "Payments vault"}}}))
+
+	require.Equal(t, []string{"tm-1"}, hitIDs(search(t, acme, index, "payments", 0)))
+	require.Equal(t, []string{"tm-2"}, hitIDs(search(t, globex, index, "payments", 0)))
+
+	_, err := index.Search(context.Background(), "payments", 0)
+	require.Equal(t, auth.ErrNoTenant, err)
+}
+
+func TestMemoryIndexSnippets(t *testing.T) {
+	var tests = []struct {
+		name             string
+		fields           []Field
+		query            string
+		expectedSnippets []Snippet
+	}{
+		{
+			"should highlight every matching term",
+			[]Field{{Path: "title", Text: "SQL injection in the login form"}},
+			"injection login",
+			[]Snippet{{Field: "title", Fragment: "SQL <mark>injection</mark> in the <mark>login</mark> form"}},
+		},
+		{
+			"should escape HTML outside highlights",
+			[]Field{{Path: "description", Text: "<script>alert(1)</script> via XSS"}},
+			"xss",
+			[]Snippet{{Field: "description", Fragment: "&lt;script&gt;alert(1)&lt;/script&gt; via <mark>XSS</mark>"}},
+		},
+		{
+			"should trim long text around the match",
+			[]Field{{Path: "description", Text: "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. Tampering with the audit log. Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo consequat."}},
+			"tampering",
+			[]Snippet{{Field: "description", Fragment: "…eiusmod tempor incididunt ut labore et dolore magna aliqua. <mark>Tampering</mark> with the audit log. Ut enim ad minim veniam, quis nostrud…"}},
+		},
+		{
+			"should order snippets by field boost",
+			[]Field{
+				{Path: "threats[0].mitigations[0].notes", Text: "Rotate keys"},
+				{Path: "title", Text: "Key management"},
+			},
+			"key",
+			[]Snippet{
+				{Field: "title", Fragment: "<mark>Key</mark> management"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctx := inTenant("acme")
+			index := NewMemoryIndex()
+			require.Nil(t, index.Index(ctx, "tm-1", test.fields))
+
+			// when
+			hits := search(t, ctx, index, test.query, 0)
+
+			// then
+			require.Len(t, hits, 1)
+			require.Equal(t, test.expectedSnippets, hits[0].Snippets)
+		})
+	}
+}
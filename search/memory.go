@@ -0,0 +1,121 @@
+package search
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+)
+
+// MemoryIndex is an in-process Index. It is not shared between instances,
+// so it suits tests and running the API locally, but not deployments of
+// more than one instance.
+type MemoryIndex struct {
+	mu      sync.RWMutex
+	tenants map[auth.TenantID]map[string]Document
+}
+
+var _ Index = (*MemoryIndex)(nil)
+
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{tenants: map[auth.TenantID]map[string]Document{}}
+}
+
+// docs returns the documents of the tenant in ctx, creating them if create
+// is true; the caller must hold the lock.
+func (x *MemoryIndex) docs(ctx context.Context, create bool) (map[string]Document, error) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, ok := x.tenants[tenantID]
+	if !ok && create {
+		docs = map[string]Document{}
+		x.tenants[tenantID] = docs
+	}
+
+	return docs, nil
+}
+
+func (x *MemoryIndex) Index(ctx context.Context, id string, fields []Field) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	docs, err := x.docs(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	docs[id] = Analyse(fields)
+	return nil
+}
+
+func (x *MemoryIndex) Remove(ctx context.Context, id string) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	docs, err := x.docs(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	delete(docs, id)
+	return nil
+}
+
+func (x *MemoryIndex) Reset(ctx context.Context, docs map[string][]Field) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	analysed := map[string]Document{}
+	for id, fields := range docs {
+		analysed[id] = Analyse(fields)
+	}
+
+	x.tenants[tenantID] = analysed
+	return nil
+}
+
+func (x *MemoryIndex) Count(ctx context.Context) (int, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	docs, err := x.docs(ctx, false)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(docs), nil
+}
+
+func (x *MemoryIndex) Search(ctx context.Context, query string, limit int) ([]Hit, error) {
+	queryTerms := Terms(query)
+	if len(queryTerms) == 0 {
+		return []Hit{}, nil
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	docs, err := x.docs(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	docFreqs := map[string]int{}
+	for _, doc := range docs {
+		for _, term := range queryTerms {
+			if _, ok := doc.Weights[term]; ok {
+				docFreqs[term]++
+			}
+		}
+	}
+
+	return Rank(docs, queryTerms, len(docs), docFreqs, limit), nil
+}
@@ -0,0 +1,50 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// token is a normalised term and its byte offsets in the original text.
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenise splits text into lower-cased terms of letters and digits.
+func tokenise(text string) []token {
+	result := []token{}
+	start := -1
+
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			result = append(result, token{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		result = append(result, token{strings.ToLower(text[start:]), start, len(text)})
+	}
+
+	return result
+}
+
+// Terms returns the distinct terms in a query, in order.
+func Terms(query string) []string {
+	result := []string{}
+	seen := map[string]bool{}
+
+	for _, t := range tokenise(query) {
+		if !seen[t.term] {
+			seen[t.term] = true
+			result = append(result, t.term)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	util "github.com/jtyers/tmaas-service-util"
+)
+
+const (
+	DefaultReadTimeout       = 15 * time.Second
+	DefaultReadHeaderTimeout = 5 * time.Second
+	DefaultWriteTimeout      = 30 * time.Second
+	DefaultIdleTimeout       = 120 * time.Second
+	DefaultMaxHeaderBytes    = http.DefaultMaxHeaderBytes
+	DefaultShutdownTimeout   = 20 * time.Second
+)
+
+// Config sets how the HTTP server accepts connections and how long it
+// waits for them on shutdown.
+type Config struct {
+	Addr string
+
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+
+	// How long to wait for in-flight requests to complete on shutdown,
+	// before closing their connections.
+	ShutdownTimeout time.Duration
+}
+
+// NewConfig reads the port from PORT, and the timeouts from
+// SERVER_READ_TIMEOUT, SERVER_READ_HEADER_TIMEOUT, SERVER_WRITE_TIMEOUT,
+// SERVER_IDLE_TIMEOUT and SERVER_SHUTDOWN_TIMEOUT, each a duration such as
+// "30s". SERVER_MAX_HEADER_BYTES sets the largest request header accepted.
+func NewConfig() (Config, error) {
+	config := Config{
+		Addr:           ":" + util.GetEnv("PORT"),
+		MaxHeaderBytes: DefaultMaxHeaderBytes,
+	}
+
+	durations := []struct {
+		env   string
+		value *time.Duration
+		def   time.Duration
+	}{
+		{"SERVER_READ_TIMEOUT", &config.ReadTimeout, DefaultReadTimeout},
+		{"SERVER_READ_HEADER_TIMEOUT", &config.ReadHeaderTimeout, DefaultReadHeaderTimeout},
+		{"SERVER_WRITE_TIMEOUT", &config.WriteTimeout, DefaultWriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", &config.IdleTimeout, DefaultIdleTimeout},
+		{"SERVER_SHUTDOWN_TIMEOUT", &config.ShutdownTimeout, DefaultShutdownTimeout},
+	}
+
+	for _, d := range durations {
+		*d.value = d.def
+
+		if s := util.GetEnvWithDefault(d.env, ""); s != "" {
+			v, err := time.ParseDuration(s)
+			if err != nil {
+				return Config{}, fmt.Errorf("error parsing %s: %v", d.env, err)
+			}
+			*d.value = v
+		}
+	}
+
+	if s := util.GetEnvWithDefault("SERVER_MAX_HEADER_BYTES", ""); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return Config{}, fmt.Errorf("error parsing SERVER_MAX_HEADER_BYTES: %v", err)
+		}
+		config.MaxHeaderBytes = v
+	}
+
+	return config, nil
+}
@@ -0,0 +1,71 @@
+// Package server runs the API's HTTP server until it is told to stop.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/jtyers/tmaas-service-util/log"
+)
+
+func NewServer(config Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              config.Addr,
+		Handler:           handler,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+	}
+}
+
+// Run serves HTTP on config.Addr until ctx is done. It then stops accepting
+// connections and waits up to config.ShutdownTimeout for in-flight
+// requests to complete, before returning.
+func Run(ctx context.Context, config Config, handler http.Handler) error {
+	listener, err := net.Listen("tcp", config.Addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %v", config.Addr, err)
+	}
+
+	return Serve(ctx, listener, config, handler)
+}
+
+// Serve is Run, but accepts connections on an existing listener.
+func Serve(ctx context.Context, listener net.Listener, config Config, handler http.Handler) error {
+	srv := NewServer(config, handler)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		// the server stopped without being asked to
+		return fmt.Errorf("error while serving: %v", err)
+
+	case <-ctx.Done():
+	}
+
+	log.Infof("shutting down, waiting up to %v for in-flight requests", config.ShutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		// close whatever connections remain, rather than leave them open
+		srv.Close()
+		return fmt.Errorf("error shutting down server: %v", err)
+	}
+
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error while serving: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	var tests = []struct {
+		name            string
+		handlerDelay    time.Duration
+		shutdownTimeout time.Duration
+		expectErr       bool
+	}{
+		{
+			"should wait for in-flight requests to complete",
+			100 * time.Millisecond,
+			time.Second,
+			false,
+		},
+		{
+			"should give up on in-flight requests after the shutdown timeout",
+			time.Second,
+			50 * time.Millisecond,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.Nil(t, err)
+
+			started := make(chan struct{})
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				time.Sleep(test.handlerDelay)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			config := Config{ShutdownTimeout: test.shutdownTimeout}
+
+			served := make(chan error, 1)
+			go func() {
+				served <- Serve(ctx, listener, config, handler)
+			}()
+
+			responded := make(chan *http.Response, 1)
+			go func() {
+				response, _ := http.Get("http://" + listener.Addr().String())
+				responded <- response
+			}()
+
+			// when
+			<-started
+			cancel()
+
+			// then
+			err = <-served
+			response := <-responded
+
+			if test.expectErr {
+				require.NotNil(t, err)
+				require.Nil(t, response)
+			} else {
+				require.Nil(t, err)
+				require.NotNil(t, response)
+				require.Equal(t, http.StatusOK, response.StatusCode)
+			}
+
+			_, err = net.Dial("tcp", listener.Addr().String())
+			require.NotNil(t, err, "server should no longer accept connections")
+		})
+	}
+}
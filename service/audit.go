@@ -0,0 +1,214 @@
+package service
+
+//go:generate mockgen -source=$GOFILE -destination=${GOFILE}_mocks.go -package $GOPACKAGE
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jtyers/tmaas-service-util/log"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	dao "github.com/jtyers/tmaas-threat-model-api/dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+const (
+	DefaultAuditPageSize = 50
+	MaxAuditPageSize     = 500
+)
+
+var (
+	ErrAdminRequired         = errors.New("only administrators may call this API")
+	ErrInvalidAuditTimeRange = errors.New("audit query 'from' must be before 'to'")
+)
+
+// Auditor records audit events.
+type Auditor interface {
+	// Record an event. The time, actor and request ID are filled in from
+	// ctx. Events are written in the background, so that recording neither
+	// slows nor fails the action being audited; errors are logged.
+	Record(ctx context.Context, record tm.AuditRecord)
+}
+
+// AuditService records audit events and allows administrators to query them.
+type AuditService interface {
+	Auditor
+
+	// Retrieve a page of audit records of the caller's tenant, newest
+	// first. Only administrators may call this.
+	Query(ctx context.Context, q tm.AuditQuery) (*tm.AuditPage, error)
+
+	// Recompute the hash chain of a resource's audit records, reporting
+	// the first broken link if any. Only administrators may call this.
+	VerifyChain(ctx context.Context, resourceType string, resourceID string) (*tm.AuditVerification, error)
+}
+
+type DefaultAuditService struct {
+	dao    dao.AuditDao
+	writer *AuditWriter
+	key    tm.AuditKey
+	now    func() time.Time
+}
+
+var _ AuditService = (*DefaultAuditService)(nil)
+
+func NewDefaultAuditService(dao dao.AuditDao, writer *AuditWriter, key tm.AuditKey) *DefaultAuditService {
+	return &DefaultAuditService{dao: dao, writer: writer, key: key, now: time.Now}
+}
+
+func (s *DefaultAuditService) Record(ctx context.Context, record tm.AuditRecord) {
+	record.Time = s.now().UTC()
+	record.RequestID = RequestIDFromContext(ctx)
+
+	identity, err := auth.IdentityFromContext(ctx)
+	if err == nil {
+		record.Actor = identity.String()
+		record.ActorType = tm.AuditActorUser
+		if identity.IsServiceAccount() {
+			record.ActorType = tm.AuditActorServiceAccount
+		}
+	}
+
+	// records are stored per tenant; callers without one (typically
+	// unauthenticated requests) can only be logged
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		log.Infof("audit: %+v", record)
+		return
+	}
+
+	s.writer.Enqueue(tenantID, record)
+}
+
+func (s *DefaultAuditService) Query(ctx context.Context, q tm.AuditQuery) (*tm.AuditPage, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return nil, ErrInvalidAuditTimeRange
+	}
+
+	if q.PageSize <= 0 {
+		q.PageSize = DefaultAuditPageSize
+	}
+	if q.PageSize > MaxAuditPageSize {
+		q.PageSize = MaxAuditPageSize
+	}
+
+	return s.dao.Query(ctx, q)
+}
+
+func (s *DefaultAuditService) VerifyChain(ctx context.Context, resourceType string, resourceID string) (*tm.AuditVerification, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if s.key == nil {
+		return nil, ErrAuditKeyNotConfigured
+	}
+
+	records, head, err := s.dao.GetChain(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("error in VerifyChain: %v", err)
+	}
+
+	result := &tm.AuditVerification{ResourceType: resourceType, ResourceID: resourceID}
+	result.BrokenLink, result.RecordsChecked = verifyAuditChain(records, head, s.key)
+	result.Valid = result.BrokenLink == nil
+
+	return result, nil
+}
+
+// verifyAuditChain checks each link of a chain, hashed with key, in turn,
+// returning the first broken one (or nil) and the number of records
+// checked.
+func verifyAuditChain(records []*tm.AuditRecord, head *dao.AuditChainHead, key tm.AuditKey) (*tm.AuditBrokenLink, int) {
+	previousHash := ""
+
+	for i, record := range records {
+		sequence := int64(i + 1)
+
+		if record.Sequence != sequence {
+			// a record has been removed (or re-sequenced) before this one
+			return &tm.AuditBrokenLink{Sequence: sequence, Reason: "record is missing"}, i
+		}
+
+		if record.PreviousHash != previousHash {
+			return &tm.AuditBrokenLink{
+				Sequence:      sequence,
+				AuditRecordID: record.AuditRecordID,
+				Reason:        "previous hash does not match the preceding record",
+			}, i + 1
+		}
+
+		if !hmac.Equal([]byte(record.Hash), []byte(record.ComputeHash(key))) {
+			return &tm.AuditBrokenLink{
+				Sequence:      sequence,
+				AuditRecordID: record.AuditRecordID,
+				Reason:        "hash does not match the record's contents",
+			}, i + 1
+		}
+
+		previousHash = record.Hash
+	}
+
+	// the head is written alongside every record, so anything beyond the
+	// last record we have has been removed
+	switch {
+	case head == nil && len(records) > 0:
+		return &tm.AuditBrokenLink{Sequence: int64(len(records)), Reason: "chain head is missing"}, len(records)
+
+	case head != nil && head.Sequence > int64(len(records)):
+		return &tm.AuditBrokenLink{Sequence: int64(len(records) + 1), Reason: "record is missing"}, len(records)
+
+	case head != nil && head.Hash != previousHash:
+		return &tm.AuditBrokenLink{Sequence: head.Sequence, Reason: "chain head does not match the last record"}, len(records)
+	}
+
+	return nil, len(records)
+}
+
+// requireAdmin returns an error unless the caller is an administrator.
+func requireAdmin(ctx context.Context) error {
+	identity, err := auth.IdentityFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !identity.IsAdmin() {
+		return ErrAdminRequired
+	}
+	return nil
+}
+
+// AuditOutcomeForError returns the outcome of an action that returned err.
+func AuditOutcomeForError(err error) tm.AuditOutcome {
+	switch {
+	case err == nil:
+		return tm.AuditOutcomeSuccess
+	case errors.Is(err, auth.ErrNoIdentity),
+		errors.Is(err, auth.ErrNoTenant),
+		errors.Is(err, ErrInsufficientProjectRole),
+		errors.Is(err, ErrAdminRequired):
+		return tm.AuditOutcomeDenied
+	default:
+		return tm.AuditOutcomeFailure
+	}
+}
+
+type requestIDContextKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the request being
+// served, which is recorded against each audit event.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID placed into ctx by
+// WithRequestID, or "" if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
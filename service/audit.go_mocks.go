@@ -0,0 +1,113 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: audit.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+// MockAuditor is a mock of Auditor interface.
+type MockAuditor struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditorMockRecorder
+}
+
+// MockAuditorMockRecorder is the mock recorder for MockAuditor.
+type MockAuditorMockRecorder struct {
+	mock *MockAuditor
+}
+
+// NewMockAuditor creates a new mock instance.
+func NewMockAuditor(ctrl *gomock.Controller) *MockAuditor {
+	mock := &MockAuditor{ctrl: ctrl}
+	mock.recorder = &MockAuditorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditor) EXPECT() *MockAuditorMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method.
+func (m *MockAuditor) Record(ctx context.Context, record model.AuditRecord) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", ctx, record)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockAuditorMockRecorder) Record(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditor)(nil).Record), ctx, record)
+}
+
+// MockAuditService is a mock of AuditService interface.
+type MockAuditService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditServiceMockRecorder
+}
+
+// MockAuditServiceMockRecorder is the mock recorder for MockAuditService.
+type MockAuditServiceMockRecorder struct {
+	mock *MockAuditService
+}
+
+// NewMockAuditService creates a new mock instance.
+func NewMockAuditService(ctrl *gomock.Controller) *MockAuditService {
+	mock := &MockAuditService{ctrl: ctrl}
+	mock.recorder = &MockAuditServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditService) EXPECT() *MockAuditServiceMockRecorder {
+	return m.recorder
+}
+
+// Query mocks base method.
+func (m *MockAuditService) Query(ctx context.Context, q model.AuditQuery) (*model.AuditPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, q)
+	ret0, _ := ret[0].(*model.AuditPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockAuditServiceMockRecorder) Query(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockAuditService)(nil).Query), ctx, q)
+}
+
+// Record mocks base method.
+func (m *MockAuditService) Record(ctx context.Context, record model.AuditRecord) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", ctx, record)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockAuditServiceMockRecorder) Record(ctx, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditService)(nil).Record), ctx, record)
+}
+
+// VerifyChain mocks base method.
+func (m *MockAuditService) VerifyChain(ctx context.Context, resourceType, resourceID string) (*model.AuditVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyChain", ctx, resourceType, resourceID)
+	ret0, _ := ret[0].(*model.AuditVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyChain indicates an expected call of VerifyChain.
+func (mr *MockAuditServiceMockRecorder) VerifyChain(ctx, resourceType, resourceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyChain", reflect.TypeOf((*MockAuditService)(nil).VerifyChain), ctx, resourceType, resourceID)
+}
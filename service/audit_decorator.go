@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+
+	m "github.com/jtyers/tmaas-model"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+const (
+	AuditResourceThreatModel = "threatmodel"
+
+	AuditActionThreatModelGet    = "threatmodel.get"
+	AuditActionThreatModelList   = "threatmodel.list"
+	AuditActionThreatModelQuery  = "threatmodel.query"
+	AuditActionThreatModelSearch = "threatmodel.search"
+	AuditActionThreatModelCreate = "threatmodel.create"
+	AuditActionThreatModelUpdate = "threatmodel.update"
+	AuditActionThreatModelDelete = "threatmodel.delete"
+)
+
+// AuditingThreatModelService decorates a ThreatModelService, recording an
+// audit event for every call, successful or not, other than GetAll and
+// Query, whose results are recorded by ListThreatModels once filtered to
+// those the caller may view.
+type AuditingThreatModelService struct {
+	next    ThreatModelService
+	auditor Auditor
+}
+
+var _ ThreatModelService = (*AuditingThreatModelService)(nil)
+
+func NewAuditingThreatModelService(next *CachingThreatModelService, auditor Auditor) *AuditingThreatModelService {
+	return &AuditingThreatModelService{next, auditor}
+}
+
+func (s *AuditingThreatModelService) record(ctx context.Context, action string, resourceID string, err error) {
+	recordThreatModel(ctx, s.auditor, action, resourceID, err)
+}
+
+// recordThreatModels records action against each of threatModels, as
+// GetMany does, or once, against no threat model, if err is not nil.
+func recordThreatModels(ctx context.Context, auditor Auditor, action string, threatModels []*m.ThreatModel, err error) {
+	if err != nil {
+		recordThreatModel(ctx, auditor, action, "", err)
+		return
+	}
+
+	for _, threatModel := range threatModels {
+		recordThreatModel(ctx, auditor, action, threatModel.ThreatModelID.String(), nil)
+	}
+}
+
+// ListThreatModels returns those of the threat models matching q (or all
+// threat models if q is nil) that the caller may view, recording a list
+// (or query) of each one returned. Threat models the caller is not shown
+// are not recorded, so the audit trail holds only what each caller saw.
+func ListThreatModels(ctx context.Context, ts ThreatModelService, checker ThreatModelAccessChecker, auditor Auditor, q *m.ThreatModelQuery) ([]*m.ThreatModel, error) {
+	action := AuditActionThreatModelList
+
+	var threatModels []*m.ThreatModel
+	var err error
+	if q == nil {
+		threatModels, err = ts.GetAll(ctx)
+	} else {
+		action = AuditActionThreatModelQuery
+		threatModels, err = ts.Query(ctx, q)
+	}
+	if err == nil {
+		threatModels, err = FilterThreatModels(ctx, checker, threatModels, tm.ProjectRoleViewer)
+	}
+
+	recordThreatModels(ctx, auditor, action, threatModels, err)
+	if err != nil {
+		return nil, err
+	}
+	return threatModels, nil
+}
+
+func recordThreatModel(ctx context.Context, auditor Auditor, action string, resourceID string, err error) {
+	record := tm.AuditRecord{
+		Action:       action,
+		ResourceType: AuditResourceThreatModel,
+		ResourceID:   resourceID,
+		Outcome:      AuditOutcomeForError(err),
+	}
+	if err != nil {
+		record.Detail = err.Error()
+	}
+
+	auditor.Record(ctx, record)
+}
+
+func (s *AuditingThreatModelService) Get(ctx context.Context, id m.ThreatModelID) (*m.ThreatModel, error) {
+	threatModel, err := s.next.Get(ctx, id)
+	s.record(ctx, AuditActionThreatModelGet, id.String(), err)
+	return threatModel, err
+}
+
+// GetMany records a get of each threat model, as if each were retrieved
+// in turn, so that every read appears in the threat model's audit trail.
+// A failed call is recorded once, as it may have been given any number
+// of IDs.
+func (s *AuditingThreatModelService) GetMany(ctx context.Context, ids []m.ThreatModelID) (*tm.BatchGetResult, error) {
+	result, err := s.next.GetMany(ctx, ids)
+	if err != nil {
+		s.record(ctx, AuditActionThreatModelGet, "", err)
+		return nil, err
+	}
+
+	for _, threatModel := range result.ThreatModels {
+		s.record(ctx, AuditActionThreatModelGet, threatModel.ThreatModelID.String(), nil)
+	}
+	for _, id := range result.Missing {
+		s.record(ctx, AuditActionThreatModelGet, id.String(), ErrNoSuchThreatModel)
+	}
+
+	return result, nil
+}
+
+// GetAll records nothing: the threat models returned include those the
+// caller may not view, so callers record those they return once filtered,
+// with ListThreatModels.
+func (s *AuditingThreatModelService) GetAll(ctx context.Context) ([]*m.ThreatModel, error) {
+	return s.next.GetAll(ctx)
+}
+
+// Query records nothing, as GetAll records nothing.
+func (s *AuditingThreatModelService) Query(ctx context.Context, q *m.ThreatModelQuery) ([]*m.ThreatModel, error) {
+	return s.next.Query(ctx, q)
+}
+
+func (s *AuditingThreatModelService) QuerySingle(ctx context.Context, q *m.ThreatModelQuery) (*m.ThreatModel, error) {
+	threatModel, err := s.next.QuerySingle(ctx, q)
+
+	resourceID := ""
+	if threatModel != nil {
+		resourceID = threatModel.ThreatModelID.String()
+	}
+	s.record(ctx, AuditActionThreatModelQuery, resourceID, err)
+
+	return threatModel, err
+}
+
+func (s *AuditingThreatModelService) Create(ctx context.Context, params m.ThreatModelParams) (*m.ThreatModel, error) {
+	threatModel, err := s.next.Create(ctx, params)
+
+	resourceID := ""
+	if threatModel != nil {
+		resourceID = threatModel.ThreatModelID.String()
+	}
+	s.record(ctx, AuditActionThreatModelCreate, resourceID, err)
+
+	return threatModel, err
+}
+
+func (s *AuditingThreatModelService) Update(ctx context.Context, id m.ThreatModelID, params m.ThreatModelParams) (*m.ThreatModel, error) {
+	threatModel, err := s.next.Update(ctx, id, params)
+	s.record(ctx, AuditActionThreatModelUpdate, id.String(), err)
+	return threatModel, err
+}
+
+func (s *AuditingThreatModelService) Delete(ctx context.Context, id m.ThreatModelID) error {
+	err := s.next.Delete(ctx, id)
+	s.record(ctx, AuditActionThreatModelDelete, id.String(), err)
+	return err
+}
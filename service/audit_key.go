@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	util "github.com/jtyers/tmaas-service-util"
+	"github.com/jtyers/tmaas-service-util/log"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	cloudkms "google.golang.org/api/cloudkms/v1"
+)
+
+// MinAuditKeyBytes is the shortest audit key accepted: the size of the
+// SHA-256 output, as recommended for HMAC keys.
+const MinAuditKeyBytes = 32
+
+var (
+	ErrAuditKeyNotConfigured = errors.New("audit records are not hash chained, as AUDIT_KMS_KEY and AUDIT_KEY_CIPHERTEXT are not set")
+	ErrAuditKeyTooShort      = fmt.Errorf("the audit key must be at least %d bytes", MinAuditKeyBytes)
+)
+
+// AuditKeyConfig locates the key audit record hashes are computed with.
+// The key is kept encrypted by a Cloud KMS key, so that it is only usable
+// by those allowed to decrypt with that key, and never stored in plain.
+type AuditKeyConfig struct {
+	// The resource name of the KMS key the audit key is encrypted with, eg
+	// "projects/p/locations/l/keyRings/r/cryptoKeys/k".
+	KMSKey string
+
+	// The audit key encrypted with KMSKey, base64 encoded, as output by
+	// "gcloud kms encrypt".
+	Ciphertext string
+}
+
+// NewAuditKeyConfig reads the KMS key from AUDIT_KMS_KEY and the encrypted
+// audit key from AUDIT_KEY_CIPHERTEXT.
+func NewAuditKeyConfig() AuditKeyConfig {
+	return AuditKeyConfig{
+		KMSKey:     util.GetEnvWithDefault("AUDIT_KMS_KEY", ""),
+		Ciphertext: util.GetEnvWithDefault("AUDIT_KEY_CIPHERTEXT", ""),
+	}
+}
+
+// NewAuditKey decrypts the audit key with Cloud KMS. It is decrypted once,
+// at startup, so that recording and verifying records costs no KMS calls.
+//
+// The key is optional: unless config gives both the KMS key and the
+// ciphertext, NewAuditKey logs a warning and returns a nil key. Records are
+// then written but not linked into hash chains, and VerifyChain returns
+// ErrAuditKeyNotConfigured. Once a key is configured, the records written
+// in the meantime are linked along with any since.
+func NewAuditKey(ctx context.Context, config AuditKeyConfig) (tm.AuditKey, error) {
+	if config.KMSKey == "" || config.Ciphertext == "" {
+		log.Warnf("audit records will not be hash chained: set both AUDIT_KMS_KEY and AUDIT_KEY_CIPHERTEXT to chain them")
+		return nil, nil
+	}
+
+	kms, err := cloudkms.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Cloud KMS client: %v", err)
+	}
+
+	response, err := kms.Projects.Locations.KeyRings.CryptoKeys.
+		Decrypt(config.KMSKey, &cloudkms.DecryptRequest{Ciphertext: config.Ciphertext}).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting audit key with %s: %v", config.KMSKey, err)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(response.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding audit key: %v", err)
+	}
+	if len(key) < MinAuditKeyBytes {
+		return nil, ErrAuditKeyTooShort
+	}
+
+	return tm.AuditKey(key), nil
+}
@@ -0,0 +1,669 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var testAuditKey = tm.AuditKey("0123456789abcdef0123456789abcdef")
+
+func TestAuditRecord(t *testing.T) {
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		name     string
+		identity *auth.Identity
+		expected *tm.AuditRecord
+	}{
+		{
+			"should record user identity and request ID",
+			&auth.Identity{UserID: "u-1234", TenantID: "acme"},
+			&tm.AuditRecord{
+				Time:      now,
+				Actor:     "u-1234",
+				ActorType: tm.AuditActorUser,
+				Action:    AuditActionThreatModelGet,
+				Outcome:   tm.AuditOutcomeSuccess,
+				RequestID: "req-1",
+			},
+		},
+		{
+			"should record service account identity",
+			&auth.Identity{ServiceAccountName: "sa-sync", TenantID: "acme"},
+			&tm.AuditRecord{
+				Time:      now,
+				Actor:     "sa-sync",
+				ActorType: tm.AuditActorServiceAccount,
+				Action:    AuditActionThreatModelGet,
+				Outcome:   tm.AuditOutcomeSuccess,
+				RequestID: "req-1",
+			},
+		},
+		{
+			"should not store records without a tenant",
+			nil,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao := dao.NewMockAuditDao(ctrl)
+
+			ctx := WithRequestID(context.Background(), "req-1")
+			if test.identity != nil {
+				ctx = auth.WithIdentity(ctx, test.identity)
+			}
+
+			if test.expected != nil {
+				mockDao.EXPECT().Append(gomock.Any(), []tm.AuditRecord{*test.expected}).DoAndReturn(
+					func(ctx context.Context, records []tm.AuditRecord) error {
+						tenantID, err := auth.TenantIDFromContext(ctx)
+						require.Nil(t, err)
+						require.Equal(t, test.identity.TenantID, tenantID)
+						return nil
+					})
+			}
+
+			writer := NewAuditWriter(mockDao, testAuditKey, metrics.NewMetrics())
+			service := NewDefaultAuditService(mockDao, writer, testAuditKey)
+			service.now = func() time.Time { return now }
+
+			// when
+			service.Record(ctx, tm.AuditRecord{
+				Action:  AuditActionThreatModelGet,
+				Outcome: tm.AuditOutcomeSuccess,
+			})
+			writer.Flush(context.Background())
+		})
+	}
+}
+
+func TestAuditQuery(t *testing.T) {
+	from := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+
+	var tests = []struct {
+		name          string
+		identity      *auth.Identity
+		query         tm.AuditQuery
+		expectedQuery *tm.AuditQuery
+		expectedError error
+	}{
+		{
+			"should apply default page size",
+			&auth.Identity{UserID: "u-1234", TenantID: "acme", Admin: true},
+			tm.AuditQuery{ResourceID: "tm-1"},
+			&tm.AuditQuery{ResourceID: "tm-1", PageSize: DefaultAuditPageSize},
+			nil,
+		},
+		{
+			"should cap page size",
+			&auth.Identity{ServiceAccountName: "sa-sync", TenantID: "acme"},
+			tm.AuditQuery{PageSize: 10000},
+			&tm.AuditQuery{PageSize: MaxAuditPageSize},
+			nil,
+		},
+		{
+			"should reject users who are not administrators",
+			&auth.Identity{UserID: "u-1234", TenantID: "acme"},
+			tm.AuditQuery{},
+			nil,
+			ErrAdminRequired,
+		},
+		{
+			"should reject callers without an identity",
+			nil,
+			tm.AuditQuery{},
+			nil,
+			auth.ErrNoIdentity,
+		},
+		{
+			"should reject empty time ranges",
+			&auth.Identity{UserID: "u-1234", TenantID: "acme", Admin: true},
+			tm.AuditQuery{From: &to, To: &from},
+			nil,
+			ErrInvalidAuditTimeRange,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao := dao.NewMockAuditDao(ctrl)
+
+			ctx := context.Background()
+			if test.identity != nil {
+				ctx = auth.WithIdentity(ctx, test.identity)
+			}
+
+			page := &tm.AuditPage{Records: []*tm.AuditRecord{}}
+			if test.expectedQuery != nil {
+				mockDao.EXPECT().Query(ctx, *test.expectedQuery).Return(page, nil)
+			}
+
+			service := NewDefaultAuditService(mockDao, nil, testAuditKey)
+
+			// when
+			result, err := service.Query(ctx, test.query)
+
+			// then
+			if test.expectedError != nil {
+				require.Equal(t, test.expectedError, err)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, page, result)
+			}
+		})
+	}
+}
+
+// recordingAuditor keeps the events it is given.
+type recordingAuditor struct {
+	records []tm.AuditRecord
+}
+
+func (a *recordingAuditor) Record(ctx context.Context, record tm.AuditRecord) {
+	a.records = append(a.records, record)
+}
+
+func TestAuditingThreatModelService(t *testing.T) {
+	id := m.NewThreatModelIDP("tm-1")
+	threatModel := &m.ThreatModel{ThreatModelID: id}
+	otherErr := errors.New("datastore unavailable")
+
+	var tests = []struct {
+		name     string
+		call     func(s ThreatModelService) error
+		setup    func(mockService *MockThreatModelService)
+		expected tm.AuditRecord
+	}{
+		{
+			"should record successful reads",
+			func(s ThreatModelService) error {
+				_, err := s.Get(context.Background(), id)
+				return err
+			},
+			func(mockService *MockThreatModelService) {
+				mockService.EXPECT().Get(gomock.Any(), id).Return(threatModel, nil)
+			},
+			tm.AuditRecord{
+				Action:       AuditActionThreatModelGet,
+				ResourceType: AuditResourceThreatModel,
+				ResourceID:   "tm-1",
+				Outcome:      tm.AuditOutcomeSuccess,
+			},
+		},
+		{
+			"should record denied deletes",
+			func(s ThreatModelService) error {
+				return s.Delete(context.Background(), id)
+			},
+			func(mockService *MockThreatModelService) {
+				mockService.EXPECT().Delete(gomock.Any(), id).Return(ErrInsufficientProjectRole)
+			},
+			tm.AuditRecord{
+				Action:       AuditActionThreatModelDelete,
+				ResourceType: AuditResourceThreatModel,
+				ResourceID:   "tm-1",
+				Outcome:      tm.AuditOutcomeDenied,
+				Detail:       ErrInsufficientProjectRole.Error(),
+			},
+		},
+		{
+			"should record failed creates",
+			func(s ThreatModelService) error {
+				_, err := s.Create(context.Background(), m.ThreatModelParams{})
+				return err
+			},
+			func(mockService *MockThreatModelService) {
+				mockService.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, otherErr)
+			},
+			tm.AuditRecord{
+				Action:       AuditActionThreatModelCreate,
+				ResourceType: AuditResourceThreatModel,
+				Outcome:      tm.AuditOutcomeFailure,
+				Detail:       otherErr.Error(),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := NewMockThreatModelService(ctrl)
+			test.setup(mockService)
+
+			auditor := &recordingAuditor{}
+			service := &AuditingThreatModelService{mockService, auditor}
+
+			// when
+			test.call(service)
+
+			// then
+			require.Equal(t, []tm.AuditRecord{test.expected}, auditor.records)
+		})
+	}
+}
+
+func TestListThreatModels(t *testing.T) {
+	threatModels := []*m.ThreatModel{
+		{ThreatModelID: m.NewThreatModelIDP("tm-1")},
+		{ThreatModelID: m.NewThreatModelIDP("tm-2")},
+	}
+	otherErr := errors.New("datastore unavailable")
+
+	// tm-2 is in a project the caller has no role on
+	visible := func(ctx context.Context, ids []m.ThreatModelID, role tm.ProjectRole) ([]m.ThreatModelID, error) {
+		result := []m.ThreatModelID{}
+		for _, id := range ids {
+			if id != m.NewThreatModelIDP("tm-2") {
+				result = append(result, id)
+			}
+		}
+		return result, nil
+	}
+
+	var tests = []struct {
+		name     string
+		query    *m.ThreatModelQuery
+		setup    func(mockService *MockThreatModelService, mockChecker *MockThreatModelAccessChecker)
+		expected []*m.ThreatModel
+		records  []tm.AuditRecord
+	}{
+		{
+			"should record only the threat models listed that the caller is shown",
+			nil,
+			func(mockService *MockThreatModelService, mockChecker *MockThreatModelAccessChecker) {
+				mockService.EXPECT().GetAll(gomock.Any()).Return(threatModels, nil)
+				mockChecker.EXPECT().FilterThreatModelAccess(gomock.Any(), gomock.Any(), tm.ProjectRoleViewer).DoAndReturn(visible)
+			},
+			threatModels[:1],
+			[]tm.AuditRecord{
+				{Action: AuditActionThreatModelList, ResourceType: AuditResourceThreatModel, ResourceID: "tm-1", Outcome: tm.AuditOutcomeSuccess},
+			},
+		},
+		{
+			"should record each threat model queried that the caller is shown",
+			&m.ThreatModelQuery{},
+			func(mockService *MockThreatModelService, mockChecker *MockThreatModelAccessChecker) {
+				mockService.EXPECT().Query(gomock.Any(), gomock.Any()).Return(threatModels, nil)
+				mockChecker.EXPECT().FilterThreatModelAccess(gomock.Any(), gomock.Any(), tm.ProjectRoleViewer).DoAndReturn(visible)
+			},
+			threatModels[:1],
+			[]tm.AuditRecord{
+				{Action: AuditActionThreatModelQuery, ResourceType: AuditResourceThreatModel, ResourceID: "tm-1", Outcome: tm.AuditOutcomeSuccess},
+			},
+		},
+		{
+			"should record failed lists once",
+			nil,
+			func(mockService *MockThreatModelService, mockChecker *MockThreatModelAccessChecker) {
+				mockService.EXPECT().GetAll(gomock.Any()).Return(nil, otherErr)
+			},
+			nil,
+			[]tm.AuditRecord{
+				{Action: AuditActionThreatModelList, ResourceType: AuditResourceThreatModel, Outcome: tm.AuditOutcomeFailure, Detail: otherErr.Error()},
+			},
+		},
+		{
+			"should record failed access checks once",
+			nil,
+			func(mockService *MockThreatModelService, mockChecker *MockThreatModelAccessChecker) {
+				mockService.EXPECT().GetAll(gomock.Any()).Return(threatModels, nil)
+				mockChecker.EXPECT().FilterThreatModelAccess(gomock.Any(), gomock.Any(), tm.ProjectRoleViewer).Return(nil, otherErr)
+			},
+			nil,
+			[]tm.AuditRecord{
+				{Action: AuditActionThreatModelList, ResourceType: AuditResourceThreatModel, Outcome: tm.AuditOutcomeFailure, Detail: otherErr.Error()},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := NewMockThreatModelService(ctrl)
+			mockChecker := NewMockThreatModelAccessChecker(ctrl)
+			test.setup(mockService, mockChecker)
+
+			auditor := &recordingAuditor{}
+
+			// when
+			result, _ := ListThreatModels(context.Background(), &AuditingThreatModelService{mockService, auditor}, mockChecker, auditor, test.query)
+
+			// then
+			require.Equal(t, test.expected, result)
+			require.Equal(t, test.records, auditor.records)
+		})
+	}
+}
+
+func TestAuditWriterLinksRecordsPerResource(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDao := dao.NewMockAuditDao(ctrl)
+
+	tm1a := &tm.AuditRecord{AuditRecordID: "aud-1", ResourceType: AuditResourceThreatModel, ResourceID: "tm-1"}
+	tm2 := &tm.AuditRecord{AuditRecordID: "aud-2", ResourceType: AuditResourceThreatModel, ResourceID: "tm-2"}
+	tm1b := &tm.AuditRecord{AuditRecordID: "aud-3", ResourceType: AuditResourceThreatModel, ResourceID: "tm-1"}
+
+	mockDao.EXPECT().GetTenants(gomock.Any()).Return([]auth.TenantID{"acme", "globex"}, nil)
+	mockDao.EXPECT().GetUnlinked(gomock.Any(), auditLinkBatchSize).DoAndReturn(func(ctx context.Context, limit int) ([]*tm.AuditRecord, error) {
+		if tenantID, _ := auth.TenantIDFromContext(ctx); tenantID == "globex" {
+			return nil, errors.New("datastore unavailable")
+		}
+		return []*tm.AuditRecord{tm1a, tm2, tm1b}, nil
+	}).Times(2)
+	mockDao.EXPECT().Link(gomock.Any(), []*tm.AuditRecord{tm1a, tm1b}, testAuditKey).Return(nil)
+	mockDao.EXPECT().Link(gomock.Any(), []*tm.AuditRecord{tm2}, testAuditKey).Return(nil)
+	mockDao.EXPECT().CountUnlinked(gomock.Any()).Return(0, nil).Times(2)
+
+	// when
+	err := NewAuditWriter(mockDao, testAuditKey, metrics.NewMetrics()).LinkOnce(context.Background())
+
+	// then
+	require.Nil(t, err)
+}
+
+func TestAuditWriterLinksBacklogUntilNonePending(t *testing.T) {
+	// given a backlog of more than one batch
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDao := dao.NewMockAuditDao(ctrl)
+	metrics := metrics.NewMetrics()
+
+	batch := func(from, n int) []*tm.AuditRecord {
+		records := []*tm.AuditRecord{}
+		for i := from; i < from+n; i++ {
+			records = append(records, &tm.AuditRecord{AuditRecordID: fmt.Sprintf("aud-%d", i), ResourceType: AuditResourceThreatModel, ResourceID: "tm-1"})
+		}
+		return records
+	}
+	first := batch(0, auditLinkBatchSize)
+	second := batch(auditLinkBatchSize, auditLinkBatchSize)
+	last := batch(2*auditLinkBatchSize, 10)
+
+	mockDao.EXPECT().GetTenants(gomock.Any()).Return([]auth.TenantID{"acme"}, nil)
+	gomock.InOrder(
+		mockDao.EXPECT().GetUnlinked(gomock.Any(), auditLinkBatchSize).Return(first, nil),
+		mockDao.EXPECT().Link(gomock.Any(), first, testAuditKey).Return(nil),
+		mockDao.EXPECT().GetUnlinked(gomock.Any(), auditLinkBatchSize).Return(second, nil),
+		mockDao.EXPECT().Link(gomock.Any(), second, testAuditKey).Return(nil),
+		mockDao.EXPECT().GetUnlinked(gomock.Any(), auditLinkBatchSize).Return(last, nil),
+		mockDao.EXPECT().Link(gomock.Any(), last, testAuditKey).Return(nil),
+		mockDao.EXPECT().CountUnlinked(gomock.Any()).Return(3, nil),
+	)
+
+	// when
+	err := NewAuditWriter(mockDao, testAuditKey, metrics).LinkOnce(context.Background())
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, 3.0, testutil.ToFloat64(metrics.AuditPendingRecords.WithLabelValues("acme")))
+}
+
+func TestAuditWriterStopsLinkingResourceThatFails(t *testing.T) {
+	// given a full batch whose resource cannot be linked
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDao := dao.NewMockAuditDao(ctrl)
+
+	records := []*tm.AuditRecord{}
+	for i := 0; i < auditLinkBatchSize; i++ {
+		records = append(records, &tm.AuditRecord{AuditRecordID: fmt.Sprintf("aud-%d", i), ResourceType: AuditResourceThreatModel, ResourceID: "tm-1"})
+	}
+
+	mockDao.EXPECT().GetTenants(gomock.Any()).Return([]auth.TenantID{"acme"}, nil)
+	mockDao.EXPECT().GetUnlinked(gomock.Any(), auditLinkBatchSize).Return(records, nil)
+	mockDao.EXPECT().Link(gomock.Any(), records, testAuditKey).Return(errors.New("datastore unavailable"))
+	mockDao.EXPECT().CountUnlinked(gomock.Any()).Return(auditLinkBatchSize, nil)
+
+	// when
+	err := NewAuditWriter(mockDao, testAuditKey, metrics.NewMetrics()).LinkOnce(context.Background())
+
+	// then the batch is left to the next interval rather than read again
+	require.Nil(t, err)
+}
+
+func TestAuditWriterRetriesFailedWrites(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDao := dao.NewMockAuditDao(ctrl)
+	writer := NewAuditWriter(mockDao, testAuditKey, metrics.NewMetrics())
+
+	first := tm.AuditRecord{Action: AuditActionThreatModelGet, ResourceID: "tm-1"}
+	second := tm.AuditRecord{Action: AuditActionThreatModelGet, ResourceID: "tm-2"}
+
+	gomock.InOrder(
+		mockDao.EXPECT().Append(gomock.Any(), []tm.AuditRecord{first}).Return(errors.New("datastore unavailable")),
+		mockDao.EXPECT().Append(gomock.Any(), []tm.AuditRecord{first, second}).Return(nil),
+	)
+
+	// when
+	writer.Enqueue("acme", first)
+	writer.Flush(context.Background())
+	writer.Enqueue("acme", second)
+	writer.Flush(context.Background())
+
+	// then nothing is left to write
+	writer.Flush(context.Background())
+}
+
+// auditChain builds an intact chain of n records, and its head.
+func auditChain(n int) ([]*tm.AuditRecord, *dao.AuditChainHead) {
+	records := []*tm.AuditRecord{}
+	previousHash := ""
+
+	for i := 1; i <= n; i++ {
+		record := &tm.AuditRecord{
+			AuditRecordID: fmt.Sprintf("aud-%d", i),
+			Time:          time.Date(2023, 4, 1, 12, i, 0, 0, time.UTC),
+			Actor:         "u-1234",
+			Action:        AuditActionThreatModelUpdate,
+			ResourceType:  AuditResourceThreatModel,
+			ResourceID:    "tm-1",
+			Outcome:       tm.AuditOutcomeSuccess,
+			Sequence:      int64(i),
+			PreviousHash:  previousHash,
+		}
+		record.Hash = record.ComputeHash(testAuditKey)
+		previousHash = record.Hash
+
+		records = append(records, record)
+	}
+
+	if n == 0 {
+		return records, nil
+	}
+	return records, &dao.AuditChainHead{Sequence: int64(n), Hash: previousHash}
+}
+
+func TestAuditVerifyChain(t *testing.T) {
+	var tests = []struct {
+		name          string
+		tamper        func(records []*tm.AuditRecord, head *dao.AuditChainHead) ([]*tm.AuditRecord, *dao.AuditChainHead)
+		expectedLink  *tm.AuditBrokenLink
+		expectedCount int
+	}{
+		{
+			"should accept an intact chain",
+			func(records []*tm.AuditRecord, head *dao.AuditChainHead) ([]*tm.AuditRecord, *dao.AuditChainHead) {
+				return records, head
+			},
+			nil,
+			4,
+		},
+		{
+			"should accept an empty chain",
+			func(records []*tm.AuditRecord, head *dao.AuditChainHead) ([]*tm.AuditRecord, *dao.AuditChainHead) {
+				return nil, nil
+			},
+			nil,
+			0,
+		},
+		{
+			"should detect altered records",
+			func(records []*tm.AuditRecord, head *dao.AuditChainHead) ([]*tm.AuditRecord, *dao.AuditChainHead) {
+				records[1].Actor = "u-5678"
+				return records, head
+			},
+			&tm.AuditBrokenLink{Sequence: 2, AuditRecordID: "aud-2", Reason: "hash does not match the record's contents"},
+			2,
+		},
+		{
+			"should detect records whose hashes were recomputed",
+			func(records []*tm.AuditRecord, head *dao.AuditChainHead) ([]*tm.AuditRecord, *dao.AuditChainHead) {
+				records[1].Actor = "u-5678"
+				records[1].Hash = records[1].ComputeHash(testAuditKey)
+				return records, head
+			},
+			&tm.AuditBrokenLink{Sequence: 3, AuditRecordID: "aud-3", Reason: "previous hash does not match the preceding record"},
+			3,
+		},
+		{
+			"should detect chains rehashed without the key",
+			func(records []*tm.AuditRecord, head *dao.AuditChainHead) ([]*tm.AuditRecord, *dao.AuditChainHead) {
+				previousHash := ""
+				for _, record := range records {
+					record.Actor = "u-5678"
+					record.PreviousHash = previousHash
+					record.Hash = record.ComputeHash(tm.AuditKey("a guess at the key"))
+					previousHash = record.Hash
+				}
+				return records, &dao.AuditChainHead{Sequence: head.Sequence, Hash: previousHash}
+			},
+			&tm.AuditBrokenLink{Sequence: 1, AuditRecordID: "aud-1", Reason: "hash does not match the record's contents"},
+			1,
+		},
+		{
+			"should detect removed records",
+			func(records []*tm.AuditRecord, head *dao.AuditChainHead) ([]*tm.AuditRecord, *dao.AuditChainHead) {
+				return append(records[:1], records[2:]...), head
+			},
+			&tm.AuditBrokenLink{Sequence: 2, Reason: "record is missing"},
+			1,
+		},
+		{
+			"should detect records removed from the end",
+			func(records []*tm.AuditRecord, head *dao.AuditChainHead) ([]*tm.AuditRecord, *dao.AuditChainHead) {
+				return records[:3], head
+			},
+			&tm.AuditBrokenLink{Sequence: 4, Reason: "record is missing"},
+			3,
+		},
+		{
+			"should detect a removed head",
+			func(records []*tm.AuditRecord, head *dao.AuditChainHead) ([]*tm.AuditRecord, *dao.AuditChainHead) {
+				return records, nil
+			},
+			&tm.AuditBrokenLink{Sequence: 4, Reason: "chain head is missing"},
+			4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao := dao.NewMockAuditDao(ctrl)
+			ctx := auth.WithIdentity(context.Background(), &auth.Identity{UserID: "u-1234", TenantID: "acme", Admin: true})
+
+			records, head := test.tamper(auditChain(4))
+			mockDao.EXPECT().GetChain(ctx, AuditResourceThreatModel, "tm-1").Return(records, head, nil)
+
+			service := NewDefaultAuditService(mockDao, nil, testAuditKey)
+
+			// when
+			result, err := service.VerifyChain(ctx, AuditResourceThreatModel, "tm-1")
+
+			// then
+			require.Nil(t, err)
+			require.Equal(t, &tm.AuditVerification{
+				ResourceType:   AuditResourceThreatModel,
+				ResourceID:     "tm-1",
+				Valid:          test.expectedLink == nil,
+				RecordsChecked: test.expectedCount,
+				BrokenLink:     test.expectedLink,
+			}, result)
+		})
+	}
+}
+
+func TestAuditVerifyChainRequiresAdmin(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := auth.WithIdentity(context.Background(), &auth.Identity{UserID: "u-1234", TenantID: "acme"})
+	service := NewDefaultAuditService(dao.NewMockAuditDao(ctrl), nil, testAuditKey)
+
+	// when
+	_, err := service.VerifyChain(ctx, AuditResourceThreatModel, "tm-1")
+
+	// then
+	require.Equal(t, ErrAdminRequired, err)
+}
+
+func TestAuditWithoutKey(t *testing.T) {
+	// given no audit key configured
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key, err := NewAuditKey(context.Background(), AuditKeyConfig{})
+	require.Nil(t, err)
+	require.Nil(t, key)
+
+	// records are still written, but never read back to be linked
+	mockDao := dao.NewMockAuditDao(ctrl)
+	record := tm.AuditRecord{Action: AuditActionThreatModelGet, ResourceID: "tm-1"}
+	mockDao.EXPECT().Append(gomock.Any(), []tm.AuditRecord{record}).Return(nil)
+
+	writer := NewAuditWriter(mockDao, key, metrics.NewMetrics())
+	service := NewDefaultAuditService(mockDao, writer, key)
+
+	ctx := auth.WithIdentity(context.Background(), &auth.Identity{UserID: "u-1234", TenantID: "acme", Admin: true})
+
+	// when
+	writer.Enqueue("acme", record)
+	writer.Flush(context.Background())
+	linkErr := writer.LinkOnce(context.Background())
+	_, verifyErr := service.VerifyChain(ctx, AuditResourceThreatModel, "tm-1")
+
+	// then
+	require.Nil(t, linkErr)
+	require.Equal(t, ErrAuditKeyNotConfigured, verifyErr)
+}
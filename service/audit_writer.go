@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jtyers/tmaas-service-util/log"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	dao "github.com/jtyers/tmaas-threat-model-api/dao"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+const (
+	// the most records held waiting to be written; records beyond this are
+	// dropped (and logged) rather than holding up requests
+	auditQueueSize = 10000
+
+	// how often records are linked into their hash chains, and writes that
+	// failed are tried again
+	auditLinkInterval = 5 * time.Second
+
+	// the most unlinked records of a tenant read and linked at once
+	auditLinkBatchSize = 200
+
+	// how long linking may keep taking batches before leaving the rest of a
+	// backlog to the next interval
+	auditLinkBudget = 4 * time.Second
+
+	// the identity audit records are written and linked as
+	auditWriterServiceAccount = "audit-writer"
+)
+
+// pendingAuditRecord is a record waiting to be written to its tenant.
+type pendingAuditRecord struct {
+	tenantID auth.TenantID
+	record   tm.AuditRecord
+}
+
+// AuditWriter writes audit records off the request path. Records are
+// queued by Enqueue and written in batches, unlinked; if there is an audit
+// key, their hash chains are then extended by LinkOnce, one transaction per resource, so that neither
+// requests nor concurrent writers contend on a chain head. Records of any
+// instance are linked by whichever instance gets to them first.
+type AuditWriter struct {
+	dao     dao.AuditDao
+	key     tm.AuditKey
+	metrics *metrics.Metrics
+
+	mu     sync.Mutex
+	queue  []pendingAuditRecord
+	notify chan struct{}
+}
+
+func NewAuditWriter(dao dao.AuditDao, key tm.AuditKey, metrics *metrics.Metrics) *AuditWriter {
+	return &AuditWriter{dao: dao, key: key, metrics: metrics, notify: make(chan struct{}, 1)}
+}
+
+// Enqueue queues record to be written to the given tenant. It does not
+// block; if the queue is full the record is logged and dropped.
+func (w *AuditWriter) Enqueue(tenantID auth.TenantID, record tm.AuditRecord) {
+	w.mu.Lock()
+	full := len(w.queue) >= auditQueueSize
+	if !full {
+		w.queue = append(w.queue, pendingAuditRecord{tenantID, record})
+	}
+	w.mu.Unlock()
+
+	if full {
+		log.Errorf("audit queue full, dropping audit event %+v", record)
+		return
+	}
+
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Run writes queued records as they arrive, and links records every
+// auditLinkInterval, until ctx is done. Records queued after that are
+// written by Flush.
+func (w *AuditWriter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(auditLinkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-w.notify:
+			w.Flush(ctx)
+
+		case <-ticker.C:
+			w.Flush(ctx)
+
+			if err := w.LinkOnce(ctx); err != nil && ctx.Err() == nil {
+				log.Errorf("error linking audit records: %v", err)
+			}
+		}
+	}
+}
+
+// Flush writes the queued records. Records that cannot be written are
+// queued again, ahead of any queued since, to be tried by the next Flush.
+func (w *AuditWriter) Flush(ctx context.Context) {
+	w.mu.Lock()
+	queued := w.queue
+	w.queue = nil
+	w.mu.Unlock()
+
+	if len(queued) == 0 {
+		return
+	}
+
+	tenants := []auth.TenantID{}
+	byTenant := map[auth.TenantID][]tm.AuditRecord{}
+	for _, p := range queued {
+		if _, ok := byTenant[p.tenantID]; !ok {
+			tenants = append(tenants, p.tenantID)
+		}
+		byTenant[p.tenantID] = append(byTenant[p.tenantID], p.record)
+	}
+
+	failed := []pendingAuditRecord{}
+	for _, tenantID := range tenants {
+		if err := w.dao.Append(w.tenantContext(ctx, tenantID), byTenant[tenantID]); err != nil {
+			log.Errorf("error writing %d audit records of tenant %s: %v", len(byTenant[tenantID]), tenantID, err)
+
+			for _, record := range byTenant[tenantID] {
+				failed = append(failed, pendingAuditRecord{tenantID, record})
+			}
+		}
+	}
+
+	if len(failed) == 0 {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	requeued := append(failed, w.queue...)
+	if len(requeued) > auditQueueSize {
+		log.Errorf("audit queue full, dropping %d audit events", len(requeued)-auditQueueSize)
+		requeued = requeued[:auditQueueSize]
+	}
+	w.queue = requeued
+}
+
+// LinkOnce links unlinked records of each tenant into their resources'
+// hash chains, oldest first. Tenants take a batch each in turn until none
+// has records pending or auditLinkBudget runs out, so a large backlog in
+// one tenant neither holds up the others nor is left to a batch per
+// interval. A failure in one tenant or resource does not hold up the
+// others. The records left pending are then counted for each tenant.
+// Without an audit key records are left unlinked.
+func (w *AuditWriter) LinkOnce(ctx context.Context) error {
+	if w.key == nil {
+		return nil
+	}
+
+	tenants, err := w.dao.GetTenants(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(auditLinkBudget)
+
+	pending := tenants
+	for len(pending) > 0 && time.Now().Before(deadline) && ctx.Err() == nil {
+		remaining := []auth.TenantID{}
+		for _, tenantID := range pending {
+			more, err := w.linkBatch(w.tenantContext(ctx, tenantID))
+			if err != nil {
+				log.Errorf("error linking audit records of tenant %s: %v", tenantID, err)
+				continue
+			}
+			if more {
+				remaining = append(remaining, tenantID)
+			}
+		}
+		pending = remaining
+	}
+
+	for _, tenantID := range tenants {
+		n, err := w.dao.CountUnlinked(w.tenantContext(ctx, tenantID))
+		if err != nil {
+			log.Errorf("error counting unlinked audit records of tenant %s: %v", tenantID, err)
+			continue
+		}
+		w.metrics.AuditPendingRecords.WithLabelValues(tenantID.String()).Set(float64(n))
+	}
+
+	return nil
+}
+
+// linkBatch links the oldest batch of a tenant's unlinked records, and
+// reports whether more may be waiting. A resource that fails to link is
+// retried by the next LinkOnce rather than read again straight away.
+func (w *AuditWriter) linkBatch(ctx context.Context) (bool, error) {
+	records, err := w.dao.GetUnlinked(ctx, auditLinkBatchSize)
+	if err != nil {
+		return false, err
+	}
+
+	// link each resource's records in the order they were recorded
+	resources := []string{}
+	byResource := map[string][]*tm.AuditRecord{}
+	for _, record := range records {
+		resource := record.ResourceType + "/" + record.ResourceID
+		if _, ok := byResource[resource]; !ok {
+			resources = append(resources, resource)
+		}
+		byResource[resource] = append(byResource[resource], record)
+	}
+
+	failed := false
+	for _, resource := range resources {
+		if err := w.dao.Link(ctx, byResource[resource], w.key); err != nil {
+			log.Errorf("error linking audit records of %s: %v", resource, err)
+			failed = true
+		}
+	}
+
+	return len(records) == auditLinkBatchSize && !failed, nil
+}
+
+func (w *AuditWriter) tenantContext(ctx context.Context, tenantID auth.TenantID) context.Context {
+	return auth.WithIdentity(ctx, &auth.Identity{ServiceAccountName: auditWriterServiceAccount, TenantID: tenantID})
+}
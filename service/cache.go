@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-service-util/log"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/cache"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+const (
+	cacheResultHit   = "hit"
+	cacheResultMiss  = "miss"
+	cacheResultError = "error"
+)
+
+// CachingThreatModelService decorates a ThreatModelService, caching the
+// threat models returned by Get for each tenant. Updates and deletes made
+// through it invalidate the threat model's entry, so a stale entry can
+// only be read through another instance using an in-memory cache, or
+// after a Get racing a write, and then only until it expires.
+//
+// The cache is only an optimisation: if it fails, calls are made to the
+// decorated service as if there were no cache.
+type CachingThreatModelService struct {
+	next    ThreatModelService
+	cache   cache.Cache
+	config  cache.Config
+	metrics *metrics.Metrics
+}
+
+var _ ThreatModelService = (*CachingThreatModelService)(nil)
+
+func NewCachingThreatModelService(next *DefaultThreatModelService, cache cache.Cache, config cache.Config, metrics *metrics.Metrics) *CachingThreatModelService {
+	return &CachingThreatModelService{next, cache, config, metrics}
+}
+
+// cacheKey returns the key of the threat model with the given ID, or false
+// if ctx has no tenant, in which case the cache is bypassed.
+func cacheKey(ctx context.Context, id m.ThreatModelID) (string, bool) {
+	tenantID, err := auth.TenantIDFromContext(ctx)
+	if err != nil {
+		return "", false
+	}
+	return "threatmodel:" + tenantID.String() + ":" + id.String(), true
+}
+
+func (s *CachingThreatModelService) Get(ctx context.Context, id m.ThreatModelID) (*m.ThreatModel, error) {
+	key, ok := cacheKey(ctx, id)
+	if !ok || s.config.TTL <= 0 {
+		return s.next.Get(ctx, id)
+	}
+
+	if threatModel := s.get(ctx, key, id); threatModel != nil {
+		return threatModel, nil
+	}
+
+	threatModel, err := s.next.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.set(ctx, key, threatModel)
+	return threatModel, nil
+}
+
+// GetMany serves what it can from the cache, and retrieves the rest from
+// the decorated service in one call.
+func (s *CachingThreatModelService) GetMany(ctx context.Context, ids []m.ThreatModelID) (*tm.BatchGetResult, error) {
+	if _, err := auth.TenantIDFromContext(ctx); err != nil || s.config.TTL <= 0 || len(ids) > MaxGetManyIDs {
+		return s.next.GetMany(ctx, ids)
+	}
+
+	cached := map[m.ThreatModelID]*m.ThreatModel{}
+	uncached := []m.ThreatModelID{}
+	for _, id := range ids {
+		if _, ok := cached[id]; ok {
+			continue
+		}
+
+		key, _ := cacheKey(ctx, id)
+		cached[id] = s.get(ctx, key, id)
+		if cached[id] == nil {
+			uncached = append(uncached, id)
+		}
+	}
+
+	missing := map[m.ThreatModelID]bool{}
+	if len(uncached) > 0 {
+		result, err := s.next.GetMany(ctx, uncached)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, threatModel := range result.ThreatModels {
+			key, _ := cacheKey(ctx, threatModel.ThreatModelID)
+			s.set(ctx, key, threatModel)
+			cached[threatModel.ThreatModelID] = threatModel
+		}
+		for _, id := range result.Missing {
+			missing[id] = true
+		}
+	}
+
+	// reassembled in the order requested, each ID once
+	result := &tm.BatchGetResult{ThreatModels: []*m.ThreatModel{}, Missing: []m.ThreatModelID{}}
+	done := map[m.ThreatModelID]bool{}
+	for _, id := range ids {
+		if done[id] {
+			continue
+		}
+		done[id] = true
+
+		if threatModel := cached[id]; threatModel != nil {
+			result.ThreatModels = append(result.ThreatModels, threatModel)
+		} else if missing[id] {
+			result.Missing = append(result.Missing, id)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *CachingThreatModelService) GetAll(ctx context.Context) ([]*m.ThreatModel, error) {
+	return s.next.GetAll(ctx)
+}
+
+func (s *CachingThreatModelService) Query(ctx context.Context, q *m.ThreatModelQuery) ([]*m.ThreatModel, error) {
+	return s.next.Query(ctx, q)
+}
+
+func (s *CachingThreatModelService) QuerySingle(ctx context.Context, q *m.ThreatModelQuery) (*m.ThreatModel, error) {
+	return s.next.QuerySingle(ctx, q)
+}
+
+func (s *CachingThreatModelService) Create(ctx context.Context, params m.ThreatModelParams) (*m.ThreatModel, error) {
+	return s.next.Create(ctx, params)
+}
+
+func (s *CachingThreatModelService) Update(ctx context.Context, id m.ThreatModelID, params m.ThreatModelParams) (*m.ThreatModel, error) {
+	// invalidated whether or not the update succeeds, as a failed update
+	// may still have been written
+	defer s.invalidate(ctx, id)
+	return s.next.Update(ctx, id, params)
+}
+
+func (s *CachingThreatModelService) Delete(ctx context.Context, id m.ThreatModelID) error {
+	defer s.invalidate(ctx, id)
+	return s.next.Delete(ctx, id)
+}
+
+// get returns the threat model cached under key, or nil if there is none
+// or the cache fails.
+func (s *CachingThreatModelService) get(ctx context.Context, key string, id m.ThreatModelID) *m.ThreatModel {
+	value, found, err := s.cache.Get(ctx, key)
+	if err != nil {
+		log.Errorf("error reading threat model %s from cache: %v", id, err)
+		s.metrics.CacheRequests.WithLabelValues(cacheResultError).Inc()
+		return nil
+	}
+
+	if !found {
+		s.metrics.CacheRequests.WithLabelValues(cacheResultMiss).Inc()
+		return nil
+	}
+
+	threatModel := &m.ThreatModel{}
+	if err := json.Unmarshal(value, threatModel); err != nil {
+		log.Errorf("error decoding threat model %s from cache: %v", id, err)
+		s.metrics.CacheRequests.WithLabelValues(cacheResultError).Inc()
+		return nil
+	}
+
+	s.metrics.CacheRequests.WithLabelValues(cacheResultHit).Inc()
+	return threatModel
+}
+
+func (s *CachingThreatModelService) set(ctx context.Context, key string, threatModel *m.ThreatModel) {
+	value, err := json.Marshal(threatModel)
+	if err != nil {
+		log.Errorf("error encoding threat model %s for cache: %v", threatModel.ThreatModelID, err)
+		return
+	}
+
+	if err := s.cache.Set(ctx, key, value, s.config.TTL); err != nil {
+		log.Errorf("error writing threat model %s to cache: %v", threatModel.ThreatModelID, err)
+	}
+}
+
+func (s *CachingThreatModelService) invalidate(ctx context.Context, id m.ThreatModelID) {
+	key, ok := cacheKey(ctx, id)
+	if !ok {
+		return
+	}
+
+	if err := s.cache.Delete(ctx, key); err != nil {
+		log.Errorf("error invalidating cached threat model %s: %v", id, err)
+	}
+}
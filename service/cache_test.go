@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-threat-model-api/cache"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCachingThreatModelService(next ThreatModelService, c cache.Cache) (*CachingThreatModelService, *metrics.Metrics) {
+	metrics := metrics.NewMetrics()
+	return &CachingThreatModelService{next, c, cache.Config{TTL: time.Minute}, metrics}, metrics
+}
+
+func TestCachingThreatModelServiceGet(t *testing.T) {
+	id := m.NewThreatModelIDP("tm-1")
+	threatModel := &m.ThreatModel{ThreatModelID: id, Title: "Payments"}
+
+	var tests = []struct {
+		name          string
+		ctxs          []context.Context
+		expectedGets  int
+		expectedHits  float64
+		expectedMiss  float64
+		expectedTitle string
+	}{
+		{
+			"should read through on a miss and serve later calls from the cache",
+			[]context.Context{inTenant("acme"), inTenant("acme"), inTenant("acme")},
+			1, 2, 1, "Payments",
+		},
+		{
+			"should cache per tenant",
+			[]context.Context{inTenant("acme"), inTenant("globex")},
+			2, 0, 2, "Payments",
+		},
+		{
+			"should bypass the cache without a tenant",
+			[]context.Context{context.Background(), context.Background()},
+			2, 0, 0, "Payments",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := NewMockThreatModelService(ctrl)
+			mockService.EXPECT().Get(gomock.Any(), id).Return(threatModel, nil).Times(test.expectedGets)
+
+			service, metrics := newTestCachingThreatModelService(mockService, cache.NewMemoryCache(10))
+
+			for _, ctx := range test.ctxs {
+				// when
+				result, err := service.Get(ctx, id)
+
+				// then
+				require.Nil(t, err)
+				require.Equal(t, test.expectedTitle, result.Title)
+			}
+
+			require.Equal(t, test.expectedHits, testutil.ToFloat64(metrics.CacheRequests.WithLabelValues(cacheResultHit)))
+			require.Equal(t, test.expectedMiss, testutil.ToFloat64(metrics.CacheRequests.WithLabelValues(cacheResultMiss)))
+		})
+	}
+}
+
+func TestCachingThreatModelServiceInvalidatesOnWrite(t *testing.T) {
+	id := m.NewThreatModelIDP("tm-1")
+	before := &m.ThreatModel{ThreatModelID: id, Title: "Payments"}
+	after := &m.ThreatModel{ThreatModelID: id, Title: "Payments v2"}
+
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockThreatModelService(ctrl)
+	ctx := inTenant("acme")
+
+	gomock.InOrder(
+		mockService.EXPECT().Get(gomock.Any(), id).Return(before, nil),
+		mockService.EXPECT().Update(gomock.Any(), id, gomock.Any()).Return(after, nil),
+		mockService.EXPECT().Get(gomock.Any(), id).Return(after, nil),
+		mockService.EXPECT().Delete(gomock.Any(), id).Return(nil),
+		mockService.EXPECT().Get(gomock.Any(), id).Return(nil, ErrNoSuchThreatModel),
+	)
+
+	service, _ := newTestCachingThreatModelService(mockService, cache.NewMemoryCache(10))
+	service.Get(ctx, id)
+
+	// when
+	service.Update(ctx, id, m.ThreatModelParams{})
+	updated, err := service.Get(ctx, id)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, "Payments v2", updated.Title)
+
+	// when
+	service.Delete(ctx, id)
+	_, err = service.Get(ctx, id)
+
+	// then
+	require.ErrorIs(t, err, ErrNoSuchThreatModel)
+}
+
+func TestCachingThreatModelServiceFallsThroughOnCacheErrors(t *testing.T) {
+	id := m.NewThreatModelIDP("tm-1")
+	threatModel := &m.ThreatModel{ThreatModelID: id, Title: "Payments"}
+	cacheErr := errors.New("connection refused")
+
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockThreatModelService(ctrl)
+	mockService.EXPECT().Get(gomock.Any(), id).Return(threatModel, nil)
+	mockService.EXPECT().Delete(gomock.Any(), id).Return(nil)
+
+	mockCache := cache.NewMockCache(ctrl)
+	mockCache.EXPECT().Get(gomock.Any(), "threatmodel:acme:tm-1").Return(nil, false, cacheErr)
+	mockCache.EXPECT().Set(gomock.Any(), "threatmodel:acme:tm-1", gomock.Any(), time.Minute).Return(cacheErr)
+	mockCache.EXPECT().Delete(gomock.Any(), "threatmodel:acme:tm-1").Return(cacheErr)
+
+	service, metrics := newTestCachingThreatModelService(mockService, mockCache)
+	ctx := inTenant("acme")
+
+	// when
+	result, getErr := service.Get(ctx, id)
+	deleteErr := service.Delete(ctx, id)
+
+	// then
+	require.Nil(t, getErr)
+	require.Equal(t, "Payments", result.Title)
+	require.Nil(t, deleteErr)
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.CacheRequests.WithLabelValues(cacheResultError)))
+}
+
+func TestCachingThreatModelServiceGetMany(t *testing.T) {
+	tm1 := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-1"), Title: "Payments"}
+	tm2 := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-2"), Title: "Checkout"}
+	missingID := m.NewThreatModelIDP("tm-404")
+
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockThreatModelService(ctrl)
+	mockService.EXPECT().Get(gomock.Any(), tm1.ThreatModelID).Return(tm1, nil)
+	mockService.EXPECT().GetMany(gomock.Any(), []m.ThreatModelID{tm2.ThreatModelID, missingID}).Return(&tm.BatchGetResult{
+		ThreatModels: []*m.ThreatModel{tm2},
+		Missing:      []m.ThreatModelID{missingID},
+	}, nil)
+
+	service, metrics := newTestCachingThreatModelService(mockService, cache.NewMemoryCache(10))
+	ctx := inTenant("acme")
+	service.Get(ctx, tm1.ThreatModelID)
+
+	// when
+	result, err := service.GetMany(ctx, []m.ThreatModelID{tm2.ThreatModelID, tm1.ThreatModelID, missingID, tm1.ThreatModelID})
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, []*m.ThreatModel{tm2, tm1}, result.ThreatModels)
+	require.Equal(t, []m.ThreatModelID{missingID}, result.Missing)
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.CacheRequests.WithLabelValues(cacheResultHit)))
+
+	// when
+	result, err = service.GetMany(ctx, []m.ThreatModelID{tm1.ThreatModelID, tm2.ThreatModelID})
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, []*m.ThreatModel{tm1, tm2}, result.ThreatModels)
+	require.Equal(t, 3.0, testutil.ToFloat64(metrics.CacheRequests.WithLabelValues(cacheResultHit)))
+}
@@ -0,0 +1,315 @@
+package service
+
+//go:generate mockgen -source=$GOFILE -destination=${GOFILE}_mocks.go -package $GOPACKAGE
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-model/validator"
+	servicedao "github.com/jtyers/tmaas-service-dao"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	dao "github.com/jtyers/tmaas-threat-model-api/dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+var (
+	ErrNoSuchComment        = errors.New("no such comment")
+	ErrNoSuchThreat         = errors.New("no such threat")
+	ErrNotCommentAuthor     = errors.New("only the author of a comment may change it")
+	ErrInvalidParentComment = errors.New("parent comment is not part of the same thread")
+	ErrCannotResolveReply   = errors.New("only top-level comments can be resolved")
+	ErrEmptyComment         = errors.New("comment body must not be empty")
+)
+
+// mentionPattern matches @mentions of user IDs within a comment body, eg
+// "@u-1234-5678", but not the @ of an email address.
+var mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9_.@-])@(` + auth.UserIDExpr + `)`)
+
+// CommentService manages threaded comments on threat models and their
+// threats. The author of each comment is taken from the identity in the
+// context.
+type CommentService interface {
+	// Retrieve all comments on a threat model, including those on its
+	// threats, oldest first.
+	GetAll(ctx context.Context, threatModelID m.ThreatModelID) ([]*tm.Comment, error)
+
+	// Retrieve all comments on a single threat, oldest first.
+	GetAllForThreat(ctx context.Context, threatModelID m.ThreatModelID, threatID string) ([]*tm.Comment, error)
+
+	// Create a comment. If threatID is non-empty the comment refers to
+	// that threat, otherwise to the threat model as a whole.
+	Create(ctx context.Context, threatModelID m.ThreatModelID, threatID string, params tm.CommentParams) (*tm.Comment, error)
+
+	// Edit the body of a comment. Only the author may do this.
+	Update(ctx context.Context, threatModelID m.ThreatModelID, id tm.CommentID, params tm.CommentParams) (*tm.Comment, error)
+
+	// Delete a comment. Only the author may do this.
+	Delete(ctx context.Context, threatModelID m.ThreatModelID, id tm.CommentID) error
+
+	// Mark a top-level comment (and thus its thread) as resolved or unresolved.
+	Resolve(ctx context.Context, threatModelID m.ThreatModelID, id tm.CommentID, resolved bool) (*tm.Comment, error)
+}
+
+type DefaultCommentService struct {
+	dao                dao.CommentDao
+	threatModelService ThreatModelService
+	validator          validator.StructValidator
+	now                func() time.Time
+}
+
+var _ CommentService = (*DefaultCommentService)(nil)
+
+func NewDefaultCommentService(
+	dao dao.CommentDao,
+	threatModelService ThreatModelService,
+	validator validator.StructValidator,
+) *DefaultCommentService {
+	return &DefaultCommentService{dao, threatModelService, validator, time.Now}
+}
+
+func (s *DefaultCommentService) GetAll(ctx context.Context, threatModelID m.ThreatModelID) ([]*tm.Comment, error) {
+	if _, err := s.threatModelService.Get(ctx, threatModelID); err != nil {
+		return nil, err
+	}
+
+	result, err := s.dao.GetAllForThreatModel(ctx, threatModelID)
+	if err != nil {
+		return nil, fmt.Errorf("error in GetAllForThreatModel: %v", err)
+	}
+
+	return result, nil
+}
+
+func (s *DefaultCommentService) GetAllForThreat(ctx context.Context, threatModelID m.ThreatModelID, threatID string) ([]*tm.Comment, error) {
+	comments, err := s.GetAll(ctx, threatModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*tm.Comment{}
+	for _, c := range comments {
+		if c.ThreatID == threatID {
+			result = append(result, c)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *DefaultCommentService) Create(ctx context.Context, threatModelID m.ThreatModelID, threatID string, params tm.CommentParams) (*tm.Comment, error) {
+	author, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.validator.ValidateForCreate(params)
+	if err != nil {
+		return nil, err
+	}
+	if params.Body == nil || strings.TrimSpace(*params.Body) == "" {
+		return nil, ErrEmptyComment
+	}
+
+	threatModel, err := s.threatModelService.Get(ctx, threatModelID)
+	if err != nil {
+		return nil, err
+	}
+	if threatID != "" && !hasThreat(threatModel, threatID) {
+		return nil, ErrNoSuchThreat
+	}
+
+	if params.ParentCommentID != nil {
+		parent, err := s.get(ctx, threatModelID, *params.ParentCommentID)
+		if err != nil {
+			if err == ErrNoSuchComment {
+				return nil, ErrInvalidParentComment
+			}
+			return nil, err
+		}
+		if parent.ThreatID != threatID {
+			return nil, ErrInvalidParentComment
+		}
+	}
+
+	now := s.now()
+	comment := tm.Comment{
+		ThreatModelID:   threatModelID,
+		ThreatID:        threatID,
+		ParentCommentID: params.ParentCommentID,
+		Author:          author,
+		Body:            *params.Body,
+		Mentions:        parseMentions(*params.Body),
+		Created:         now,
+		Updated:         now,
+	}
+
+	result, err := s.dao.Create(ctx, comment)
+	if err != nil {
+		return nil, fmt.Errorf("error creating comment: %v", err)
+	}
+
+	return result, nil
+}
+
+func (s *DefaultCommentService) Update(ctx context.Context, threatModelID m.ThreatModelID, id tm.CommentID, params tm.CommentParams) (*tm.Comment, error) {
+	err := s.validator.ValidateForUpdate(params)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.getAsAuthor(ctx, threatModelID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Body == nil || strings.TrimSpace(*params.Body) == "" {
+		return nil, ErrEmptyComment
+	}
+
+	comment.Body = *params.Body
+	comment.Mentions = parseMentions(*params.Body)
+	comment.Updated = s.now()
+
+	result, err := s.dao.Update(ctx, *comment)
+	if err != nil {
+		return nil, fmt.Errorf("error updating comment: %v", err)
+	}
+
+	return result, nil
+}
+
+func (s *DefaultCommentService) Delete(ctx context.Context, threatModelID m.ThreatModelID, id tm.CommentID) error {
+	comment, err := s.getAsAuthor(ctx, threatModelID, id)
+	if err != nil {
+		return err
+	}
+
+	comments, err := s.dao.GetAllForThreatModel(ctx, threatModelID)
+	if err != nil {
+		return fmt.Errorf("error in GetAllForThreatModel: %v", err)
+	}
+
+	for _, c := range comments {
+		if c.ParentCommentID != nil && *c.ParentCommentID == id {
+			// keep the thread intact by blanking, rather than removing, the comment
+			comment.Deleted = true
+			comment.Body = ""
+			comment.Mentions = []m.UserID{}
+			comment.Updated = s.now()
+
+			if _, err := s.dao.Update(ctx, *comment); err != nil {
+				return fmt.Errorf("error updating comment: %v", err)
+			}
+			return nil
+		}
+	}
+
+	err = s.dao.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error deleting comment: %v", err)
+	}
+
+	return nil
+}
+
+func (s *DefaultCommentService) Resolve(ctx context.Context, threatModelID m.ThreatModelID, id tm.CommentID, resolved bool) (*tm.Comment, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.get(ctx, threatModelID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.ParentCommentID != nil {
+		return nil, ErrCannotResolveReply
+	}
+
+	comment.Resolved = resolved
+	if resolved {
+		now := s.now()
+		comment.ResolvedBy = &userID
+		comment.ResolvedAt = &now
+	} else {
+		comment.ResolvedBy = nil
+		comment.ResolvedAt = nil
+	}
+
+	result, err := s.dao.Update(ctx, *comment)
+	if err != nil {
+		return nil, fmt.Errorf("error updating comment: %v", err)
+	}
+
+	return result, nil
+}
+
+// get retrieves a comment, checking it belongs to the given threat model.
+func (s *DefaultCommentService) get(ctx context.Context, threatModelID m.ThreatModelID, id tm.CommentID) (*tm.Comment, error) {
+	comment, err := s.dao.Get(ctx, id)
+	if err != nil {
+		if err == servicedao.ErrNoSuchDocument {
+			return nil, ErrNoSuchComment
+		}
+		return nil, fmt.Errorf("error retrieving comment: %v", err)
+	}
+
+	if comment.ThreatModelID.String() != threatModelID.String() || comment.Deleted {
+		return nil, ErrNoSuchComment
+	}
+
+	return comment, nil
+}
+
+// getAsAuthor retrieves a comment, checking that the caller is its author.
+func (s *DefaultCommentService) getAsAuthor(ctx context.Context, threatModelID m.ThreatModelID, id tm.CommentID) (*tm.Comment, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.get(ctx, threatModelID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.Author != userID {
+		return nil, ErrNotCommentAuthor
+	}
+
+	return comment, nil
+}
+
+// hasThreat returns true if threatModel has a threat with the given ID.
+// Threats carry no ID of their own, so are identified by title.
+func hasThreat(threatModel *m.ThreatModel, threatID string) bool {
+	for _, threat := range threatModel.Threats {
+		if threat.Title == threatID {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMentions returns the distinct user IDs @mentioned in body, in the
+// order they first appear.
+func parseMentions(body string) []m.UserID {
+	result := []m.UserID{}
+	seen := map[string]bool{}
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			result = append(result, m.UserID(match[1]))
+		}
+	}
+
+	return result
+}
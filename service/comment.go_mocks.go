@@ -0,0 +1,126 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: comment.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/jtyers/tmaas-model"
+	model0 "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+// MockCommentService is a mock of CommentService interface.
+type MockCommentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentServiceMockRecorder
+}
+
+// MockCommentServiceMockRecorder is the mock recorder for MockCommentService.
+type MockCommentServiceMockRecorder struct {
+	mock *MockCommentService
+}
+
+// NewMockCommentService creates a new mock instance.
+func NewMockCommentService(ctrl *gomock.Controller) *MockCommentService {
+	mock := &MockCommentService{ctrl: ctrl}
+	mock.recorder = &MockCommentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommentService) EXPECT() *MockCommentServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockCommentService) Create(ctx context.Context, threatModelID model.ThreatModelID, threatID string, params model0.CommentParams) (*model0.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, threatModelID, threatID, params)
+	ret0, _ := ret[0].(*model0.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCommentServiceMockRecorder) Create(ctx, threatModelID, threatID, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCommentService)(nil).Create), ctx, threatModelID, threatID, params)
+}
+
+// Delete mocks base method.
+func (m *MockCommentService) Delete(ctx context.Context, threatModelID model.ThreatModelID, id model0.CommentID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, threatModelID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCommentServiceMockRecorder) Delete(ctx, threatModelID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCommentService)(nil).Delete), ctx, threatModelID, id)
+}
+
+// GetAll mocks base method.
+func (m *MockCommentService) GetAll(ctx context.Context, threatModelID model.ThreatModelID) ([]*model0.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx, threatModelID)
+	ret0, _ := ret[0].([]*model0.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockCommentServiceMockRecorder) GetAll(ctx, threatModelID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockCommentService)(nil).GetAll), ctx, threatModelID)
+}
+
+// GetAllForThreat mocks base method.
+func (m *MockCommentService) GetAllForThreat(ctx context.Context, threatModelID model.ThreatModelID, threatID string) ([]*model0.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllForThreat", ctx, threatModelID, threatID)
+	ret0, _ := ret[0].([]*model0.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllForThreat indicates an expected call of GetAllForThreat.
+func (mr *MockCommentServiceMockRecorder) GetAllForThreat(ctx, threatModelID, threatID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllForThreat", reflect.TypeOf((*MockCommentService)(nil).GetAllForThreat), ctx, threatModelID, threatID)
+}
+
+// Resolve mocks base method.
+func (m *MockCommentService) Resolve(ctx context.Context, threatModelID model.ThreatModelID, id model0.CommentID, resolved bool) (*model0.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resolve", ctx, threatModelID, id, resolved)
+	ret0, _ := ret[0].(*model0.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Resolve indicates an expected call of Resolve.
+func (mr *MockCommentServiceMockRecorder) Resolve(ctx, threatModelID, id, resolved interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockCommentService)(nil).Resolve), ctx, threatModelID, id, resolved)
+}
+
+// Update mocks base method.
+func (m *MockCommentService) Update(ctx context.Context, threatModelID model.ThreatModelID, id model0.CommentID, params model0.CommentParams) (*model0.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, threatModelID, id, params)
+	ret0, _ := ret[0].(*model0.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCommentServiceMockRecorder) Update(ctx, threatModelID, id, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCommentService)(nil).Update), ctx, threatModelID, id, params)
+}
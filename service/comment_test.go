@@ -0,0 +1,456 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-model/validator"
+	servicedao "github.com/jtyers/tmaas-service-dao"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/stretchr/testify/require"
+)
+
+func commentIDP(id string) *tm.CommentID {
+	result := tm.CommentID(id)
+	return &result
+}
+
+func TestCreateComment(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	threatModelID := m.NewThreatModelIDP("1234-1234-1234-1234")
+	author := &auth.Identity{UserID: "u-1234"}
+
+	threatModel := &m.ThreatModel{
+		ThreatModelID: threatModelID,
+		Threats:       []*m.Threat{{Title: "t-1"}, {Title: "t-2"}},
+	}
+
+	parent := &tm.Comment{
+		CommentID:     "cmt-1",
+		ThreatModelID: threatModelID,
+		ThreatID:      "t-1",
+	}
+
+	var tests = []struct {
+		name                 string
+		identity             *auth.Identity
+		threatID             string
+		params               tm.CommentParams
+		threatModelError     error
+		parent               *tm.Comment
+		parentError          error
+		expectedDaoCreate    *tm.Comment
+		expectedError        error
+		threatModelChecked   bool
+		validatorCalled      bool
+		daoCreateReturnError error
+	}{
+		{
+			name:               "should create comment with mentions",
+			identity:           author,
+			params:             tm.CommentParams{Body: m.String("@u-5678 and @u-9999, please review (cc @u-5678)")},
+			threatModelChecked: true,
+			validatorCalled:    true,
+			expectedDaoCreate: &tm.Comment{
+				ThreatModelID: threatModelID,
+				Author:        "u-1234",
+				Body:          "@u-5678 and @u-9999, please review (cc @u-5678)",
+				Mentions:      []m.UserID{"u-5678", "u-9999"},
+				Created:       now,
+				Updated:       now,
+			},
+		},
+		{
+			name:               "should create reply on a threat",
+			identity:           author,
+			threatID:           "t-1",
+			params:             tm.CommentParams{Body: m.String("agreed"), ParentCommentID: commentIDP("cmt-1")},
+			threatModelChecked: true,
+			validatorCalled:    true,
+			parent:             parent,
+			expectedDaoCreate: &tm.Comment{
+				ThreatModelID:   threatModelID,
+				ThreatID:        "t-1",
+				ParentCommentID: commentIDP("cmt-1"),
+				Author:          "u-1234",
+				Body:            "agreed",
+				Mentions:        []m.UserID{},
+				Created:         now,
+				Updated:         now,
+			},
+		},
+		{
+			name:          "should fail without an identity",
+			params:        tm.CommentParams{Body: m.String("hello")},
+			expectedError: auth.ErrNoIdentity,
+		},
+		{
+			name:          "should fail for service accounts",
+			identity:      &auth.Identity{ServiceAccountName: "lookup-service-go"},
+			params:        tm.CommentParams{Body: m.String("hello")},
+			expectedError: auth.ErrNoIdentity,
+		},
+		{
+			name:            "should fail for empty comments",
+			identity:        author,
+			params:          tm.CommentParams{Body: m.String("   ")},
+			validatorCalled: true,
+			expectedError:   ErrEmptyComment,
+		},
+		{
+			name:               "should pass through threat model errors",
+			identity:           author,
+			params:             tm.CommentParams{Body: m.String("hello")},
+			validatorCalled:    true,
+			threatModelChecked: true,
+			threatModelError:   ErrNoSuchThreatModel,
+			expectedError:      ErrNoSuchThreatModel,
+		},
+		{
+			name:               "should fail if the threat does not exist",
+			identity:           author,
+			threatID:           "t-3",
+			params:             tm.CommentParams{Body: m.String("hello")},
+			validatorCalled:    true,
+			threatModelChecked: true,
+			expectedError:      ErrNoSuchThreat,
+		},
+		{
+			name:               "should fail if parent comment does not exist",
+			identity:           author,
+			params:             tm.CommentParams{Body: m.String("hello"), ParentCommentID: commentIDP("cmt-1")},
+			validatorCalled:    true,
+			threatModelChecked: true,
+			parentError:        servicedao.ErrNoSuchDocument,
+			expectedError:      ErrInvalidParentComment,
+		},
+		{
+			name:               "should fail if parent comment is on a different threat",
+			identity:           author,
+			threatID:           "t-2",
+			params:             tm.CommentParams{Body: m.String("hello"), ParentCommentID: commentIDP("cmt-1")},
+			validatorCalled:    true,
+			threatModelChecked: true,
+			parent:             parent,
+			expectedError:      ErrInvalidParentComment,
+		},
+		{
+			name:               "should fail if DAO create fails",
+			identity:           author,
+			params:             tm.CommentParams{Body: m.String("hello")},
+			validatorCalled:    true,
+			threatModelChecked: true,
+			expectedDaoCreate: &tm.Comment{
+				ThreatModelID: threatModelID,
+				Author:        "u-1234",
+				Body:          "hello",
+				Mentions:      []m.UserID{},
+				Created:       now,
+				Updated:       now,
+			},
+			daoCreateReturnError: fmt.Errorf("dao failure"),
+			expectedError:        fmt.Errorf("error creating comment: dao failure"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao := dao.NewMockCommentDao(ctrl)
+			mockThreatModelService := NewMockThreatModelService(ctrl)
+			mockValidator := validator.NewMockStructValidator(ctrl)
+
+			ctx := context.Background()
+			if test.identity != nil {
+				ctx = auth.WithIdentity(ctx, test.identity)
+			}
+
+			if test.validatorCalled {
+				mockValidator.EXPECT().ValidateForCreate(test.params).Return(nil)
+			}
+			if test.threatModelChecked {
+				mockThreatModelService.EXPECT().Get(ctx, threatModelID).Return(threatModel, test.threatModelError)
+			}
+			if test.parent != nil || test.parentError != nil {
+				mockDao.EXPECT().Get(ctx, *test.params.ParentCommentID).Return(test.parent, test.parentError)
+			}
+
+			var expectedResult *tm.Comment
+			if test.expectedDaoCreate != nil {
+				if test.daoCreateReturnError == nil {
+					created := *test.expectedDaoCreate
+					created.CommentID = "cmt-new"
+					expectedResult = &created
+					mockDao.EXPECT().Create(ctx, *test.expectedDaoCreate).Return(expectedResult, nil)
+				} else {
+					mockDao.EXPECT().Create(ctx, *test.expectedDaoCreate).Return(nil, test.daoCreateReturnError)
+				}
+			}
+
+			// when
+			service := NewDefaultCommentService(mockDao, mockThreatModelService, mockValidator)
+			service.now = func() time.Time { return now }
+			result, err := service.Create(ctx, threatModelID, test.threatID, test.params)
+
+			// then
+			require.Equal(t, test.expectedError, err)
+			require.Equal(t, expectedResult, result)
+		})
+	}
+}
+
+func TestParseMentions(t *testing.T) {
+	var tests = []struct {
+		body     string
+		expected []m.UserID
+	}{
+		{"@u-5678 and @u-9999, please review (cc @u-5678)", []m.UserID{"u-5678", "u-9999"}},
+		{"ping @Xk3vQ9_abc-1", []m.UserID{"Xk3vQ9_abc-1"}},
+		{"mail alice@example.com", []m.UserID{}},
+		{"no mentions @ all", []m.UserID{}},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.expected, parseMentions(test.body), test.body)
+	}
+}
+
+func TestUpdateComment(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	threatModelID := m.NewThreatModelIDP("1234-1234-1234-1234")
+
+	existing := tm.Comment{
+		CommentID:     "cmt-1",
+		ThreatModelID: threatModelID,
+		Author:        "u-1234",
+		Body:          "old",
+		Mentions:      []m.UserID{},
+	}
+
+	var tests = []struct {
+		name          string
+		identity      *auth.Identity
+		daoGet        *tm.Comment
+		daoGetError   error
+		expectUpdate  bool
+		expectedError error
+	}{
+		{
+			name:         "should allow the author to edit",
+			identity:     &auth.Identity{UserID: "u-1234"},
+			daoGet:       &existing,
+			expectUpdate: true,
+		},
+		{
+			name:          "should not allow other users to edit",
+			identity:      &auth.Identity{UserID: "u-5678"},
+			daoGet:        &existing,
+			expectedError: ErrNotCommentAuthor,
+		},
+		{
+			name:          "should return ErrNoSuchComment for non-existent comments",
+			identity:      &auth.Identity{UserID: "u-1234"},
+			daoGetError:   servicedao.ErrNoSuchDocument,
+			expectedError: ErrNoSuchComment,
+		},
+		{
+			name:     "should return ErrNoSuchComment for comments on other threat models",
+			identity: &auth.Identity{UserID: "u-1234"},
+			daoGet: &tm.Comment{
+				CommentID:     "cmt-1",
+				ThreatModelID: m.NewThreatModelIDP("2345-2345-2345-2345"),
+				Author:        "u-1234",
+			},
+			expectedError: ErrNoSuchComment,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao := dao.NewMockCommentDao(ctrl)
+			mockValidator := validator.NewMockStructValidator(ctrl)
+
+			ctx := auth.WithIdentity(context.Background(), test.identity)
+			params := tm.CommentParams{Body: m.String("new @u-5678")}
+
+			mockValidator.EXPECT().ValidateForUpdate(params).Return(nil)
+
+			if test.daoGet != nil {
+				// return a copy, as the service modifies what it is given
+				c := *test.daoGet
+				mockDao.EXPECT().Get(ctx, existing.CommentID).Return(&c, nil)
+			} else {
+				mockDao.EXPECT().Get(ctx, existing.CommentID).Return(nil, test.daoGetError)
+			}
+
+			var expectedResult *tm.Comment
+			if test.expectUpdate {
+				updated := existing
+				updated.Body = "new @u-5678"
+				updated.Mentions = []m.UserID{"u-5678"}
+				updated.Updated = now
+				expectedResult = &updated
+
+				mockDao.EXPECT().Update(ctx, updated).Return(&updated, nil)
+			}
+
+			// when
+			service := NewDefaultCommentService(mockDao, nil, mockValidator)
+			service.now = func() time.Time { return now }
+			result, err := service.Update(ctx, threatModelID, existing.CommentID, params)
+
+			// then
+			require.Equal(t, test.expectedError, err)
+			require.Equal(t, expectedResult, result)
+		})
+	}
+}
+
+func TestDeleteComment(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	threatModelID := m.NewThreatModelIDP("1234-1234-1234-1234")
+
+	existing := tm.Comment{
+		CommentID:     "cmt-1",
+		ThreatModelID: threatModelID,
+		Author:        "u-1234",
+		Body:          "hello @u-5678",
+		Mentions:      []m.UserID{"u-5678"},
+	}
+	reply := &tm.Comment{
+		CommentID:       "cmt-2",
+		ThreatModelID:   threatModelID,
+		ParentCommentID: commentIDP("cmt-1"),
+		Author:          "u-5678",
+	}
+
+	var tests = []struct {
+		name           string
+		allComments    []*tm.Comment
+		expectSoftDel  bool
+		expectedResult error
+	}{
+		{
+			"should delete comments without replies",
+			[]*tm.Comment{&existing},
+			false,
+			nil,
+		},
+		{
+			"should blank, rather than delete, comments with replies",
+			[]*tm.Comment{&existing, reply},
+			true,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao := dao.NewMockCommentDao(ctrl)
+			ctx := auth.WithIdentity(context.Background(), &auth.Identity{UserID: "u-1234"})
+
+			c := existing
+			mockDao.EXPECT().Get(ctx, existing.CommentID).Return(&c, nil)
+			mockDao.EXPECT().GetAllForThreatModel(ctx, threatModelID).Return(test.allComments, nil)
+
+			if test.expectSoftDel {
+				deleted := existing
+				deleted.Deleted = true
+				deleted.Body = ""
+				deleted.Mentions = []m.UserID{}
+				deleted.Updated = now
+				mockDao.EXPECT().Update(ctx, deleted).Return(&deleted, nil)
+			} else {
+				mockDao.EXPECT().Delete(ctx, existing.CommentID).Return(nil)
+			}
+
+			// when
+			service := NewDefaultCommentService(mockDao, nil, nil)
+			service.now = func() time.Time { return now }
+			err := service.Delete(ctx, threatModelID, existing.CommentID)
+
+			// then
+			require.Equal(t, test.expectedResult, err)
+		})
+	}
+}
+
+func TestResolveComment(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	threatModelID := m.NewThreatModelIDP("1234-1234-1234-1234")
+	resolver := m.UserID("u-5678")
+
+	var tests = []struct {
+		name           string
+		existing       tm.Comment
+		resolved       bool
+		expectedUpdate *tm.Comment
+		expectedError  error
+	}{
+		{
+			name:     "should resolve top-level comments",
+			existing: tm.Comment{CommentID: "cmt-1", ThreatModelID: threatModelID, Author: "u-1234"},
+			resolved: true,
+			expectedUpdate: &tm.Comment{CommentID: "cmt-1", ThreatModelID: threatModelID, Author: "u-1234",
+				Resolved: true, ResolvedBy: &resolver, ResolvedAt: &now},
+		},
+		{
+			name: "should unresolve top-level comments",
+			existing: tm.Comment{CommentID: "cmt-1", ThreatModelID: threatModelID, Author: "u-1234",
+				Resolved: true, ResolvedBy: &resolver, ResolvedAt: &now},
+			resolved:       false,
+			expectedUpdate: &tm.Comment{CommentID: "cmt-1", ThreatModelID: threatModelID, Author: "u-1234"},
+		},
+		{
+			name: "should not resolve replies",
+			existing: tm.Comment{CommentID: "cmt-1", ThreatModelID: threatModelID, Author: "u-1234",
+				ParentCommentID: commentIDP("cmt-0")},
+			resolved:      true,
+			expectedError: ErrCannotResolveReply,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao := dao.NewMockCommentDao(ctrl)
+			ctx := auth.WithIdentity(context.Background(), &auth.Identity{UserID: resolver})
+
+			c := test.existing
+			mockDao.EXPECT().Get(ctx, test.existing.CommentID).Return(&c, nil)
+
+			if test.expectedUpdate != nil {
+				mockDao.EXPECT().Update(ctx, *test.expectedUpdate).Return(test.expectedUpdate, nil)
+			}
+
+			// when
+			service := NewDefaultCommentService(mockDao, nil, nil)
+			service.now = func() time.Time { return now }
+			result, err := service.Resolve(ctx, threatModelID, test.existing.CommentID, test.resolved)
+
+			// then
+			require.Equal(t, test.expectedError, err)
+			require.Equal(t, test.expectedUpdate, result)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-service-util/log"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/events"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+)
+
+const (
+	// Tags marking threat models for review, as their data flow diagram
+	// was changed or deleted since they were last worked on. They are
+	// removed like any other tag once the threat model is reviewed.
+	TagReviewDataFlowDiagramChanged = "review:dfd-changed"
+	TagReviewDataFlowDiagramDeleted = "review:dfd-deleted"
+
+	// the identity threat models are read and tagged as on receiving
+	// data flow diagram events, as seen in audit records
+	dataFlowDiagramEventsServiceAccount = "dfd-event-consumer"
+
+	// the type label of events that are malformed or of unknown types, so
+	// that arbitrary types cannot blow up the number of series
+	eventTypeUnknown = "unknown"
+
+	eventResultProcessed = "processed"
+	eventResultIgnored   = "ignored"
+	eventResultFailed    = "failed"
+)
+
+// DataFlowDiagramEventHandler flags the threat models of data flow diagrams
+// that are changed or deleted as needing review, by tagging them. Threat
+// models stay linked to deleted data flow diagrams, so that reviewers can
+// still see what they modelled.
+type DataFlowDiagramEventHandler struct {
+	threatModelService ThreatModelService
+	tagService         ThreatModelTagService
+	metrics            *metrics.Metrics
+}
+
+var _ events.Handler = (*DataFlowDiagramEventHandler)(nil)
+
+func NewDataFlowDiagramEventHandler(threatModelService ThreatModelService, tagService ThreatModelTagService, metrics *metrics.Metrics) *DataFlowDiagramEventHandler {
+	return &DataFlowDiagramEventHandler{threatModelService, tagService, metrics}
+}
+
+// Handle tags the threat models of the data flow diagram in msg. Messages
+// that can never be handled, such as those of unknown types, are logged
+// and dropped rather than failing, so that they are not redelivered.
+func (h *DataFlowDiagramEventHandler) Handle(ctx context.Context, msg *events.Message) error {
+	event := events.DataFlowDiagramEvent{}
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		log.Errorf("ignoring malformed data flow diagram event %s: %v", msg.ID, err)
+		h.observe(eventTypeUnknown, eventResultIgnored)
+		return nil
+	}
+
+	var tag string
+	switch event.Type {
+	case events.DataFlowDiagramUpdated:
+		tag = TagReviewDataFlowDiagramChanged
+	case events.DataFlowDiagramDeleted:
+		tag = TagReviewDataFlowDiagramDeleted
+	default:
+		log.Warnf("ignoring data flow diagram event %s of unknown type %q", msg.ID, event.Type)
+		h.observe(eventTypeUnknown, eventResultIgnored)
+		return nil
+	}
+
+	tenantID, err := auth.ParseTenantID(event.TenantID)
+	if err != nil || event.DataFlowDiagramID == "" {
+		log.Warnf("ignoring data flow diagram event %s with tenant %q and data flow diagram %q", msg.ID, event.TenantID, event.DataFlowDiagramID)
+		h.observe(event.Type, eventResultIgnored)
+		return nil
+	}
+
+	ctx = auth.WithIdentity(ctx, &auth.Identity{ServiceAccountName: dataFlowDiagramEventsServiceAccount, TenantID: tenantID})
+
+	err = h.tagThreatModels(ctx, m.NewDataFlowDiagramIDP(event.DataFlowDiagramID), tag)
+	if err != nil {
+		h.observe(event.Type, eventResultFailed)
+		return fmt.Errorf("error handling data flow diagram event %s: %v", msg.ID, err)
+	}
+
+	h.observe(event.Type, eventResultProcessed)
+	return nil
+}
+
+// tagThreatModels adds tag to every threat model of dfdID. Adding a tag a
+// threat model already carries changes nothing, so redelivered events are
+// harmless.
+func (h *DataFlowDiagramEventHandler) tagThreatModels(ctx context.Context, dfdID m.DataFlowDiagramID, tag string) error {
+	threatModels, err := h.threatModelService.Query(ctx, &m.ThreatModelQuery{DataFlowDiagramID: &dfdID})
+	if err != nil {
+		return err
+	}
+
+	for _, threatModel := range threatModels {
+		_, err = h.tagService.AddTags(ctx, threatModel.ThreatModelID, []string{tag})
+		if err == ErrTooManyTags {
+			// retrying will not help, and the other threat models should
+			// still be flagged
+			log.Errorf("cannot tag threat model %s with %s: it has too many tags", threatModel.ThreatModelID, tag)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *DataFlowDiagramEventHandler) observe(eventType string, result string) {
+	h.metrics.EventsReceived.WithLabelValues(eventType, result).Inc()
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/events"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataFlowDiagramEventHandler(t *testing.T) {
+	threatModel1 := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("1234-1234-1234-1234")}
+	threatModel2 := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("2345-2345-2345-2345")}
+
+	dfdID := m.NewDataFlowDiagramIDP("dfd-1234")
+
+	var tests = []struct {
+		name string
+		data string
+
+		// the threat models of the DFD, or nil if the DFD should not be
+		// looked up
+		threatModels []*m.ThreatModel
+
+		// the tag each threat model should be given
+		expectedTag string
+
+		expectedType   string
+		expectedResult string
+	}{
+		{
+			"should flag the threat models of changed DFDs",
+			`{"type": "dfd.updated", "dataFlowDiagramId": "dfd-1234", "tenantId": "acme"}`,
+			[]*m.ThreatModel{threatModel1, threatModel2},
+			TagReviewDataFlowDiagramChanged,
+			events.DataFlowDiagramUpdated,
+			eventResultProcessed,
+		},
+		{
+			"should flag the threat models of deleted DFDs",
+			`{"type": "dfd.deleted", "dataFlowDiagramId": "dfd-1234", "tenantId": "acme"}`,
+			[]*m.ThreatModel{threatModel1},
+			TagReviewDataFlowDiagramDeleted,
+			events.DataFlowDiagramDeleted,
+			eventResultProcessed,
+		},
+		{
+			"should ignore events of unknown types",
+			`{"type": "dfd.created", "dataFlowDiagramId": "dfd-1234", "tenantId": "acme"}`,
+			nil,
+			"",
+			eventTypeUnknown,
+			eventResultIgnored,
+		},
+		{
+			"should ignore malformed events",
+			`{"type": `,
+			nil,
+			"",
+			eventTypeUnknown,
+			eventResultIgnored,
+		},
+		{
+			"should ignore events with invalid tenants",
+			`{"type": "dfd.deleted", "dataFlowDiagramId": "dfd-1234", "tenantId": "Not A Tenant!"}`,
+			nil,
+			"",
+			events.DataFlowDiagramDeleted,
+			eventResultIgnored,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockThreatModelService := NewMockThreatModelService(ctrl)
+			mockTagService := NewMockThreatModelTagService(ctrl)
+			metrics := metrics.NewMetrics()
+
+			if test.threatModels != nil {
+				mockThreatModelService.EXPECT().Query(gomock.Any(), &m.ThreatModelQuery{DataFlowDiagramID: &dfdID}).
+					DoAndReturn(func(ctx context.Context, q *m.ThreatModelQuery) ([]*m.ThreatModel, error) {
+						tenantID, err := auth.TenantIDFromContext(ctx)
+						require.Nil(t, err)
+						require.Equal(t, auth.TenantID("acme"), tenantID)
+
+						return test.threatModels, nil
+					})
+			}
+
+			for _, threatModel := range test.threatModels {
+				mockTagService.EXPECT().AddTags(gomock.Any(), threatModel.ThreatModelID, []string{test.expectedTag}).Return([]string{test.expectedTag}, nil)
+			}
+
+			handler := NewDataFlowDiagramEventHandler(mockThreatModelService, mockTagService, metrics)
+
+			// when
+			err := handler.Handle(context.Background(), &events.Message{ID: "1", Data: []byte(test.data)})
+
+			// then
+			require.Nil(t, err)
+			require.Equal(t, 1.0, testutil.ToFloat64(metrics.EventsReceived.WithLabelValues(test.expectedType, test.expectedResult)))
+		})
+	}
+}
+
+func TestDataFlowDiagramEventHandlerFailure(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockThreatModelService := NewMockThreatModelService(ctrl)
+	mockTagService := NewMockThreatModelTagService(ctrl)
+	metrics := metrics.NewMetrics()
+
+	threatModel1 := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("1234-1234-1234-1234")}
+	threatModel2 := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("2345-2345-2345-2345")}
+
+	mockThreatModelService.EXPECT().Query(gomock.Any(), gomock.Any()).Return([]*m.ThreatModel{threatModel1, threatModel2}, nil)
+
+	// a threat model with too many tags is skipped, but a failure to
+	// tag another fails the event so that it is redelivered
+	mockTagService.EXPECT().AddTags(gomock.Any(), threatModel1.ThreatModelID, gomock.Any()).Return(nil, ErrTooManyTags)
+	mockTagService.EXPECT().AddTags(gomock.Any(), threatModel2.ThreatModelID, gomock.Any()).Return(nil, errors.New("datastore unavailable"))
+
+	handler := NewDataFlowDiagramEventHandler(mockThreatModelService, mockTagService, metrics)
+
+	// when
+	err := handler.Handle(context.Background(), &events.Message{
+		ID:   "1",
+		Data: []byte(`{"type": "dfd.updated", "dataFlowDiagramId": "dfd-1234", "tenantId": "acme"}`),
+	})
+
+	// then
+	require.NotNil(t, err)
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.EventsReceived.WithLabelValues(events.DataFlowDiagramUpdated, eventResultFailed)))
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	m "github.com/jtyers/tmaas-model"
+)
+
+// ThreatModelWriteHook is notified after DefaultThreatModelService writes
+// a threat model, so that data derived from threat models (such as the
+// search index) can be kept in sync. Hooks run after the write has
+// succeeded and cannot fail it, so should log their own errors.
+type ThreatModelWriteHook interface {
+	// Called after a threat model is created or updated.
+	AfterSave(ctx context.Context, threatModel *m.ThreatModel)
+
+	// Called after a threat model is deleted.
+	AfterDelete(ctx context.Context, id m.ThreatModelID)
+}
+
+type ThreatModelWriteHooks []ThreatModelWriteHook
+
+func (h ThreatModelWriteHooks) afterSave(ctx context.Context, threatModel *m.ThreatModel) {
+	for _, hook := range h {
+		hook.AfterSave(ctx, threatModel)
+	}
+}
+
+func (h ThreatModelWriteHooks) afterDelete(ctx context.Context, id m.ThreatModelID) {
+	for _, hook := range h {
+		hook.AfterDelete(ctx, id)
+	}
+}
+
+func NewThreatModelWriteHooks(search *IndexingThreatModelSearchService) ThreatModelWriteHooks {
+	return ThreatModelWriteHooks{
+		search,
+	}
+}
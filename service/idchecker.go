@@ -3,10 +3,120 @@ package service
 import (
 	"context"
 
+	dfdclient "github.com/jtyers/tmaas-dfd-api/client"
 	m "github.com/jtyers/tmaas-model"
+	servicedao "github.com/jtyers/tmaas-service-dao"
 	"github.com/jtyers/tmaas-service-util/idchecker"
+	dao "github.com/jtyers/tmaas-threat-model-api/dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"golang.org/x/sync/errgroup"
 )
 
+// BatchIDChecker is an IDChecker that can check many IDs at once, which
+// checkers backed by other APIs do in fewer round-trips than checking
+// each ID in turn. Use CheckIDs to check IDs against any IDChecker.
+type BatchIDChecker interface {
+	idchecker.IDChecker
+
+	// CheckIDs reports whether each of ids exists, in the order given.
+	CheckIDs(ctx context.Context, ids []any) ([]bool, error)
+}
+
+// BatchIDCheckerForType is an IDCheckerForType that can check many IDs
+// (each of which it can handle) at once.
+type BatchIDCheckerForType interface {
+	idchecker.IDCheckerForType
+
+	CheckIDs(ctx context.Context, ids []any) ([]bool, error)
+}
+
+// CheckIDs reports whether each of ids exists, checking them all at once
+// if checker is a BatchIDChecker, and one at a time otherwise.
+func CheckIDs(ctx context.Context, checker idchecker.IDChecker, ids []any) ([]bool, error) {
+	if batch, ok := checker.(BatchIDChecker); ok {
+		return batch.CheckIDs(ctx, ids)
+	}
+	return checkIDsInTurn(ctx, checker, ids)
+}
+
+func checkIDsInTurn(ctx context.Context, checker idchecker.IDChecker, ids []any) ([]bool, error) {
+	result := make([]bool, len(ids))
+	for i, id := range ids {
+		exists, err := checker.CheckID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = exists
+	}
+	return result, nil
+}
+
+// BatchingIDChecker is an IDChecker that, like idchecker.DefaultIDChecker,
+// hands each ID to the first checker that can handle it. IDs checked
+// together are grouped by checker, so that a BatchIDCheckerForType is
+// called once for all of its IDs. IDs no checker can handle do not exist.
+type BatchingIDChecker struct {
+	checkers idchecker.IDCheckerForTypes
+}
+
+var _ BatchIDChecker = (*BatchingIDChecker)(nil)
+
+func NewBatchingIDChecker(checkers idchecker.IDCheckerForTypes) *BatchingIDChecker {
+	return &BatchingIDChecker{checkers}
+}
+
+func (c *BatchingIDChecker) CheckID(ctx context.Context, id any) (bool, error) {
+	for _, checker := range c.checkers {
+		if checker.CanHandle(id) {
+			return checker.CheckID(ctx, id)
+		}
+	}
+	return false, nil
+}
+
+func (c *BatchingIDChecker) CheckIDs(ctx context.Context, ids []any) ([]bool, error) {
+	// the indexes into ids handled by each checker
+	indexes := make([][]int, len(c.checkers))
+
+	for i, id := range ids {
+		for j, checker := range c.checkers {
+			if checker.CanHandle(id) {
+				indexes[j] = append(indexes[j], i)
+				break
+			}
+		}
+	}
+
+	result := make([]bool, len(ids))
+	for j, checker := range c.checkers {
+		if len(indexes[j]) == 0 {
+			continue
+		}
+
+		checkerIDs := make([]any, len(indexes[j]))
+		for k, i := range indexes[j] {
+			checkerIDs[k] = ids[i]
+		}
+
+		var exists []bool
+		var err error
+		if batch, ok := checker.(BatchIDCheckerForType); ok {
+			exists, err = batch.CheckIDs(ctx, checkerIDs)
+		} else {
+			exists, err = checkIDsInTurn(ctx, checker, checkerIDs)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for k, i := range indexes[j] {
+			result[i] = exists[k]
+		}
+	}
+
+	return result, nil
+}
+
 type ServiceThreatModelIDChecker struct {
 	service ThreatModelService
 }
@@ -15,7 +125,7 @@ func NewServiceThreatModelIDChecker(service ThreatModelService) *ServiceThreatMo
 	return &ServiceThreatModelIDChecker{service}
 }
 
-var _ idchecker.IDCheckerForType = (*ServiceThreatModelIDChecker)(nil)
+var _ BatchIDCheckerForType = (*ServiceThreatModelIDChecker)(nil)
 
 func (c *ServiceThreatModelIDChecker) CanHandle(id any) bool {
 	switch id.(type) {
@@ -26,20 +136,142 @@ func (c *ServiceThreatModelIDChecker) CanHandle(id any) bool {
 }
 
 func (c *ServiceThreatModelIDChecker) CheckID(ctx context.Context, id any) (bool, error) {
-	var idStruct m.ThreatModelID
-	switch id.(type) {
+	_, err := c.service.Get(ctx, ThreatModelIDOf(id))
+	if err == nil {
+		return true, nil
+	} else if err == ErrNoSuchThreatModel {
+		return false, nil
+	} else {
+		return false, err
+	}
+}
+
+// CheckIDs checks IDs with GetMany.
+func (c *ServiceThreatModelIDChecker) CheckIDs(ctx context.Context, ids []any) ([]bool, error) {
+	return CheckThreatModelIDs(ctx, c.service.GetMany, ids)
+}
+
+// ThreatModelIDOf returns the m.ThreatModelID or *m.ThreatModelID id as an
+// m.ThreatModelID.
+func ThreatModelIDOf(id any) m.ThreatModelID {
+	switch id := id.(type) {
 	case m.ThreatModelID:
-		idStruct = id.(m.ThreatModelID)
+		return id
 	case *m.ThreatModelID:
-		idStruct = *(id.(*m.ThreatModelID))
+		return *id
+	}
+	return m.ThreatModelID{}
+}
+
+// CheckThreatModelIDs reports whether each threat model ID in ids exists,
+// looking them up with getMany, MaxGetManyIDs at a time.
+func CheckThreatModelIDs(ctx context.Context, getMany func(ctx context.Context, ids []m.ThreatModelID) (*tm.BatchGetResult, error), ids []any) ([]bool, error) {
+	found := map[m.ThreatModelID]bool{}
+
+	for start := 0; start < len(ids); start += MaxGetManyIDs {
+		end := start + MaxGetManyIDs
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		batch := make([]m.ThreatModelID, end-start)
+		for i, id := range ids[start:end] {
+			batch[i] = ThreatModelIDOf(id)
+		}
+
+		result, err := getMany(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, threatModel := range result.ThreatModels {
+			found[threatModel.ThreatModelID] = true
+		}
+	}
+
+	result := make([]bool, len(ids))
+	for i, id := range ids {
+		result[i] = found[ThreatModelIDOf(id)]
+	}
+	return result, nil
+}
+
+// DaoProjectIDChecker checks project IDs directly against the DAO, rather
+// than via ProjectService, since ProjectService itself depends on the
+// IDChecker.
+type DaoProjectIDChecker struct {
+	dao dao.ProjectDao
+}
+
+func NewDaoProjectIDChecker(dao dao.ProjectDao) *DaoProjectIDChecker {
+	return &DaoProjectIDChecker{dao}
+}
+
+var _ idchecker.IDCheckerForType = (*DaoProjectIDChecker)(nil)
+
+func (c *DaoProjectIDChecker) CanHandle(id any) bool {
+	switch id.(type) {
+	case tm.ProjectID, *tm.ProjectID:
+		return true
+	}
+	return false
+}
+
+func (c *DaoProjectIDChecker) CheckID(ctx context.Context, id any) (bool, error) {
+	var idStruct tm.ProjectID
+	switch id.(type) {
+	case tm.ProjectID:
+		idStruct = id.(tm.ProjectID)
+	case *tm.ProjectID:
+		idStruct = *(id.(*tm.ProjectID))
 	}
 
-	_, err := c.service.Get(ctx, idStruct)
+	_, err := c.dao.Get(ctx, idStruct)
 	if err == nil {
 		return true, nil
-	} else if err == ErrNoSuchThreatModel {
+	} else if err == servicedao.ErrNoSuchDocument {
 		return false, nil
 	} else {
 		return false, err
 	}
 }
+
+// maxConcurrentDataFlowDiagramChecks is the most data flow diagram IDs
+// checked against the DFD API at once.
+const maxConcurrentDataFlowDiagramChecks = 8
+
+// DataFlowDiagramIDChecker checks data flow diagram IDs against the DFD
+// API, adding batch checks to the client's own IDChecker.
+type DataFlowDiagramIDChecker struct {
+	idchecker.IDCheckerForType
+}
+
+var _ BatchIDCheckerForType = (*DataFlowDiagramIDChecker)(nil)
+
+func NewDataFlowDiagramIDChecker(checker *dfdclient.ClientDataFlowDiagramIDChecker) *DataFlowDiagramIDChecker {
+	return &DataFlowDiagramIDChecker{checker}
+}
+
+// CheckIDs checks each ID with the client's IDChecker, several at once, as
+// the DFD API has no multi-get of its own. Each result depends only on its
+// own ID, however many are checked together.
+func (c *DataFlowDiagramIDChecker) CheckIDs(ctx context.Context, ids []any) ([]bool, error) {
+	result := make([]bool, len(ids))
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentDataFlowDiagramChecks)
+
+	for i, id := range ids {
+		i, id := i, id
+		g.Go(func() error {
+			exists, err := c.CheckID(ctx, id)
+			result[i] = exists
+			return err
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
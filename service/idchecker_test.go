@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-service-util/idchecker"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/stretchr/testify/require"
+)
+
+// countingIDChecker handles IDs of one type, all of which exist, counting
+// the calls made to it.
+type countingIDChecker struct {
+	canHandle func(id any) bool
+	checks    int
+}
+
+func (c *countingIDChecker) CanHandle(id any) bool {
+	return c.canHandle(id)
+}
+
+func (c *countingIDChecker) CheckID(ctx context.Context, id any) (bool, error) {
+	c.checks++
+	return true, nil
+}
+
+// countingBatchIDChecker is a countingIDChecker that can also check IDs in
+// batches, finding only those in exists.
+type countingBatchIDChecker struct {
+	countingIDChecker
+	exists  map[any]bool
+	batches [][]any
+}
+
+func (c *countingBatchIDChecker) CheckIDs(ctx context.Context, ids []any) ([]bool, error) {
+	c.batches = append(c.batches, ids)
+
+	result := make([]bool, len(ids))
+	for i, id := range ids {
+		result[i] = c.exists[id]
+	}
+	return result, nil
+}
+
+func isThreatModelID(id any) bool {
+	_, ok := id.(m.ThreatModelID)
+	return ok
+}
+
+func isProjectID(id any) bool {
+	_, ok := id.(tm.ProjectID)
+	return ok
+}
+
+// dataFlowDiagramIDChecker handles data flow diagram IDs, checking them
+// with IDChecker.
+type dataFlowDiagramIDChecker struct {
+	idchecker.IDChecker
+}
+
+func (dataFlowDiagramIDChecker) CanHandle(id any) bool {
+	_, ok := id.(*m.DataFlowDiagramID)
+	return ok
+}
+
+func TestBatchingIDCheckerCheckIDs(t *testing.T) {
+	tm1 := m.NewThreatModelIDP("tm-1")
+	tm2 := m.NewThreatModelIDP("tm-2")
+	tm3 := m.NewThreatModelIDP("tm-3")
+	p1 := tm.ProjectID("prj-1")
+
+	// given
+	threatModels := &countingBatchIDChecker{
+		countingIDChecker: countingIDChecker{canHandle: isThreatModelID},
+		exists:            map[any]bool{tm1: true, tm3: true},
+	}
+	projects := &countingIDChecker{canHandle: isProjectID}
+
+	checker := NewBatchingIDChecker(idchecker.IDCheckerForTypes{threatModels, projects})
+
+	// when
+	exists, err := checker.CheckIDs(context.Background(), []any{tm1, p1, tm2, "unknown", tm3})
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, []bool{true, true, false, false, true}, exists)
+	require.Equal(t, [][]any{{tm1, tm2, tm3}}, threatModels.batches)
+	require.Equal(t, 0, threatModels.checks)
+	require.Equal(t, 1, projects.checks)
+}
+
+func TestCheckIDsFallsBackToCheckID(t *testing.T) {
+	id1 := m.NewDataFlowDiagramIDPPtr("dfd-1")
+	id2 := m.NewDataFlowDiagramIDPPtr("dfd-2")
+
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIDChecker := idchecker.NewMockIDChecker(ctrl)
+	mockIDChecker.EXPECT().CheckID(gomock.Any(), id1).Return(true, nil)
+	mockIDChecker.EXPECT().CheckID(gomock.Any(), id2).Return(false, nil)
+
+	// when
+	exists, err := CheckIDs(context.Background(), mockIDChecker, []any{id1, id2})
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, []bool{true, false}, exists)
+}
+
+func TestDataFlowDiagramIDCheckerCheckIDs(t *testing.T) {
+	id1 := m.NewDataFlowDiagramIDPPtr("dfd-1")
+	id2 := m.NewDataFlowDiagramIDPPtr("dfd-2")
+	id3 := m.NewDataFlowDiagramIDPPtr("dfd-3")
+
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIDChecker := idchecker.NewMockIDChecker(ctrl)
+	mockIDChecker.EXPECT().CheckID(gomock.Any(), id1).Return(true, nil)
+	mockIDChecker.EXPECT().CheckID(gomock.Any(), id2).Return(false, nil)
+	mockIDChecker.EXPECT().CheckID(gomock.Any(), id3).Return(true, nil)
+
+	checker := &DataFlowDiagramIDChecker{dataFlowDiagramIDChecker{mockIDChecker}}
+
+	// when
+	exists, err := checker.CheckIDs(context.Background(), []any{id1, id2, id3})
+
+	// then each ID is checked on its own
+	require.Nil(t, err)
+	require.Equal(t, []bool{true, false, true}, exists)
+
+	// when a check fails
+	mockIDChecker.EXPECT().CheckID(gomock.Any(), id1).Return(false, errors.New("connection refused"))
+
+	_, err = checker.CheckIDs(context.Background(), []any{id1})
+
+	// then
+	require.NotNil(t, err)
+}
+
+func TestServiceThreatModelIDCheckerCheckIDs(t *testing.T) {
+	tm1 := m.NewThreatModelIDP("tm-1")
+	tm2 := m.NewThreatModelIDP("tm-2")
+	tm404 := m.NewThreatModelIDP("tm-404")
+
+	// more IDs than one GetMany may take, none of which exist
+	manyIDs := make([]any, MaxGetManyIDs+1)
+	for i := range manyIDs {
+		manyIDs[i] = m.NewThreatModelIDP(fmt.Sprintf("tm-%d", i))
+	}
+
+	var tests = []struct {
+		name           string
+		ids            []any
+		setup          func(mockService *MockThreatModelService)
+		expectedResult []bool
+		expectedError  error
+	}{
+		{
+			"should check IDs with one GetMany",
+			[]any{tm1, &tm2, tm404},
+			func(mockService *MockThreatModelService) {
+				mockService.EXPECT().GetMany(gomock.Any(), []m.ThreatModelID{tm1, tm2, tm404}).Return(&tm.BatchGetResult{
+					ThreatModels: []*m.ThreatModel{{ThreatModelID: tm1}, {ThreatModelID: tm2}},
+					Missing:      []m.ThreatModelID{tm404},
+				}, nil)
+			},
+			[]bool{true, true, false},
+			nil,
+		},
+		{
+			"should split IDs into batches GetMany can take",
+			manyIDs,
+			func(mockService *MockThreatModelService) {
+				mockService.EXPECT().GetMany(gomock.Any(), gomock.Len(MaxGetManyIDs)).Return(&tm.BatchGetResult{}, nil)
+				mockService.EXPECT().GetMany(gomock.Any(), gomock.Len(1)).Return(&tm.BatchGetResult{}, nil)
+			},
+			make([]bool, MaxGetManyIDs+1),
+			nil,
+		},
+		{
+			"should fail if GetMany fails",
+			[]any{tm1, tm2},
+			func(mockService *MockThreatModelService) {
+				mockService.EXPECT().GetMany(gomock.Any(), gomock.Any()).Return(nil, errors.New("failure"))
+			},
+			nil,
+			errors.New("failure"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := NewMockThreatModelService(ctrl)
+			test.setup(mockService)
+
+			checker := NewServiceThreatModelIDChecker(mockService)
+
+			// when
+			result, err := checker.CheckIDs(context.Background(), test.ids)
+
+			// then
+			require.Equal(t, test.expectedError, err)
+			require.Equal(t, test.expectedResult, result)
+		})
+	}
+}
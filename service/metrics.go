@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-service-util/idchecker"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentedThreatModelService decorates a ThreatModelService, recording
+// the latency and errors of each method and a span for each call.
+type InstrumentedThreatModelService struct {
+	next    ThreatModelService
+	metrics *metrics.Metrics
+}
+
+var _ ThreatModelService = (*InstrumentedThreatModelService)(nil)
+
+func NewInstrumentedThreatModelService(next *AuditingThreatModelService, metrics *metrics.Metrics) *InstrumentedThreatModelService {
+	return &InstrumentedThreatModelService{next, metrics}
+}
+
+// instrument starts a span for a call to method, returning the span's
+// context and a function to call with the outcome once the call is done.
+func (s *InstrumentedThreatModelService) instrument(ctx context.Context, method string) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "ThreatModelService."+method)
+
+	return ctx, func(err error) {
+		if err == ErrNoSuchThreatModel {
+			err = nil
+		}
+		metrics.ObserveOperation(s.metrics.ServiceDuration, s.metrics.ServiceErrors, method, start, err)
+		tracing.End(span, err)
+	}
+}
+
+func (s *InstrumentedThreatModelService) Get(ctx context.Context, id m.ThreatModelID) (*m.ThreatModel, error) {
+	ctx, done := s.instrument(ctx, "Get")
+	result, err := s.next.Get(ctx, id)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedThreatModelService) GetMany(ctx context.Context, ids []m.ThreatModelID) (*tm.BatchGetResult, error) {
+	ctx, done := s.instrument(ctx, "GetMany")
+	result, err := s.next.GetMany(ctx, ids)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedThreatModelService) GetAll(ctx context.Context) ([]*m.ThreatModel, error) {
+	ctx, done := s.instrument(ctx, "GetAll")
+	result, err := s.next.GetAll(ctx)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedThreatModelService) Query(ctx context.Context, q *m.ThreatModelQuery) ([]*m.ThreatModel, error) {
+	ctx, done := s.instrument(ctx, "Query")
+	result, err := s.next.Query(ctx, q)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedThreatModelService) QuerySingle(ctx context.Context, q *m.ThreatModelQuery) (*m.ThreatModel, error) {
+	ctx, done := s.instrument(ctx, "QuerySingle")
+	result, err := s.next.QuerySingle(ctx, q)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedThreatModelService) Create(ctx context.Context, params m.ThreatModelParams) (*m.ThreatModel, error) {
+	ctx, done := s.instrument(ctx, "Create")
+	result, err := s.next.Create(ctx, params)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedThreatModelService) Update(ctx context.Context, id m.ThreatModelID, params m.ThreatModelParams) (*m.ThreatModel, error) {
+	ctx, done := s.instrument(ctx, "Update")
+	result, err := s.next.Update(ctx, id, params)
+	done(err)
+	return result, err
+}
+
+func (s *InstrumentedThreatModelService) Delete(ctx context.Context, id m.ThreatModelID) error {
+	ctx, done := s.instrument(ctx, "Delete")
+	err := s.next.Delete(ctx, id)
+	done(err)
+	return err
+}
+
+// InstrumentedIDCheckerForType decorates an IDCheckerForType, recording the
+// latency and failures of its checks under the given name, and a span for
+// each check. For checkers backed by other APIs (such as the DFD API) this
+// measures those calls.
+type InstrumentedIDCheckerForType struct {
+	next    idchecker.IDCheckerForType
+	name    string
+	metrics *metrics.Metrics
+}
+
+var _ BatchIDCheckerForType = (*InstrumentedIDCheckerForType)(nil)
+
+func NewInstrumentedIDCheckerForType(next idchecker.IDCheckerForType, name string, metrics *metrics.Metrics) *InstrumentedIDCheckerForType {
+	return &InstrumentedIDCheckerForType{next, name, metrics}
+}
+
+func (c *InstrumentedIDCheckerForType) CanHandle(id any) bool {
+	return c.next.CanHandle(id)
+}
+
+func (c *InstrumentedIDCheckerForType) CheckID(ctx context.Context, id any) (bool, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "IDChecker.CheckID", trace.WithAttributes(attribute.String("checker", c.name)))
+
+	result, err := c.next.CheckID(ctx, id)
+
+	metrics.ObserveOperation(c.metrics.IDCheckDuration, c.metrics.IDCheckFailures, c.name, start, err)
+	tracing.End(span, err)
+
+	return result, err
+}
+
+// CheckIDs checks ids all at once if the decorated checker can, and one at
+// a time otherwise, recording the batch as a single check.
+func (c *InstrumentedIDCheckerForType) CheckIDs(ctx context.Context, ids []any) ([]bool, error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "IDChecker.CheckIDs", trace.WithAttributes(
+		attribute.String("checker", c.name),
+		attribute.Int("ids", len(ids)),
+	))
+
+	var result []bool
+	var err error
+	if batch, ok := c.next.(BatchIDCheckerForType); ok {
+		result, err = batch.CheckIDs(ctx, ids)
+	} else {
+		result, err = checkIDsInTurn(ctx, c.next, ids)
+	}
+
+	metrics.ObserveOperation(c.metrics.IDCheckDuration, c.metrics.IDCheckFailures, c.name, start, err)
+	tracing.End(span, err)
+
+	return result, err
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentedThreatModelService(t *testing.T) {
+	id := m.NewThreatModelIDP("tm-1")
+
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockThreatModelService(ctrl)
+	ctx := inTenant("acme")
+
+	gomock.InOrder(
+		mockService.EXPECT().Delete(gomock.Any(), id).Return(nil),
+		mockService.EXPECT().Delete(gomock.Any(), id).Return(errors.New("datastore unavailable")),
+		mockService.EXPECT().Get(gomock.Any(), id).Return(nil, ErrNoSuchThreatModel),
+	)
+
+	metrics := metrics.NewMetrics()
+	service := &InstrumentedThreatModelService{mockService, metrics}
+
+	// when
+	service.Delete(ctx, id)
+	service.Delete(ctx, id)
+	service.Get(ctx, id)
+
+	// then
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.ServiceErrors.WithLabelValues("Delete")))
+	require.Equal(t, 0.0, testutil.ToFloat64(metrics.ServiceErrors.WithLabelValues("Get")))
+	require.Equal(t, 2, testutil.CollectAndCount(metrics.ServiceDuration))
+}
+
+func TestThreatModelCounter(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDao := dao.NewMockThreatModelDao(ctrl)
+	mockDao.EXPECT().GetTenants(gomock.Any()).Return([]auth.TenantID{"acme", "globex", "initech"}, nil).Times(2)
+	mockDao.EXPECT().Count(gomock.Any()).DoAndReturn(func(ctx context.Context) (int, error) {
+		tenantID, err := auth.TenantIDFromContext(ctx)
+		require.Nil(t, err)
+
+		switch tenantID {
+		case "acme":
+			return 3, nil
+		case "globex":
+			return 0, nil
+		}
+		return 0, errors.New("datastore unavailable")
+	}).Times(6)
+
+	metrics := metrics.NewMetrics()
+	metrics.ThreatModels.WithLabelValues("initech").Set(7)
+	counter := NewThreatModelCounter(mockDao, metrics)
+
+	// when counted, and counted again
+	require.Nil(t, counter.CountOnce(context.Background()))
+	require.Nil(t, counter.CountOnce(context.Background()))
+
+	// then the counts are those in the store, and tenants that could not be
+	// counted keep their last count
+	require.Equal(t, 3.0, testutil.ToFloat64(metrics.ThreatModels.WithLabelValues("acme")))
+	require.Equal(t, 0.0, testutil.ToFloat64(metrics.ThreatModels.WithLabelValues("globex")))
+	require.Equal(t, 7.0, testutil.ToFloat64(metrics.ThreatModels.WithLabelValues("initech")))
+}
+
+func TestInstrumentedIDCheckerForType(t *testing.T) {
+	// given
+	metrics := metrics.NewMetrics()
+	checker := NewInstrumentedIDCheckerForType(failingIDChecker{}, "dfd", metrics)
+
+	// when
+	_, err := checker.CheckID(context.Background(), "dfd-1")
+
+	// then
+	require.NotNil(t, err)
+	require.Equal(t, 1.0, testutil.ToFloat64(metrics.IDCheckFailures.WithLabelValues("dfd")))
+}
+
+// failingIDChecker fails every check, as if its API were down.
+type failingIDChecker struct{}
+
+func (failingIDChecker) CanHandle(id any) bool {
+	return true
+}
+
+func (failingIDChecker) CheckID(ctx context.Context, id any) (bool, error) {
+	return false, errors.New("connection refused")
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+// outboxRecord returns a dao.OutboxRecordFunc building the record of a
+// ThreatModelEvent of type eventType, in the tenant of ctx. The records are
+// published to downstream systems by the outbox relay.
+func outboxRecord(ctx context.Context, eventType string) dao.OutboxRecordFunc {
+	return func(threatModel *m.ThreatModel, sequence int64) (*tm.OutboxRecord, error) {
+		tenantID, err := auth.TenantIDFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now().UTC()
+
+		event := tm.ThreatModelEvent{
+			Type:          eventType,
+			ThreatModelID: threatModel.ThreatModelID,
+			TenantID:      tenantID.String(),
+			Time:          now,
+			Sequence:      sequence,
+		}
+		if eventType != tm.ThreatModelDeleted {
+			event.ThreatModel = threatModel
+		}
+
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding %s event: %v", eventType, err)
+		}
+
+		return &tm.OutboxRecord{
+			Type:          eventType,
+			Time:          now,
+			Payload:       payload,
+			NextAttemptAt: now,
+		}, nil
+	}
+}
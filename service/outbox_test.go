@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxRecord(t *testing.T) {
+	threatModel := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-1"), Title: "Payments gateway"}
+
+	var tests = []struct {
+		name                string
+		eventType           string
+		expectedThreatModel *m.ThreatModel
+	}{
+		{"should include created threat models", tm.ThreatModelCreated, threatModel},
+		{"should include updated threat models", tm.ThreatModelUpdated, threatModel},
+		{"should omit deleted threat models", tm.ThreatModelDeleted, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// when
+			record, err := outboxRecord(inTenant("acme"), test.eventType)(threatModel, 3)
+
+			// then
+			require.Nil(t, err)
+			require.Equal(t, test.eventType, record.Type)
+			require.Equal(t, record.Time, record.NextAttemptAt)
+
+			event := tm.ThreatModelEvent{}
+			require.Nil(t, json.Unmarshal(record.Payload, &event))
+
+			require.Equal(t, tm.ThreatModelEvent{
+				Type:          test.eventType,
+				ThreatModelID: threatModel.ThreatModelID,
+				TenantID:      "acme",
+				Time:          record.Time,
+				Sequence:      3,
+				ThreatModel:   test.expectedThreatModel,
+			}, event)
+		})
+	}
+}
+
+func TestOutboxRecordWithoutTenant(t *testing.T) {
+	_, err := outboxRecord(context.Background(), tm.ThreatModelCreated)(&m.ThreatModel{}, 1)
+
+	require.Equal(t, auth.ErrNoTenant, err)
+}
@@ -0,0 +1,595 @@
+package service
+
+//go:generate mockgen -source=$GOFILE -destination=${GOFILE}_mocks.go -package $GOPACKAGE
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-model/validator"
+	servicedao "github.com/jtyers/tmaas-service-dao"
+	"github.com/jtyers/tmaas-service-util/idchecker"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	dao "github.com/jtyers/tmaas-threat-model-api/dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+var (
+	ErrNoSuchProject           = errors.New("no such project")
+	ErrInsufficientProjectRole = errors.New("your role on this project does not permit this")
+	ErrEmptyProjectName        = errors.New("project name must not be empty")
+	ErrProjectCycle            = errors.New("a project cannot be moved beneath itself or one of its sub-projects")
+	ErrProjectNotEmpty         = errors.New("a project must have no sub-projects or threat models before it can be deleted")
+	ErrInvalidProjectRole      = errors.New("role must be 'viewer', 'editor' or 'owner'")
+	ErrLastProjectOwner        = errors.New("a top-level project must keep at least one owner")
+)
+
+// ThreatModelAccessChecker enforces the permissions a threat model
+// inherits from the project it belongs to.
+type ThreatModelAccessChecker interface {
+	// Check that the caller has at least the given role on the project
+	// containing a threat model. Threat models not in a project are
+	// unaffected. Callers with no role at all get ErrNoSuchThreatModel,
+	// so as not to reveal that the threat model exists.
+	CheckThreatModelAccess(ctx context.Context, id m.ThreatModelID, role tm.ProjectRole) error
+
+	// Return those of ids on whose project the caller has at least the
+	// given role, in order, for routes returning many threat models.
+	// Threat models not in a project are always returned.
+	FilterThreatModelAccess(ctx context.Context, ids []m.ThreatModelID, role tm.ProjectRole) ([]m.ThreatModelID, error)
+}
+
+// FilterThreatModels returns those of threatModels on whose project the
+// caller has at least the given role, in order.
+func FilterThreatModels(ctx context.Context, checker ThreatModelAccessChecker, threatModels []*m.ThreatModel, role tm.ProjectRole) ([]*m.ThreatModel, error) {
+	ids := make([]m.ThreatModelID, len(threatModels))
+	for i, threatModel := range threatModels {
+		ids[i] = threatModel.ThreatModelID
+	}
+
+	permitted, err := checker.FilterThreatModelAccess(ctx, ids, role)
+	if err != nil {
+		return nil, err
+	}
+
+	isPermitted := map[m.ThreatModelID]bool{}
+	for _, id := range permitted {
+		isPermitted[id] = true
+	}
+
+	result := []*m.ThreatModel{}
+	for _, threatModel := range threatModels {
+		if isPermitted[threatModel.ThreatModelID] {
+			result = append(result, threatModel)
+		}
+	}
+
+	return result, nil
+}
+
+// ProjectService manages projects, which organise threat models into
+// nestable folders. Members' roles on a project apply to all of its
+// sub-projects and the threat models within them.
+type ProjectService interface {
+	ThreatModelAccessChecker
+
+	// Retrieve a Project by ID.
+	Get(ctx context.Context, id tm.ProjectID) (*tm.Project, error)
+
+	// Retrieve all projects on which the caller has a role.
+	GetAll(ctx context.Context) ([]*tm.Project, error)
+
+	// Creates a Project, with the caller as its owner.
+	Create(ctx context.Context, params tm.ProjectParams) (*tm.Project, error)
+
+	// Updates a Project.
+	Update(ctx context.Context, id tm.ProjectID, params tm.ProjectParams) (*tm.Project, error)
+
+	// Delete an empty Project by ID.
+	Delete(ctx context.Context, id tm.ProjectID) error
+
+	// Give a user a role on a project, replacing any role they already have.
+	SetMember(ctx context.Context, id tm.ProjectID, userID m.UserID, role tm.ProjectRole) (*tm.Project, error)
+
+	// Remove a user's role on a project.
+	RemoveMember(ctx context.Context, id tm.ProjectID, userID m.UserID) (*tm.Project, error)
+
+	// Retrieve the threat models directly within a project.
+	GetThreatModels(ctx context.Context, id tm.ProjectID) ([]*m.ThreatModel, error)
+
+	// Move a threat model into a project, or out of all projects if
+	// projectID is nil.
+	SetThreatModelProject(ctx context.Context, threatModelID m.ThreatModelID, projectID *tm.ProjectID) error
+}
+
+type DefaultProjectService struct {
+	*ProjectAccessChecker
+
+	dao                dao.ProjectDao
+	threatModelService ThreatModelService
+	validator          validator.StructValidator
+	idChecker          idchecker.IDChecker
+	now                func() time.Time
+}
+
+var _ ProjectService = (*DefaultProjectService)(nil)
+
+func NewDefaultProjectService(
+	dao dao.ProjectDao,
+	threatModelService ThreatModelService,
+	validator validator.StructValidator,
+	idChecker idchecker.IDChecker,
+) *DefaultProjectService {
+	return &DefaultProjectService{NewProjectAccessChecker(dao), dao, threatModelService, validator, idChecker, time.Now}
+}
+
+func (s *DefaultProjectService) Get(ctx context.Context, id tm.ProjectID) (*tm.Project, error) {
+	return s.getWithRole(ctx, id, tm.ProjectRoleViewer)
+}
+
+func (s *DefaultProjectService) GetAll(ctx context.Context) ([]*tm.Project, error) {
+	identity, err := auth.IdentityFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := s.dao.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error in GetAll: %v", err)
+	}
+
+	byID := map[tm.ProjectID]*tm.Project{}
+	for _, p := range projects {
+		byID[p.ProjectID] = p
+	}
+	lookup := func(ctx context.Context, id tm.ProjectID) (*tm.Project, error) {
+		if p, ok := byID[id]; ok {
+			return p, nil
+		}
+		return nil, servicedao.ErrNoSuchDocument
+	}
+
+	result := []*tm.Project{}
+	for _, p := range projects {
+		role, err := effectiveRole(ctx, identity, p, lookup)
+		if err != nil {
+			return nil, err
+		}
+		if role != "" {
+			result = append(result, p)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *DefaultProjectService) Create(ctx context.Context, params tm.ProjectParams) (*tm.Project, error) {
+	userID, err := auth.UserIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.validator.ValidateForCreate(params)
+	if err != nil {
+		return nil, err
+	}
+	if params.Name == nil || strings.TrimSpace(*params.Name) == "" {
+		return nil, ErrEmptyProjectName
+	}
+
+	if params.ParentProjectID != nil {
+		if _, err := s.getWithRole(ctx, *params.ParentProjectID, tm.ProjectRoleEditor); err != nil {
+			return nil, err
+		}
+	}
+
+	now := s.now()
+	project := tm.Project{
+		ParentProjectID: params.ParentProjectID,
+		Name:            strings.TrimSpace(*params.Name),
+		Members:         []tm.ProjectMember{{UserID: userID, Role: tm.ProjectRoleOwner}},
+		Created:         now,
+		Updated:         now,
+	}
+	if params.Description != nil {
+		project.Description = *params.Description
+	}
+
+	result, err := s.dao.Create(ctx, project)
+	if err != nil {
+		return nil, fmt.Errorf("error creating project: %v", err)
+	}
+
+	return result, nil
+}
+
+func (s *DefaultProjectService) Update(ctx context.Context, id tm.ProjectID, params tm.ProjectParams) (*tm.Project, error) {
+	err := s.validator.ValidateForUpdate(params)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.getWithRole(ctx, id, tm.ProjectRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Name != nil {
+		if strings.TrimSpace(*params.Name) == "" {
+			return nil, ErrEmptyProjectName
+		}
+		project.Name = strings.TrimSpace(*params.Name)
+	}
+	if params.Description != nil {
+		project.Description = *params.Description
+	}
+
+	if params.ParentProjectID != nil {
+		if *params.ParentProjectID == "" {
+			project.ParentProjectID = nil
+
+		} else {
+			if err := s.checkNewParent(ctx, id, *params.ParentProjectID); err != nil {
+				return nil, err
+			}
+			project.ParentProjectID = params.ParentProjectID
+		}
+	}
+
+	project.Updated = s.now()
+
+	result, err := s.dao.Update(ctx, *project)
+	if err != nil {
+		return nil, fmt.Errorf("error updating project: %v", err)
+	}
+
+	return result, nil
+}
+
+func (s *DefaultProjectService) Delete(ctx context.Context, id tm.ProjectID) error {
+	if _, err := s.getWithRole(ctx, id, tm.ProjectRoleOwner); err != nil {
+		return err
+	}
+
+	projects, err := s.dao.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("error in GetAll: %v", err)
+	}
+	for _, p := range projects {
+		if p.ParentProjectID != nil && *p.ParentProjectID == id {
+			return ErrProjectNotEmpty
+		}
+	}
+
+	threatModelIDs, err := s.dao.GetThreatModelIDs(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error in GetThreatModelIDs: %v", err)
+	}
+	if len(threatModelIDs) > 0 {
+		return ErrProjectNotEmpty
+	}
+
+	err = s.dao.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error deleting project: %v", err)
+	}
+
+	return nil
+}
+
+func (s *DefaultProjectService) SetMember(ctx context.Context, id tm.ProjectID, userID m.UserID, role tm.ProjectRole) (*tm.Project, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidProjectRole
+	}
+
+	project, err := s.getWithRole(ctx, id, tm.ProjectRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []tm.ProjectMember{}
+	for _, member := range project.Members {
+		if member.UserID != userID {
+			members = append(members, member)
+		}
+	}
+	members = append(members, tm.ProjectMember{UserID: userID, Role: role})
+
+	return s.updateMembers(ctx, project, members)
+}
+
+func (s *DefaultProjectService) RemoveMember(ctx context.Context, id tm.ProjectID, userID m.UserID) (*tm.Project, error) {
+	project, err := s.getWithRole(ctx, id, tm.ProjectRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []tm.ProjectMember{}
+	for _, member := range project.Members {
+		if member.UserID != userID {
+			members = append(members, member)
+		}
+	}
+
+	return s.updateMembers(ctx, project, members)
+}
+
+func (s *DefaultProjectService) GetThreatModels(ctx context.Context, id tm.ProjectID) ([]*m.ThreatModel, error) {
+	if _, err := s.getWithRole(ctx, id, tm.ProjectRoleViewer); err != nil {
+		return nil, err
+	}
+
+	threatModelIDs, err := s.dao.GetThreatModelIDs(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error in GetThreatModelIDs: %v", err)
+	}
+
+	result := []*m.ThreatModel{}
+	for _, threatModelID := range threatModelIDs {
+		threatModel, err := s.threatModelService.Get(ctx, threatModelID)
+		if err == ErrNoSuchThreatModel {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		result = append(result, threatModel)
+	}
+
+	return result, nil
+}
+
+func (s *DefaultProjectService) SetThreatModelProject(ctx context.Context, threatModelID m.ThreatModelID, projectID *tm.ProjectID) error {
+	// moving a threat model out of its current project needs the same
+	// role as moving it into a new one
+	if err := s.CheckThreatModelAccess(ctx, threatModelID, tm.ProjectRoleEditor); err != nil {
+		return err
+	}
+
+	if _, err := s.threatModelService.Get(ctx, threatModelID); err != nil {
+		return err
+	}
+
+	if projectID != nil {
+		exists, err := s.idChecker.CheckID(ctx, projectID)
+		if err != nil {
+			return fmt.Errorf("CheckID failed: %v", err)
+		}
+		if !exists {
+			return ErrNoSuchProject
+		}
+
+		if _, err := s.getWithRole(ctx, *projectID, tm.ProjectRoleEditor); err != nil {
+			return err
+		}
+	}
+
+	err := s.dao.SetThreatModelProject(ctx, threatModelID, projectID)
+	if err != nil {
+		return fmt.Errorf("error in SetThreatModelProject: %v", err)
+	}
+
+	return nil
+}
+
+// checkNewParent checks that the project id may be moved beneath parentID:
+// the caller must be able to edit parentID, and parentID must not be id
+// or one of its descendants.
+func (s *DefaultProjectService) checkNewParent(ctx context.Context, id tm.ProjectID, parentID tm.ProjectID) error {
+	parent, err := s.getWithRole(ctx, parentID, tm.ProjectRoleEditor)
+	if err != nil {
+		return err
+	}
+
+	for p := parent; p != nil; {
+		if p.ProjectID == id {
+			return ErrProjectCycle
+		}
+		if p.ParentProjectID == nil {
+			break
+		}
+
+		p, err = s.dao.Get(ctx, *p.ParentProjectID)
+		if err == servicedao.ErrNoSuchDocument {
+			break
+		} else if err != nil {
+			return fmt.Errorf("error retrieving project: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *DefaultProjectService) updateMembers(ctx context.Context, project *tm.Project, members []tm.ProjectMember) (*tm.Project, error) {
+	// sub-projects inherit their parent's owners, but a top-level project
+	// would be orphaned without one
+	if project.ParentProjectID == nil {
+		owners := 0
+		for _, member := range members {
+			if member.Role == tm.ProjectRoleOwner {
+				owners++
+			}
+		}
+		if owners == 0 {
+			return nil, ErrLastProjectOwner
+		}
+	}
+
+	project.Members = members
+	project.Updated = s.now()
+
+	result, err := s.dao.Update(ctx, *project)
+	if err != nil {
+		return nil, fmt.Errorf("error updating project: %v", err)
+	}
+
+	return result, nil
+}
+
+// ProjectAccessChecker is the ThreatModelAccessChecker of
+// DefaultProjectService. It needs only the project DAO, so services that
+// DefaultProjectService depends on can check access too.
+type ProjectAccessChecker struct {
+	dao dao.ProjectDao
+}
+
+var _ ThreatModelAccessChecker = (*ProjectAccessChecker)(nil)
+
+func NewProjectAccessChecker(dao dao.ProjectDao) *ProjectAccessChecker {
+	return &ProjectAccessChecker{dao}
+}
+
+func (c *ProjectAccessChecker) CheckThreatModelAccess(ctx context.Context, id m.ThreatModelID, role tm.ProjectRole) error {
+	projectID, err := c.dao.GetThreatModelProject(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error in GetThreatModelProject: %v", err)
+	}
+	if projectID == nil {
+		return nil
+	}
+
+	_, err = c.getWithRole(ctx, *projectID, role)
+	if err == ErrNoSuchProject {
+		return ErrNoSuchThreatModel
+	}
+	return err
+}
+
+// getWithRole retrieves a project, checking the caller has at least the
+// given role on it. Callers with no role at all get ErrNoSuchProject, so
+// as not to reveal that the project exists.
+func (c *ProjectAccessChecker) getWithRole(ctx context.Context, id tm.ProjectID, role tm.ProjectRole) (*tm.Project, error) {
+	identity, err := auth.IdentityFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := c.dao.Get(ctx, id)
+	if err != nil {
+		if err == servicedao.ErrNoSuchDocument {
+			return nil, ErrNoSuchProject
+		}
+		return nil, fmt.Errorf("error retrieving project: %v", err)
+	}
+
+	actual, err := effectiveRole(ctx, identity, project, c.dao.Get)
+	if err != nil {
+		return nil, err
+	}
+
+	if actual == "" {
+		return nil, ErrNoSuchProject
+	}
+	if !actual.Includes(role) {
+		return nil, ErrInsufficientProjectRole
+	}
+
+	return project, nil
+}
+
+func (c *ProjectAccessChecker) FilterThreatModelAccess(ctx context.Context, ids []m.ThreatModelID, role tm.ProjectRole) ([]m.ThreatModelID, error) {
+	identity, err := auth.IdentityFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	projectIDs, err := c.dao.GetThreatModelProjects(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error in GetThreatModelProjects: %v", err)
+	}
+
+	// load every project at most once, rather than once per threat model
+	// and ancestor
+	projects := map[tm.ProjectID]*tm.Project{}
+	lookup := func(ctx context.Context, id tm.ProjectID) (*tm.Project, error) {
+		if p, ok := projects[id]; ok {
+			if p == nil {
+				return nil, servicedao.ErrNoSuchDocument
+			}
+			return p, nil
+		}
+
+		p, err := c.dao.Get(ctx, id)
+		if err == servicedao.ErrNoSuchDocument {
+			projects[id] = nil
+		} else if err == nil {
+			projects[id] = p
+		}
+		return p, err
+	}
+
+	roles := map[tm.ProjectID]tm.ProjectRole{}
+	result := []m.ThreatModelID{}
+
+	for _, id := range ids {
+		projectID, inProject := projectIDs[id]
+		if !inProject {
+			result = append(result, id)
+			continue
+		}
+
+		actual, known := roles[projectID]
+		if !known {
+			project, err := lookup(ctx, projectID)
+			if err != nil && err != servicedao.ErrNoSuchDocument {
+				return nil, fmt.Errorf("error retrieving project: %v", err)
+			}
+
+			// as with CheckThreatModelAccess, threat models in a missing
+			// project are hidden
+			if project != nil {
+				actual, err = effectiveRole(ctx, identity, project, lookup)
+				if err != nil {
+					return nil, err
+				}
+			}
+			roles[projectID] = actual
+		}
+
+		if actual != "" && actual.Includes(role) {
+			result = append(result, id)
+		}
+	}
+
+	return result, nil
+}
+
+// effectiveRole returns the strongest role the identity holds on project
+// or any of its ancestors, or "" if it has none. Service accounts act as
+// owners of every project.
+func effectiveRole(ctx context.Context, identity *auth.Identity, project *tm.Project, get func(context.Context, tm.ProjectID) (*tm.Project, error)) (tm.ProjectRole, error) {
+	if identity.IsServiceAccount() {
+		return tm.ProjectRoleOwner, nil
+	}
+
+	var result tm.ProjectRole
+	seen := map[tm.ProjectID]bool{}
+
+	for p := project; p != nil && !seen[p.ProjectID]; {
+		seen[p.ProjectID] = true
+
+		for _, member := range p.Members {
+			if member.UserID == identity.UserID && !result.Includes(member.Role) {
+				result = member.Role
+			}
+		}
+
+		if p.ParentProjectID == nil {
+			break
+		}
+
+		var err error
+		p, err = get(ctx, *p.ParentProjectID)
+		if err == servicedao.ErrNoSuchDocument {
+			break
+		} else if err != nil {
+			return "", fmt.Errorf("error retrieving project: %v", err)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,251 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: project.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/jtyers/tmaas-model"
+	model0 "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+// MockThreatModelAccessChecker is a mock of ThreatModelAccessChecker interface.
+type MockThreatModelAccessChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockThreatModelAccessCheckerMockRecorder
+}
+
+// MockThreatModelAccessCheckerMockRecorder is the mock recorder for MockThreatModelAccessChecker.
+type MockThreatModelAccessCheckerMockRecorder struct {
+	mock *MockThreatModelAccessChecker
+}
+
+// NewMockThreatModelAccessChecker creates a new mock instance.
+func NewMockThreatModelAccessChecker(ctrl *gomock.Controller) *MockThreatModelAccessChecker {
+	mock := &MockThreatModelAccessChecker{ctrl: ctrl}
+	mock.recorder = &MockThreatModelAccessCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockThreatModelAccessChecker) EXPECT() *MockThreatModelAccessCheckerMockRecorder {
+	return m.recorder
+}
+
+// CheckThreatModelAccess mocks base method.
+func (m *MockThreatModelAccessChecker) CheckThreatModelAccess(ctx context.Context, id model.ThreatModelID, role model0.ProjectRole) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckThreatModelAccess", ctx, id, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckThreatModelAccess indicates an expected call of CheckThreatModelAccess.
+func (mr *MockThreatModelAccessCheckerMockRecorder) CheckThreatModelAccess(ctx, id, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckThreatModelAccess", reflect.TypeOf((*MockThreatModelAccessChecker)(nil).CheckThreatModelAccess), ctx, id, role)
+}
+
+// FilterThreatModelAccess mocks base method.
+func (m *MockThreatModelAccessChecker) FilterThreatModelAccess(ctx context.Context, ids []model.ThreatModelID, role model0.ProjectRole) ([]model.ThreatModelID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterThreatModelAccess", ctx, ids, role)
+	ret0, _ := ret[0].([]model.ThreatModelID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilterThreatModelAccess indicates an expected call of FilterThreatModelAccess.
+func (mr *MockThreatModelAccessCheckerMockRecorder) FilterThreatModelAccess(ctx, ids, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterThreatModelAccess", reflect.TypeOf((*MockThreatModelAccessChecker)(nil).FilterThreatModelAccess), ctx, ids, role)
+}
+
+// MockProjectService is a mock of ProjectService interface.
+type MockProjectService struct {
+	ctrl     *gomock.Controller
+	recorder *MockProjectServiceMockRecorder
+}
+
+// MockProjectServiceMockRecorder is the mock recorder for MockProjectService.
+type MockProjectServiceMockRecorder struct {
+	mock *MockProjectService
+}
+
+// NewMockProjectService creates a new mock instance.
+func NewMockProjectService(ctrl *gomock.Controller) *MockProjectService {
+	mock := &MockProjectService{ctrl: ctrl}
+	mock.recorder = &MockProjectServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProjectService) EXPECT() *MockProjectServiceMockRecorder {
+	return m.recorder
+}
+
+// CheckThreatModelAccess mocks base method.
+func (m *MockProjectService) CheckThreatModelAccess(ctx context.Context, id model.ThreatModelID, role model0.ProjectRole) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckThreatModelAccess", ctx, id, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckThreatModelAccess indicates an expected call of CheckThreatModelAccess.
+func (mr *MockProjectServiceMockRecorder) CheckThreatModelAccess(ctx, id, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckThreatModelAccess", reflect.TypeOf((*MockProjectService)(nil).CheckThreatModelAccess), ctx, id, role)
+}
+
+// Create mocks base method.
+func (m *MockProjectService) Create(ctx context.Context, params model0.ProjectParams) (*model0.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, params)
+	ret0, _ := ret[0].(*model0.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockProjectServiceMockRecorder) Create(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockProjectService)(nil).Create), ctx, params)
+}
+
+// Delete mocks base method.
+func (m *MockProjectService) Delete(ctx context.Context, id model0.ProjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockProjectServiceMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockProjectService)(nil).Delete), ctx, id)
+}
+
+// FilterThreatModelAccess mocks base method.
+func (m *MockProjectService) FilterThreatModelAccess(ctx context.Context, ids []model.ThreatModelID, role model0.ProjectRole) ([]model.ThreatModelID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterThreatModelAccess", ctx, ids, role)
+	ret0, _ := ret[0].([]model.ThreatModelID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilterThreatModelAccess indicates an expected call of FilterThreatModelAccess.
+func (mr *MockProjectServiceMockRecorder) FilterThreatModelAccess(ctx, ids, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterThreatModelAccess", reflect.TypeOf((*MockProjectService)(nil).FilterThreatModelAccess), ctx, ids, role)
+}
+
+// Get mocks base method.
+func (m *MockProjectService) Get(ctx context.Context, id model0.ProjectID) (*model0.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*model0.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockProjectServiceMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockProjectService)(nil).Get), ctx, id)
+}
+
+// GetAll mocks base method.
+func (m *MockProjectService) GetAll(ctx context.Context) ([]*model0.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", ctx)
+	ret0, _ := ret[0].([]*model0.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockProjectServiceMockRecorder) GetAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockProjectService)(nil).GetAll), ctx)
+}
+
+// GetThreatModels mocks base method.
+func (m *MockProjectService) GetThreatModels(ctx context.Context, id model0.ProjectID) ([]*model.ThreatModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetThreatModels", ctx, id)
+	ret0, _ := ret[0].([]*model.ThreatModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetThreatModels indicates an expected call of GetThreatModels.
+func (mr *MockProjectServiceMockRecorder) GetThreatModels(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetThreatModels", reflect.TypeOf((*MockProjectService)(nil).GetThreatModels), ctx, id)
+}
+
+// RemoveMember mocks base method.
+func (m *MockProjectService) RemoveMember(ctx context.Context, id model0.ProjectID, userID model.UserID) (*model0.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, id, userID)
+	ret0, _ := ret[0].(*model0.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockProjectServiceMockRecorder) RemoveMember(ctx, id, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockProjectService)(nil).RemoveMember), ctx, id, userID)
+}
+
+// SetMember mocks base method.
+func (m *MockProjectService) SetMember(ctx context.Context, id model0.ProjectID, userID model.UserID, role model0.ProjectRole) (*model0.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMember", ctx, id, userID, role)
+	ret0, _ := ret[0].(*model0.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetMember indicates an expected call of SetMember.
+func (mr *MockProjectServiceMockRecorder) SetMember(ctx, id, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMember", reflect.TypeOf((*MockProjectService)(nil).SetMember), ctx, id, userID, role)
+}
+
+// SetThreatModelProject mocks base method.
+func (m *MockProjectService) SetThreatModelProject(ctx context.Context, threatModelID model.ThreatModelID, projectID *model0.ProjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetThreatModelProject", ctx, threatModelID, projectID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetThreatModelProject indicates an expected call of SetThreatModelProject.
+func (mr *MockProjectServiceMockRecorder) SetThreatModelProject(ctx, threatModelID, projectID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetThreatModelProject", reflect.TypeOf((*MockProjectService)(nil).SetThreatModelProject), ctx, threatModelID, projectID)
+}
+
+// Update mocks base method.
+func (m *MockProjectService) Update(ctx context.Context, id model0.ProjectID, params model0.ProjectParams) (*model0.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, params)
+	ret0, _ := ret[0].(*model0.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockProjectServiceMockRecorder) Update(ctx, id, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockProjectService)(nil).Update), ctx, id, params)
+}
@@ -0,0 +1,567 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-model/validator"
+	servicedao "github.com/jtyers/tmaas-service-dao"
+	"github.com/jtyers/tmaas-service-util/idchecker"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/stretchr/testify/require"
+)
+
+// projectTree returns a mock DAO holding the projects:
+//
+//	prj-root (u-owner: owner, u-viewer: viewer)
+//	└── prj-child (u-editor: editor)
+//	    └── prj-grandchild
+func projectTree(ctrl *gomock.Controller) (*dao.MockProjectDao, map[tm.ProjectID]*tm.Project) {
+	rootID, childID := tm.ProjectID("prj-root"), tm.ProjectID("prj-child")
+
+	projects := map[tm.ProjectID]*tm.Project{
+		"prj-root": {
+			ProjectID: rootID,
+			Members: []tm.ProjectMember{
+				{UserID: "u-owner", Role: tm.ProjectRoleOwner},
+				{UserID: "u-viewer", Role: tm.ProjectRoleViewer},
+			},
+		},
+		"prj-child": {
+			ProjectID:       childID,
+			ParentProjectID: &rootID,
+			Members:         []tm.ProjectMember{{UserID: "u-editor", Role: tm.ProjectRoleEditor}},
+		},
+		"prj-grandchild": {
+			ProjectID:       "prj-grandchild",
+			ParentProjectID: &childID,
+			Members:         []tm.ProjectMember{},
+		},
+	}
+
+	mockDao := dao.NewMockProjectDao(ctrl)
+	mockDao.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id tm.ProjectID) (*tm.Project, error) {
+		if p, ok := projects[id]; ok {
+			copy := *p
+			return &copy, nil
+		}
+		return nil, servicedao.ErrNoSuchDocument
+	}).AnyTimes()
+	mockDao.EXPECT().GetAll(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]*tm.Project, error) {
+		result := []*tm.Project{}
+		for _, id := range []tm.ProjectID{"prj-root", "prj-child", "prj-grandchild"} {
+			result = append(result, projects[id])
+		}
+		return result, nil
+	}).AnyTimes()
+
+	return mockDao, projects
+}
+
+func asUser(userID m.UserID) context.Context {
+	return auth.WithIdentity(context.Background(), &auth.Identity{UserID: userID})
+}
+
+func TestProjectGetInheritsRoles(t *testing.T) {
+	var tests = []struct {
+		name          string
+		userID        m.UserID
+		projectID     tm.ProjectID
+		expectedError error
+	}{
+		{
+			"should allow direct members",
+			"u-viewer",
+			"prj-root",
+			nil,
+		},
+		{
+			"should allow members of an ancestor",
+			"u-viewer",
+			"prj-grandchild",
+			nil,
+		},
+		{
+			"should not grant roles on a parent to members of a child",
+			"u-editor",
+			"prj-root",
+			ErrNoSuchProject,
+		},
+		{
+			"should hide projects from non-members",
+			"u-stranger",
+			"prj-child",
+			ErrNoSuchProject,
+		},
+		{
+			"should return ErrNoSuchProject for non-existent projects",
+			"u-owner",
+			"prj-missing",
+			ErrNoSuchProject,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao, _ := projectTree(ctrl)
+
+			// when
+			service := NewDefaultProjectService(mockDao, nil, nil, nil)
+			result, err := service.Get(asUser(test.userID), test.projectID)
+
+			// then
+			require.Equal(t, test.expectedError, err)
+			if test.expectedError == nil {
+				require.Equal(t, test.projectID, result.ProjectID)
+			}
+		})
+	}
+}
+
+func TestProjectGetAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDao, _ := projectTree(ctrl)
+	service := NewDefaultProjectService(mockDao, nil, nil, nil)
+
+	ids := func(projects []*tm.Project) []tm.ProjectID {
+		result := []tm.ProjectID{}
+		for _, p := range projects {
+			result = append(result, p.ProjectID)
+		}
+		return result
+	}
+
+	result, err := service.GetAll(asUser("u-viewer"))
+	require.Nil(t, err)
+	require.Equal(t, []tm.ProjectID{"prj-root", "prj-child", "prj-grandchild"}, ids(result))
+
+	result, err = service.GetAll(asUser("u-editor"))
+	require.Nil(t, err)
+	require.Equal(t, []tm.ProjectID{"prj-child", "prj-grandchild"}, ids(result))
+
+	result, err = service.GetAll(asUser("u-stranger"))
+	require.Nil(t, err)
+	require.Equal(t, []tm.ProjectID{}, ids(result))
+}
+
+func TestProjectUpdateParent(t *testing.T) {
+	var tests = []struct {
+		name          string
+		userID        m.UserID
+		projectID     tm.ProjectID
+		newParentID   tm.ProjectID
+		expectedError error
+	}{
+		{
+			"should move a project beneath another",
+			"u-owner",
+			"prj-grandchild",
+			"prj-root",
+			nil,
+		},
+		{
+			"should move a project to the top level",
+			"u-owner",
+			"prj-child",
+			"",
+			nil,
+		},
+		{
+			"should refuse to move a project beneath itself",
+			"u-owner",
+			"prj-child",
+			"prj-child",
+			ErrProjectCycle,
+		},
+		{
+			"should refuse to move a project beneath its descendant",
+			"u-owner",
+			"prj-root",
+			"prj-grandchild",
+			ErrProjectCycle,
+		},
+		{
+			"should refuse viewers",
+			"u-viewer",
+			"prj-grandchild",
+			"prj-root",
+			ErrInsufficientProjectRole,
+		},
+		{
+			"should require editor on the new parent",
+			"u-editor",
+			"prj-grandchild",
+			"prj-root",
+			ErrNoSuchProject,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao, projects := projectTree(ctrl)
+			mockValidator := validator.NewMockStructValidator(ctrl)
+			mockValidator.EXPECT().ValidateForUpdate(gomock.Any()).Return(nil)
+			ctx := asUser(test.userID)
+
+			if test.expectedError == nil {
+				mockDao.EXPECT().Update(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, p tm.Project) (*tm.Project, error) {
+					return &p, nil
+				})
+			}
+
+			// when
+			service := NewDefaultProjectService(mockDao, nil, mockValidator, nil)
+			result, err := service.Update(ctx, test.projectID, tm.ProjectParams{ParentProjectID: &test.newParentID})
+
+			// then
+			require.Equal(t, test.expectedError, err)
+			if test.expectedError == nil {
+				if test.newParentID == "" {
+					require.Nil(t, result.ParentProjectID)
+				} else {
+					require.Equal(t, test.newParentID, *result.ParentProjectID)
+				}
+				require.Equal(t, projects[test.projectID].Members, result.Members)
+			}
+		})
+	}
+}
+
+func TestProjectDelete(t *testing.T) {
+	var tests = []struct {
+		name           string
+		userID         m.UserID
+		projectID      tm.ProjectID
+		threatModelIDs []m.ThreatModelID
+		expectedError  error
+	}{
+		{
+			"should delete empty projects",
+			"u-owner",
+			"prj-grandchild",
+			[]m.ThreatModelID{},
+			nil,
+		},
+		{
+			"should refuse projects with sub-projects",
+			"u-owner",
+			"prj-child",
+			nil,
+			ErrProjectNotEmpty,
+		},
+		{
+			"should refuse projects with threat models",
+			"u-owner",
+			"prj-grandchild",
+			[]m.ThreatModelID{m.NewThreatModelIDP("tm-1")},
+			ErrProjectNotEmpty,
+		},
+		{
+			"should refuse editors",
+			"u-editor",
+			"prj-grandchild",
+			nil,
+			ErrInsufficientProjectRole,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao, _ := projectTree(ctrl)
+			ctx := asUser(test.userID)
+
+			if test.threatModelIDs != nil {
+				mockDao.EXPECT().GetThreatModelIDs(ctx, test.projectID).Return(test.threatModelIDs, nil)
+			}
+			if test.expectedError == nil {
+				mockDao.EXPECT().Delete(ctx, test.projectID).Return(nil)
+			}
+
+			// when
+			service := NewDefaultProjectService(mockDao, nil, nil, nil)
+			err := service.Delete(ctx, test.projectID)
+
+			// then
+			require.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestProjectMembers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDao, _ := projectTree(ctrl)
+	ctx := asUser("u-owner")
+	mockDao.EXPECT().Update(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, p tm.Project) (*tm.Project, error) {
+		return &p, nil
+	})
+
+	service := NewDefaultProjectService(mockDao, nil, nil, nil)
+
+	result, err := service.SetMember(ctx, "prj-root", "u-viewer", tm.ProjectRoleEditor)
+	require.Nil(t, err)
+	require.Equal(t, []tm.ProjectMember{
+		{UserID: "u-owner", Role: tm.ProjectRoleOwner},
+		{UserID: "u-viewer", Role: tm.ProjectRoleEditor},
+	}, result.Members)
+
+	_, err = service.SetMember(ctx, "prj-root", "u-viewer", "admin")
+	require.Equal(t, ErrInvalidProjectRole, err)
+
+	_, err = service.RemoveMember(ctx, "prj-root", "u-owner")
+	require.Equal(t, ErrLastProjectOwner, err)
+
+	_, err = service.SetMember(asUser("u-editor"), "prj-child", "u-viewer", tm.ProjectRoleEditor)
+	require.Equal(t, ErrInsufficientProjectRole, err)
+}
+
+func TestSetThreatModelProject(t *testing.T) {
+	threatModelID := m.NewThreatModelIDP("tm-1")
+	grandchildID := tm.ProjectID("prj-grandchild")
+	missingID := tm.ProjectID("prj-missing")
+
+	var tests = []struct {
+		name           string
+		userID         m.UserID
+		currentProject *tm.ProjectID
+		newProject     *tm.ProjectID
+		checkIDResult  bool
+		checkIDError   error
+		expectedError  error
+	}{
+		{
+			"should move a threat model into a project",
+			"u-editor",
+			nil,
+			&grandchildID,
+			true,
+			nil,
+			nil,
+		},
+		{
+			"should move a threat model out of its project",
+			"u-editor",
+			&grandchildID,
+			nil,
+			false,
+			nil,
+			nil,
+		},
+		{
+			"should fail if CheckID() returns false",
+			"u-editor",
+			nil,
+			&missingID,
+			false,
+			nil,
+			ErrNoSuchProject,
+		},
+		{
+			"should fail if IDChecker fails",
+			"u-editor",
+			nil,
+			&grandchildID,
+			false,
+			fmt.Errorf("failure"),
+			fmt.Errorf("CheckID failed: failure"),
+		},
+		{
+			"should require editor on the new project",
+			"u-viewer",
+			nil,
+			&grandchildID,
+			true,
+			nil,
+			ErrInsufficientProjectRole,
+		},
+		{
+			"should require editor on the current project",
+			"u-viewer",
+			&grandchildID,
+			nil,
+			false,
+			nil,
+			ErrInsufficientProjectRole,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao, _ := projectTree(ctrl)
+			mockThreatModelService := NewMockThreatModelService(ctrl)
+			mockIDChecker := idchecker.NewMockIDChecker(ctrl)
+			ctx := asUser(test.userID)
+
+			mockDao.EXPECT().GetThreatModelProject(ctx, threatModelID).Return(test.currentProject, nil)
+			mockThreatModelService.EXPECT().Get(ctx, threatModelID).Return(&m.ThreatModel{ThreatModelID: threatModelID}, nil).AnyTimes()
+			if test.newProject != nil {
+				mockIDChecker.EXPECT().CheckID(ctx, test.newProject).Return(test.checkIDResult, test.checkIDError)
+			}
+			if test.expectedError == nil {
+				mockDao.EXPECT().SetThreatModelProject(ctx, threatModelID, test.newProject).Return(nil)
+			}
+
+			// when
+			service := NewDefaultProjectService(mockDao, mockThreatModelService, nil, mockIDChecker)
+			err := service.SetThreatModelProject(ctx, threatModelID, test.newProject)
+
+			// then
+			require.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestCheckThreatModelAccess(t *testing.T) {
+	threatModelID := m.NewThreatModelIDP("tm-1")
+	childID := tm.ProjectID("prj-child")
+
+	var tests = []struct {
+		name          string
+		identity      *auth.Identity
+		project       *tm.ProjectID
+		role          tm.ProjectRole
+		expectedError error
+	}{
+		{
+			"should allow anyone when the threat model is not in a project",
+			&auth.Identity{UserID: "u-stranger"},
+			nil,
+			tm.ProjectRoleEditor,
+			nil,
+		},
+		{
+			"should allow roles inherited from a parent project",
+			&auth.Identity{UserID: "u-viewer"},
+			&childID,
+			tm.ProjectRoleViewer,
+			nil,
+		},
+		{
+			"should refuse insufficient roles",
+			&auth.Identity{UserID: "u-viewer"},
+			&childID,
+			tm.ProjectRoleEditor,
+			ErrInsufficientProjectRole,
+		},
+		{
+			"should hide threat models from non-members",
+			&auth.Identity{UserID: "u-stranger"},
+			&childID,
+			tm.ProjectRoleViewer,
+			ErrNoSuchThreatModel,
+		},
+		{
+			"should allow service accounts",
+			&auth.Identity{ServiceAccountName: "sa@project.iam.gserviceaccount.com"},
+			&childID,
+			tm.ProjectRoleOwner,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao, _ := projectTree(ctrl)
+			ctx := auth.WithIdentity(context.Background(), test.identity)
+
+			mockDao.EXPECT().GetThreatModelProject(ctx, threatModelID).Return(test.project, nil)
+
+			// when
+			service := NewDefaultProjectService(mockDao, nil, nil, nil)
+			err := service.CheckThreatModelAccess(ctx, threatModelID, test.role)
+
+			// then
+			require.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
+func TestFilterThreatModelAccess(t *testing.T) {
+	unfiled, inRoot, inChild, inGrandchild, inMissing := m.NewThreatModelIDP("tm-1"), m.NewThreatModelIDP("tm-2"), m.NewThreatModelIDP("tm-3"), m.NewThreatModelIDP("tm-4"), m.NewThreatModelIDP("tm-5")
+	ids := []m.ThreatModelID{unfiled, inRoot, inChild, inGrandchild, inMissing}
+
+	var tests = []struct {
+		name     string
+		identity *auth.Identity
+		role     tm.ProjectRole
+		expected []m.ThreatModelID
+	}{
+		{
+			"should keep threat models not in a project and those in projects the caller can view",
+			&auth.Identity{UserID: "u-viewer"},
+			tm.ProjectRoleViewer,
+			[]m.ThreatModelID{unfiled, inRoot, inChild, inGrandchild},
+		},
+		{
+			"should drop threat models in projects where the caller's role is insufficient",
+			&auth.Identity{UserID: "u-editor"},
+			tm.ProjectRoleEditor,
+			[]m.ThreatModelID{unfiled, inChild, inGrandchild},
+		},
+		{
+			"should hide threat models in projects from non-members",
+			&auth.Identity{UserID: "u-stranger"},
+			tm.ProjectRoleViewer,
+			[]m.ThreatModelID{unfiled},
+		},
+		{
+			"should allow service accounts",
+			&auth.Identity{ServiceAccountName: "sa@project.iam.gserviceaccount.com"},
+			tm.ProjectRoleOwner,
+			[]m.ThreatModelID{unfiled, inRoot, inChild, inGrandchild},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao, _ := projectTree(ctrl)
+			ctx := auth.WithIdentity(context.Background(), test.identity)
+
+			mockDao.EXPECT().GetThreatModelProjects(ctx, ids).Return(map[m.ThreatModelID]tm.ProjectID{
+				inRoot:       "prj-root",
+				inChild:      "prj-child",
+				inGrandchild: "prj-grandchild",
+				inMissing:    "prj-deleted",
+			}, nil)
+
+			// when
+			result, err := NewProjectAccessChecker(mockDao).FilterThreatModelAccess(ctx, ids, test.role)
+
+			// then
+			require.Nil(t, err)
+			require.Equal(t, test.expected, result)
+		})
+	}
+}
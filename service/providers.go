@@ -9,30 +9,78 @@ import (
 	dfdclient "github.com/jtyers/tmaas-dfd-api/client"
 	"github.com/jtyers/tmaas-model/validator"
 	"github.com/jtyers/tmaas-service-util/idchecker"
+	"github.com/jtyers/tmaas-service-util/requestor"
+	"github.com/jtyers/tmaas-threat-model-api/cache"
+	"github.com/jtyers/tmaas-threat-model-api/events"
+	"github.com/jtyers/tmaas-threat-model-api/httpclient"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
 )
 
 var ServiceDepsProviderSet = wire.NewSet(
 	validator.StructValidatorProviderSet,
 )
 
-func NewIDCheckerForTypes(dfd *dfdclient.ClientDataFlowDiagramIDChecker) idchecker.IDCheckerForTypes {
+func NewIDCheckerForTypes(dfd *DataFlowDiagramIDChecker, project *DaoProjectIDChecker, metrics *metrics.Metrics) idchecker.IDCheckerForTypes {
 	return idchecker.IDCheckerForTypes([]idchecker.IDCheckerForType{
-		dfd,
+		NewInstrumentedIDCheckerForType(dfd, "dfd", metrics),
+		NewInstrumentedIDCheckerForType(project, "project", metrics),
 	})
 }
 
 var ThreatModelServiceProviderSet = wire.NewSet(
 	ServiceDepsProviderSet,
 
-	wire.Bind(new(ThreatModelService), new(*DefaultThreatModelService)),
+	wire.Bind(new(ThreatModelService), new(*InstrumentedThreatModelService)),
+	NewInstrumentedThreatModelService,
+	NewAuditingThreatModelService,
+	NewCachingThreatModelService,
 	NewDefaultThreatModelService,
 
+	wire.Bind(new(AuditService), new(*DefaultAuditService)),
+	wire.Bind(new(Auditor), new(*DefaultAuditService)),
+	NewDefaultAuditService,
+	NewAuditWriter,
+	NewThreatModelCounter,
+	NewAuditKeyConfig,
+	NewAuditKey,
+
 	NewServiceThreatModelIDChecker,
 
-	wire.Bind(new(idchecker.IDChecker), new(*idchecker.DefaultIDChecker)),
-	idchecker.NewDefaultIDChecker,
+	wire.Bind(new(CommentService), new(*DefaultCommentService)),
+	NewDefaultCommentService,
+
+	wire.Bind(new(ThreatModelRevisionService), new(*OutboxThreatModelRevisionService)),
+	NewOutboxThreatModelRevisionService,
+
+	wire.Bind(new(ThreatModelTagService), new(*DefaultThreatModelTagService)),
+	NewDefaultThreatModelTagService,
+
+	wire.Bind(new(ThreatModelSearchService), new(*IndexingThreatModelSearchService)),
+	NewIndexingThreatModelSearchService,
+	NewThreatModelWriteHooks,
+
+	wire.Bind(new(ProjectService), new(*DefaultProjectService)),
+	wire.Bind(new(ThreatModelAccessChecker), new(*ProjectAccessChecker)),
+	NewDefaultProjectService,
+	NewProjectAccessChecker,
+	NewDaoProjectIDChecker,
+	cache.CacheProviderSet,
+
+	wire.Bind(new(events.Handler), new(*DataFlowDiagramEventHandler)),
+	NewDataFlowDiagramEventHandler,
+	events.EventsProviderSet,
+
+	wire.Bind(new(idchecker.IDChecker), new(*BatchingIDChecker)),
+	NewBatchingIDChecker,
+	NewDataFlowDiagramIDChecker,
 
-	dfdclient.DataFlowDiagramServiceClientProviderSet,
+	// the DFD client's requests go through an http.Client of our own, not
+	// http.DefaultClient, so the client's own provider set is not used
+	dfdclient.NewDataFlowDiagramServiceClientConfig,
+	dfdclient.NewDataFlowDiagramServiceClient,
+	dfdclient.NewClientDataFlowDiagramIDChecker,
+	wire.Bind(new(requestor.RequestorWithContext), new(*httpclient.Requestor)),
+	httpclient.NewRequestor,
 
 	NewIDCheckerForTypes,
 )
@@ -0,0 +1,61 @@
+package service
+
+//go:generate mockgen -source=$GOFILE -destination=${GOFILE}_mocks.go -package $GOPACKAGE
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	m "github.com/jtyers/tmaas-model"
+	servicedao "github.com/jtyers/tmaas-service-dao"
+	dao "github.com/jtyers/tmaas-threat-model-api/dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+var ErrNoSuchRevision = errors.New("no such revision")
+
+// ThreatModelRevisionService retrieves earlier revisions of threat models.
+// Revision N of a threat model is the threat model as of its Nth change,
+// starting with its creation at revision 1; the snapshots are those
+// recorded in the outbox with each change.
+type ThreatModelRevisionService interface {
+	// Retrieve a revision of a threat model, returning ErrNoSuchRevision
+	// if there is none, or if that change deleted it.
+	GetRevision(ctx context.Context, id m.ThreatModelID, revision int64) (*m.ThreatModel, error)
+}
+
+type OutboxThreatModelRevisionService struct {
+	dao dao.OutboxDao
+}
+
+var _ ThreatModelRevisionService = (*OutboxThreatModelRevisionService)(nil)
+
+func NewOutboxThreatModelRevisionService(dao dao.OutboxDao) *OutboxThreatModelRevisionService {
+	return &OutboxThreatModelRevisionService{dao}
+}
+
+func (s *OutboxThreatModelRevisionService) GetRevision(ctx context.Context, id m.ThreatModelID, revision int64) (*m.ThreatModel, error) {
+	if revision < 1 {
+		return nil, ErrNoSuchRevision
+	}
+
+	record, err := s.dao.GetRecord(ctx, id, revision)
+	if err == servicedao.ErrNoSuchDocument {
+		return nil, ErrNoSuchRevision
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	event := tm.ThreatModelEvent{}
+	if err := json.Unmarshal(record.Payload, &event); err != nil {
+		return nil, fmt.Errorf("error decoding revision %d of %s: %v", revision, id, err)
+	}
+
+	if event.ThreatModel == nil {
+		return nil, ErrNoSuchRevision
+	}
+	return event.ThreatModel, nil
+}
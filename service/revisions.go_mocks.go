@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: revisions.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/jtyers/tmaas-model"
+)
+
+// MockThreatModelRevisionService is a mock of ThreatModelRevisionService interface.
+type MockThreatModelRevisionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockThreatModelRevisionServiceMockRecorder
+}
+
+// MockThreatModelRevisionServiceMockRecorder is the mock recorder for MockThreatModelRevisionService.
+type MockThreatModelRevisionServiceMockRecorder struct {
+	mock *MockThreatModelRevisionService
+}
+
+// NewMockThreatModelRevisionService creates a new mock instance.
+func NewMockThreatModelRevisionService(ctrl *gomock.Controller) *MockThreatModelRevisionService {
+	mock := &MockThreatModelRevisionService{ctrl: ctrl}
+	mock.recorder = &MockThreatModelRevisionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockThreatModelRevisionService) EXPECT() *MockThreatModelRevisionServiceMockRecorder {
+	return m.recorder
+}
+
+// GetRevision mocks base method.
+func (m *MockThreatModelRevisionService) GetRevision(ctx context.Context, id model.ThreatModelID, revision int64) (*model.ThreatModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", ctx, id, revision)
+	ret0, _ := ret[0].(*model.ThreatModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockThreatModelRevisionServiceMockRecorder) GetRevision(ctx, id, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockThreatModelRevisionService)(nil).GetRevision), ctx, id, revision)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	m "github.com/jtyers/tmaas-model"
+	servicedao "github.com/jtyers/tmaas-service-dao"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRevision(t *testing.T) {
+	threatModelID := m.NewThreatModelIDP("tm-1")
+	threatModel := &m.ThreatModel{ThreatModelID: threatModelID, Title: "Payments gateway"}
+
+	eventRecord := func(eventType string, threatModel *m.ThreatModel) *tm.OutboxRecord {
+		payload, err := json.Marshal(tm.ThreatModelEvent{Type: eventType, ThreatModelID: threatModelID, Sequence: 2, ThreatModel: threatModel})
+		require.Nil(t, err)
+		return &tm.OutboxRecord{Type: eventType, Sequence: 2, Payload: payload}
+	}
+
+	daoErr := errors.New("datastore unavailable")
+
+	var tests = []struct {
+		name          string
+		record        *tm.OutboxRecord
+		daoErr        error
+		expected      *m.ThreatModel
+		expectedError error
+	}{
+		{"should return the threat model as updated", eventRecord(tm.ThreatModelUpdated, threatModel), nil, threatModel, nil},
+		{"should not return deleted revisions", eventRecord(tm.ThreatModelDeleted, nil), nil, nil, ErrNoSuchRevision},
+		{"should not return missing revisions", nil, servicedao.ErrNoSuchDocument, nil, ErrNoSuchRevision},
+		{"should return other errors", nil, daoErr, nil, daoErr},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao := dao.NewMockOutboxDao(ctrl)
+			service := NewOutboxThreatModelRevisionService(mockDao)
+
+			ctx := inTenant("acme")
+			mockDao.EXPECT().GetRecord(ctx, threatModelID, int64(2)).Return(test.record, test.daoErr)
+
+			// when
+			result, err := service.GetRevision(ctx, threatModelID, 2)
+
+			// then
+			require.Equal(t, test.expectedError, err)
+			require.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestGetRevisionBeforeFirst(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := NewOutboxThreatModelRevisionService(dao.NewMockOutboxDao(ctrl))
+
+	_, err := service.GetRevision(inTenant("acme"), m.NewThreatModelIDP("tm-1"), 0)
+
+	require.Equal(t, ErrNoSuchRevision, err)
+}
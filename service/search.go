@@ -0,0 +1,180 @@
+package service
+
+//go:generate mockgen -source=$GOFILE -destination=${GOFILE}_mocks.go -package $GOPACKAGE
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-service-util/log"
+	dao "github.com/jtyers/tmaas-threat-model-api/dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/search"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+var (
+	ErrEmptySearchQuery = errors.New("search query must contain at least one letter or digit")
+)
+
+// ThreatModelSearchService provides full-text search across threat models,
+// including their threats and mitigations.
+type ThreatModelSearchService interface {
+	// Search threat models visible to the caller for all terms in query,
+	// best match first. A limit of zero means DefaultSearchLimit.
+	Search(ctx context.Context, query string, limit int) ([]*tm.SearchResult, error)
+
+	// Rebuild the search index of the caller's tenant from the datastore,
+	// returning the number of threat models indexed.
+	Rebuild(ctx context.Context) (int, error)
+}
+
+// IndexingThreatModelSearchService searches a search.Index, which it keeps
+// in sync by acting as a ThreatModelWriteHook. The index can be rebuilt
+// from the datastore via Rebuild, for instance by tmadmin. Each threat
+// model returned by Search is audited.
+type IndexingThreatModelSearchService struct {
+	dao           dao.ThreatModelDao
+	index         search.Index
+	accessChecker ThreatModelAccessChecker
+	auditor       Auditor
+}
+
+var _ ThreatModelSearchService = (*IndexingThreatModelSearchService)(nil)
+var _ ThreatModelWriteHook = (*IndexingThreatModelSearchService)(nil)
+
+func NewIndexingThreatModelSearchService(dao dao.ThreatModelDao, index search.Index, accessChecker ThreatModelAccessChecker, auditor Auditor) *IndexingThreatModelSearchService {
+	return &IndexingThreatModelSearchService{dao: dao, index: index, accessChecker: accessChecker, auditor: auditor}
+}
+
+func (s *IndexingThreatModelSearchService) Search(ctx context.Context, query string, limit int) ([]*tm.SearchResult, error) {
+	result, err := s.search(ctx, query, limit)
+
+	threatModels := make([]*m.ThreatModel, len(result))
+	for i, r := range result {
+		threatModels[i] = r.ThreatModel
+	}
+	recordThreatModels(ctx, s.auditor, AuditActionThreatModelSearch, threatModels, err)
+
+	return result, err
+}
+
+func (s *IndexingThreatModelSearchService) search(ctx context.Context, query string, limit int) ([]*tm.SearchResult, error) {
+	if len(search.Terms(query)) == 0 {
+		return nil, ErrEmptySearchQuery
+	}
+
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	hits, err := s.index.Search(ctx, query, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*tm.SearchResult{}
+
+	// fetch hits a page at a time, skipping those the caller cannot view,
+	// and those deleted since they were indexed
+	for start := 0; start < len(hits) && len(result) < limit; start += limit {
+		end := start + limit
+		if end > len(hits) {
+			end = len(hits)
+		}
+
+		ids := make([]m.ThreatModelID, end-start)
+		for i, hit := range hits[start:end] {
+			ids[i] = m.NewThreatModelID(hit.ID)
+		}
+
+		ids, err := s.accessChecker.FilterThreatModelAccess(ctx, ids, tm.ProjectRoleViewer)
+		if err != nil {
+			return nil, err
+		}
+
+		threatModels, err := s.dao.GetMany(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("error in Search: %v", err)
+		}
+
+		found := map[string]*m.ThreatModel{}
+		for _, threatModel := range threatModels {
+			found[threatModel.ThreatModelID.String()] = threatModel
+		}
+
+		for _, hit := range hits[start:end] {
+			threatModel, ok := found[hit.ID]
+			if !ok {
+				continue
+			}
+
+			snippets := make([]tm.SearchSnippet, len(hit.Snippets))
+			for i, snippet := range hit.Snippets {
+				snippets[i] = tm.SearchSnippet{Field: snippet.Field, Fragment: snippet.Fragment}
+			}
+
+			result = append(result, &tm.SearchResult{
+				ThreatModel: threatModel,
+				Score:       hit.Score,
+				Snippets:    snippets,
+			})
+
+			if len(result) == limit {
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (s *IndexingThreatModelSearchService) Rebuild(ctx context.Context) (int, error) {
+	threatModels, err := s.dao.GetAll(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error in Rebuild: %v", err)
+	}
+
+	docs := map[string][]search.Field{}
+	for _, threatModel := range threatModels {
+		fields, err := search.ExtractFields(threatModel)
+		if err != nil {
+			return 0, fmt.Errorf("error indexing %s: %v", threatModel.ThreatModelID, err)
+		}
+
+		docs[threatModel.ThreatModelID.String()] = fields
+	}
+
+	if err := s.index.Reset(ctx, docs); err != nil {
+		return 0, err
+	}
+
+	return len(docs), nil
+}
+
+func (s *IndexingThreatModelSearchService) AfterSave(ctx context.Context, threatModel *m.ThreatModel) {
+	fields, err := search.ExtractFields(threatModel)
+	if err != nil {
+		log.Errorf("error indexing %s: %v", threatModel.ThreatModelID, err)
+		return
+	}
+
+	if err := s.index.Index(ctx, threatModel.ThreatModelID.String(), fields); err != nil {
+		log.Errorf("error indexing %s: %v", threatModel.ThreatModelID, err)
+	}
+}
+
+func (s *IndexingThreatModelSearchService) AfterDelete(ctx context.Context, id m.ThreatModelID) {
+	if err := s.index.Remove(ctx, id.String()); err != nil {
+		log.Errorf("error un-indexing %s: %v", id, err)
+	}
+}
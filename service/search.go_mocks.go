@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: search.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+// MockThreatModelSearchService is a mock of ThreatModelSearchService interface.
+type MockThreatModelSearchService struct {
+	ctrl     *gomock.Controller
+	recorder *MockThreatModelSearchServiceMockRecorder
+}
+
+// MockThreatModelSearchServiceMockRecorder is the mock recorder for MockThreatModelSearchService.
+type MockThreatModelSearchServiceMockRecorder struct {
+	mock *MockThreatModelSearchService
+}
+
+// NewMockThreatModelSearchService creates a new mock instance.
+func NewMockThreatModelSearchService(ctrl *gomock.Controller) *MockThreatModelSearchService {
+	mock := &MockThreatModelSearchService{ctrl: ctrl}
+	mock.recorder = &MockThreatModelSearchServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockThreatModelSearchService) EXPECT() *MockThreatModelSearchServiceMockRecorder {
+	return m.recorder
+}
+
+// Rebuild mocks base method.
+func (m *MockThreatModelSearchService) Rebuild(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rebuild", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rebuild indicates an expected call of Rebuild.
+func (mr *MockThreatModelSearchServiceMockRecorder) Rebuild(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rebuild", reflect.TypeOf((*MockThreatModelSearchService)(nil).Rebuild), ctx)
+}
+
+// Search mocks base method.
+func (m *MockThreatModelSearchService) Search(ctx context.Context, query string, limit int) ([]*model.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query, limit)
+	ret0, _ := ret[0].([]*model.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockThreatModelSearchServiceMockRecorder) Search(ctx, query, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockThreatModelSearchService)(nil).Search), ctx, query, limit)
+}
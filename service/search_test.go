@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-model/validator"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/search"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	payments := &m.ThreatModel{
+		ThreatModelID: m.NewThreatModelIDP("tm-1"),
+		Title:         "Payments gateway",
+	}
+	vault := &m.ThreatModel{
+		ThreatModelID: m.NewThreatModelIDP("tm-2"),
+		Title:         "Card vault",
+		Description:   "Stores card data for payments",
+	}
+	deleted := &m.ThreatModel{
+		ThreatModelID: m.NewThreatModelIDP("tm-3"),
+		Title:         "Payments reconciliation",
+	}
+	hidden := &m.ThreatModel{
+		ThreatModelID: m.NewThreatModelIDP("tm-4"),
+		Title:         "Payments fraud rules",
+	}
+
+	var tests = []struct {
+		name          string
+		query         string
+		limit         int
+		expectedIDs   []m.ThreatModelID
+		expectedError error
+	}{
+		{
+			"should return matches, best first, skipping those since deleted or hidden",
+			"payments",
+			0,
+			[]m.ThreatModelID{payments.ThreatModelID, vault.ThreatModelID},
+			nil,
+		},
+		{
+			"should apply limit after skipping deleted matches",
+			"payments",
+			1,
+			[]m.ThreatModelID{payments.ThreatModelID},
+			nil,
+		},
+		{
+			"should return no results when nothing matches",
+			"spoofing",
+			0,
+			[]m.ThreatModelID{},
+			nil,
+		},
+		{
+			"should reject queries without terms",
+			" - ",
+			0,
+			nil,
+			ErrEmptySearchQuery,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao := dao.NewMockThreatModelDao(ctrl)
+			auditor := &recordingAuditor{}
+			ctx := inTenant("acme")
+
+			if test.expectedError == nil {
+				mockDao.EXPECT().GetAll(ctx).Return([]*m.ThreatModel{payments, vault, deleted, hidden}, nil)
+				mockDao.EXPECT().GetMany(ctx, gomock.Any()).DoAndReturn(getManyOf(payments, vault, hidden)).AnyTimes()
+			}
+
+			service := NewIndexingThreatModelSearchService(mockDao, search.NewMemoryIndex(), hiding(ctrl, hidden.ThreatModelID), auditor)
+			if test.expectedError == nil {
+				_, err := service.Rebuild(ctx)
+				require.Nil(t, err)
+			}
+
+			// when
+			result, err := service.Search(ctx, test.query, test.limit)
+
+			// then
+			require.Equal(t, test.expectedError, err)
+
+			if test.expectedError == nil {
+				ids := []m.ThreatModelID{}
+				for _, r := range result {
+					ids = append(ids, r.ThreatModel.ThreatModelID)
+					require.NotEmpty(t, r.Snippets)
+				}
+				require.Equal(t, test.expectedIDs, ids)
+			}
+
+			// each threat model returned is audited, or the search once if
+			// it failed
+			audited := []m.ThreatModelID{}
+			for _, record := range auditor.records {
+				require.Equal(t, AuditActionThreatModelSearch, record.Action)
+				if record.ResourceID != "" {
+					audited = append(audited, m.NewThreatModelID(record.ResourceID))
+				}
+			}
+			if test.expectedError == nil {
+				require.Equal(t, test.expectedIDs, audited)
+			} else {
+				require.Len(t, auditor.records, 1)
+				require.Equal(t, tm.AuditOutcomeFailure, auditor.records[0].Outcome)
+			}
+		})
+	}
+}
+
+func TestSearchIsKeptInSyncByWriteHooks(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDao := dao.NewMockThreatModelDao(ctrl)
+	mockValidator := validator.NewMockStructValidator(ctrl)
+	ctx := inTenant("acme")
+
+	mockValidator.EXPECT().ValidateForCreate(gomock.Any()).Return(nil).AnyTimes()
+	mockValidator.EXPECT().ValidateForUpdate(gomock.Any()).Return(nil).AnyTimes()
+
+	created := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-1"), Title: "Payments gateway"}
+	updated := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-1"), Title: "Billing gateway"}
+
+	mockDao.EXPECT().GetAll(ctx).Return([]*m.ThreatModel{}, nil)
+	mockDao.EXPECT().CreateWithOutbox(ctx, gomock.Any(), gomock.Any()).Return(created, nil)
+	mockDao.EXPECT().UpdateWithOutbox(ctx, created.ThreatModelID, gomock.Any(), gomock.Any()).Return(updated, nil)
+	mockDao.EXPECT().DeleteWithOutbox(ctx, created.ThreatModelID, gomock.Any()).Return(nil)
+	mockDao.EXPECT().GetMany(ctx, []m.ThreatModelID{created.ThreatModelID}).Return([]*m.ThreatModel{updated}, nil)
+
+	searchService := NewIndexingThreatModelSearchService(mockDao, search.NewMemoryIndex(), hiding(ctrl), &recordingAuditor{})
+	_, err := searchService.Rebuild(ctx)
+	require.Nil(t, err)
+
+	service := NewDefaultThreatModelService(mockDao, mockValidator, nil, ThreatModelWriteHooks{searchService})
+
+	// when
+	_, err = service.Create(ctx, m.ThreatModelParams{Title: m.String(created.Title)})
+	require.Nil(t, err)
+	_, err = service.Update(ctx, created.ThreatModelID, m.ThreatModelParams{Title: m.String(updated.Title)})
+	require.Nil(t, err)
+
+	// then
+	result, err := searchService.Search(ctx, "payments", 0)
+	require.Nil(t, err)
+	require.Empty(t, result)
+
+	result, err = searchService.Search(ctx, "billing", 0)
+	require.Nil(t, err)
+	require.Len(t, result, 1)
+
+	// when deleted
+	require.Nil(t, service.Delete(ctx, created.ThreatModelID))
+
+	// then
+	result, err = searchService.Search(ctx, "billing", 0)
+	require.Nil(t, err)
+	require.Empty(t, result)
+}
+
+// getManyOf returns a ThreatModelDao.GetMany that returns those of
+// threatModels requested, in the order requested.
+func getManyOf(threatModels ...*m.ThreatModel) func(context.Context, []m.ThreatModelID) ([]*m.ThreatModel, error) {
+	return func(ctx context.Context, ids []m.ThreatModelID) ([]*m.ThreatModel, error) {
+		result := []*m.ThreatModel{}
+		for _, id := range ids {
+			for _, threatModel := range threatModels {
+				if threatModel.ThreatModelID == id {
+					result = append(result, threatModel)
+				}
+			}
+		}
+		return result, nil
+	}
+}
+
+// hiding returns a ThreatModelAccessChecker hiding the given threat models
+// from the caller.
+func hiding(ctrl *gomock.Controller, hidden ...m.ThreatModelID) *MockThreatModelAccessChecker {
+	accessChecker := NewMockThreatModelAccessChecker(ctrl)
+	accessChecker.EXPECT().FilterThreatModelAccess(gomock.Any(), gomock.Any(), tm.ProjectRoleViewer).DoAndReturn(
+		func(ctx context.Context, ids []m.ThreatModelID, role tm.ProjectRole) ([]m.ThreatModelID, error) {
+			result := []m.ThreatModelID{}
+			for _, id := range ids {
+				visible := true
+				for _, h := range hidden {
+					visible = visible && id != h
+				}
+				if visible {
+					result = append(result, id)
+				}
+			}
+			return result, nil
+		}).AnyTimes()
+
+	return accessChecker
+}
+
+func inTenant(tenantID auth.TenantID) context.Context {
+	return auth.WithIdentity(context.Background(), &auth.Identity{UserID: "u-1234", TenantID: tenantID})
+}
+
+func TestSearchIsolatesTenants(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDao := dao.NewMockThreatModelDao(ctrl)
+	acme, globex := inTenant("acme"), inTenant("globex")
+
+	acmeModel := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-1"), Title: "Payments gateway"}
+
+	mockDao.EXPECT().GetAll(acme).Return([]*m.ThreatModel{acmeModel}, nil)
+	mockDao.EXPECT().GetAll(globex).Return([]*m.ThreatModel{}, nil)
+	mockDao.EXPECT().GetMany(acme, []m.ThreatModelID{acmeModel.ThreatModelID}).Return([]*m.ThreatModel{acmeModel}, nil)
+
+	service := NewIndexingThreatModelSearchService(mockDao, search.NewMemoryIndex(), hiding(ctrl), &recordingAuditor{})
+	_, err := service.Rebuild(acme)
+	require.Nil(t, err)
+	_, err = service.Rebuild(globex)
+	require.Nil(t, err)
+
+	// when
+	acmeResult, err := service.Search(acme, "payments", 0)
+	require.Nil(t, err)
+	globexResult, err := service.Search(globex, "payments", 0)
+	require.Nil(t, err)
+
+	// a write in one tenant must not be indexed into another
+	service.AfterSave(acme, &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-2"), Title: "Payments ledger"})
+	globexAfterWrite, err := service.Search(globex, "ledger", 0)
+	require.Nil(t, err)
+
+	_, noTenantErr := service.Search(context.Background(), "payments", 0)
+
+	// then
+	require.Len(t, acmeResult, 1)
+	require.Empty(t, globexResult)
+	require.Empty(t, globexAfterWrite)
+	require.Equal(t, auth.ErrNoTenant, noTenantErr)
+}
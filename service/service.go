@@ -12,10 +12,16 @@ import (
 	servicedao "github.com/jtyers/tmaas-service-dao"
 	"github.com/jtyers/tmaas-service-util/idchecker"
 	dao "github.com/jtyers/tmaas-threat-model-api/dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
 )
 
+// MaxGetManyIDs is the most threat models GetMany will retrieve at once.
+const MaxGetManyIDs = 100
+
 var (
-	ErrNoSuchThreatModel = errors.New("no such threat model")
+	ErrNoSuchThreatModel   = errors.New("no such threat model")
+	ErrTooManyIDs          = fmt.Errorf("at most %d threat models may be retrieved at once", MaxGetManyIDs)
+	ErrThreatModelModified = errors.New("the threat model has changed since the ETag given in If-Match")
 )
 
 // ThreatModelService provides the interface to manage threat models.
@@ -23,6 +29,10 @@ type ThreatModelService interface {
 	// Retrieve a ThreatModel by ID.
 	Get(ctx context.Context, id m.ThreatModelID) (*m.ThreatModel, error)
 
+	// Retrieve the ThreatModels with the given IDs (at most MaxGetManyIDs),
+	// along with the IDs of any that do not exist.
+	GetMany(ctx context.Context, ids []m.ThreatModelID) (*tm.BatchGetResult, error)
+
 	// Retrieve all ThreatModels.
 	GetAll(ctx context.Context) ([]*m.ThreatModel, error)
 
@@ -33,7 +43,9 @@ type ThreatModelService interface {
 	// Creates a ThreatModel.
 	Create(ctx context.Context, params m.ThreatModelParams) (*m.ThreatModel, error)
 
-	// Updates a ThreatModel
+	// Updates a ThreatModel. If ctx carries an ETag from dao.WithIfMatch,
+	// the update is made only if the threat model still matches it, and
+	// fails with ErrThreatModelModified otherwise.
 	Update(ctx context.Context, id m.ThreatModelID, params m.ThreatModelParams) (*m.ThreatModel, error)
 
 	// Delete a ThreatModel by ID.
@@ -44,6 +56,7 @@ type DefaultThreatModelService struct {
 	dao       dao.ThreatModelDao
 	validator validator.StructValidator
 	idChecker idchecker.IDChecker
+	hooks     ThreatModelWriteHooks
 }
 
 var _ ThreatModelService = (*DefaultThreatModelService)(nil)
@@ -52,8 +65,9 @@ func NewDefaultThreatModelService(
 	dao dao.ThreatModelDao,
 	validator validator.StructValidator,
 	idChecker idchecker.IDChecker,
+	hooks ThreatModelWriteHooks,
 ) *DefaultThreatModelService {
-	return &DefaultThreatModelService{dao, validator, idChecker}
+	return &DefaultThreatModelService{dao, validator, idChecker, hooks}
 }
 
 func (g *DefaultThreatModelService) Get(ctx context.Context, id m.ThreatModelID) (*m.ThreatModel, error) {
@@ -80,23 +94,18 @@ func (g *DefaultThreatModelService) Create(ctx context.Context, params m.ThreatM
 		return nil, err
 	}
 
-	if params.DataFlowDiagramID != nil {
-		exists, err := g.idChecker.CheckID(ctx, params.DataFlowDiagramID)
-		if err != nil {
-			return nil, fmt.Errorf("CheckID failed: %v", err)
-		}
-		if !exists {
-			return nil, fmt.Errorf("params.DataFlowDiagramID %v does not exist",
-				params.DataFlowDiagramID)
-		}
+	if err := g.checkReferences(ctx, "params", params); err != nil {
+		return nil, err
 	}
 
 	// leave ID blank - the DAO will generate one for us
-	result, err := g.dao.Create(ctx, params)
+	result, err := g.dao.CreateWithOutbox(ctx, params, outboxRecord(ctx, tm.ThreatModelCreated))
 	if err != nil {
 		return nil, fmt.Errorf("error creating threatModel: %v", err)
 	}
 
+	g.hooks.afterSave(ctx, result)
+
 	return result, nil
 }
 
@@ -106,25 +115,61 @@ func (g *DefaultThreatModelService) Update(ctx context.Context, id m.ThreatModel
 		return nil, err
 	}
 
-	if params.DataFlowDiagramID != nil {
-		exists, err := g.idChecker.CheckID(ctx, params.DataFlowDiagramID)
-		if err != nil {
-			return nil, fmt.Errorf("CheckID failed: %v", err)
-		}
-		if !exists {
-			return nil, fmt.Errorf("threatModel.DataFlowDiagramID %v does not exist",
-				params.DataFlowDiagramID)
-		}
+	if err := g.checkReferences(ctx, "threatModel", params); err != nil {
+		return nil, err
 	}
 
-	updated, err := g.dao.Update(ctx, id, params)
+	updated, err := g.dao.UpdateWithOutbox(ctx, id, params, outboxRecord(ctx, tm.ThreatModelUpdated))
 	if err != nil {
+		if err == servicedao.ErrNoSuchDocument {
+			return nil, ErrNoSuchThreatModel
+		}
+		if err == dao.ErrPreconditionFailed {
+			return nil, ErrThreatModelModified
+		}
 		return nil, fmt.Errorf("error updating threatModel: %v", err)
 	}
 
+	g.hooks.afterSave(ctx, updated)
+
 	return updated, nil
 }
 
+func (g *DefaultThreatModelService) GetMany(ctx context.Context, ids []m.ThreatModelID) (*tm.BatchGetResult, error) {
+	if len(ids) > MaxGetManyIDs {
+		return nil, ErrTooManyIDs
+	}
+
+	// each ID is looked up once, however many times it is given
+	unique := []m.ThreatModelID{}
+	seen := map[m.ThreatModelID]bool{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	threatModels, err := g.dao.GetMany(ctx, unique)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving threatModels: %v", err)
+	}
+
+	found := map[m.ThreatModelID]bool{}
+	for _, threatModel := range threatModels {
+		found[threatModel.ThreatModelID] = true
+	}
+
+	result := &tm.BatchGetResult{ThreatModels: threatModels, Missing: []m.ThreatModelID{}}
+	for _, id := range unique {
+		if !found[id] {
+			result.Missing = append(result.Missing, id)
+		}
+	}
+
+	return result, nil
+}
+
 func (g *DefaultThreatModelService) GetAll(ctx context.Context) ([]*m.ThreatModel, error) {
 	result, err := g.dao.GetAll(ctx)
 
@@ -136,11 +181,16 @@ func (g *DefaultThreatModelService) GetAll(ctx context.Context) ([]*m.ThreatMode
 }
 
 func (g *DefaultThreatModelService) Delete(ctx context.Context, id m.ThreatModelID) error {
-	err := g.dao.Delete(ctx, id)
+	err := g.dao.DeleteWithOutbox(ctx, id, outboxRecord(ctx, tm.ThreatModelDeleted))
 	if err != nil {
+		if err == servicedao.ErrNoSuchDocument {
+			return ErrNoSuchThreatModel
+		}
 		return fmt.Errorf("error in Delete %s: %v", id, err)
 	}
 
+	g.hooks.afterDelete(ctx, id)
+
 	return nil
 }
 
@@ -163,3 +213,22 @@ func (g *DefaultThreatModelService) QuerySingle(ctx context.Context, q *m.Threat
 
 	return result, nil
 }
+
+// checkReferences checks that the data flow diagram referenced by params,
+// if any, exists. name describes params in the error returned for a
+// missing diagram.
+func (g *DefaultThreatModelService) checkReferences(ctx context.Context, name string, params m.ThreatModelParams) error {
+	if params.DataFlowDiagramID == nil {
+		return nil
+	}
+
+	exists, err := g.idChecker.CheckID(ctx, params.DataFlowDiagramID)
+	if err != nil {
+		return fmt.Errorf("CheckID failed: %v", err)
+	}
+	if !exists {
+		return fmt.Errorf("%s.DataFlowDiagramID %v does not exist", name, params.DataFlowDiagramID)
+	}
+
+	return nil
+}
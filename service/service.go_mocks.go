@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	model "github.com/jtyers/tmaas-model"
+	model0 "github.com/jtyers/tmaas-threat-model-api/model"
 )
 
 // MockThreatModelService is a mock of ThreatModelService interface.
@@ -94,6 +95,21 @@ func (mr *MockThreatModelServiceMockRecorder) GetAll(ctx interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockThreatModelService)(nil).GetAll), ctx)
 }
 
+// GetMany mocks base method.
+func (m *MockThreatModelService) GetMany(ctx context.Context, ids []model.ThreatModelID) (*model0.BatchGetResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMany", ctx, ids)
+	ret0, _ := ret[0].(*model0.BatchGetResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMany indicates an expected call of GetMany.
+func (mr *MockThreatModelServiceMockRecorder) GetMany(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMany", reflect.TypeOf((*MockThreatModelService)(nil).GetMany), ctx, ids)
+}
+
 // Query mocks base method.
 func (m *MockThreatModelService) Query(ctx context.Context, q *model.ThreatModelQuery) ([]*model.ThreatModel, error) {
 	m.ctrl.T.Helper()
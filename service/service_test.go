@@ -11,6 +11,7 @@ import (
 	servicedao "github.com/jtyers/tmaas-service-dao"
 	"github.com/jtyers/tmaas-service-util/idchecker"
 	"github.com/jtyers/tmaas-threat-model-api/dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
 	"github.com/stretchr/testify/require"
 )
 
@@ -57,7 +58,7 @@ func TestGet(t *testing.T) {
 			mockDao.EXPECT().Get(ctx, test.inputThreatModelID).Return(&test.daoReturnValue, test.daoReturnError)
 
 			// when
-			service := NewDefaultThreatModelService(mockDao, nil, nil)
+			service := NewDefaultThreatModelService(mockDao, nil, nil, nil)
 			g, err := service.Get(ctx, test.inputThreatModelID)
 
 			// then
@@ -117,6 +118,17 @@ func TestUpdateThreatModel(t *testing.T) {
 			nil,
 			fmt.Errorf("error updating threatModel: dao failure"),
 		},
+		{
+			"should return ErrNoSuchThreatModel for non-existent threatModel",
+			threatModel.ThreatModelID,
+			m.ThreatModelParams{Title: m.String("my new threatModel")},
+			servicedao.ErrNoSuchDocument,
+			nil,
+			true,
+			nil,
+			nil,
+			ErrNoSuchThreatModel,
+		},
 		{
 			"should fail if IDChecker fails",
 			threatModel.ThreatModelID,
@@ -160,13 +172,13 @@ func TestUpdateThreatModel(t *testing.T) {
 				}
 
 				if test.checkIDResult && test.checkIDError == nil {
-					mockDao.EXPECT().Update(ctx, test.inputID, test.input).Return(test.expectedResult, test.daoReturnError)
+					mockDao.EXPECT().UpdateWithOutbox(ctx, test.inputID, test.input, gomock.Any()).Return(test.expectedResult, test.daoReturnError)
 
 				}
 			}
 
 			// when
-			service := NewDefaultThreatModelService(mockDao, mockValidator, mockIDChecker)
+			service := NewDefaultThreatModelService(mockDao, mockValidator, mockIDChecker, nil)
 			result, err := service.Update(ctx, test.inputID, test.input)
 
 			// then
@@ -176,6 +188,52 @@ func TestUpdateThreatModel(t *testing.T) {
 	}
 }
 
+func TestDeleteThreatModel(t *testing.T) {
+	id := m.NewThreatModelIDP("1234-1234-1234-1234")
+
+	var tests = []struct {
+		name           string
+		daoReturnError error
+		expectedError  error
+	}{
+		{
+			"should delete threatModel",
+			nil,
+			nil,
+		},
+		{
+			"should return ErrNoSuchThreatModel for non-existent threatModel",
+			servicedao.ErrNoSuchDocument,
+			ErrNoSuchThreatModel,
+		},
+		{
+			"should fail if DAO delete fails",
+			fmt.Errorf("dao failure"),
+			fmt.Errorf("error in Delete %s: dao failure", id),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao := dao.NewMockThreatModelDao(ctrl)
+			ctx := context.Background()
+
+			mockDao.EXPECT().DeleteWithOutbox(ctx, id, gomock.Any()).Return(test.daoReturnError)
+
+			// when
+			service := NewDefaultThreatModelService(mockDao, nil, nil, nil)
+			err := service.Delete(ctx, id)
+
+			// then
+			require.Equal(t, test.expectedError, err)
+		})
+	}
+}
+
 func TestCreate(t *testing.T) {
 	threatModel := m.ThreatModel{}
 
@@ -280,13 +338,13 @@ func TestCreate(t *testing.T) {
 					}
 
 					if test.checkIDResult && test.checkIDError == nil {
-						mockDao.EXPECT().Create(ctx, test.input).Return(test.expectedResult, test.daoReturnError)
+						mockDao.EXPECT().CreateWithOutbox(ctx, test.input, gomock.Any()).Return(test.expectedResult, test.daoReturnError)
 					}
 				}
 			}
 
 			// when
-			service := NewDefaultThreatModelService(mockDao, mockValidator, mockIDChecker)
+			service := NewDefaultThreatModelService(mockDao, mockValidator, mockIDChecker, nil)
 			g, err := service.Create(ctx, test.input)
 
 			// then
@@ -344,7 +402,7 @@ func TestGetAll(t *testing.T) {
 			mockDao.EXPECT().GetAll(ctx).Return(test.daoReturnValue, test.daoReturnError)
 
 			// when
-			service := NewDefaultThreatModelService(mockDao, nil, nil)
+			service := NewDefaultThreatModelService(mockDao, nil, nil, nil)
 			g, err := service.GetAll(ctx)
 
 			// then
@@ -353,3 +411,57 @@ func TestGetAll(t *testing.T) {
 		})
 	}
 }
+
+func TestGetManyThreatModels(t *testing.T) {
+	tm1 := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-1")}
+	tm2 := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-2")}
+	missingID := m.NewThreatModelIDP("tm-404")
+
+	var tests = []struct {
+		name           string
+		ids            []m.ThreatModelID
+		expectedDaoIDs []m.ThreatModelID // dao.GetMany not expected if nil
+		daoResult      []*m.ThreatModel
+		expectedResult *tm.BatchGetResult
+		expectedError  error
+	}{
+		{
+			"should return found threat models and missing IDs, each once",
+			[]m.ThreatModelID{tm2.ThreatModelID, missingID, tm1.ThreatModelID, tm2.ThreatModelID},
+			[]m.ThreatModelID{tm2.ThreatModelID, missingID, tm1.ThreatModelID},
+			[]*m.ThreatModel{tm2, tm1},
+			&tm.BatchGetResult{ThreatModels: []*m.ThreatModel{tm2, tm1}, Missing: []m.ThreatModelID{missingID}},
+			nil,
+		},
+		{
+			"should fail for too many IDs",
+			make([]m.ThreatModelID, MaxGetManyIDs+1),
+			nil,
+			nil,
+			nil,
+			ErrTooManyIDs,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao := dao.NewMockThreatModelDao(ctrl)
+			if test.expectedDaoIDs != nil {
+				mockDao.EXPECT().GetMany(gomock.Any(), test.expectedDaoIDs).Return(test.daoResult, nil)
+			}
+
+			service := NewDefaultThreatModelService(mockDao, nil, nil, nil)
+
+			// when
+			result, err := service.GetMany(context.Background(), test.ids)
+
+			// then
+			require.Equal(t, test.expectedError, err)
+			require.Equal(t, test.expectedResult, result)
+		})
+	}
+}
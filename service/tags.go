@@ -0,0 +1,242 @@
+package service
+
+//go:generate mockgen -source=$GOFILE -destination=${GOFILE}_mocks.go -package $GOPACKAGE
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	m "github.com/jtyers/tmaas-model"
+	dao "github.com/jtyers/tmaas-threat-model-api/dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+const (
+	MaxTagsPerThreatModel = 20
+	MaxTagLength          = 64
+)
+
+var (
+	ErrInvalidTag  = errors.New("tags must be lower-case letters, digits, '.', '_' or '-', optionally with a single ':' separating a key and value, eg \"criticality:high\"")
+	ErrTooManyTags = fmt.Errorf("threat models may have at most %d tags", MaxTagsPerThreatModel)
+)
+
+// tagPattern matches a plain tag such as "payments", or a key:value tag
+// such as "compliance:pci-dss".
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*(:[a-z0-9][a-z0-9._-]*)?$`)
+
+// ThreatModelTagService manages the tags on threat models, which are used
+// to organise threat models by business unit, criticality, compliance
+// scope and so on.
+type ThreatModelTagService interface {
+	// Retrieve the tags on a threat model.
+	GetTags(ctx context.Context, id m.ThreatModelID) ([]string, error)
+
+	// Replace the tags on a threat model, returning the normalised tags.
+	SetTags(ctx context.Context, id m.ThreatModelID, tags []string) ([]string, error)
+
+	// Add tags to a threat model, keeping those it already carries,
+	// returning the tags it then carries. Concurrent changes to its tags
+	// are not lost.
+	AddTags(ctx context.Context, id m.ThreatModelID, tags []string) ([]string, error)
+
+	// Remove tags from a threat model, returning the tags it then carries.
+	RemoveTags(ctx context.Context, id m.ThreatModelID, tags []string) ([]string, error)
+
+	// Retrieve all tags in use on threat models the caller can view, with
+	// the number of those threat models carrying each.
+	GetAllTags(ctx context.Context) ([]tm.TagCount, error)
+
+	// Retrieve threat models the caller can view carrying any (or, if
+	// matchAll is true, all) of the given tags.
+	GetAllWithTags(ctx context.Context, tags []string, matchAll bool) ([]*m.ThreatModel, error)
+}
+
+type DefaultThreatModelTagService struct {
+	dao                dao.ThreatModelDao
+	threatModelService ThreatModelService
+	accessChecker      ThreatModelAccessChecker
+}
+
+var _ ThreatModelTagService = (*DefaultThreatModelTagService)(nil)
+
+func NewDefaultThreatModelTagService(dao dao.ThreatModelDao, threatModelService ThreatModelService, accessChecker ThreatModelAccessChecker) *DefaultThreatModelTagService {
+	return &DefaultThreatModelTagService{dao, threatModelService, accessChecker}
+}
+
+func (s *DefaultThreatModelTagService) GetTags(ctx context.Context, id m.ThreatModelID) ([]string, error) {
+	if _, err := s.threatModelService.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	tags, err := s.dao.GetTags(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error in GetTags: %v", err)
+	}
+
+	return tags, nil
+}
+
+func (s *DefaultThreatModelTagService) SetTags(ctx context.Context, id m.ThreatModelID, tags []string) ([]string, error) {
+	normalised, err := NormaliseTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.threatModelService.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	err = s.dao.SetTags(ctx, id, normalised)
+	if err != nil {
+		return nil, fmt.Errorf("error in SetTags: %v", err)
+	}
+
+	return normalised, nil
+}
+
+func (s *DefaultThreatModelTagService) AddTags(ctx context.Context, id m.ThreatModelID, tags []string) ([]string, error) {
+	normalised, err := NormaliseTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.threatModelService.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	result, err := s.dao.AddTags(ctx, id, normalised, MaxTagsPerThreatModel)
+	if err == dao.ErrTooManyTags {
+		return nil, ErrTooManyTags
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error in AddTags: %v", err)
+	}
+
+	return result, nil
+}
+
+func (s *DefaultThreatModelTagService) RemoveTags(ctx context.Context, id m.ThreatModelID, tags []string) ([]string, error) {
+	normalised, err := NormaliseTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.threatModelService.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	result, err := s.dao.RemoveTags(ctx, id, normalised)
+	if err != nil {
+		return nil, fmt.Errorf("error in RemoveTags: %v", err)
+	}
+
+	return result, nil
+}
+
+func (s *DefaultThreatModelTagService) GetAllTags(ctx context.Context) ([]tm.TagCount, error) {
+	tagsByID, err := s.dao.GetAllTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error in GetAllTags: %v", err)
+	}
+
+	ids := make([]m.ThreatModelID, 0, len(tagsByID))
+	for id := range tagsByID {
+		ids = append(ids, id)
+	}
+
+	visible, err := s.accessChecker.FilterThreatModelAccess(ctx, ids, tm.ProjectRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, id := range visible {
+		for _, tag := range tagsByID[id] {
+			counts[tag]++
+		}
+	}
+
+	result := make([]tm.TagCount, 0, len(counts))
+	for tag, count := range counts {
+		result = append(result, tm.TagCount{Tag: tag, Count: count})
+	}
+
+	// most-used first, then alphabetically
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Tag < result[j].Tag
+	})
+
+	return result, nil
+}
+
+func (s *DefaultThreatModelTagService) GetAllWithTags(ctx context.Context, tags []string, matchAll bool) ([]*m.ThreatModel, error) {
+	normalised, err := NormaliseTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := s.dao.QueryIDsByTags(ctx, normalised, matchAll)
+	if err != nil {
+		return nil, fmt.Errorf("error in QueryIDsByTags: %v", err)
+	}
+
+	ids, err = s.accessChecker.FilterThreatModelAccess(ctx, ids, tm.ProjectRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	// retrieve through the service, so that reads are audited, cached and
+	// counted as any other; tags may outlive their threat model, so any
+	// reported missing are left out
+	result := []*m.ThreatModel{}
+	for start := 0; start < len(ids); start += MaxGetManyIDs {
+		end := start + MaxGetManyIDs
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		batch, err := s.threatModelService.GetMany(ctx, ids[start:end])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, batch.ThreatModels...)
+	}
+
+	return result, nil
+}
+
+// NormaliseTags trims and lower-cases tags, removes duplicates and sorts
+// them, returning ErrInvalidTag or ErrTooManyTags if the result breaks the
+// tag rules.
+func NormaliseTags(tags []string) ([]string, error) {
+	result := []string{}
+	seen := map[string]bool{}
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+
+		if len(tag) > MaxTagLength || !tagPattern.MatchString(tag) {
+			return nil, ErrInvalidTag
+		}
+
+		if !seen[tag] {
+			seen[tag] = true
+			result = append(result, tag)
+		}
+	}
+
+	if len(result) > MaxTagsPerThreatModel {
+		return nil, ErrTooManyTags
+	}
+
+	sort.Strings(result)
+	return result, nil
+}
@@ -0,0 +1,127 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: tags.go
+
+// Package service is a generated GoMock package.
+package service
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	model "github.com/jtyers/tmaas-model"
+	model0 "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+// MockThreatModelTagService is a mock of ThreatModelTagService interface.
+type MockThreatModelTagService struct {
+	ctrl     *gomock.Controller
+	recorder *MockThreatModelTagServiceMockRecorder
+}
+
+// MockThreatModelTagServiceMockRecorder is the mock recorder for MockThreatModelTagService.
+type MockThreatModelTagServiceMockRecorder struct {
+	mock *MockThreatModelTagService
+}
+
+// NewMockThreatModelTagService creates a new mock instance.
+func NewMockThreatModelTagService(ctrl *gomock.Controller) *MockThreatModelTagService {
+	mock := &MockThreatModelTagService{ctrl: ctrl}
+	mock.recorder = &MockThreatModelTagServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockThreatModelTagService) EXPECT() *MockThreatModelTagServiceMockRecorder {
+	return m.recorder
+}
+
+// AddTags mocks base method.
+func (m *MockThreatModelTagService) AddTags(ctx context.Context, id model.ThreatModelID, tags []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTags", ctx, id, tags)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTags indicates an expected call of AddTags.
+func (mr *MockThreatModelTagServiceMockRecorder) AddTags(ctx, id, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTags", reflect.TypeOf((*MockThreatModelTagService)(nil).AddTags), ctx, id, tags)
+}
+
+// GetAllTags mocks base method.
+func (m *MockThreatModelTagService) GetAllTags(ctx context.Context) ([]model0.TagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllTags", ctx)
+	ret0, _ := ret[0].([]model0.TagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllTags indicates an expected call of GetAllTags.
+func (mr *MockThreatModelTagServiceMockRecorder) GetAllTags(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTags", reflect.TypeOf((*MockThreatModelTagService)(nil).GetAllTags), ctx)
+}
+
+// GetAllWithTags mocks base method.
+func (m *MockThreatModelTagService) GetAllWithTags(ctx context.Context, tags []string, matchAll bool) ([]*model.ThreatModel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllWithTags", ctx, tags, matchAll)
+	ret0, _ := ret[0].([]*model.ThreatModel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllWithTags indicates an expected call of GetAllWithTags.
+func (mr *MockThreatModelTagServiceMockRecorder) GetAllWithTags(ctx, tags, matchAll interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllWithTags", reflect.TypeOf((*MockThreatModelTagService)(nil).GetAllWithTags), ctx, tags, matchAll)
+}
+
+// GetTags mocks base method.
+func (m *MockThreatModelTagService) GetTags(ctx context.Context, id model.ThreatModelID) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTags", ctx, id)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTags indicates an expected call of GetTags.
+func (mr *MockThreatModelTagServiceMockRecorder) GetTags(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTags", reflect.TypeOf((*MockThreatModelTagService)(nil).GetTags), ctx, id)
+}
+
+// RemoveTags mocks base method.
+func (m *MockThreatModelTagService) RemoveTags(ctx context.Context, id model.ThreatModelID, tags []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTags", ctx, id, tags)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveTags indicates an expected call of RemoveTags.
+func (mr *MockThreatModelTagServiceMockRecorder) RemoveTags(ctx, id, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTags", reflect.TypeOf((*MockThreatModelTagService)(nil).RemoveTags), ctx, id, tags)
+}
+
+// SetTags mocks base method.
+func (m *MockThreatModelTagService) SetTags(ctx context.Context, id model.ThreatModelID, tags []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTags", ctx, id, tags)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTags indicates an expected call of SetTags.
+func (mr *MockThreatModelTagServiceMockRecorder) SetTags(ctx, id, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTags", reflect.TypeOf((*MockThreatModelTagService)(nil).SetTags), ctx, id, tags)
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormaliseTags(t *testing.T) {
+	tooMany := []string{}
+	for i := 0; i <= MaxTagsPerThreatModel; i++ {
+		tooMany = append(tooMany, fmt.Sprintf("tag-%d", i))
+	}
+
+	var tests = []struct {
+		name          string
+		input         []string
+		expected      []string
+		expectedError error
+	}{
+		{
+			"should trim, lower-case, de-duplicate and sort tags",
+			[]string{" Criticality:High", "bu:payments", "criticality:high", "pci"},
+			[]string{"bu:payments", "criticality:high", "pci"},
+			nil,
+		},
+		{
+			"should accept no tags",
+			[]string{},
+			[]string{},
+			nil,
+		},
+		{
+			"should reject tags with spaces",
+			[]string{"business unit"},
+			nil,
+			ErrInvalidTag,
+		},
+		{
+			"should reject tags with more than one separator",
+			[]string{"a:b:c"},
+			nil,
+			ErrInvalidTag,
+		},
+		{
+			"should reject empty tags",
+			[]string{""},
+			nil,
+			ErrInvalidTag,
+		},
+		{
+			"should reject long tags",
+			[]string{strings.Repeat("a", MaxTagLength+1)},
+			nil,
+			ErrInvalidTag,
+		},
+		{
+			"should reject too many tags",
+			tooMany,
+			nil,
+			ErrTooManyTags,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := NormaliseTags(test.input)
+
+			require.Equal(t, test.expectedError, err)
+			require.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestGetAllWithTags(t *testing.T) {
+	threatModel1 := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("1234-1234-1234-1234")}
+	threatModel2 := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("2345-2345-2345-2345")}
+
+	var tests = []struct {
+		name     string
+		matchAll bool
+	}{
+		{"should query for any tag", false},
+		{"should query for all tags", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao := dao.NewMockThreatModelDao(ctrl)
+			mockThreatModelService := NewMockThreatModelService(ctrl)
+			ctx := context.Background()
+
+			staleID := m.NewThreatModelIDP("3456-3456-3456-3456")
+			hiddenID := m.NewThreatModelIDP("4567-4567-4567-4567")
+			ids := []m.ThreatModelID{threatModel1.ThreatModelID, staleID, threatModel2.ThreatModelID}
+
+			mockDao.EXPECT().QueryIDsByTags(ctx, []string{"bu:payments", "pci"}, test.matchAll).Return(append(ids, hiddenID), nil)
+			mockThreatModelService.EXPECT().GetMany(ctx, ids).Return(&tm.BatchGetResult{
+				ThreatModels: []*m.ThreatModel{threatModel1, threatModel2},
+				Missing:      []m.ThreatModelID{staleID},
+			}, nil)
+
+			// when
+			service := NewDefaultThreatModelTagService(mockDao, mockThreatModelService, hiding(ctrl, hiddenID))
+			result, err := service.GetAllWithTags(ctx, []string{"PCI", "bu:payments"}, test.matchAll)
+
+			// then
+			require.Nil(t, err)
+			require.Equal(t, []*m.ThreatModel{threatModel1, threatModel2}, result)
+		})
+	}
+}
+
+func TestGetAllWithTagsInBatches(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDao := dao.NewMockThreatModelDao(ctrl)
+	mockThreatModelService := NewMockThreatModelService(ctrl)
+	ctx := context.Background()
+
+	ids := make([]m.ThreatModelID, MaxGetManyIDs+1)
+	threatModels := make([]*m.ThreatModel, len(ids))
+	for i := range ids {
+		ids[i] = m.NewThreatModelIDP(fmt.Sprintf("tm-%d", i))
+		threatModels[i] = &m.ThreatModel{ThreatModelID: ids[i]}
+	}
+
+	mockDao.EXPECT().QueryIDsByTags(ctx, []string{"pci"}, false).Return(ids, nil)
+	gomock.InOrder(
+		mockThreatModelService.EXPECT().GetMany(ctx, ids[:MaxGetManyIDs]).Return(&tm.BatchGetResult{ThreatModels: threatModels[:MaxGetManyIDs]}, nil),
+		mockThreatModelService.EXPECT().GetMany(ctx, ids[MaxGetManyIDs:]).Return(&tm.BatchGetResult{ThreatModels: threatModels[MaxGetManyIDs:]}, nil),
+	)
+
+	// when
+	service := NewDefaultThreatModelTagService(mockDao, mockThreatModelService, hiding(ctrl))
+	result, err := service.GetAllWithTags(ctx, []string{"pci"}, false)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, threatModels, result)
+}
+
+func TestGetAllTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDao := dao.NewMockThreatModelDao(ctrl)
+	ctx := context.Background()
+
+	mockDao.EXPECT().GetAllTags(ctx).Return(map[m.ThreatModelID][]string{
+		m.NewThreatModelIDP("tm-1"): {"bu:payments", "pci"},
+		m.NewThreatModelIDP("tm-2"): {"bu:payments", "pci"},
+		m.NewThreatModelIDP("tm-3"): {"bu:cards", "bu:payments"},
+		m.NewThreatModelIDP("tm-4"): {"bu:cards"},
+		m.NewThreatModelIDP("tm-5"): {"bu:payments", "secret"},
+		m.NewThreatModelIDP("tm-6"): {"bu:payments"},
+	}, nil)
+
+	service := NewDefaultThreatModelTagService(mockDao, nil, hiding(ctrl, m.NewThreatModelIDP("tm-5")))
+	result, err := service.GetAllTags(ctx)
+
+	require.Nil(t, err)
+	require.Equal(t, []tm.TagCount{
+		{Tag: "bu:payments", Count: 4},
+		{Tag: "bu:cards", Count: 2},
+		{Tag: "pci", Count: 2},
+	}, result)
+}
+
+func TestAddTags(t *testing.T) {
+	id := m.NewThreatModelIDP("1234-1234-1234-1234")
+
+	var tests = []struct {
+		name          string
+		daoError      error
+		expected      []string
+		expectedError error
+	}{
+		{"should add normalised tags", nil, []string{"bu:payments", "pci"}, nil},
+		{"should report too many tags", dao.ErrTooManyTags, nil, ErrTooManyTags},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDao := dao.NewMockThreatModelDao(ctrl)
+			mockThreatModelService := NewMockThreatModelService(ctrl)
+			ctx := context.Background()
+
+			mockThreatModelService.EXPECT().Get(ctx, id).Return(&m.ThreatModel{ThreatModelID: id}, nil)
+			mockDao.EXPECT().AddTags(ctx, id, []string{"pci"}, MaxTagsPerThreatModel).Return(test.expected, test.daoError)
+
+			// when
+			service := NewDefaultThreatModelTagService(mockDao, mockThreatModelService, nil)
+			result, err := service.AddTags(ctx, id, []string{" PCI"})
+
+			// then
+			require.Equal(t, test.expectedError, err)
+			require.Equal(t, test.expected, result)
+		})
+	}
+}
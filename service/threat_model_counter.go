@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/jtyers/tmaas-service-util/log"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	dao "github.com/jtyers/tmaas-threat-model-api/dao"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+)
+
+const (
+	// how often the threat models of each tenant are counted
+	threatModelCountInterval = time.Minute
+
+	// the identity threat models are counted as
+	threatModelCounterServiceAccount = "threat-model-counter"
+)
+
+// ThreatModelCounter keeps the threat model gauge up to date by counting
+// each tenant's threat models in the store, so that every instance reports
+// the same, true count however requests are spread between them.
+type ThreatModelCounter struct {
+	dao     dao.ThreatModelDao
+	metrics *metrics.Metrics
+}
+
+func NewThreatModelCounter(dao dao.ThreatModelDao, metrics *metrics.Metrics) *ThreatModelCounter {
+	return &ThreatModelCounter{dao, metrics}
+}
+
+// Run counts threat models every threatModelCountInterval until ctx is
+// done.
+func (c *ThreatModelCounter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(threatModelCountInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.CountOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("error counting threat models: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// CountOnce counts the threat models of every tenant. Tenants that cannot
+// be counted keep their last count.
+func (c *ThreatModelCounter) CountOnce(ctx context.Context) error {
+	tenants, err := c.dao.GetTenants(ctx)
+	if err != nil {
+		return err
+	}
+
+	counts := map[auth.TenantID]int{}
+	for _, tenantID := range tenants {
+		tenantCtx := auth.WithIdentity(ctx, &auth.Identity{ServiceAccountName: threatModelCounterServiceAccount, TenantID: tenantID})
+
+		n, err := c.dao.Count(tenantCtx)
+		if err != nil {
+			log.Errorf("error counting threat models of tenant %s: %v", tenantID, err)
+			continue
+		}
+		counts[tenantID] = n
+	}
+
+	for tenantID, n := range counts {
+		c.metrics.ThreatModels.WithLabelValues(tenantID.String()).Set(float64(n))
+	}
+
+	return nil
+}
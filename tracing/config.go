@@ -0,0 +1,117 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	util "github.com/jtyers/tmaas-service-util"
+	"github.com/jtyers/tmaas-service-util/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// Export spans via OTLP over gRPC. The endpoint and so on are set by
+	// the standard OTEL_EXPORTER_OTLP_* environment variables.
+	ExporterOTLP = "otlp"
+
+	// Print spans to stdout, for local debugging.
+	ExporterStdout = "stdout"
+
+	// Do not record spans. Trace context is still propagated.
+	ExporterNone = "none"
+
+	// how long to wait for remaining spans to be exported on shutdown
+	shutdownTimeout = 5 * time.Second
+)
+
+type Config struct {
+	Exporter    string
+	ServiceName string
+
+	// The fraction of traces to sample, for traces not already sampled
+	// (or not) by the caller.
+	SampleRatio float64
+}
+
+// NewConfig reads the exporter from TRACING_EXPORTER, the service name from
+// OTEL_SERVICE_NAME and the sample ratio from TRACING_SAMPLE_RATIO.
+func NewConfig() (Config, error) {
+	config := Config{
+		Exporter:    util.GetEnvWithDefault("TRACING_EXPORTER", ExporterNone),
+		ServiceName: util.GetEnvWithDefault("OTEL_SERVICE_NAME", "threat-model-api"),
+		SampleRatio: 1,
+	}
+
+	if ratio := util.GetEnvWithDefault("TRACING_SAMPLE_RATIO", ""); ratio != "" {
+		var err error
+		config.SampleRatio, err = strconv.ParseFloat(ratio, 64)
+		if err != nil {
+			return Config{}, fmt.Errorf("error parsing TRACING_SAMPLE_RATIO: %v", err)
+		}
+	}
+
+	return config, nil
+}
+
+// NewTracerProvider creates a TracerProvider exporting spans as configured,
+// and installs it globally along with the W3C trace context and baggage
+// propagators. Outgoing calls carry the trace context when sent through an
+// http.Client from NewHTTPClient.
+//
+// The returned cleanup function shuts the provider down, flushing any spans
+// not yet exported. For ExporterNone the provider is a no-op provider.
+func NewTracerProvider(ctx context.Context, config Config) (trace.TracerProvider, func(), error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch config.Exporter {
+	case ExporterOTLP:
+		exporter, err = otlptracegrpc.New(ctx)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterNone, "":
+		tp := trace.NewNoopTracerProvider()
+		otel.SetTracerProvider(tp)
+		return tp, func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown tracing exporter %q", config.Exporter)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating %s trace exporter: %v", config.Exporter, err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(config.ServiceName),
+		)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := tp.Shutdown(ctx); err != nil {
+			log.Errorf("error shutting down tracer provider: %v", err)
+		}
+	}
+
+	return tp, cleanup, nil
+}
@@ -0,0 +1,11 @@
+package tracing
+
+import (
+	"github.com/google/wire"
+)
+
+var TracingProviderSet = wire.NewSet(
+	NewConfig,
+	NewTracerProvider,
+	NewHTTPClient,
+)
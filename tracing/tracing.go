@@ -0,0 +1,35 @@
+// Package tracing sets up OpenTelemetry tracing, and provides helpers for
+// creating spans.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TracerName = "github.com/jtyers/tmaas-threat-model-api"
+)
+
+// Tracer returns the tracer of the global TracerProvider, as set by
+// NewTracerProvider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Start a span named name as a child of any span in ctx.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// End a span, first marking it as failed if err is non-nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// withRecorder installs a TracerProvider recording spans for the duration
+// of a test.
+func withRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	return recorder
+}
+
+func TestTransport(t *testing.T) {
+	var tests = []struct {
+		name           string
+		status         int
+		expectedStatus string
+	}{
+		{"should propagate trace context on success", http.StatusOK, "Unset"},
+		{"should mark server errors as failed", http.StatusBadGateway, "Error"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			recorder := withRecorder(t)
+
+			var traceparent string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				traceparent = r.Header.Get("traceparent")
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			ctx, parent := Start(context.Background(), "parent")
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			require.Nil(t, err)
+
+			client := &http.Client{Transport: NewTransport(nil)}
+
+			// when
+			resp, err := client.Do(req)
+			parent.End()
+
+			// then
+			require.Nil(t, err)
+			resp.Body.Close()
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 2)
+
+			clientSpan := spans[0]
+			require.Equal(t, "HTTP GET", clientSpan.Name())
+			require.Equal(t, trace.SpanKindClient, clientSpan.SpanKind())
+			require.Equal(t, parent.SpanContext().SpanID(), clientSpan.Parent().SpanID())
+			require.Equal(t, test.expectedStatus, clientSpan.Status().Code.String())
+
+			// the server sees the client span as its parent
+			require.Contains(t, traceparent, clientSpan.SpanContext().TraceID().String())
+			require.Contains(t, traceparent, clientSpan.SpanContext().SpanID().String())
+
+			// the caller's request is untouched
+			require.Empty(t, req.Header.Get("traceparent"))
+		})
+	}
+}
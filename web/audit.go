@@ -0,0 +1,108 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+const (
+	AuditUrlPrefix = "/api/v1/audit"
+
+	RequestIDHeader = "X-Request-ID"
+)
+
+var (
+	ErrInvalidAuditTime = errors.New("from and to must be RFC 3339 times")
+	ErrInvalidPageSize  = errors.New("pageSize must be a number")
+)
+
+// request IDs supplied by callers (or load balancers) are kept if they look
+// sane, so that our audit records can be correlated with their logs
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:/+=-]{1,128}$`)
+
+// auditResourceParams are the route parameters naming the resource a
+// request acts on, most specific last.
+var auditResourceParams = []struct {
+	param        string
+	resourceType string
+}{
+	{"projectID", "project"},
+	{"threatModelID", service.AuditResourceThreatModel},
+}
+
+// AuditMiddleware assigns each request an ID, and records an audit event
+// for it once it has been handled. The request ID is taken from the
+// X-Request-ID header if present, echoed in the response, and placed into
+// the context so that service-level audit events carry it too. Requests
+// rejected by a rate limit are not recorded, so that they cannot be used to
+// flood the audit log. It must run after IdentityMiddleware and
+// RateLimiter.Middleware, and before the errors middleware so that it sees
+// the final status code.
+func AuditMiddleware(auditor service.Auditor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(service.WithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+
+		if c.Writer.Status() == http.StatusTooManyRequests {
+			return
+		}
+
+		record := tm.AuditRecord{
+			Action:     c.Request.Method + " " + c.FullPath(),
+			StatusCode: c.Writer.Status(),
+		}
+
+		if c.FullPath() == "" {
+			record.Action = c.Request.Method + " " + c.Request.URL.Path
+		}
+
+		for _, p := range auditResourceParams {
+			if id := c.Param(p.param); id != "" {
+				record.ResourceType = p.resourceType
+				record.ResourceID = id
+			}
+		}
+
+		switch status := c.Writer.Status(); {
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			record.Outcome = tm.AuditOutcomeDenied
+		case status >= http.StatusBadRequest:
+			record.Outcome = tm.AuditOutcomeFailure
+		default:
+			record.Outcome = tm.AuditOutcomeSuccess
+		}
+
+		if len(c.Errors) > 0 {
+			record.Detail = c.Errors.Last().Error()
+		}
+
+		auditor.Record(c, record)
+	}
+}
+
+// parseAuditTime parses an optional RFC 3339 time query parameter.
+func parseAuditTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, ErrInvalidAuditTime
+	}
+	return &t, nil
+}
@@ -0,0 +1,82 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+type AuditHandlers struct {
+	auditService service.AuditService
+}
+
+func NewAuditHandlers(as service.AuditService) *AuditHandlers {
+	return &AuditHandlers{auditService: as}
+}
+
+// @Summary Retrieves audit records of the caller's tenant, newest first. Only administrators may call this.
+// @Produce json
+// @Param resource query string false "Only records acting on this resource ID"
+// @Param user query string false "Only records of this user ID or service account name"
+// @Param from query string false "Only records at or after this RFC 3339 time"
+// @Param to query string false "Only records before this RFC 3339 time"
+// @Param pageSize query int false "The maximum number of records to return (default 50, maximum 500)"
+// @Param pageToken query string false "The nextPageToken of the previous page"
+// @Security firebase
+// @Success 200 {object} tm.AuditPage "The matching audit records"
+// @Failure 400 {string} string "If a time, page size or page token is invalid, or from is not before to."
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Failure 403 {string} string "If the caller is not an administrator."
+// @Router /api/v1/audit [get]
+func (ah *AuditHandlers) GetAuditHandler(c *gin.Context) {
+	q := tm.AuditQuery{
+		ResourceID: c.Query("resource"),
+		Actor:      c.Query("user"),
+		PageToken:  c.Query("pageToken"),
+	}
+
+	var err error
+	if q.From, err = parseAuditTime(c.Query("from")); err != nil {
+		c.Error(err)
+		return
+	}
+	if q.To, err = parseAuditTime(c.Query("to")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if pageSize := c.Query("pageSize"); pageSize != "" {
+		if q.PageSize, err = strconv.Atoi(pageSize); err != nil {
+			c.Error(ErrInvalidPageSize)
+			return
+		}
+	}
+
+	result, err := ah.auditService.Query(c, q)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// @Summary Verifies the hash chain of a threat model's audit records, reporting the first broken link. Only administrators may call this.
+// @Produce json
+// @Param threatModelID path string true "The threat model ID"
+// @Security firebase
+// @Success 200 {object} tm.AuditVerification "Whether the chain is intact, and if not, where it is broken"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Failure 403 {string} string "If the caller is not an administrator."
+// @Failure 501 {string} string "If audit records are not hash chained, as no audit key is configured."
+// @Router /api/v1/threatmodel/{threatModelID}/audit/verify [get]
+func (ah *AuditHandlers) VerifyThreatModelAuditHandler(c *gin.Context) {
+	result, err := ah.auditService.VerifyChain(c, service.AuditResourceThreatModel, c.Param("threatModelID"))
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,117 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAuditor keeps the events it is given, along with the request ID
+// in their context.
+type recordingAuditor struct {
+	records    []tm.AuditRecord
+	requestIDs []string
+}
+
+func (a *recordingAuditor) Record(ctx context.Context, record tm.AuditRecord) {
+	a.records = append(a.records, record)
+	a.requestIDs = append(a.requestIDs, service.RequestIDFromContext(ctx))
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	var tests = []struct {
+		name              string
+		status            int
+		requestID         string
+		expectedOutcome   tm.AuditOutcome
+		expectedRequestID string
+	}{
+		{
+			"should record successful requests and keep the caller's request ID",
+			http.StatusOK,
+			"req-1",
+			tm.AuditOutcomeSuccess,
+			"req-1",
+		},
+		{
+			"should record forbidden requests as denied",
+			http.StatusForbidden,
+			"req-2",
+			tm.AuditOutcomeDenied,
+			"req-2",
+		},
+		{
+			"should record errors as failures and generate missing request IDs",
+			http.StatusInternalServerError,
+			"",
+			tm.AuditOutcomeFailure,
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			auditor := &recordingAuditor{}
+
+			r := gin.New()
+			r.ContextWithFallback = true
+			r.Use(IdentityMiddleware(auth.NewStaticIdentityExtractor(&auth.Identity{UserID: "u-1234", TenantID: "acme"})))
+			r.Use(AuditMiddleware(auditor))
+			r.GET(UrlPrefix+"/:threatModelID", func(c *gin.Context) {
+				c.Status(test.status)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, UrlPrefix+"/tm-1", nil)
+			if test.requestID != "" {
+				req.Header.Set(RequestIDHeader, test.requestID)
+			}
+			w := httptest.NewRecorder()
+
+			// when
+			r.ServeHTTP(w, req)
+
+			// then
+			require.Len(t, auditor.records, 1)
+
+			record := auditor.records[0]
+			require.Equal(t, "GET "+UrlPrefix+"/:threatModelID", record.Action)
+			require.Equal(t, service.AuditResourceThreatModel, record.ResourceType)
+			require.Equal(t, "tm-1", record.ResourceID)
+			require.Equal(t, test.status, record.StatusCode)
+			require.Equal(t, test.expectedOutcome, record.Outcome)
+
+			requestID := w.Header().Get(RequestIDHeader)
+			require.NotEmpty(t, requestID)
+			require.Equal(t, requestID, auditor.requestIDs[0])
+			if test.expectedRequestID != "" {
+				require.Equal(t, test.expectedRequestID, requestID)
+			}
+		})
+	}
+}
+
+func TestAuditMiddlewareSkipsRateLimitedRequests(t *testing.T) {
+	// given
+	auditor := &recordingAuditor{}
+
+	r := gin.New()
+	r.Use(IdentityMiddleware(auth.NewStaticIdentityExtractor(&auth.Identity{UserID: "u-1234", TenantID: "acme"})))
+	r.Use(AuditMiddleware(auditor))
+	r.GET(UrlPrefix+"/:threatModelID", func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusTooManyRequests)
+	})
+
+	// when
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, UrlPrefix+"/tm-1", nil))
+
+	// then
+	require.Empty(t, auditor.records)
+}
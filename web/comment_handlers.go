@@ -0,0 +1,204 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	m "github.com/jtyers/tmaas-model"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+type CommentHandlers struct {
+	commentService service.CommentService
+}
+
+func NewCommentHandlers(cs service.CommentService) *CommentHandlers {
+	return &CommentHandlers{commentService: cs}
+}
+
+// @Summary Retrieves all comments on a threat model, including comments on its threats
+// @Produce json
+// @Param id path string true "The threat model ID to retrieve comments for"
+// @Security firebase
+// @Success 200 {array} tm.Comment "The comments, oldest first"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Failure 404 {string} string "If the threat model ID does not exist or is not visible to this user."
+// @Router /api/v1/threatmodel/{id}/comments [get]
+func (ch *CommentHandlers) GetCommentsHandler(c *gin.Context) {
+	threatModelID := m.NewThreatModelIDP(c.Param("threatModelID"))
+
+	result, err := ch.commentService.GetAll(c, threatModelID)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// @Summary Retrieves all comments on a single threat within a threat model
+// @Produce json
+// @Param id path string true "The threat model ID containing the threat"
+// @Param threatId path string true "The title of the threat to retrieve comments for"
+// @Security firebase
+// @Success 200 {array} tm.Comment "The comments, oldest first"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Failure 404 {string} string "If the threat model ID does not exist or is not visible to this user."
+// @Router /api/v1/threatmodel/{id}/threats/{threatId}/comments [get]
+func (ch *CommentHandlers) GetThreatCommentsHandler(c *gin.Context) {
+	threatModelID := m.NewThreatModelIDP(c.Param("threatModelID"))
+
+	result, err := ch.commentService.GetAllForThreat(c, threatModelID, c.Param("threatID"))
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// @Summary Add a comment to a threat model, optionally as a reply to another comment
+// @Accept json
+// @Produce json
+// @Param id path string true "The threat model ID to comment on"
+// @Param data body tm.CommentParams true "The comment to add"
+// @Security firebase
+// @Success 200 {object} tm.Comment "The created comment"
+// @Failure 400 {string} string "If the comment is empty, or the parent comment is not part of the same thread"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not belong to a user"
+// @Failure 404 {string} string "If the threat model ID does not exist or is not visible to this user."
+// @Router /api/v1/threatmodel/{id}/comments [post]
+func (ch *CommentHandlers) PostCommentHandler(c *gin.Context) {
+	ch.createComment(c, "")
+}
+
+// @Summary Add a comment to a threat within a threat model, optionally as a reply to another comment
+// @Accept json
+// @Produce json
+// @Param id path string true "The threat model ID containing the threat"
+// @Param threatId path string true "The title of the threat to comment on"
+// @Param data body tm.CommentParams true "The comment to add"
+// @Security firebase
+// @Success 200 {object} tm.Comment "The created comment"
+// @Failure 400 {string} string "If the comment is empty, or the parent comment is not part of the same thread"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not belong to a user"
+// @Failure 404 {string} string "If the threat model ID does not exist or is not visible to this user, or has no such threat."
+// @Router /api/v1/threatmodel/{id}/threats/{threatId}/comments [post]
+func (ch *CommentHandlers) PostThreatCommentHandler(c *gin.Context) {
+	ch.createComment(c, c.Param("threatID"))
+}
+
+func (ch *CommentHandlers) createComment(c *gin.Context, threatID string) {
+	threatModelID := m.NewThreatModelIDP(c.Param("threatModelID"))
+
+	var params tm.CommentParams
+
+	err := c.BindJSON(&params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := ch.commentService.Create(c, threatModelID, threatID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.PureJSON(http.StatusOK, result)
+}
+
+// @Summary Edit a comment. Only the comment's author may do this.
+// @Accept json
+// @Produce json
+// @Param id path string true "The threat model ID the comment belongs to"
+// @Param commentId path string true "The comment ID to edit"
+// @Param data body tm.CommentParams true "The new comment body"
+// @Security firebase
+// @Success 200 {object} tm.Comment "The updated comment"
+// @Failure 400 {string} string "If the comment is empty"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not belong to a user"
+// @Failure 403 {string} string "If the caller is not the comment's author"
+// @Failure 404 {string} string "If the comment does not exist"
+// @Router /api/v1/threatmodel/{id}/comments/{commentId} [patch]
+func (ch *CommentHandlers) PatchCommentHandler(c *gin.Context) {
+	threatModelID := m.NewThreatModelIDP(c.Param("threatModelID"))
+	commentID := tm.CommentID(c.Param("commentID"))
+
+	var params tm.CommentParams
+
+	err := c.BindJSON(&params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := ch.commentService.Update(c, threatModelID, commentID, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.PureJSON(http.StatusOK, result)
+}
+
+// @Summary Delete a comment. Only the comment's author may do this.
+// @Produce json
+// @Param id path string true "The threat model ID the comment belongs to"
+// @Param commentId path string true "The comment ID to delete"
+// @Security firebase
+// @Success 200 {string} string "Returned when the delete succeeds."
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not belong to a user"
+// @Failure 403 {string} string "If the caller is not the comment's author"
+// @Failure 404 {string} string "If the comment does not exist"
+// @Router /api/v1/threatmodel/{id}/comments/{commentId} [delete]
+func (ch *CommentHandlers) DeleteCommentHandler(c *gin.Context) {
+	threatModelID := m.NewThreatModelIDP(c.Param("threatModelID"))
+	commentID := tm.CommentID(c.Param("commentID"))
+
+	err := ch.commentService.Delete(c, threatModelID, commentID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+}
+
+// @Summary Mark a comment thread as resolved
+// @Produce json
+// @Param id path string true "The threat model ID the comment belongs to"
+// @Param commentId path string true "The top-level comment ID to resolve"
+// @Security firebase
+// @Success 200 {object} tm.Comment "The updated comment"
+// @Failure 400 {string} string "If the comment is a reply rather than a top-level comment"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not belong to a user"
+// @Failure 404 {string} string "If the comment does not exist"
+// @Router /api/v1/threatmodel/{id}/comments/{commentId}/resolve [post]
+func (ch *CommentHandlers) ResolveCommentHandler(c *gin.Context) {
+	ch.resolveComment(c, true)
+}
+
+// @Summary Mark a comment thread as unresolved
+// @Produce json
+// @Param id path string true "The threat model ID the comment belongs to"
+// @Param commentId path string true "The top-level comment ID to unresolve"
+// @Security firebase
+// @Success 200 {object} tm.Comment "The updated comment"
+// @Failure 400 {string} string "If the comment is a reply rather than a top-level comment"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not belong to a user"
+// @Failure 404 {string} string "If the comment does not exist"
+// @Router /api/v1/threatmodel/{id}/comments/{commentId}/unresolve [post]
+func (ch *CommentHandlers) UnresolveCommentHandler(c *gin.Context) {
+	ch.resolveComment(c, false)
+}
+
+func (ch *CommentHandlers) resolveComment(c *gin.Context, resolved bool) {
+	threatModelID := m.NewThreatModelIDP(c.Param("threatModelID"))
+	commentID := tm.CommentID(c.Param("commentID"))
+
+	result, err := ch.commentService.Resolve(c, threatModelID, commentID, resolved)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.PureJSON(http.StatusOK, result)
+}
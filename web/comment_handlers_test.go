@@ -0,0 +1,271 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jtyers/tmaas-api-util/combo"
+	"github.com/jtyers/tmaas-api-util/errors"
+	cmocks "github.com/jtyers/tmaas-cors-config/mocks"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/health"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/ratelimit"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+func createCommentServer(comboFactory combo.ComboMiddlewareFactory, cs service.CommentService) (*httptest.Server, func()) {
+	handlers := NewThreatModelHandlers(nil, nil, nil, allowAllAccessChecker{}, noopAuditor{})
+	testServer := httptest.NewServer(NewRouter(handlers, NewCommentHandlers(cs), NewSearchHandlers(nil), NewProjectHandlers(nil), NewAuditHandlers(nil), NewHealthHandlers(health.NewChecker(time.Second)), NewGraphQLHandlers(nil), comboFactory, errors.NewDefaultErrorsMiddlewareFactory(), cmocks.NewMockCorsMiddleware(), auth.NewStaticIdentityExtractor(nil), allowAllAccessChecker{}, noopAuditor{}, NewRateLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{}), metrics.NewMetrics(), trace.NewNoopTracerProvider()))
+
+	gin.SetMode(gin.TestMode)
+	closer := func() { testServer.Close() }
+	return testServer, closer
+}
+
+func TestCommentHandlers(t *testing.T) {
+	threatModelID := m.NewThreatModelIDP("tm-1")
+	user := &m.AuthenticationInfo{UserID: "u-1234", Roles: []m.Role{m.RoleUser}}
+
+	comment := &tm.Comment{
+		CommentID:     "cmt-1",
+		ThreatModelID: threatModelID,
+		Author:        "u-1234",
+		Body:          "hello @u-5678",
+		Mentions:      []m.UserID{"u-5678"},
+	}
+	threatComment := &tm.Comment{
+		CommentID:     "cmt-2",
+		ThreatModelID: threatModelID,
+		ThreatID:      "Spoofing",
+		Author:        "u-1234",
+		Body:          "agreed",
+		Mentions:      []m.UserID{},
+	}
+	params := tm.CommentParams{Body: m.String("hello @u-5678")}
+
+	var tests = []struct {
+		name             string
+		token            m.AuthenticationToken
+		method           string
+		path             string
+		body             any
+		expect           func(cs *service.MockCommentService)
+		expectedResponse int
+		expectedBody     any // not checked if nil
+	}{
+		{
+			name:   "should get comments",
+			token:  user,
+			method: http.MethodGet,
+			path:   "/comments",
+			expect: func(cs *service.MockCommentService) {
+				cs.EXPECT().GetAll(gomock.Any(), threatModelID).Return([]*tm.Comment{comment, threatComment}, nil)
+			},
+			expectedResponse: http.StatusOK,
+			expectedBody:     []*tm.Comment{comment, threatComment},
+		},
+		{
+			name:   "should get comments on a threat",
+			token:  user,
+			method: http.MethodGet,
+			path:   "/threats/Spoofing/comments",
+			expect: func(cs *service.MockCommentService) {
+				cs.EXPECT().GetAllForThreat(gomock.Any(), threatModelID, "Spoofing").Return([]*tm.Comment{threatComment}, nil)
+			},
+			expectedResponse: http.StatusOK,
+			expectedBody:     []*tm.Comment{threatComment},
+		},
+		{
+			name:   "should return 404 for comments on missing threat models",
+			token:  user,
+			method: http.MethodGet,
+			path:   "/comments",
+			expect: func(cs *service.MockCommentService) {
+				cs.EXPECT().GetAll(gomock.Any(), threatModelID).Return(nil, service.ErrNoSuchThreatModel)
+			},
+			expectedResponse: http.StatusNotFound,
+		},
+		{
+			name:   "should create a comment",
+			token:  user,
+			method: http.MethodPost,
+			path:   "/comments",
+			body:   params,
+			expect: func(cs *service.MockCommentService) {
+				cs.EXPECT().Create(gomock.Any(), threatModelID, "", params).Return(comment, nil)
+			},
+			expectedResponse: http.StatusOK,
+			expectedBody:     comment,
+		},
+		{
+			name:   "should create a comment on a threat",
+			token:  user,
+			method: http.MethodPost,
+			path:   "/threats/Spoofing/comments",
+			body:   tm.CommentParams{Body: m.String("agreed")},
+			expect: func(cs *service.MockCommentService) {
+				cs.EXPECT().Create(gomock.Any(), threatModelID, "Spoofing", tm.CommentParams{Body: m.String("agreed")}).Return(threatComment, nil)
+			},
+			expectedResponse: http.StatusOK,
+			expectedBody:     threatComment,
+		},
+		{
+			name:   "should return 404 for comments on missing threats",
+			token:  user,
+			method: http.MethodPost,
+			path:   "/threats/Tampering/comments",
+			body:   params,
+			expect: func(cs *service.MockCommentService) {
+				cs.EXPECT().Create(gomock.Any(), threatModelID, "Tampering", params).Return(nil, service.ErrNoSuchThreat)
+			},
+			expectedResponse: http.StatusNotFound,
+		},
+		{
+			name:   "should reject empty comments",
+			token:  user,
+			method: http.MethodPost,
+			path:   "/comments",
+			body:   tm.CommentParams{Body: m.String(" ")},
+			expect: func(cs *service.MockCommentService) {
+				cs.EXPECT().Create(gomock.Any(), threatModelID, "", gomock.Any()).Return(nil, service.ErrEmptyComment)
+			},
+			expectedResponse: http.StatusBadRequest,
+		},
+		{
+			name:             "should reject badly formed comments",
+			token:            user,
+			method:           http.MethodPost,
+			path:             "/comments",
+			body:             "not a comment",
+			expectedResponse: http.StatusBadRequest,
+		},
+		{
+			name:   "should edit a comment",
+			token:  user,
+			method: http.MethodPatch,
+			path:   "/comments/cmt-1",
+			body:   params,
+			expect: func(cs *service.MockCommentService) {
+				cs.EXPECT().Update(gomock.Any(), threatModelID, tm.CommentID("cmt-1"), params).Return(comment, nil)
+			},
+			expectedResponse: http.StatusOK,
+			expectedBody:     comment,
+		},
+		{
+			name:   "should return 403 when editing another user's comment",
+			token:  user,
+			method: http.MethodPatch,
+			path:   "/comments/cmt-1",
+			body:   params,
+			expect: func(cs *service.MockCommentService) {
+				cs.EXPECT().Update(gomock.Any(), threatModelID, tm.CommentID("cmt-1"), params).Return(nil, service.ErrNotCommentAuthor)
+			},
+			expectedResponse: http.StatusForbidden,
+		},
+		{
+			name:   "should delete a comment",
+			token:  user,
+			method: http.MethodDelete,
+			path:   "/comments/cmt-1",
+			expect: func(cs *service.MockCommentService) {
+				cs.EXPECT().Delete(gomock.Any(), threatModelID, tm.CommentID("cmt-1")).Return(nil)
+			},
+			expectedResponse: http.StatusOK,
+		},
+		{
+			name:   "should return 404 when deleting missing comments",
+			token:  user,
+			method: http.MethodDelete,
+			path:   "/comments/cmt-404",
+			expect: func(cs *service.MockCommentService) {
+				cs.EXPECT().Delete(gomock.Any(), threatModelID, tm.CommentID("cmt-404")).Return(service.ErrNoSuchComment)
+			},
+			expectedResponse: http.StatusNotFound,
+		},
+		{
+			name:   "should resolve a comment",
+			token:  user,
+			method: http.MethodPost,
+			path:   "/comments/cmt-1/resolve",
+			expect: func(cs *service.MockCommentService) {
+				cs.EXPECT().Resolve(gomock.Any(), threatModelID, tm.CommentID("cmt-1"), true).Return(comment, nil)
+			},
+			expectedResponse: http.StatusOK,
+			expectedBody:     comment,
+		},
+		{
+			name:   "should unresolve a comment",
+			token:  user,
+			method: http.MethodPost,
+			path:   "/comments/cmt-1/unresolve",
+			expect: func(cs *service.MockCommentService) {
+				cs.EXPECT().Resolve(gomock.Any(), threatModelID, tm.CommentID("cmt-1"), false).Return(comment, nil)
+			},
+			expectedResponse: http.StatusOK,
+			expectedBody:     comment,
+		},
+		{
+			name:   "should not resolve replies",
+			token:  user,
+			method: http.MethodPost,
+			path:   "/comments/cmt-2/resolve",
+			expect: func(cs *service.MockCommentService) {
+				cs.EXPECT().Resolve(gomock.Any(), threatModelID, tm.CommentID("cmt-2"), true).Return(nil, service.ErrCannotResolveReply)
+			},
+			expectedResponse: http.StatusBadRequest,
+		},
+		{
+			name:             "should return 401 when no token passed",
+			method:           http.MethodGet,
+			path:             "/comments",
+			expectedResponse: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// given
+			cs := service.NewMockCommentService(ctrl)
+			if test.expect != nil {
+				test.expect(cs)
+			}
+
+			comboFactory := combo.NewMockComboMiddlewareFactoryWithTokensAndPermissions(ctrl, test.token, combo.ServiceAccountPermissionsJson(`{}`))
+			server, closeServer := createCommentServer(comboFactory, cs)
+			defer closeServer()
+
+			var body *strings.Reader
+			if test.body != nil {
+				body = strings.NewReader(toJsonString(test.body))
+			} else {
+				body = strings.NewReader("")
+			}
+
+			// when
+			request, _ := http.NewRequest(test.method, server.URL+UrlPrefix+"/"+threatModelID.String()+test.path, body)
+			response, err := http.DefaultClient.Do(request)
+
+			// then
+			require.Nil(t, err)
+			require.Equal(t, test.expectedResponse, response.StatusCode)
+
+			if test.expectedBody != nil {
+				require.JSONEq(t, toJsonString(test.expectedBody), readToString(response.Body))
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package web
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BatchGetMethod is the custom method retrieving many threat models at
+// once, as in POST /api/v1/threatmodel:batchGet.
+const BatchGetMethod = "batchGet"
+
+// CustomMethods dispatches custom methods (a colon and a verb appended to
+// a collection's path, as in /api/v1/threatmodel:batchGet) to their
+// handlers. gin cannot route a literal colon, so a route ending ":method"
+// takes every custom method on the collection, and this handler picks one
+// by name. Unknown methods get the same 404 as any other unknown path.
+func CustomMethods(handlers map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handler, ok := handlers[strings.TrimPrefix(c.Param("method"), ":")]
+		if !ok {
+			c.JSON(404, gin.H{"code": "PAGE_NOT_FOUND", "message": "Page not found"})
+			return
+		}
+
+		handler(c)
+	}
+}
@@ -0,0 +1,46 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	m "github.com/jtyers/tmaas-model"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+const (
+	DiffFormatJSON    = "json"
+	DiffFormatUnified = "unified"
+)
+
+var (
+	ErrInvalidDiffSource = errors.New("from must be a revision number or a threat model ID")
+	ErrInvalidDiffFormat = errors.New("format must be 'json' or 'unified'")
+)
+
+// getDiffSource retrieves the version of a threat model named by the from
+// or to query parameter of a diff of threatModelID: a revision number of
+// it, the ID of another threat model, or if empty, its current version.
+// It returns the version along with a label for it.
+func (th *ThreatModelHandlers) getDiffSource(c *gin.Context, threatModelID m.ThreatModelID, source string) (*m.ThreatModel, string, error) {
+	if source == "" {
+		threatModel, err := th.threatModelService.Get(c, threatModelID)
+		return threatModel, threatModelID.String(), err
+	}
+
+	if revision, err := strconv.ParseInt(source, 10, 64); err == nil {
+		threatModel, err := th.revisionService.GetRevision(c, threatModelID, revision)
+		return threatModel, fmt.Sprintf("%s@%d", threatModelID, revision), err
+	}
+
+	// the route checks access to threatModelID only
+	otherID := m.NewThreatModelIDP(source)
+	if err := th.accessChecker.CheckThreatModelAccess(c, otherID, tm.ProjectRoleViewer); err != nil {
+		return nil, "", err
+	}
+
+	threatModel, err := th.threatModelService.Get(c, otherID)
+	return threatModel, otherID.String(), err
+}
@@ -0,0 +1,29 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jtyers/tmaas-threat-model-api/docs"
+)
+
+var (
+	OpenAPIPath = UrlPrefix + "/openapi.json"
+	DocsPath    = UrlPrefix + "/docs"
+)
+
+// @Summary Retrieves the OpenAPI 3 document describing this API
+// @Produce json
+// @Success 200 {object} map[string]interface{} "The OpenAPI document"
+// @Router /api/v1/threatmodel/openapi.json [get]
+func OpenAPIHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", docs.OpenAPI)
+}
+
+// @Summary Renders the OpenAPI document as interactive documentation
+// @Produce html
+// @Success 200 {string} string "The documentation page"
+// @Router /api/v1/threatmodel/docs [get]
+func DocsHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docs.Redoc)
+}
@@ -0,0 +1,121 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jtyers/tmaas-api-util/combo"
+	apierrors "github.com/jtyers/tmaas-api-util/errors"
+	cmocks "github.com/jtyers/tmaas-cors-config/mocks"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/docs"
+	"github.com/jtyers/tmaas-threat-model-api/health"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	"github.com/jtyers/tmaas-threat-model-api/ratelimit"
+)
+
+var (
+	ginParam     = regexp.MustCompile(`:[^/]+`)
+	openAPIParam = regexp.MustCompile(`{[^/]+}`)
+
+	// custom methods are documented by name, but routed as a parameter
+	openAPICustomMethod = regexp.MustCompile(`([^/]):[^/]+$`)
+)
+
+func newDocsTestRouter(ctrl *gomock.Controller) *gin.Engine {
+	comboFactory := combo.NewMockComboMiddlewareFactoryWithTokensAndPermissions(ctrl, nil, combo.ServiceAccountPermissionsJson(`{}`))
+
+	return NewRouter(NewThreatModelHandlers(nil, nil, nil, nil, nil), NewCommentHandlers(nil), NewSearchHandlers(nil), NewProjectHandlers(nil), NewAuditHandlers(nil), NewHealthHandlers(health.NewChecker(time.Second)), NewGraphQLHandlers(nil), comboFactory, apierrors.NewDefaultErrorsMiddlewareFactory(), cmocks.NewMockCorsMiddleware(), auth.NewStaticIdentityExtractor(nil), allowAllAccessChecker{}, noopAuditor{}, NewRateLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{}), metrics.NewMetrics(), trace.NewNoopTracerProvider()).(*gin.Engine)
+}
+
+// If this fails, annotate the handler of the route and regenerate the
+// document with go generate ./docs.
+func TestEveryRouteIsDocumented(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// given
+	var spec struct {
+		Paths map[string]map[string]interface{} `json:"paths"`
+	}
+	require.Nil(t, json.Unmarshal(docs.OpenAPI, &spec))
+
+	// path parameters are named differently in routes and the document,
+	// so compare paths with the names removed
+	documented := map[string]bool{}
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			path := openAPICustomMethod.ReplaceAllString(openAPIParam.ReplaceAllString(path, "{}"), "${1}{}")
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	// when
+	routes := newDocsTestRouter(ctrl).Routes()
+
+	// then
+	require.NotEmpty(t, routes)
+
+	routed := map[string]bool{}
+	for _, route := range routes {
+		key := route.Method + " " + ginParam.ReplaceAllString(route.Path, "{}")
+		routed[key] = true
+
+		require.True(t, documented[key], "route %s %s is not documented", route.Method, route.Path)
+	}
+
+	for key := range documented {
+		require.True(t, routed[key], "%s is documented but not routed", key)
+	}
+}
+
+func TestDocsHandlers(t *testing.T) {
+	var tests = []struct {
+		name                string
+		path                string
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			"should serve the OpenAPI document",
+			OpenAPIPath,
+			"application/json",
+			`"openapi": "3.`,
+		},
+		{
+			"should serve the documentation page",
+			DocsPath,
+			"text/html; charset=utf-8",
+			`<redoc spec-url="openapi.json">`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// given
+			router := newDocsTestRouter(ctrl)
+			w := httptest.NewRecorder()
+
+			// when
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+			// then
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, test.expectedContentType, w.Header().Get("Content-Type"))
+			require.Contains(t, w.Body.String(), test.expectedBody)
+		})
+	}
+}
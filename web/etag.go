@@ -0,0 +1,19 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	m "github.com/jtyers/tmaas-model"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+)
+
+// setETag sets the ETag header of the response to that of threatModel, for
+// clients to send back in If-Match when updating it.
+func setETag(c *gin.Context, threatModel *m.ThreatModel) error {
+	etag, err := tm.ThreatModelETag(threatModel)
+	if err != nil {
+		return err
+	}
+
+	c.Header("ETag", etag)
+	return nil
+}
@@ -0,0 +1,28 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jtyers/tmaas-threat-model-api/gql"
+)
+
+const GraphQLPath = "/graphql"
+
+type GraphQLHandlers struct {
+	handler *gql.Handler
+}
+
+func NewGraphQLHandlers(handler *gql.Handler) *GraphQLHandlers {
+	return &GraphQLHandlers{handler: handler}
+}
+
+// @Summary Executes a GraphQL query or mutation against threat models, their data flow diagrams and threats. Errors are reported in the response body, with a code in their extensions.
+// @Accept json
+// @Produce json
+// @Param request body map[string]interface{} true "The query, and optionally operationName and variables"
+// @Success 200 {object} map[string]interface{} "The data and any errors"
+// @Failure 400 {string} string "The request body is not a GraphQL request"
+// @Security firebase
+// @Router /graphql [post]
+func (gh *GraphQLHandlers) GraphQLHandler(c *gin.Context) {
+	gh.handler.Serve(c, c.Writer, c.Request)
+}
@@ -1,19 +1,27 @@
 package web
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+	"github.com/jtyers/tmaas-threat-model-api/diff"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
 	"github.com/jtyers/tmaas-threat-model-api/service"
 )
 
 type ThreatModelHandlers struct {
 	threatModelService service.ThreatModelService
+	tagService         service.ThreatModelTagService
+	revisionService    service.ThreatModelRevisionService
+	accessChecker      service.ThreatModelAccessChecker
+	auditor            service.Auditor
 }
 
-func NewThreatModelHandlers(ts service.ThreatModelService) *ThreatModelHandlers {
-	return &ThreatModelHandlers{threatModelService: ts}
+func NewThreatModelHandlers(ts service.ThreatModelService, tagService service.ThreatModelTagService, revisionService service.ThreatModelRevisionService, accessChecker service.ThreatModelAccessChecker, auditor service.Auditor) *ThreatModelHandlers {
+	return &ThreatModelHandlers{threatModelService: ts, tagService: tagService, revisionService: revisionService, accessChecker: accessChecker, auditor: auditor}
 }
 
 // @Summary Retrieves threat models by threat model ID
@@ -21,6 +29,7 @@ func NewThreatModelHandlers(ts service.ThreatModelService) *ThreatModelHandlers
 // @Param id path string true "The threat model ID to retrieve data for"
 // @Security firebase
 // @Success 200 {object} m.ThreatModel "The threat model data"
+// @Header 200 {string} ETag "The threat model's entity tag, to send in If-Match when updating it"
 // @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
 // @Failure 404 {string} string "If the threat model ID does not exist or is not visible to this user."
 // @Router /api/v1/threatmodel/{id} [get]
@@ -29,21 +38,163 @@ func (th *ThreatModelHandlers) GetThreatModelHandler(c *gin.Context) {
 	threatModelID := m.NewThreatModelIDP(threatModelIDStr)
 
 	result, err := th.threatModelService.Get(c, threatModelID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := setETag(c, result); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.PureJSON(http.StatusOK, result)
+}
+
+// @Summary Compares two versions of a threat model, or two threat models, field by field
+// @Produce json
+// @Produce plain
+// @Param id path string true "The threat model ID to compare"
+// @Param from query string true "A revision number of the threat model, or the ID of another threat model, to compare from"
+// @Param to query string false "A revision number of the threat model, or the ID of another threat model, to compare to (default the threat model as it is now)"
+// @Param format query string false "Whether to return the changes as 'json' (the default), or as a 'unified' diff in plain text" Enums(json, unified)
+// @Security firebase
+// @Success 200 {object} tm.ThreatModelDiff "The fields added, removed and changed, with their old and new values"
+// @Failure 400 {string} string "If from is missing, or format is invalid."
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Failure 404 {string} string "If a threat model or revision does not exist or is not visible to this user."
+// @Router /api/v1/threatmodel/{id}/diff [get]
+func (th *ThreatModelHandlers) DiffThreatModelHandler(c *gin.Context) {
+	threatModelID := m.NewThreatModelIDP(c.Param("threatModelID"))
+
+	format := c.DefaultQuery("format", DiffFormatJSON)
+	if format != DiffFormatJSON && format != DiffFormatUnified {
+		c.Error(ErrInvalidDiffFormat)
+		return
+	}
+
+	fromSource := c.Query("from")
+	if fromSource == "" {
+		c.Error(ErrInvalidDiffSource)
+		return
+	}
+
+	from, fromLabel, err := th.getDiffSource(c, threatModelID, fromSource)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	to, toLabel, err := th.getDiffSource(c, threatModelID, c.Query("to"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if format == DiffFormatUnified {
+		result, err := diff.Unified(from, to, fromLabel, toLabel)
+		if err != nil {
+			c.Error(err)
+		} else {
+			c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(result))
+		}
+		return
+	}
+
+	changes, err := diff.Compare(from, to)
 	if err != nil {
 		c.Error(err)
 	} else {
-		c.PureJSON(http.StatusOK, result)
+		c.PureJSON(http.StatusOK, tm.ThreatModelDiff{From: fromLabel, To: toLabel, Changes: changes})
+	}
+}
+
+// @Summary Retrieves many threat models by threat model ID in one call
+// @Accept json
+// @Produce json
+// @Param data body tm.BatchGetRequest true "The IDs of the threat models to retrieve"
+// @Security firebase
+// @Success 200 {object} tm.BatchGetResult "The threat models found, in the order requested, and the IDs of those that do not exist or are not visible to this user"
+// @Failure 400 {string} string "If the request is badly formed, or names more than 100 threat models."
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Router /api/v1/threatmodel:batchGet [post]
+func (th *ThreatModelHandlers) BatchGetThreatModelsHandler(c *gin.Context) {
+	var request tm.BatchGetRequest
+
+	err := c.BindJSON(&request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if len(request.IDs) > service.MaxGetManyIDs {
+		c.Error(service.ErrTooManyIDs)
+		return
+	}
+
+	// threat models the caller cannot view are reported missing, as the
+	// single-item route does, and never retrieved
+	visible, err := th.accessChecker.FilterThreatModelAccess(c, request.IDs, tm.ProjectRoleViewer)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	hidden := map[m.ThreatModelID]bool{}
+	for _, id := range request.IDs {
+		hidden[id] = true
+	}
+	for _, id := range visible {
+		delete(hidden, id)
+	}
+
+	result, err := th.threatModelService.GetMany(c, visible)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	missing := map[m.ThreatModelID]bool{}
+	for _, id := range result.Missing {
+		missing[id] = true
 	}
+
+	result.Missing = []m.ThreatModelID{}
+	for _, id := range request.IDs {
+		if hidden[id] || missing[id] {
+			result.Missing = append(result.Missing, id)
+			delete(hidden, id)
+			delete(missing, id)
+		}
+	}
+
+	c.PureJSON(http.StatusOK, result)
 }
 
-// @Summary Retrieves all threat models visible to the user
+// @Summary Retrieves all threat models visible to the user, optionally filtered by tag
 // @Produce json
+// @Param tag query []string false "Only return threat models carrying these tags"
+// @Param tagMatch query string false "Whether threat models must carry 'any' (the default) or 'all' of the tags" Enums(any, all)
 // @Security firebase
 // @Success 200 {array} m.ThreatModel "The threat model data"
+// @Failure 400 {string} string "If a tag or tagMatch value is invalid."
 // @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
 // @Router /api/v1/threatmodel [get]
 func (th *ThreatModelHandlers) GetThreatModelsHandler(c *gin.Context) {
-	result, err := th.threatModelService.GetAll(c)
+	var result []*m.ThreatModel
+	var err error
+
+	if tags := c.QueryArray("tag"); len(tags) > 0 {
+		var matchAll bool
+		matchAll, err = parseTagMatch(c.DefaultQuery("tagMatch", TagMatchAny))
+		if err == nil {
+			result, err = th.tagService.GetAllWithTags(c, tags, matchAll)
+		}
+
+	} else {
+		result, err = service.ListThreatModels(c, th.threatModelService, th.accessChecker, th.auditor, nil)
+	}
+
 	if err != nil {
 		c.Error(err)
 	} else {
@@ -84,9 +235,12 @@ func (th *ThreatModelHandlers) PutThreatModelHandler(c *gin.Context) {
 // @Param id path string true "The threat model ID to update"
 // @Security firebase
 // @Param data body m.ThreatModelParams true "The parameters containing fields to update"
+// @Param If-Match header string false "The ETag the threat model was retrieved with; if given, the update is only made if the threat model has not changed since"
 // @Success 200 {object} m.ThreatModel "The (full) updated threat model data"
+// @Header 200 {string} ETag "The updated threat model's entity tag"
 // @Failure 400 {string} string "If the threat model data supplied was invalid or badly formed, or any field failed validation (such as a missing required field or a value out of range), or an invalid ID supplied for any fields that accept IDs"
 // @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API"
+// @Failure 412 {string} string "If If-Match was given and the threat model has changed since"
 // @Router /api/v1/threatmodel/{id} [patch]
 func (th *ThreatModelHandlers) PatchThreatModelHandler(c *gin.Context) {
 	threatModelIDStr := c.Param("threatModelID")
@@ -100,12 +254,22 @@ func (th *ThreatModelHandlers) PatchThreatModelHandler(c *gin.Context) {
 		return
 	}
 
-	updated, err := th.threatModelService.Update(c, threatModelID, t)
+	var ctx context.Context = c
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		ctx = dao.WithIfMatch(ctx, ifMatch)
+	}
+
+	updated, err := th.threatModelService.Update(ctx, threatModelID, t)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
+	if err := setETag(c, updated); err != nil {
+		c.Error(err)
+		return
+	}
+
 	c.PureJSON(http.StatusOK, updated)
 }
 
@@ -127,3 +291,69 @@ func (th *ThreatModelHandlers) DeleteThreatModelHandler(c *gin.Context) {
 		return
 	}
 }
+
+// @Summary Retrieves all tags in use, with the number of threat models carrying each
+// @Produce json
+// @Security firebase
+// @Success 200 {array} tm.TagCount "The tags, most-used first"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Router /api/v1/threatmodel/tags [get]
+func (th *ThreatModelHandlers) GetAllTagsHandler(c *gin.Context) {
+	var result []tm.TagCount
+	result, err := th.tagService.GetAllTags(c)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// @Summary Retrieves the tags on a threat model
+// @Produce json
+// @Param id path string true "The threat model ID to retrieve tags for"
+// @Security firebase
+// @Success 200 {array} string "The tags"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Failure 404 {string} string "If the threat model ID does not exist or is not visible to this user."
+// @Router /api/v1/threatmodel/{id}/tags [get]
+func (th *ThreatModelHandlers) GetTagsHandler(c *gin.Context) {
+	threatModelID := m.NewThreatModelIDP(c.Param("threatModelID"))
+
+	result, err := th.tagService.GetTags(c, threatModelID)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// @Summary Replaces the tags on a threat model
+// @Accept json
+// @Produce json
+// @Param id path string true "The threat model ID to set tags on"
+// @Param data body []string true "The new tags, such as bu:payments or criticality:high"
+// @Security firebase
+// @Success 200 {array} string "The tags as stored, after normalisation"
+// @Failure 400 {string} string "If any tag is invalid, or there are too many tags"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Failure 404 {string} string "If the threat model ID does not exist or is not visible to this user."
+// @Router /api/v1/threatmodel/{id}/tags [put]
+func (th *ThreatModelHandlers) PutTagsHandler(c *gin.Context) {
+	threatModelID := m.NewThreatModelIDP(c.Param("threatModelID"))
+
+	var tags []string
+
+	err := c.BindJSON(&tags)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := th.tagService.SetTags(c, threatModelID, tags)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
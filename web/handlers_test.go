@@ -2,6 +2,7 @@ package web
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,30 +10,67 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/jtyers/tmaas-api-util/combo"
 	"github.com/jtyers/tmaas-api-util/errors"
 	cmocks "github.com/jtyers/tmaas-cors-config/mocks"
 	m "github.com/jtyers/tmaas-model"
 	"github.com/jtyers/tmaas-model/structs"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/health"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/ratelimit"
 	"github.com/jtyers/tmaas-threat-model-api/service"
 )
 
 type msi map[string]interface{}
 
+// allowAllAccessChecker treats every threat model as outside any project.
+type allowAllAccessChecker struct{}
+
+func (allowAllAccessChecker) CheckThreatModelAccess(ctx context.Context, id m.ThreatModelID, role tm.ProjectRole) error {
+	return nil
+}
+
+func (allowAllAccessChecker) FilterThreatModelAccess(ctx context.Context, ids []m.ThreatModelID, role tm.ProjectRole) ([]m.ThreatModelID, error) {
+	return ids, nil
+}
+
+// noopAuditor discards audit events.
+type noopAuditor struct{}
+
+func (noopAuditor) Record(ctx context.Context, record tm.AuditRecord) {}
+
 func createServer(comboFactory combo.ComboMiddlewareFactory, ts service.ThreatModelService) (*httptest.Server, func()) {
+	return createServerWithTags(comboFactory, ts, nil)
+}
+
+func createServerWithTags(comboFactory combo.ComboMiddlewareFactory, ts service.ThreatModelService, tagService service.ThreatModelTagService) (*httptest.Server, func()) {
+	return createServerWithAccessChecker(comboFactory, ts, tagService, allowAllAccessChecker{})
+}
+
+func createServerWithAccessChecker(comboFactory combo.ComboMiddlewareFactory, ts service.ThreatModelService, tagService service.ThreatModelTagService, accessChecker service.ThreatModelAccessChecker) (*httptest.Server, func()) {
+	return createServerWithRevisions(comboFactory, ts, tagService, nil, accessChecker)
+}
+
+func createServerWithRevisions(comboFactory combo.ComboMiddlewareFactory, ts service.ThreatModelService, tagService service.ThreatModelTagService, revisionService service.ThreatModelRevisionService, accessChecker service.ThreatModelAccessChecker) (*httptest.Server, func()) {
 	errors := errors.NewDefaultErrorsMiddlewareFactory() // use real middleware to check error handling
 
 	// use dummy CORS middleware
 	corsMiddlware := cmocks.NewMockCorsMiddleware()
 
 	// generate a test server so we can capture and inspect the request
-	handlers := NewThreatModelHandlers(ts)
-	testServer := httptest.NewServer(NewRouter(handlers, comboFactory, errors, corsMiddlware))
+	handlers := NewThreatModelHandlers(ts, tagService, revisionService, accessChecker, noopAuditor{})
+	commentHandlers := NewCommentHandlers(nil)
+	identityExtractor := auth.NewStaticIdentityExtractor(nil)
+	testServer := httptest.NewServer(NewRouter(handlers, commentHandlers, NewSearchHandlers(nil), NewProjectHandlers(nil), NewAuditHandlers(nil), NewHealthHandlers(health.NewChecker(time.Second)), NewGraphQLHandlers(nil), comboFactory, errors, corsMiddlware, identityExtractor, allowAllAccessChecker{}, noopAuditor{}, NewRateLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{}), metrics.NewMetrics(), trace.NewNoopTracerProvider()))
 
 	gin.SetMode(gin.TestMode)
 	closer := func() { testServer.Close() }
@@ -145,6 +183,113 @@ func TestGetThreatModelHandler(t *testing.T) {
 				require.Nil(t, err)
 
 				require.Equal(t, &got, test.expectedBody)
+
+				etag, err := tm.ThreatModelETag(test.expectedBody)
+				require.Nil(t, err)
+				require.Equal(t, etag, response.Header.Get("ETag"))
+			}
+		})
+	}
+}
+
+// hidingAccessChecker hides the given threat models from the caller.
+type hidingAccessChecker map[m.ThreatModelID]bool
+
+func (h hidingAccessChecker) CheckThreatModelAccess(ctx context.Context, id m.ThreatModelID, role tm.ProjectRole) error {
+	if h[id] {
+		return service.ErrNoSuchThreatModel
+	}
+	return nil
+}
+
+func (h hidingAccessChecker) FilterThreatModelAccess(ctx context.Context, ids []m.ThreatModelID, role tm.ProjectRole) ([]m.ThreatModelID, error) {
+	result := []m.ThreatModelID{}
+	for _, id := range ids {
+		if !h[id] {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+func TestBatchGetThreatModelsHandler(t *testing.T) {
+	threatModel1 := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-1"), Title: "my-first-threatModel"}
+	threatModel2 := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-2"), Title: "my-second-threatModel"}
+	hiddenID := m.NewThreatModelIDP("tm-hidden")
+	missingID := m.NewThreatModelIDP("tm-404")
+
+	tooManyIDs := make([]m.ThreatModelID, service.MaxGetManyIDs+1)
+
+	var tests = []struct {
+		name             string
+		path             string
+		ids              []m.ThreatModelID
+		expectedGetMany  []m.ThreatModelID // GetMany not expected if nil
+		getManyResult    *tm.BatchGetResult
+		expectedResponse int
+		expectedBody     *tm.BatchGetResult // not checked if nil
+	}{
+		{
+			"should return found threat models and report missing and hidden ones",
+			UrlPrefix + ":batchGet",
+			[]m.ThreatModelID{threatModel2.ThreatModelID, hiddenID, missingID, threatModel1.ThreatModelID},
+			[]m.ThreatModelID{threatModel2.ThreatModelID, missingID, threatModel1.ThreatModelID},
+			&tm.BatchGetResult{ThreatModels: []*m.ThreatModel{threatModel2, threatModel1}, Missing: []m.ThreatModelID{missingID}},
+			http.StatusOK,
+			&tm.BatchGetResult{ThreatModels: []*m.ThreatModel{threatModel2, threatModel1}, Missing: []m.ThreatModelID{hiddenID, missingID}},
+		},
+		{
+			"should reject too many IDs",
+			UrlPrefix + ":batchGet",
+			tooManyIDs,
+			nil,
+			nil,
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"should return 404 for unknown custom methods",
+			UrlPrefix + ":batchDelete",
+			[]m.ThreatModelID{threatModel1.ThreatModelID},
+			nil,
+			nil,
+			http.StatusNotFound,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// given
+			svc := service.NewMockThreatModelService(ctrl)
+			accessChecker := service.NewMockThreatModelAccessChecker(ctrl)
+			if test.expectedGetMany != nil {
+				// access is checked once for the whole batch
+				accessChecker.EXPECT().FilterThreatModelAccess(gomock.Any(), test.ids, tm.ProjectRoleViewer).
+					DoAndReturn(hidingAccessChecker{hiddenID: true}.FilterThreatModelAccess)
+				svc.EXPECT().GetMany(gomock.Any(), test.expectedGetMany).Return(test.getManyResult, nil)
+			}
+
+			token := &m.AuthenticationInfo{UserID: "u-12345678", Roles: []m.Role{m.RoleUser}}
+			comboFactory := combo.NewMockComboMiddlewareFactoryWithTokensAndPermissions(ctrl, token, combo.ServiceAccountPermissionsJson(`{}`))
+			server, closeServer := createServerWithAccessChecker(comboFactory, svc, nil, accessChecker)
+			defer closeServer()
+
+			// when
+			response, err := http.Post(server.URL+test.path, "application/json",
+				strings.NewReader(toJsonString(tm.BatchGetRequest{IDs: test.ids})))
+
+			// then
+			require.Nil(t, err)
+			require.Equal(t, test.expectedResponse, response.StatusCode)
+
+			if test.expectedBody != nil {
+				got := tm.BatchGetResult{}
+				require.Nil(t, json.NewDecoder(response.Body).Decode(&got))
+				require.Equal(t, test.expectedBody, &got)
 			}
 		})
 	}
@@ -240,6 +385,116 @@ func TestGetThreatModelsHandler(t *testing.T) {
 	}
 }
 
+func TestGetThreatModelsHandlerHidesThoseTheCallerCannotView(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// given
+	visible := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-1"), Title: "visible"}
+	hidden := &m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("tm-2"), Title: "in a project the caller has no role on"}
+
+	mockThreatModelService := service.NewMockThreatModelService(ctrl)
+	mockThreatModelService.EXPECT().GetAll(gomock.Any()).Return([]*m.ThreatModel{visible, hidden}, nil)
+
+	accessChecker := service.NewMockThreatModelAccessChecker(ctrl)
+	accessChecker.EXPECT().FilterThreatModelAccess(gomock.Any(), []m.ThreatModelID{visible.ThreatModelID, hidden.ThreatModelID}, tm.ProjectRoleViewer).
+		Return([]m.ThreatModelID{visible.ThreatModelID}, nil)
+
+	comboFactory := combo.NewMockComboMiddlewareFactoryWithTokensAndPermissions(ctrl,
+		&m.AuthenticationInfo{UserID: m.UserID("u-12345678"), Roles: []m.Role{m.RoleUser}},
+		combo.ServiceAccountPermissionsJson(`{}`))
+	server, closeServer := createServerWithAccessChecker(comboFactory, mockThreatModelService, nil, accessChecker)
+	defer closeServer()
+
+	// when
+	response, err := http.Get(server.URL + UrlPrefix)
+
+	// then
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	got := []*m.ThreatModel{}
+	require.Nil(t, json.Unmarshal(readToBytes(response.Body), &got))
+	require.Equal(t, []*m.ThreatModel{visible}, got)
+}
+
+func TestGetThreatModelsHandlerWithTags(t *testing.T) {
+	serviceAccountPermissionsJson := combo.ServiceAccountPermissionsJson(`{}`)
+	ai := &m.AuthenticationInfo{UserID: m.UserID("u-12345678"), Roles: []m.Role{m.RoleUser}}
+
+	threatModel1 := m.ThreatModel{
+		ThreatModelID: m.NewThreatModelIDP("1234-1234-1234-1234"),
+		Title:         "my-first-threatModel",
+	}
+
+	var tests = []struct {
+		name             string
+		query            string
+		expectedTags     []string
+		expectedMatchAll bool
+		expectedResponse int
+	}{
+		{
+			"should filter by any tag by default",
+			"?tag=pci&tag=bu:payments",
+			[]string{"pci", "bu:payments"},
+			false,
+			http.StatusOK,
+		},
+		{
+			"should filter by all tags",
+			"?tag=pci&tag=bu:payments&tagMatch=all",
+			[]string{"pci", "bu:payments"},
+			true,
+			http.StatusOK,
+		},
+		{
+			"should reject invalid tagMatch",
+			"?tag=pci&tagMatch=some",
+			nil,
+			false,
+			http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// given
+			mockThreatModelService := service.NewMockThreatModelService(ctrl)
+			mockTagService := service.NewMockThreatModelTagService(ctrl)
+
+			if test.expectedTags != nil {
+				mockTagService.EXPECT().GetAllWithTags(gomock.AssignableToTypeOf(&gin.Context{}), test.expectedTags,
+					test.expectedMatchAll).Return([]*m.ThreatModel{&threatModel1}, nil)
+			}
+
+			comboFactory := combo.NewMockComboMiddlewareFactoryWithTokensAndPermissions(ctrl, ai,
+				serviceAccountPermissionsJson)
+			server, closeServer := createServerWithTags(comboFactory, mockThreatModelService, mockTagService)
+			defer closeServer()
+
+			// when
+			request, _ := http.NewRequest(http.MethodGet, server.URL+UrlPrefix+test.query, nil)
+			response, err := http.DefaultClient.Do(request)
+
+			// then
+			require.Nil(t, err)
+			require.Equal(t, test.expectedResponse, response.StatusCode)
+
+			if test.expectedResponse == http.StatusOK {
+				got := []*m.ThreatModel{}
+				err = json.Unmarshal(readToBytes(response.Body), &got)
+				require.Nil(t, err)
+
+				require.Equal(t, []*m.ThreatModel{&threatModel1}, got)
+			}
+		})
+	}
+}
+
 func TestCreateThreatModelHandler(t *testing.T) {
 	serviceAccountPermissionsJson := combo.ServiceAccountPermissionsJson(`{}`)
 
@@ -336,6 +591,7 @@ func TestPatchThreatModelHandler(t *testing.T) {
 		ai                 *m.AuthenticationInfo
 		inputThreatModelID m.ThreatModelID
 		input              m.ThreatModelParams
+		ifMatch            string
 		dsReturn           *m.ThreatModel
 		dsReturnError      error
 		expectedResponse   int
@@ -346,16 +602,29 @@ func TestPatchThreatModelHandler(t *testing.T) {
 			&m.AuthenticationInfo{UserID: "u-1234", Roles: []m.Role{m.RoleUser}},
 			m.NewThreatModelIDP("d-1234"),
 			m.ThreatModelParams{Title: m.String("foo")},
+			"",
 			&m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("d-1234"), Title: "foo"},
 			nil,
 			http.StatusOK,
 			&m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("d-1234"), Title: "foo"},
 		},
+		{
+			"should return 412 if the threat model has changed since the ETag given",
+			&m.AuthenticationInfo{UserID: "u-1234", Roles: []m.Role{m.RoleUser}},
+			m.NewThreatModelIDP("d-1234"),
+			m.ThreatModelParams{Title: m.String("foo")},
+			`"stale"`,
+			nil,
+			service.ErrThreatModelModified,
+			http.StatusPreconditionFailed,
+			nil,
+		},
 		{
 			"should return 401 if no JWT supplied",
 			nil,
 			m.NewThreatModelIDP("d-1234"),
 			m.ThreatModelParams{Title: m.String("foo")},
+			"",
 			&m.ThreatModel{ThreatModelID: m.NewThreatModelIDP("d-1234"), Title: "foo"},
 			nil,
 			http.StatusUnauthorized,
@@ -386,6 +655,9 @@ func TestPatchThreatModelHandler(t *testing.T) {
 			// when
 			request, _ := http.NewRequest(http.MethodPatch,
 				server.URL+UrlPrefix+"/"+test.inputThreatModelID.String(), bodyReader)
+			if test.ifMatch != "" {
+				request.Header.Set("If-Match", test.ifMatch)
+			}
 			response, err := http.DefaultClient.Do(request)
 
 			// then
@@ -399,6 +671,10 @@ func TestPatchThreatModelHandler(t *testing.T) {
 				require.Nil(t, err)
 
 				require.Equal(t, &got, test.expectedBody)
+
+				etag, err := tm.ThreatModelETag(test.expectedBody)
+				require.Nil(t, err)
+				require.Equal(t, etag, response.Header.Get("ETag"))
 			}
 		})
 	}
@@ -458,3 +734,166 @@ func TestDeleteThreatModelHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestDiffThreatModelHandler(t *testing.T) {
+	threatModelID := m.NewThreatModelIDP("tm-1")
+	otherID := m.NewThreatModelIDP("tm-2")
+	hiddenID := m.NewThreatModelIDP("tm-hidden")
+
+	current := map[m.ThreatModelID]*m.ThreatModel{
+		threatModelID: {ThreatModelID: threatModelID, Title: "Billing"},
+		otherID:       {ThreatModelID: otherID, Title: "Payments gateway"},
+		hiddenID:      {ThreatModelID: hiddenID, Title: "Secret"},
+	}
+	revisions := map[int64]*m.ThreatModel{
+		1: {ThreatModelID: threatModelID, Title: "Payments"},
+		2: {ThreatModelID: threatModelID, Title: "Billing"},
+	}
+
+	var tests = []struct {
+		name             string
+		query            string
+		expectedResponse int
+		expectedFrom     string // not checked if empty
+		expectedTo       string
+		expectedChanges  [][2]any // old and new values; not checked if nil
+		expectedText     string   // not checked if empty
+	}{
+		{
+			"should compare a revision with the current version",
+			"?from=1",
+			http.StatusOK,
+			"tm-1@1",
+			"tm-1",
+			[][2]any{{"Payments", "Billing"}},
+			"",
+		},
+		{
+			"should compare two revisions",
+			"?from=2&to=1",
+			http.StatusOK,
+			"tm-1@2",
+			"tm-1@1",
+			[][2]any{{"Billing", "Payments"}},
+			"",
+		},
+		{
+			"should find no changes between equal versions",
+			"?from=2",
+			http.StatusOK,
+			"tm-1@2",
+			"tm-1",
+			[][2]any{},
+			"",
+		},
+		{
+			"should compare with another threat model",
+			"?from=tm-2",
+			http.StatusOK,
+			"tm-2",
+			"tm-1",
+			nil,
+			"",
+		},
+		{
+			"should render a unified diff",
+			"?from=1&format=unified",
+			http.StatusOK,
+			"",
+			"",
+			nil,
+			"--- tm-1@1\n+++ tm-1\n@@ ",
+		},
+		{
+			"should not compare with hidden threat models",
+			"?from=tm-hidden",
+			http.StatusNotFound,
+			"",
+			"",
+			nil,
+			"",
+		},
+		{
+			"should return 404 for missing revisions",
+			"?from=1&to=3",
+			http.StatusNotFound,
+			"",
+			"",
+			nil,
+			"",
+		},
+		{
+			"should require from",
+			"?to=1",
+			http.StatusBadRequest,
+			"",
+			"",
+			nil,
+			"",
+		},
+		{
+			"should reject invalid formats",
+			"?from=1&format=xml",
+			http.StatusBadRequest,
+			"",
+			"",
+			nil,
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// given
+			svc := service.NewMockThreatModelService(ctrl)
+			svc.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id m.ThreatModelID) (*m.ThreatModel, error) {
+				return current[id], nil
+			}).AnyTimes()
+
+			revisionService := service.NewMockThreatModelRevisionService(ctrl)
+			revisionService.EXPECT().GetRevision(gomock.Any(), threatModelID, gomock.Any()).DoAndReturn(func(ctx context.Context, id m.ThreatModelID, revision int64) (*m.ThreatModel, error) {
+				if result, ok := revisions[revision]; ok {
+					return result, nil
+				}
+				return nil, service.ErrNoSuchRevision
+			}).AnyTimes()
+
+			token := &m.AuthenticationInfo{UserID: "u-12345678", Roles: []m.Role{m.RoleUser}}
+			comboFactory := combo.NewMockComboMiddlewareFactoryWithTokensAndPermissions(ctrl, token, combo.ServiceAccountPermissionsJson(`{}`))
+			server, closeServer := createServerWithRevisions(comboFactory, svc, nil, revisionService, hidingAccessChecker{hiddenID: true})
+			defer closeServer()
+
+			// when
+			response, err := http.Get(server.URL + UrlPrefix + "/" + threatModelID.String() + "/diff" + test.query)
+
+			// then
+			require.Nil(t, err)
+			require.Equal(t, test.expectedResponse, response.StatusCode)
+
+			if test.expectedText != "" {
+				require.Equal(t, "text/plain; charset=utf-8", response.Header.Get("Content-Type"))
+
+				body := readToString(response.Body)
+				require.True(t, strings.HasPrefix(body, test.expectedText), body)
+			}
+
+			if test.expectedFrom != "" {
+				got := tm.ThreatModelDiff{}
+				require.Nil(t, json.NewDecoder(response.Body).Decode(&got))
+				require.Equal(t, test.expectedFrom, got.From)
+				require.Equal(t, test.expectedTo, got.To)
+
+				if test.expectedChanges != nil {
+					changes := [][2]any{}
+					for _, change := range got.Changes {
+						changes = append(changes, [2]any{change.Old, change.New})
+					}
+					require.Equal(t, test.expectedChanges, changes)
+				}
+			}
+		})
+	}
+}
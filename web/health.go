@@ -0,0 +1,45 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jtyers/tmaas-threat-model-api/health"
+)
+
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+type HealthHandlers struct {
+	readiness *health.Checker
+}
+
+func NewHealthHandlers(readiness *health.Checker) *HealthHandlers {
+	return &HealthHandlers{readiness: readiness}
+}
+
+// @Summary Reports that the process is up. It does not check dependencies.
+// @Produce json
+// @Success 200 {object} map[string]string "Always {"status": "ok"}"
+// @Router /healthz [get]
+func (hh *HealthHandlers) LivenessHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// @Summary Reports whether the API can serve requests, by checking Datastore and the DFD API.
+// @Produce json
+// @Success 200 {object} health.Report "Every check passed"
+// @Failure 503 {object} health.Report "At least one check failed or timed out"
+// @Router /readyz [get]
+func (hh *HealthHandlers) ReadinessHandler(c *gin.Context) {
+	report := hh.readiness.Run(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, report)
+}
@@ -0,0 +1,106 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/jtyers/tmaas-api-util/combo"
+	apierrors "github.com/jtyers/tmaas-api-util/errors"
+	cmocks "github.com/jtyers/tmaas-cors-config/mocks"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/health"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	"github.com/jtyers/tmaas-threat-model-api/ratelimit"
+)
+
+func TestHealthHandlers(t *testing.T) {
+	ok := health.CheckFunc(func(ctx context.Context) error { return nil })
+	failing := health.CheckFunc(func(ctx context.Context) error { return errors.New("connection refused") })
+
+	var tests = []struct {
+		name             string
+		path             string
+		checks           []health.NamedCheck
+		expectedResponse int
+		expectedReport   *health.Report // not checked if nil
+	}{
+		{
+			"liveness should not need a token",
+			LivenessPath,
+			[]health.NamedCheck{{"datastore", failing}},
+			http.StatusOK,
+			nil,
+		},
+		{
+			"readiness should succeed if every check passes",
+			ReadinessPath,
+			[]health.NamedCheck{{"datastore", ok}},
+			http.StatusOK,
+			&health.Report{
+				Status: health.StatusOK,
+				Checks: map[string]*health.CheckResult{"datastore": {Status: health.StatusOK}},
+			},
+		},
+		{
+			"readiness should fail with a breakdown if a check fails",
+			ReadinessPath,
+			[]health.NamedCheck{{"datastore", ok}, {"dataFlowDiagramApi", failing}},
+			http.StatusServiceUnavailable,
+			&health.Report{
+				Status: health.StatusUnavailable,
+				Checks: map[string]*health.CheckResult{
+					"datastore":          {Status: health.StatusOK},
+					"dataFlowDiagramApi": {Status: health.StatusUnavailable, Error: "connection refused"},
+				},
+			},
+		},
+		{
+			"other routes should still need a token",
+			UrlPrefix + "/tm-1234",
+			nil,
+			http.StatusUnauthorized,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// given
+			comboFactory := combo.NewMockComboMiddlewareFactoryWithTokensAndPermissions(ctrl, nil, combo.ServiceAccountPermissionsJson(`{}`))
+			healthHandlers := NewHealthHandlers(health.NewChecker(time.Second, test.checks...))
+
+			router := NewRouter(NewThreatModelHandlers(nil, nil, nil, nil, nil), NewCommentHandlers(nil), NewSearchHandlers(nil), NewProjectHandlers(nil), NewAuditHandlers(nil), healthHandlers, NewGraphQLHandlers(nil), comboFactory, apierrors.NewDefaultErrorsMiddlewareFactory(), cmocks.NewMockCorsMiddleware(), auth.NewStaticIdentityExtractor(nil), allowAllAccessChecker{}, noopAuditor{}, NewRateLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{}), metrics.NewMetrics(), trace.NewNoopTracerProvider())
+
+			w := httptest.NewRecorder()
+
+			// when
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+			// then
+			require.Equal(t, test.expectedResponse, w.Code)
+
+			if test.expectedReport != nil {
+				got := health.Report{}
+				require.Nil(t, json.Unmarshal(w.Body.Bytes(), &got))
+
+				// durations vary, so are not compared
+				for _, result := range got.Checks {
+					result.DurationMs = 0
+				}
+				require.Equal(t, test.expectedReport, &got)
+			}
+		})
+	}
+}
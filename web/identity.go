@@ -0,0 +1,53 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+// IdentityMiddleware places the caller's identity, if any, into the request
+// context so that services can retrieve it via auth.IdentityFromContext.
+// Requests without an identity are passed through untouched; it is up to
+// the permission middleware to reject those where necessary. Requests
+// naming their tenant in a way that is not permitted are rejected with 400
+// Bad Request, as they run before the errors middleware.
+func IdentityMiddleware(extractor auth.IdentityExtractor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, err := extractor.Extract(c)
+		switch err {
+		case nil:
+			c.Request = c.Request.WithContext(auth.WithIdentity(c.Request.Context(), identity))
+
+		case auth.ErrTenantHeader, auth.ErrInvalidTenant:
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"code": "INVALID_TENANT", "message": err.Error()})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireAdmin rejects requests from anyone but administrators, that is
+// service accounts and users with the admin claim. It must run after
+// IdentityMiddleware.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, err := auth.IdentityFromContext(c)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		if !identity.IsAdmin() {
+			c.Error(service.ErrAdminRequired)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+)
+
+const (
+	MetricsPath = "/metrics"
+)
+
+// MetricsMiddleware counts requests and records their latency by method,
+// route and status code. It should run first, so that it covers the time
+// spent in every other middleware.
+func MetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = metrics.UnmatchedRoute
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		m.HTTPRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.HTTPRequestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// NewMetricsHandler serves the metrics in the Prometheus exposition format
+// on MetricsPath. It is served on a port of its own rather than by
+// NewRouter: the metrics name tenants, and scrapes should not be
+// authenticated, audited or rate limited.
+func NewMetricsHandler(m *metrics.Metrics) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, m.Handler())
+	return mux
+}
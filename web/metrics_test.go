@@ -0,0 +1,38 @@
+package web
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	// given
+	m := metrics.NewMetrics()
+
+	r := gin.New()
+	r.Use(MetricsMiddleware(m))
+	r.GET(UrlPrefix+"/:threatModelID", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+	// when
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, UrlPrefix+"/tm-1", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, UrlPrefix+"/tm-2", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/no/such/path", nil))
+
+	w := httptest.NewRecorder()
+	NewMetricsHandler(m).ServeHTTP(w, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+
+	// then
+	require.Equal(t, 2.0, testutil.ToFloat64(m.HTTPRequests.WithLabelValues("GET", UrlPrefix+"/:threatModelID", "404")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.HTTPRequests.WithLabelValues("GET", metrics.UnmatchedRoute, "404")))
+
+	body, err := io.ReadAll(w.Body)
+	require.Nil(t, err)
+	require.Contains(t, string(body), `threatmodel_http_requests_total{method="GET",route="/api/v1/threatmodel/:threatModelID",status="404"} 2`)
+}
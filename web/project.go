@@ -0,0 +1,24 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	m "github.com/jtyers/tmaas-model"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+// RequireThreatModelRole applies the permissions a threat model inherits
+// from its project, if it is in one, to routes taking a :threatModelID.
+func RequireThreatModelRole(checker service.ThreatModelAccessChecker, role tm.ProjectRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		threatModelID := m.NewThreatModelIDP(c.Param("threatModelID"))
+
+		if err := checker.CheckThreatModelAccess(c, threatModelID, role); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,237 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	m "github.com/jtyers/tmaas-model"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+var (
+	ProjectUrlPrefix = "/api/v1/projects"
+)
+
+type ProjectHandlers struct {
+	projectService service.ProjectService
+}
+
+func NewProjectHandlers(ps service.ProjectService) *ProjectHandlers {
+	return &ProjectHandlers{projectService: ps}
+}
+
+// projectMemberParams is the body used to give a user a role on a project.
+type projectMemberParams struct {
+	Role tm.ProjectRole `json:"role"`
+}
+
+// @Summary Retrieves all projects the user has a role on, directly or via a parent project
+// @Produce json
+// @Security firebase
+// @Success 200 {array} tm.Project "The projects"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Router /api/v1/projects [get]
+func (ph *ProjectHandlers) GetProjectsHandler(c *gin.Context) {
+	result, err := ph.projectService.GetAll(c)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// @Summary Retrieves a project by project ID
+// @Produce json
+// @Param id path string true "The project ID to retrieve"
+// @Security firebase
+// @Success 200 {object} tm.Project "The project"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Failure 404 {string} string "If the project ID does not exist or is not visible to this user."
+// @Router /api/v1/projects/{id} [get]
+func (ph *ProjectHandlers) GetProjectHandler(c *gin.Context) {
+	result, err := ph.projectService.Get(c, tm.ProjectID(c.Param("projectID")))
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// @Summary Create a new project, optionally as a sub-project of another. The caller becomes its owner.
+// @Accept json
+// @Produce json
+// @Param data body tm.ProjectParams true "Parameters for the project to create"
+// @Security firebase
+// @Success 200 {object} tm.Project "The created project"
+// @Failure 400 {string} string "If the project data supplied was invalid or badly formed, or the name is empty"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API"
+// @Failure 403 {string} string "If the caller may not edit the parent project"
+// @Failure 404 {string} string "If the parent project does not exist or is not visible to this user."
+// @Router /api/v1/projects [post]
+func (ph *ProjectHandlers) PostProjectHandler(c *gin.Context) {
+	var params tm.ProjectParams
+
+	err := c.BindJSON(&params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := ph.projectService.Create(c, params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Update a project, including moving it beneath another project
+// @Accept json
+// @Produce json
+// @Param id path string true "The project ID to update"
+// @Param data body tm.ProjectParams true "The fields to update; a parentProjectId of \"\" moves the project to the top level"
+// @Security firebase
+// @Success 200 {object} tm.Project "The (full) updated project"
+// @Failure 400 {string} string "If the project data supplied was invalid or badly formed, or the move would make the project its own ancestor"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API"
+// @Failure 403 {string} string "If the caller may not edit the project or the new parent project"
+// @Failure 404 {string} string "If the project ID does not exist or is not visible to this user."
+// @Router /api/v1/projects/{id} [patch]
+func (ph *ProjectHandlers) PatchProjectHandler(c *gin.Context) {
+	var params tm.ProjectParams
+
+	err := c.BindJSON(&params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := ph.projectService.Update(c, tm.ProjectID(c.Param("projectID")), params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Delete a project by ID. The project must have no sub-projects or threat models.
+// @Produce json
+// @Param id path string true "Project ID"
+// @Security firebase
+// @Success 200 {string} string "Returned when the delete succeeds."
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Failure 403 {string} string "If the caller is not an owner of the project."
+// @Failure 404 {string} string "If the project ID does not exist or is not visible to this user."
+// @Failure 409 {string} string "If the project still has sub-projects or threat models."
+// @Router /api/v1/projects/{id} [delete]
+func (ph *ProjectHandlers) DeleteProjectHandler(c *gin.Context) {
+	err := ph.projectService.Delete(c, tm.ProjectID(c.Param("projectID")))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+}
+
+// @Summary Give a user a role on a project and its sub-projects, replacing any role they already have
+// @Accept json
+// @Produce json
+// @Param id path string true "The project ID"
+// @Param userId path string true "The user ID"
+// @Param data body projectMemberParams true "The role, one of viewer, editor or owner"
+// @Security firebase
+// @Success 200 {object} tm.Project "The updated project"
+// @Failure 400 {string} string "If the role is invalid, or the change would leave a top-level project without an owner"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Failure 403 {string} string "If the caller is not an owner of the project."
+// @Failure 404 {string} string "If the project ID does not exist or is not visible to this user."
+// @Router /api/v1/projects/{id}/members/{userId} [put]
+func (ph *ProjectHandlers) PutMemberHandler(c *gin.Context) {
+	var params projectMemberParams
+
+	err := c.BindJSON(&params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	result, err := ph.projectService.SetMember(c, tm.ProjectID(c.Param("projectID")), m.UserID(c.Param("userID")), params.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Remove a user's role on a project
+// @Produce json
+// @Param id path string true "The project ID"
+// @Param userId path string true "The user ID"
+// @Security firebase
+// @Success 200 {object} tm.Project "The updated project"
+// @Failure 400 {string} string "If the change would leave a top-level project without an owner"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Failure 403 {string} string "If the caller is not an owner of the project."
+// @Failure 404 {string} string "If the project ID does not exist or is not visible to this user."
+// @Router /api/v1/projects/{id}/members/{userId} [delete]
+func (ph *ProjectHandlers) DeleteMemberHandler(c *gin.Context) {
+	result, err := ph.projectService.RemoveMember(c, tm.ProjectID(c.Param("projectID")), m.UserID(c.Param("userID")))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Retrieves the threat models directly within a project
+// @Produce json
+// @Param id path string true "The project ID"
+// @Security firebase
+// @Success 200 {array} m.ThreatModel "The threat models"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Failure 404 {string} string "If the project ID does not exist or is not visible to this user."
+// @Router /api/v1/projects/{id}/threatmodels [get]
+func (ph *ProjectHandlers) GetProjectThreatModelsHandler(c *gin.Context) {
+	result, err := ph.projectService.GetThreatModels(c, tm.ProjectID(c.Param("projectID")))
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// @Summary Move a threat model into a project, or out of all projects
+// @Accept json
+// @Produce json
+// @Param id path string true "The threat model ID"
+// @Param data body tm.ThreatModelProject true "The project to move the threat model into, or a null projectId to remove it from its project"
+// @Security firebase
+// @Success 200 {object} tm.ThreatModelProject "The threat model's new project"
+// @Failure 400 {string} string "If the data supplied was badly formed"
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Failure 403 {string} string "If the caller may not edit the current or new project."
+// @Failure 404 {string} string "If the threat model or project does not exist or is not visible to this user."
+// @Router /api/v1/threatmodel/{id}/project [put]
+func (ph *ProjectHandlers) PutThreatModelProjectHandler(c *gin.Context) {
+	threatModelID := m.NewThreatModelIDP(c.Param("threatModelID"))
+
+	var params tm.ThreatModelProject
+
+	err := c.BindJSON(&params)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	err = ph.projectService.SetThreatModelProject(c, threatModelID, params.ProjectID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, params)
+}
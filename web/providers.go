@@ -6,6 +6,8 @@ import (
 	"github.com/jtyers/tmaas-api-util/combo"
 	"github.com/jtyers/tmaas-api-util/errors"
 	corsconfig "github.com/jtyers/tmaas-cors-config"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/ratelimit"
 )
 
 var ThreatModelWebProviderSet = wire.NewSet(
@@ -14,6 +16,16 @@ var ThreatModelWebProviderSet = wire.NewSet(
 	combo.ComboMiddlewareFactoryProviderSet,
 	errors.ErrorsMiddlewareFactoryProviderSet,
 
+	auth.AuthProviderSet,
+	ratelimit.RateLimitProviderSet,
+
 	NewRouter,
 	NewThreatModelHandlers,
+	NewCommentHandlers,
+	NewSearchHandlers,
+	NewProjectHandlers,
+	NewAuditHandlers,
+	NewHealthHandlers,
+	NewGraphQLHandlers,
+	NewRateLimiter,
 )
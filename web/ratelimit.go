@@ -0,0 +1,83 @@
+package web
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/ratelimit"
+)
+
+// RateLimiter limits the rate of requests of each caller, identified by
+// their user ID or service account name (or for anonymous requests, their
+// IP address).
+type RateLimiter struct {
+	store  ratelimit.Store
+	config ratelimit.Config
+}
+
+func NewRateLimiter(store ratelimit.Store, config ratelimit.Config) *RateLimiter {
+	return &RateLimiter{store, config}
+}
+
+// Middleware rejects requests over the caller's route or default limit
+// with 429 Too Many Requests, and a Retry-After header giving the seconds
+// until the caller may try again. It must run after IdentityMiddleware, and
+// before AuditMiddleware so that rejected requests cost no audit writes. If
+// the store fails, requests are allowed rather than taking the API down
+// with it.
+func (rl *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := callerKey(c)
+
+		route := c.Request.Method + " " + c.FullPath()
+
+		limit, ok := rl.config.Routes[route]
+		if ok {
+			key += ":" + route
+		} else {
+			limit = rl.config.Default
+		}
+
+		rl.take(c, key, limit)
+	}
+}
+
+// Permission rejects requests over the caller's limit on routes requiring
+// permission p, if there is one, as Middleware does. It goes alongside the
+// permission middleware of each route.
+func (rl *RateLimiter) Permission(p m.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, ok := rl.config.Permissions[string(p)]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		rl.take(c, callerKey(c)+":permission:"+string(p), limit)
+	}
+}
+
+// take takes a token from the bucket with the given key, aborting the
+// request if there is none.
+func (rl *RateLimiter) take(c *gin.Context, key string, limit ratelimit.Limit) {
+	if allowed, retryAfter := ratelimit.Allow(c, rl.store, key, limit); !allowed {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"code": "RATE_LIMITED", "message": "Too many requests"})
+		return
+	}
+
+	c.Next()
+}
+
+// callerKey identifies the caller by their identity, or for anonymous
+// requests, their IP address.
+func callerKey(c *gin.Context) string {
+	if identity, err := auth.IdentityFromContext(c); err == nil {
+		return "id:" + identity.String()
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,104 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	m "github.com/jtyers/tmaas-model"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/ratelimit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	config := ratelimit.Config{
+		Default: ratelimit.Limit{Rate: 1, Burst: 2},
+		Routes: map[string]ratelimit.Limit{
+			"GET " + UrlPrefix: {Rate: 0.5, Burst: 1},
+		},
+	}
+
+	var tests = []struct {
+		name               string
+		requests           []string
+		expectedStatuses   []int
+		expectedRetryAfter string
+	}{
+		{
+			"should reject requests over the default limit",
+			[]string{UrlPrefix + "/tm-1", UrlPrefix + "/tm-2", UrlPrefix + "/tm-1"},
+			[]int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+			"1",
+		},
+		{
+			"should apply route limits separately",
+			[]string{UrlPrefix, UrlPrefix + "/tm-1", UrlPrefix},
+			[]int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+			"2",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			r := gin.New()
+			r.Use(IdentityMiddleware(auth.NewStaticIdentityExtractor(&auth.Identity{ServiceAccountName: "sa-sync"})))
+			r.Use(NewRateLimiter(ratelimit.NewMemoryStore(), config).Middleware())
+			r.GET(UrlPrefix, func(c *gin.Context) { c.Status(http.StatusOK) })
+			r.GET(UrlPrefix+"/:threatModelID", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			var w *httptest.ResponseRecorder
+			for i, path := range test.requests {
+				// when
+				w = httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+				// then
+				require.Equal(t, test.expectedStatuses[i], w.Code, "request %d", i)
+			}
+
+			require.Equal(t, test.expectedRetryAfter, w.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestRateLimiterPermissions(t *testing.T) {
+	// given
+	config := ratelimit.Config{
+		Default: ratelimit.Limit{Rate: 1, Burst: 10},
+		Permissions: map[string]ratelimit.Limit{
+			string(m.PermissionEditOwnThreatModels): {Rate: 1, Burst: 1},
+		},
+	}
+	rateLimiter := NewRateLimiter(ratelimit.NewMemoryStore(), config)
+
+	r := gin.New()
+	r.Use(IdentityMiddleware(auth.NewStaticIdentityExtractor(&auth.Identity{UserID: "u-1234"})))
+	r.Use(rateLimiter.Middleware())
+	r.GET(UrlPrefix+"/:threatModelID", rateLimiter.Permission(m.PermissionReadOwnThreatModels), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.PATCH(UrlPrefix+"/:threatModelID", rateLimiter.Permission(m.PermissionEditOwnThreatModels), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.DELETE(UrlPrefix+"/:threatModelID", rateLimiter.Permission(m.PermissionEditOwnThreatModels), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	var tests = []struct {
+		method         string
+		expectedStatus int
+	}{
+		{http.MethodPatch, http.StatusOK},
+		// every route requiring the permission shares its bucket
+		{http.MethodDelete, http.StatusTooManyRequests},
+		// routes requiring other permissions are only subject to the default
+		{http.MethodGet, http.StatusOK},
+		{http.MethodGet, http.StatusOK},
+	}
+
+	for i, test := range tests {
+		// when
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(test.method, UrlPrefix+"/tm-1", nil))
+
+		// then
+		require.Equal(t, test.expectedStatus, w.Code, "request %d", i)
+	}
+}
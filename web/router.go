@@ -11,24 +11,76 @@ import (
 	corsconfig "github.com/jtyers/tmaas-cors-config"
 	m "github.com/jtyers/tmaas-model"
 	"github.com/jtyers/tmaas-service-util/log"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/dao"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
 	"github.com/jtyers/tmaas-threat-model-api/service"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
 	UrlPrefix = "/api/v1/threatmodel"
 )
 
-func NewRouter(handlers *ThreatModelHandlers, comboFactory combo.ComboMiddlewareFactory, errorsMiddlewareFactory errors.ErrorsMiddlewareFactory, corsMiddleware corsconfig.CorsMiddleware) http.Handler {
+func NewRouter(handlers *ThreatModelHandlers, commentHandlers *CommentHandlers, searchHandlers *SearchHandlers, projectHandlers *ProjectHandlers, auditHandlers *AuditHandlers, healthHandlers *HealthHandlers, graphQLHandlers *GraphQLHandlers, comboFactory combo.ComboMiddlewareFactory, errorsMiddlewareFactory errors.ErrorsMiddlewareFactory, corsMiddleware corsconfig.CorsMiddleware, identityExtractor auth.IdentityExtractor, accessChecker service.ThreatModelAccessChecker, auditor service.Auditor, rateLimiter *RateLimiter, metrics *metrics.Metrics, tracerProvider trace.TracerProvider) http.Handler {
 	r := gin.New()
+
+	// allow values placed into the request context (such as the caller's
+	// identity) to be seen by services we pass the gin.Context to
+	r.ContextWithFallback = true
+
+	// probed by the orchestrator every few seconds, so registered before any
+	// middleware: these need no token, and should not be logged, audited,
+	// rate limited or traced
+	r.GET(LivenessPath, healthHandlers.LivenessHandler)
+	r.GET(ReadinessPath, healthHandlers.ReadinessHandler)
+
+	r.Use(MetricsMiddleware(metrics))
+	r.Use(TracingMiddleware(tracerProvider))
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 	r.Use(corsMiddleware.Handler())
 
 	r.Use(comboFactory.ExtractTokensToContext())
+	r.Use(IdentityMiddleware(identityExtractor))
+	r.Use(rateLimiter.Middleware())
+	r.Use(AuditMiddleware(auditor))
 
 	r.Use(errorsMiddlewareFactory.NewErrorMiddleware([]errors.ErrorConfig{
 		errors.NewErrorConfig(errors.ForExact(service.ErrNoSuchThreatModel), errors.StatusCode(http.StatusNotFound)),
 		errors.NewErrorConfig(errors.ForExact(errors.ErrUnauthorized), errors.StatusCode(http.StatusUnauthorized)),
+		errors.NewErrorConfig(errors.ForExact(auth.ErrNoIdentity), errors.StatusCode(http.StatusUnauthorized)),
+		errors.NewErrorConfig(errors.ForExact(auth.ErrNoTenant), errors.StatusCode(http.StatusForbidden)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrNoSuchComment), errors.StatusCode(http.StatusNotFound)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrNoSuchThreat), errors.StatusCode(http.StatusNotFound)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrNotCommentAuthor), errors.StatusCode(http.StatusForbidden)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrInvalidParentComment), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrCannotResolveReply), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrEmptyComment), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrInvalidTag), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrTooManyTags), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(ErrInvalidTagMatch), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrEmptySearchQuery), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrTooManyIDs), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrThreatModelModified), errors.StatusCode(http.StatusPreconditionFailed)),
+		errors.NewErrorConfig(errors.ForExact(ErrInvalidLimit), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrNoSuchProject), errors.StatusCode(http.StatusNotFound)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrInsufficientProjectRole), errors.StatusCode(http.StatusForbidden)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrEmptyProjectName), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrProjectCycle), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrInvalidProjectRole), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrLastProjectOwner), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrProjectNotEmpty), errors.StatusCode(http.StatusConflict)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrAdminRequired), errors.StatusCode(http.StatusForbidden)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrInvalidAuditTimeRange), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrAuditKeyNotConfigured), errors.StatusCode(http.StatusNotImplemented)),
+		errors.NewErrorConfig(errors.ForExact(ErrInvalidAuditTime), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(ErrInvalidPageSize), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(dao.ErrInvalidPageToken), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(service.ErrNoSuchRevision), errors.StatusCode(http.StatusNotFound)),
+		errors.NewErrorConfig(errors.ForExact(ErrInvalidDiffSource), errors.StatusCode(http.StatusBadRequest)),
+		errors.NewErrorConfig(errors.ForExact(ErrInvalidDiffFormat), errors.StatusCode(http.StatusBadRequest)),
 		errors.NewErrorConfig(errors.ForValidationErrors(), errors.ConvertValidationErrors()),
 	}))
 
@@ -38,28 +90,194 @@ func NewRouter(handlers *ThreatModelHandlers, comboFactory combo.ComboMiddleware
 		c.JSON(404, gin.H{"code": "PAGE_NOT_FOUND", "message": "Page not found"})
 	})
 
+	// public, like any other API documentation
+	r.GET(OpenAPIPath, OpenAPIHandler)
+	r.GET(DocsPath, DocsHandler)
+
+	// resolvers apply the same permission checks as the routes below
+	r.POST(GraphQLPath,
+		comboFactory.StrictPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		graphQLHandlers.GraphQLHandler,
+	)
+
 	r.PUT(UrlPrefix,
 		comboFactory.StrictUserPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
 		handlers.PutThreatModelHandler,
 	)
 
 	r.GET(UrlPrefix,
 		comboFactory.StrictUserPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
 		handlers.GetThreatModelsHandler,
 	)
+	r.POST(UrlPrefix+":method",
+		comboFactory.StrictPermission(m.PermissionReadOwnThreatModels), // Permit service accounts to access this
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		CustomMethods(map[string]gin.HandlerFunc{
+			BatchGetMethod: handlers.BatchGetThreatModelsHandler,
+		}),
+	)
+	r.GET(UrlPrefix+"/tags",
+		comboFactory.StrictUserPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		handlers.GetAllTagsHandler,
+	)
+	r.GET(UrlPrefix+"/search",
+		comboFactory.StrictUserPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		searchHandlers.SearchHandler,
+	)
 	r.GET(UrlPrefix+"/:threatModelID",
 		comboFactory.StrictPermission(m.PermissionReadOwnThreatModels), // Permit service accounts to access this
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		RequireThreatModelRole(accessChecker, tm.ProjectRoleViewer),
 		handlers.GetThreatModelHandler,
 	)
 
 	r.DELETE(UrlPrefix+"/:threatModelID",
 		comboFactory.StrictUserPermission(m.PermissionEditOwnThreatModels),
+		rateLimiter.Permission(m.PermissionEditOwnThreatModels),
+		RequireThreatModelRole(accessChecker, tm.ProjectRoleEditor),
 		handlers.DeleteThreatModelHandler,
 	)
 	r.PATCH(UrlPrefix+"/:threatModelID",
 		comboFactory.StrictPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		RequireThreatModelRole(accessChecker, tm.ProjectRoleEditor),
 		handlers.PatchThreatModelHandler,
 	)
 
+	r.GET(UrlPrefix+"/:threatModelID/tags",
+		comboFactory.StrictPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		RequireThreatModelRole(accessChecker, tm.ProjectRoleViewer),
+		handlers.GetTagsHandler,
+	)
+	r.PUT(UrlPrefix+"/:threatModelID/tags",
+		comboFactory.StrictUserPermission(m.PermissionEditOwnThreatModels),
+		rateLimiter.Permission(m.PermissionEditOwnThreatModels),
+		RequireThreatModelRole(accessChecker, tm.ProjectRoleEditor),
+		handlers.PutTagsHandler,
+	)
+
+	r.GET(UrlPrefix+"/:threatModelID/diff",
+		comboFactory.StrictPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		RequireThreatModelRole(accessChecker, tm.ProjectRoleViewer),
+		handlers.DiffThreatModelHandler,
+	)
+
+	r.GET(UrlPrefix+"/:threatModelID/comments",
+		comboFactory.StrictUserPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		RequireThreatModelRole(accessChecker, tm.ProjectRoleViewer),
+		commentHandlers.GetCommentsHandler,
+	)
+	r.POST(UrlPrefix+"/:threatModelID/comments",
+		comboFactory.StrictUserPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		RequireThreatModelRole(accessChecker, tm.ProjectRoleViewer),
+		commentHandlers.PostCommentHandler,
+	)
+	r.PATCH(UrlPrefix+"/:threatModelID/comments/:commentID",
+		comboFactory.StrictUserPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		RequireThreatModelRole(accessChecker, tm.ProjectRoleViewer),
+		commentHandlers.PatchCommentHandler,
+	)
+	r.DELETE(UrlPrefix+"/:threatModelID/comments/:commentID",
+		comboFactory.StrictUserPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		RequireThreatModelRole(accessChecker, tm.ProjectRoleViewer),
+		commentHandlers.DeleteCommentHandler,
+	)
+	r.POST(UrlPrefix+"/:threatModelID/comments/:commentID/resolve",
+		comboFactory.StrictUserPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		RequireThreatModelRole(accessChecker, tm.ProjectRoleViewer),
+		commentHandlers.ResolveCommentHandler,
+	)
+	r.POST(UrlPrefix+"/:threatModelID/comments/:commentID/unresolve",
+		comboFactory.StrictUserPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		RequireThreatModelRole(accessChecker, tm.ProjectRoleViewer),
+		commentHandlers.UnresolveCommentHandler,
+	)
+	r.GET(UrlPrefix+"/:threatModelID/threats/:threatID/comments",
+		comboFactory.StrictUserPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		RequireThreatModelRole(accessChecker, tm.ProjectRoleViewer),
+		commentHandlers.GetThreatCommentsHandler,
+	)
+	r.POST(UrlPrefix+"/:threatModelID/threats/:threatID/comments",
+		comboFactory.StrictUserPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		RequireThreatModelRole(accessChecker, tm.ProjectRoleViewer),
+		commentHandlers.PostThreatCommentHandler,
+	)
+
+	r.GET(UrlPrefix+"/:threatModelID/audit/verify",
+		comboFactory.StrictPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		RequireAdmin(),
+		auditHandlers.VerifyThreatModelAuditHandler,
+	)
+
+	r.PUT(UrlPrefix+"/:threatModelID/project",
+		comboFactory.StrictUserPermission(m.PermissionEditOwnThreatModels),
+		rateLimiter.Permission(m.PermissionEditOwnThreatModels),
+		projectHandlers.PutThreatModelProjectHandler,
+	)
+
+	r.GET(ProjectUrlPrefix,
+		comboFactory.StrictUserPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		projectHandlers.GetProjectsHandler,
+	)
+	r.POST(ProjectUrlPrefix,
+		comboFactory.StrictUserPermission(m.PermissionEditOwnThreatModels),
+		rateLimiter.Permission(m.PermissionEditOwnThreatModels),
+		projectHandlers.PostProjectHandler,
+	)
+	r.GET(ProjectUrlPrefix+"/:projectID",
+		comboFactory.StrictPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		projectHandlers.GetProjectHandler,
+	)
+	r.PATCH(ProjectUrlPrefix+"/:projectID",
+		comboFactory.StrictUserPermission(m.PermissionEditOwnThreatModels),
+		rateLimiter.Permission(m.PermissionEditOwnThreatModels),
+		projectHandlers.PatchProjectHandler,
+	)
+	r.DELETE(ProjectUrlPrefix+"/:projectID",
+		comboFactory.StrictUserPermission(m.PermissionEditOwnThreatModels),
+		rateLimiter.Permission(m.PermissionEditOwnThreatModels),
+		projectHandlers.DeleteProjectHandler,
+	)
+	r.PUT(ProjectUrlPrefix+"/:projectID/members/:userID",
+		comboFactory.StrictUserPermission(m.PermissionEditOwnThreatModels),
+		rateLimiter.Permission(m.PermissionEditOwnThreatModels),
+		projectHandlers.PutMemberHandler,
+	)
+	r.DELETE(ProjectUrlPrefix+"/:projectID/members/:userID",
+		comboFactory.StrictUserPermission(m.PermissionEditOwnThreatModels),
+		rateLimiter.Permission(m.PermissionEditOwnThreatModels),
+		projectHandlers.DeleteMemberHandler,
+	)
+	r.GET(ProjectUrlPrefix+"/:projectID/threatmodels",
+		comboFactory.StrictPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		projectHandlers.GetProjectThreatModelsHandler,
+	)
+
+	r.GET(AuditUrlPrefix,
+		comboFactory.StrictPermission(m.PermissionReadOwnThreatModels),
+		rateLimiter.Permission(m.PermissionReadOwnThreatModels),
+		RequireAdmin(),
+		auditHandlers.GetAuditHandler,
+	)
+
 	return r
 }
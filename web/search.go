@@ -0,0 +1,9 @@
+package web
+
+import (
+	"errors"
+)
+
+var (
+	ErrInvalidLimit = errors.New("limit must be a number")
+)
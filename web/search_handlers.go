@@ -0,0 +1,47 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	tm "github.com/jtyers/tmaas-threat-model-api/model"
+	"github.com/jtyers/tmaas-threat-model-api/service"
+)
+
+type SearchHandlers struct {
+	searchService service.ThreatModelSearchService
+}
+
+func NewSearchHandlers(ss service.ThreatModelSearchService) *SearchHandlers {
+	return &SearchHandlers{searchService: ss}
+}
+
+// @Summary Searches the titles, descriptions, threats and mitigations of threat models visible to the user
+// @Produce json
+// @Param q query string true "The search terms; threat models must contain all of them"
+// @Param limit query int false "The maximum number of results to return (default 20, maximum 100)"
+// @Security firebase
+// @Success 200 {array} tm.SearchResult "The matching threat models, best match first, with highlighted snippets"
+// @Failure 400 {string} string "If the query is empty or limit is not a number."
+// @Failure 401 {string} string "If the token supplied is invalid, expired or does not have access to call this API."
+// @Router /api/v1/threatmodel/search [get]
+func (sh *SearchHandlers) SearchHandler(c *gin.Context) {
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			c.Error(ErrInvalidLimit)
+			return
+		}
+	}
+
+	var result []*tm.SearchResult
+	result, err := sh.searchService.Search(c, c.Query("q"), limit)
+	if err != nil {
+		c.Error(err)
+	} else {
+		c.JSON(http.StatusOK, result)
+	}
+}
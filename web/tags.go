@@ -0,0 +1,26 @@
+package web
+
+import (
+	"errors"
+)
+
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+var (
+	ErrInvalidTagMatch = errors.New("tagMatch must be 'any' or 'all'")
+)
+
+// parseTagMatch converts the tagMatch query parameter into whether all
+// tags must match.
+func parseTagMatch(tagMatch string) (bool, error) {
+	switch tagMatch {
+	case TagMatchAny:
+		return false, nil
+	case TagMatchAll:
+		return true, nil
+	}
+	return false, ErrInvalidTagMatch
+}
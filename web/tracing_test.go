@@ -0,0 +1,55 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	// given
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previousPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previousPropagator)
+
+	var handlerSpan trace.SpanContext
+
+	r := gin.New()
+	r.ContextWithFallback = true
+	r.Use(TracingMiddleware(tp))
+	r.GET(UrlPrefix+"/:threatModelID", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c)
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, UrlPrefix+"/tm-1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// when
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	// then
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	require.Equal(t, "GET "+UrlPrefix+"/:threatModelID", span.Name())
+	require.Equal(t, trace.SpanKindServer, span.SpanKind())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	require.Equal(t, "Error", span.Status().Code.String())
+
+	// handlers (and the services they call) see the request's span
+	require.Equal(t, span.SpanContext().SpanID(), handlerSpan.SpanID())
+}
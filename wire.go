@@ -4,19 +4,30 @@
 package main
 
 import (
-	"net/http"
-
 	"github.com/google/wire"
 	"github.com/jtyers/tmaas-threat-model-api/dao"
+	"github.com/jtyers/tmaas-threat-model-api/gql"
+	"github.com/jtyers/tmaas-threat-model-api/grpcapi"
+	"github.com/jtyers/tmaas-threat-model-api/health"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	"github.com/jtyers/tmaas-threat-model-api/outbox"
 	"github.com/jtyers/tmaas-threat-model-api/service"
+	"github.com/jtyers/tmaas-threat-model-api/tracing"
 	"github.com/jtyers/tmaas-threat-model-api/web"
 )
 
-func InitialiseRouter() (http.Handler, error) {
+func InitialiseApp() (*App, func(), error) {
 	wire.Build(
+		metrics.MetricsProviderSet,
+		tracing.TracingProviderSet,
 		dao.ThreatModelDaoProviderSet,
+		health.HealthProviderSet,
 		service.ThreatModelServiceProviderSet,
 		web.ThreatModelWebProviderSet,
+		gql.GraphQLProviderSet,
+		grpcapi.GRPCProviderSet,
+		outbox.OutboxProviderSet,
+		NewApp,
 	)
-	return nil, nil
+	return nil, nil, nil
 }
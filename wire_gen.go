@@ -16,65 +16,211 @@ import (
 	"github.com/jtyers/tmaas-model/validator"
 	"github.com/jtyers/tmaas-service-dao/datastore"
 	"github.com/jtyers/tmaas-service-util/id"
-	"github.com/jtyers/tmaas-service-util/idchecker"
-	"github.com/jtyers/tmaas-service-util/requestor"
+	"github.com/jtyers/tmaas-threat-model-api/auth"
+	"github.com/jtyers/tmaas-threat-model-api/cache"
 	"github.com/jtyers/tmaas-threat-model-api/dao"
+	"github.com/jtyers/tmaas-threat-model-api/events"
+	"github.com/jtyers/tmaas-threat-model-api/gql"
+	"github.com/jtyers/tmaas-threat-model-api/grpcapi"
+	"github.com/jtyers/tmaas-threat-model-api/health"
+	"github.com/jtyers/tmaas-threat-model-api/httpclient"
+	"github.com/jtyers/tmaas-threat-model-api/metrics"
+	"github.com/jtyers/tmaas-threat-model-api/outbox"
+	"github.com/jtyers/tmaas-threat-model-api/ratelimit"
 	"github.com/jtyers/tmaas-threat-model-api/service"
+	"github.com/jtyers/tmaas-threat-model-api/tracing"
 	"github.com/jtyers/tmaas-threat-model-api/web"
-	"net/http"
 )
 
 // Injectors from wire.go:
 
-func InitialiseRouter() (http.Handler, error) {
+func InitialiseApp() (*App, func(), error) {
 	context := datastore.NewContext()
 	datastoreConfiguration := dao.NewDatastoreConfig()
-	datastoreClient, err := datastore.NewDatastoreClient(context, datastoreConfiguration)
+	datastoreClient, cleanup, err := dao.NewDatastoreClient(context, datastoreConfiguration)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	randomIDProviderPrefix := dao.NewThreatModelRandomIDProviderPrefix()
 	defaultRandomIDProvider := id.NewDefaultRandomIDProvider(randomIDProviderPrefix)
 	threatModelIDCreator := dao.NewThreatModelIDCreator()
-	threatModelDao, err := dao.NewThreatModelDao(datastoreClient, defaultRandomIDProvider, datastoreConfiguration, threatModelIDCreator)
+	datastoreThreatModelDao, err := dao.NewThreatModelDao(datastoreClient, defaultRandomIDProvider, datastoreConfiguration, threatModelIDCreator)
 	if err != nil {
-		return nil, err
+		cleanup()
+		return nil, nil, err
 	}
+	metricsMetrics := metrics.NewMetrics()
+	instrumentedThreatModelDao := dao.NewInstrumentedThreatModelDao(datastoreThreatModelDao, metricsMetrics)
 	defaultStructValidator, err := validator.NewDefaultStructValidator()
 	if err != nil {
-		return nil, err
+		cleanup()
+		return nil, nil, err
 	}
 	dataFlowDiagramServiceClientConfig := client.NewDataFlowDiagramServiceClientConfig()
-	defaultRequestorWithContext := requestor.NewDefaultRequestorWithContext()
-	dataFlowDiagramServiceClient := client.NewDataFlowDiagramServiceClient(dataFlowDiagramServiceClientConfig, defaultRequestorWithContext)
+	httpClient := tracing.NewHTTPClient()
+	requestor := httpclient.NewRequestor(httpClient)
+	dataFlowDiagramServiceClient := client.NewDataFlowDiagramServiceClient(dataFlowDiagramServiceClientConfig, requestor)
 	clientDataFlowDiagramIDChecker := client.NewClientDataFlowDiagramIDChecker(dataFlowDiagramServiceClient)
-	idCheckerForTypes := service.NewIDCheckerForTypes(clientDataFlowDiagramIDChecker)
-	defaultIDChecker := idchecker.NewDefaultIDChecker(idCheckerForTypes)
-	defaultThreatModelService := service.NewDefaultThreatModelService(threatModelDao, defaultStructValidator, defaultIDChecker)
-	threatModelHandlers := web.NewThreatModelHandlers(defaultThreatModelService)
+	datastoreProjectDao := dao.NewDatastoreProjectDao(datastoreClient)
+	projectAccessChecker := service.NewProjectAccessChecker(datastoreProjectDao)
+	daoProjectIDChecker := service.NewDaoProjectIDChecker(datastoreProjectDao)
+	dataFlowDiagramIDChecker := service.NewDataFlowDiagramIDChecker(clientDataFlowDiagramIDChecker)
+	idCheckerForTypes := service.NewIDCheckerForTypes(dataFlowDiagramIDChecker, daoProjectIDChecker, metricsMetrics)
+	batchingIDChecker := service.NewBatchingIDChecker(idCheckerForTypes)
+	datastoreSearchIndex := dao.NewDatastoreSearchIndex(datastoreClient)
+	datastoreAuditDao := dao.NewDatastoreAuditDao(datastoreClient)
+	auditKeyConfig := service.NewAuditKeyConfig()
+	auditKey, err := service.NewAuditKey(context, auditKeyConfig)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	auditWriter := service.NewAuditWriter(datastoreAuditDao, auditKey, metricsMetrics)
+	defaultAuditService := service.NewDefaultAuditService(datastoreAuditDao, auditWriter, auditKey)
+	indexingThreatModelSearchService := service.NewIndexingThreatModelSearchService(instrumentedThreatModelDao, datastoreSearchIndex, projectAccessChecker, defaultAuditService)
+	threatModelWriteHooks := service.NewThreatModelWriteHooks(indexingThreatModelSearchService)
+	defaultThreatModelService := service.NewDefaultThreatModelService(instrumentedThreatModelDao, defaultStructValidator, batchingIDChecker, threatModelWriteHooks)
+	cacheConfig, err := cache.NewConfig()
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	cacheCache, cleanup2 := cache.NewCache(cacheConfig)
+	cachingThreatModelService := service.NewCachingThreatModelService(defaultThreatModelService, cacheCache, cacheConfig, metricsMetrics)
+	auditingThreatModelService := service.NewAuditingThreatModelService(cachingThreatModelService, defaultAuditService)
+	instrumentedThreatModelService := service.NewInstrumentedThreatModelService(auditingThreatModelService, metricsMetrics)
+	defaultThreatModelTagService := service.NewDefaultThreatModelTagService(instrumentedThreatModelDao, instrumentedThreatModelService, projectAccessChecker)
+	defaultProjectService := service.NewDefaultProjectService(datastoreProjectDao, instrumentedThreatModelService, defaultStructValidator, batchingIDChecker)
+	datastoreOutboxDao := dao.NewDatastoreOutboxDao(datastoreClient)
+	outboxThreatModelRevisionService := service.NewOutboxThreatModelRevisionService(datastoreOutboxDao)
+	threatModelHandlers := web.NewThreatModelHandlers(instrumentedThreatModelService, defaultThreatModelTagService, outboxThreatModelRevisionService, projectAccessChecker, defaultAuditService)
+	datastoreCommentDao := dao.NewDatastoreCommentDao(datastoreClient)
+	defaultCommentService := service.NewDefaultCommentService(datastoreCommentDao, instrumentedThreatModelService, defaultStructValidator)
+	commentHandlers := web.NewCommentHandlers(defaultCommentService)
+	searchHandlers := web.NewSearchHandlers(indexingThreatModelSearchService)
+	projectHandlers := web.NewProjectHandlers(defaultProjectService)
+	auditHandlers := web.NewAuditHandlers(defaultAuditService)
 	iamClient, err := extractor.NewIamClient(context)
 	if err != nil {
-		return nil, err
+		cleanup2()
+		cleanup()
+		return nil, nil, err
 	}
 	defaultVerifier := extractor.NewDefaultVerifier(iamClient)
 	defaultExtractor := extractor.NewDefaultExtractor(defaultVerifier)
 	app, err := extractor2.NewFirebaseApp(context)
 	if err != nil {
-		return nil, err
+		cleanup2()
+		cleanup()
+		return nil, nil, err
 	}
 	authClient, err := extractor2.NewFirebaseAuthClient(context, app)
 	if err != nil {
-		return nil, err
+		cleanup2()
+		cleanup()
+		return nil, nil, err
 	}
 	defaultFirebaseVerifier := extractor2.NewDefaultFirebaseVerifier(authClient)
 	defaultFirebaseExtractor := extractor2.NewDefaultFirebaseExtractor(defaultFirebaseVerifier)
 	serviceAccountPermissionsJson := combo.NewServiceAccountPermissionsJson()
 	defaultComboMiddlewareFactory, err := combo.NewDefaultComboMiddlewareFactory(defaultExtractor, defaultFirebaseExtractor, serviceAccountPermissionsJson)
 	if err != nil {
-		return nil, err
+		cleanup2()
+		cleanup()
+		return nil, nil, err
 	}
 	defaultErrorsMiddlewareFactory := errors.NewDefaultErrorsMiddlewareFactory()
 	corsMiddleware := corsconfig.FromEnv()
-	handler := web.NewRouter(threatModelHandlers, defaultComboMiddlewareFactory, defaultErrorsMiddlewareFactory, corsMiddleware)
-	return handler, nil
+	tenantConfig, err := auth.NewTenantConfig()
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	claimsIdentityExtractor := auth.NewClaimsIdentityExtractor(tenantConfig)
+	config, err := ratelimit.NewConfig()
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	store, cleanup3 := ratelimit.NewStore(config)
+	rateLimiter := web.NewRateLimiter(store, config)
+	tracingConfig, err := tracing.NewConfig()
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	tracerProvider, cleanup4, err := tracing.NewTracerProvider(context, tracingConfig)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	checkTimeout, err := health.NewCheckTimeout()
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	checker := health.NewReadinessChecker(checkTimeout, instrumentedThreatModelDao, dataFlowDiagramServiceClient)
+	healthHandlers := web.NewHealthHandlers(checker)
+	permissionChecker := auth.NewPermissionChecker(defaultComboMiddlewareFactory, claimsIdentityExtractor)
+	resolver := gql.NewResolver(instrumentedThreatModelService, projectAccessChecker, permissionChecker, defaultAuditService)
+	schema, err := gql.NewSchema(resolver)
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	gqlHandler := gql.NewHandler(schema, dataFlowDiagramServiceClient)
+	graphQLHandlers := web.NewGraphQLHandlers(gqlHandler)
+	handler := web.NewRouter(threatModelHandlers, commentHandlers, searchHandlers, projectHandlers, auditHandlers, healthHandlers, graphQLHandlers, defaultComboMiddlewareFactory, defaultErrorsMiddlewareFactory, corsMiddleware, claimsIdentityExtractor, projectAccessChecker, defaultAuditService, rateLimiter, metricsMetrics, tracerProvider)
+	threatModelServer := grpcapi.NewThreatModelServer(instrumentedThreatModelService, projectAccessChecker, defaultAuditService)
+	authenticator := grpcapi.NewAuthenticator(permissionChecker)
+	grpcapiRateLimiter := grpcapi.NewRateLimiter(store, config)
+	server := grpcapi.NewGRPCServer(threatModelServer, authenticator, grpcapiRateLimiter, metricsMetrics, tracerProvider)
+	eventsConfig := events.NewConfig()
+	subscriber, err := events.NewSubscriber(context, eventsConfig)
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	dataFlowDiagramEventHandler := service.NewDataFlowDiagramEventHandler(instrumentedThreatModelService, defaultThreatModelTagService, metricsMetrics)
+	consumer := events.NewConsumer(subscriber, dataFlowDiagramEventHandler)
+	outboxConfig, err := outbox.NewConfig()
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	publisher, err := outbox.NewPublisher(context, outboxConfig)
+	if err != nil {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	relay := outbox.NewRelay(datastoreOutboxDao, publisher, outboxConfig, metricsMetrics)
+	threatModelCounter := service.NewThreatModelCounter(instrumentedThreatModelDao, metricsMetrics)
+	mainApp := NewApp(handler, server, consumer, relay, auditWriter, metricsMetrics, threatModelCounter)
+	return mainApp, func() {
+		cleanup4()
+		cleanup3()
+		cleanup2()
+		cleanup()
+	}, nil
 }